@@ -0,0 +1,3 @@
+ALTER TABLE tasks
+    ADD COLUMN status       varchar(32) not null default 'todo',
+    ADD COLUMN completed_at timestamp
@@ -0,0 +1,5 @@
+ALTER TABLE tasks
+    ADD COLUMN start_at timestamp,
+    ADD COLUMN due_at   timestamp;
+
+CREATE INDEX tasks_user_id_due_at_idx ON tasks (user_id, due_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS tasks_user_id_status_idx;
+DROP INDEX IF EXISTS tasks_user_id_updated_at_idx;
+DROP INDEX IF EXISTS tasks_user_id_created_at_idx;
@@ -0,0 +1,3 @@
+CREATE INDEX tasks_user_id_created_at_idx ON tasks (user_id, created_at, id);
+CREATE INDEX tasks_user_id_updated_at_idx ON tasks (user_id, updated_at, id);
+CREATE INDEX tasks_user_id_status_idx ON tasks (user_id, status);
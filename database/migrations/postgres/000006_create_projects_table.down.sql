@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS tasks_user_id_project_id_idx;
+
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS project_id;
+
+DROP TABLE IF EXISTS projects;
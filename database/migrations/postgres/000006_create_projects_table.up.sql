@@ -0,0 +1,13 @@
+CREATE TABLE projects
+(
+    id         serial                                      not null unique,
+    user_id    int references users (id) on delete cascade not null,
+    name       varchar(255)                                not null,
+    created_at timestamp,
+    updated_at timestamp
+);
+
+ALTER TABLE tasks
+    ADD COLUMN project_id int references projects (id) on delete set null;
+
+CREATE INDEX tasks_user_id_project_id_idx ON tasks (user_id, project_id);
@@ -0,0 +1,18 @@
+CREATE TABLE labels
+(
+    id         serial                                      not null unique,
+    user_id    int references users (id) on delete cascade not null,
+    name       varchar(64)                                 not null,
+    color      varchar(7)                                  not null default '',
+    created_at timestamp,
+    unique (user_id, name)
+);
+
+CREATE TABLE task_labels
+(
+    task_id  int references tasks (id) on delete cascade  not null,
+    label_id int references labels (id) on delete cascade not null,
+    primary key (task_id, label_id)
+);
+
+CREATE INDEX task_labels_label_id_idx ON task_labels (label_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS tasks_user_id_parent_id_idx;
+
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,4 @@
+ALTER TABLE tasks
+    ADD COLUMN parent_id int references tasks (id) on delete cascade;
+
+CREATE INDEX tasks_user_id_parent_id_idx ON tasks (user_id, parent_id);
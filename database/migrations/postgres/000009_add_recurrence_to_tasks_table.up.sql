@@ -0,0 +1,2 @@
+ALTER TABLE tasks
+    ADD COLUMN recurrence varchar(255) not null default '';
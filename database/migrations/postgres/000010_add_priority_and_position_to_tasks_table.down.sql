@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS tasks_user_id_priority_idx;
+DROP INDEX IF EXISTS tasks_user_id_position_idx;
+
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS position,
+    DROP COLUMN IF EXISTS priority;
@@ -0,0 +1,11 @@
+ALTER TABLE tasks
+    ADD COLUMN priority smallint         not null default 4,
+    ADD COLUMN position double precision not null default 0;
+
+UPDATE tasks
+SET position = ranked.rn * 1024
+FROM (SELECT id, row_number() OVER (PARTITION BY user_id ORDER BY created_at, id) AS rn FROM tasks) AS ranked
+WHERE tasks.id = ranked.id;
+
+CREATE INDEX tasks_user_id_position_idx ON tasks (user_id, position, id);
+CREATE INDEX tasks_user_id_priority_idx ON tasks (user_id, priority, id);
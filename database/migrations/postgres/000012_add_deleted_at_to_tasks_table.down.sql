@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS tasks_user_id_deleted_at_idx;
+DROP INDEX IF EXISTS tasks_deleted_at_idx;
+
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS deleted_at;
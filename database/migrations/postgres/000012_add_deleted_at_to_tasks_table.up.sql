@@ -0,0 +1,5 @@
+ALTER TABLE tasks
+    ADD COLUMN deleted_at timestamp;
+
+CREATE INDEX tasks_deleted_at_idx ON tasks (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX tasks_user_id_deleted_at_idx ON tasks (user_id, deleted_at) WHERE deleted_at IS NOT NULL;
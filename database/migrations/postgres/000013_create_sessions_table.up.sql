@@ -0,0 +1,12 @@
+CREATE TABLE sessions
+(
+    id         serial                                      not null unique,
+    user_id    int references users (id) on delete cascade not null,
+    family_id  varchar(64)                                 not null,
+    token_hash varchar(64)                                 not null unique,
+    expires_at timestamp                                   not null,
+    revoked_at timestamp,
+    created_at timestamp
+);
+
+CREATE INDEX sessions_family_id_idx ON sessions (family_id);
@@ -0,0 +1,15 @@
+CREATE TABLE revoked_tokens
+(
+    token_id   varchar(64) not null primary key,
+    expires_at timestamp   not null
+);
+
+CREATE INDEX revoked_tokens_expires_at_idx ON revoked_tokens (expires_at);
+
+CREATE TABLE user_token_revocations
+(
+    user_id        int references users (id) on delete cascade not null primary key,
+    revoked_before timestamp                                   not null
+);
+
+CREATE INDEX sessions_user_id_idx ON sessions (user_id);
@@ -0,0 +1,13 @@
+CREATE TABLE access_tokens
+(
+    id           serial                                      not null unique,
+    user_id      int references users (id) on delete cascade not null,
+    name         varchar(64)                                 not null,
+    scopes       text[]                                      not null,
+    token_hash   varchar(64)                                 not null unique,
+    expires_at   timestamp,
+    last_used_at timestamp,
+    created_at   timestamp
+);
+
+CREATE INDEX access_tokens_user_id_idx ON access_tokens (user_id);
@@ -0,0 +1,4 @@
+ALTER TABLE users
+    ADD COLUMN totp_secret    varchar(64) not null default '',
+    ADD COLUMN totp_enabled   boolean     not null default false,
+    ADD COLUMN recovery_codes text[]      not null default '{}';
@@ -0,0 +1,10 @@
+CREATE TABLE login_attempts
+(
+    key             varchar(320) not null primary key,
+    failures        int          not null,
+    last_failure_at timestamp    not null,
+    locked_until    timestamp,
+    expires_at      timestamp    not null
+);
+
+CREATE INDEX login_attempts_expires_at_idx ON login_attempts (expires_at);
@@ -0,0 +1,5 @@
+ALTER TABLE users
+    ADD COLUMN email          varchar(320),
+    ADD COLUMN email_verified boolean not null default false;
+
+CREATE UNIQUE INDEX users_email_idx ON users (email);
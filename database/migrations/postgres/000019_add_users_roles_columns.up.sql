@@ -0,0 +1,3 @@
+ALTER TABLE users
+    ADD COLUMN roles    text[]  not null default '{}',
+    ADD COLUMN disabled boolean not null default false;
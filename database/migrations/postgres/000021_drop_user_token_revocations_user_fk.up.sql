@@ -0,0 +1,2 @@
+ALTER TABLE user_token_revocations
+    DROP CONSTRAINT IF EXISTS user_token_revocations_user_id_fkey;
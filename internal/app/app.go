@@ -2,16 +2,22 @@ package app
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/i-vasilkov/go-todo-app/internal/config"
 	delivery "github.com/i-vasilkov/go-todo-app/internal/handler/http"
-	"github.com/i-vasilkov/go-todo-app/internal/repository"
+	"github.com/i-vasilkov/go-todo-app/internal/repository/memrep"
 	"github.com/i-vasilkov/go-todo-app/internal/server"
 	"github.com/i-vasilkov/go-todo-app/internal/service"
+	"github.com/i-vasilkov/go-todo-app/internal/worker"
 	"github.com/i-vasilkov/go-todo-app/pkg/auth/jwt"
-	"github.com/i-vasilkov/go-todo-app/pkg/database/mongodb"
+	"github.com/i-vasilkov/go-todo-app/pkg/auth/opaque"
+	"github.com/i-vasilkov/go-todo-app/pkg/auth/totp"
 	"github.com/i-vasilkov/go-todo-app/pkg/hash"
+	"github.com/i-vasilkov/go-todo-app/pkg/mailer"
 	_ "github.com/lib/pq"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
@@ -35,47 +41,146 @@ func Run(cfgPath, envPath string) {
 		log.Fatal(err.Error())
 	}
 
-	mongoClient, err := mongodb.NewClient(mongodb.Connection{
-		Uri:  cfg.Mongo.GetURI(),
-		User: cfg.Mongo.UserName,
-		Pass: cfg.Mongo.Password,
-	})
+	store, err := openStorage(&cfg)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	jwtManager, err := newJwtManager(cfg.Jwt)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	mail, err := newMailer(cfg.Mail)
 	if err != nil {
 		log.Fatal(err.Error())
 	}
-	db := mongoClient.Database(cfg.Mongo.DbName)
 
 	deps := service.Dependencies{
-		Hasher:     hash.NewSHA1Hasher(cfg.Auth.PwdSalt),
-		JwtManager: jwt.NewManager(cfg.Jwt.Ttl, cfg.Jwt.Signature),
+		Hasher:              hash.NewArgon2idHasher(hash.DefaultArgon2idParams, hash.NewSHA1Hasher(cfg.Auth.PwdSalt)),
+		JwtManager:          jwtManager,
+		RefreshTokenManager: opaque.NewManager(opaque.DefaultTokenSize),
+		RefreshTokenTtl:     cfg.Jwt.RefreshTtl,
+		AccessTokenManager:  opaque.NewManager(opaque.DefaultTokenSize),
+		TotpManager:         totp.NewManager(cfg.Auth.TotpIssuer),
+		MfaChallengeTtl:     cfg.Auth.MfaChallengeTtl,
+		SignInLimits: service.SignInLimits{
+			Window:         cfg.Auth.SignIn.Window,
+			BackoffAfter:   cfg.Auth.SignIn.BackoffAfter,
+			IpBackoffAfter: cfg.Auth.SignIn.IpBackoffAfter,
+			BackoffBase:    cfg.Auth.SignIn.BackoffBase,
+			BackoffMax:     cfg.Auth.SignIn.BackoffMax,
+			LockAfter:      cfg.Auth.SignIn.LockAfter,
+			LockDuration:   cfg.Auth.SignIn.LockDuration,
+		},
+		Mailer:               mail,
+		AppUrl:               cfg.Mail.AppUrl,
+		PasswordResetTtl:     cfg.Auth.PasswordResetTtl,
+		EmailVerificationTtl: cfg.Auth.EmailVerificationTtl,
+		TaskMaxDepth:         cfg.Task.MaxDepth,
 	}
 
-	repBuilder := repository.NewMongoRepositoriesBuilder(db)
-	serviceBuilder := service.NewAppServiceBuilder(&deps, repBuilder.Build())
-	handler := delivery.NewHandler(serviceBuilder.Build())
+	reps := store.reps
+	if cfg.Auth.RevocationStore == config.AuthStoreMemory {
+		reps.Revocation = memrep.NewRevocationRepository()
+	}
+	if cfg.Auth.AttemptStore == config.AuthStoreMemory {
+		reps.LoginAttempt = memrep.NewLoginAttemptRepository()
+	}
+
+	serviceBuilder := service.NewAppServiceBuilder(&deps, reps)
+	services := serviceBuilder.Build()
+	handler := delivery.NewHandler(services)
+	if err := handler.SetTrustedProxies(cfg.Http.TrustedProxies); err != nil {
+		log.Fatal(err.Error())
+	}
 
 	srv := server.NewServer(handler.Init(), &cfg)
 	go func() {
-		if err := srv.Run(); err != nil {
+		if err := srv.Run(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatal(err.Error())
 		}
 	}()
 
+	trashPurger := worker.NewTrashPurger(services.Task, &cfg)
+	go trashPurger.Run()
+
 	// Graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
 
 	<-quit
 
-	timeout := time.Second * 5
-	ctx, shutdown := context.WithTimeout(context.Background(), timeout)
-	defer shutdown()
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
 
+	shutdown(ctx, srv, trashPurger, store)
+}
+
+const shutdownTimeout = time.Second * 5
+
+// shutdown stops taking requests, waits for the running ones and the worker, and only then
+// closes the storage they use. A step that fails is logged and the rest still run.
+func shutdown(ctx context.Context, srv *server.Server, trashPurger *worker.TrashPurger, store storage) {
 	if err := srv.Stop(ctx); err != nil {
-		log.Fatal(err.Error())
+		log.Printf("stopping the server: %s", err)
 	}
 
-	if err := mongoClient.Disconnect(context.Background()); err != nil {
-		log.Fatal(err.Error())
+	if err := trashPurger.Stop(ctx); err != nil {
+		log.Printf("stopping the trash purger: %s", err)
 	}
+
+	if err := store.close(ctx); err != nil {
+		log.Printf("closing the %s storage: %s", store.driver, err)
+	}
+}
+
+func newMailer(cfg config.MailConfig) (mailer.Mailer, error) {
+	switch cfg.Driver {
+	case config.MailDriverSMTP:
+		return mailer.NewSMTPMailer(cfg.Host, cfg.Port, cfg.Username, cfg.Password, cfg.From), nil
+	case config.MailDriverFile:
+		return mailer.NewFileMailer(cfg.Dir, cfg.From), nil
+	case config.MailDriverLog:
+		return mailer.NewLogMailer(log.Default(), cfg.From), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.Driver)
+	}
+}
+
+// newJwtManager builds the key set from the configured key files and the legacy secret.
+func newJwtManager(cfg config.JwtConfig) (*jwt.Manager, error) {
+	var keys []jwt.Key
+	if cfg.Signature != "" {
+		keys = append(keys, jwt.NewHMACKey("", []byte(cfg.Signature), cfg.SignatureRetiresAt))
+	}
+
+	for _, keyCfg := range cfg.Keys {
+		var privatePEM, publicPEM []byte
+		var err error
+
+		if keyCfg.PrivateKeyFile != "" {
+			if privatePEM, err = os.ReadFile(keyCfg.PrivateKeyFile); err != nil {
+				return nil, err
+			}
+		}
+		if keyCfg.PublicKeyFile != "" {
+			if publicPEM, err = os.ReadFile(keyCfg.PublicKeyFile); err != nil {
+				return nil, err
+			}
+		}
+
+		key, err := jwt.ParseKey(keyCfg.Id, keyCfg.Algorithm, privatePEM, publicPEM, keyCfg.RetiresAt)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	keySet, err := jwt.NewKeySet(cfg.SigningKey, keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	return jwt.NewManager(cfg.Ttl, keySet), nil
 }
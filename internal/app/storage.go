@@ -0,0 +1,94 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"github.com/i-vasilkov/go-todo-app/internal/config"
+	"github.com/i-vasilkov/go-todo-app/internal/repository"
+	"github.com/i-vasilkov/go-todo-app/internal/repository/memrep"
+	"github.com/i-vasilkov/go-todo-app/internal/repository/mongorep"
+	"github.com/i-vasilkov/go-todo-app/internal/service"
+	"github.com/i-vasilkov/go-todo-app/pkg/database/mongodb"
+	"github.com/i-vasilkov/go-todo-app/pkg/database/postgresdb"
+)
+
+// storage is the set of repositories of the configured driver, with the connection they share.
+type storage struct {
+	driver string
+	reps   *service.Repositories
+	close  func(ctx context.Context) error
+}
+
+func openStorage(cfg *config.Config) (storage, error) {
+	switch cfg.Storage.Driver {
+	case config.StorageDriverMongo:
+		return openMongoStorage(cfg.Mongo)
+	case config.StorageDriverPostgres:
+		return openPostgresStorage(cfg.Postgres)
+	case config.StorageDriverMemory:
+		return openMemoryStorage(), nil
+	default:
+		return storage{}, fmt.Errorf("unknown storage driver %q", cfg.Storage.Driver)
+	}
+}
+
+func openMongoStorage(cfg config.MongoConfig) (storage, error) {
+	client, err := mongodb.NewClient(mongodb.Connection{
+		Uri:  cfg.GetURI(),
+		User: cfg.UserName,
+		Pass: cfg.Password,
+	})
+	if err != nil {
+		return storage{}, err
+	}
+	db := client.Database(cfg.DbName)
+
+	if err := mongorep.Migrate(context.Background(), db); err != nil {
+		_ = client.Disconnect(context.Background())
+		return storage{}, err
+	}
+	if err := mongorep.EnsureIndexes(context.Background(), db); err != nil {
+		_ = client.Disconnect(context.Background())
+		return storage{}, err
+	}
+
+	return storage{
+		driver: config.StorageDriverMongo,
+		reps:   repository.NewMongoRepositoriesBuilder(db).Build(),
+		close:  client.Disconnect,
+	}, nil
+}
+
+// openPostgresStorage expects the schema to be migrated already, see `make migrate-up`.
+func openPostgresStorage(cfg config.PostgresConfig) (storage, error) {
+	db, err := postgresdb.NewDB(&postgresdb.Connection{
+		Host:     cfg.Host,
+		Port:     cfg.Port,
+		Username: cfg.User,
+		Password: cfg.Password,
+		DBName:   cfg.Database,
+		SSLMode:  cfg.SSLMode,
+	})
+	if err != nil {
+		return storage{}, err
+	}
+
+	return storage{
+		driver: config.StorageDriverPostgres,
+		reps:   repository.NewPostgresRepositoriesBuilder(db).Build(),
+		close: func(ctx context.Context) error {
+			return db.Close()
+		},
+	}, nil
+}
+
+// openMemoryStorage keeps the data in the process, it is gone once the app stops.
+func openMemoryStorage() storage {
+	return storage{
+		driver: config.StorageDriverMemory,
+		reps:   repository.NewMemoryRepositoriesBuilder(memrep.NewStore()).Build(),
+		close: func(ctx context.Context) error {
+			return nil
+		},
+	}
+}
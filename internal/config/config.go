@@ -3,16 +3,32 @@ package config
 import (
 	"errors"
 	"fmt"
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 	"time"
 )
 
 type Config struct {
+	Storage  StorageConfig
 	Mongo    MongoConfig
 	Postgres PostgresConfig
 	Http     HttpConfig
 	Auth     AuthConfig
 	Jwt      JwtConfig
+	Mail     MailConfig
+	Task     TaskConfig
+}
+
+// Storage drivers pick the database the repositories are backed by. The memory driver keeps
+// everything in the process and loses it on restart, it is meant for development and tests.
+const (
+	StorageDriverMongo    = "mongo"
+	StorageDriverPostgres = "postgres"
+	StorageDriverMemory   = "memory"
+)
+
+type StorageConfig struct {
+	Driver string `mapstructure:"driver"`
 }
 
 type MongoConfig struct {
@@ -32,19 +48,89 @@ type HttpConfig struct {
 	Port         string        `mapstructure:"HTTP_PORT"`
 	ReadTimeout  time.Duration `mapstructure:"readTimeout"`
 	WriteTimeout time.Duration `mapstructure:"writeTimeout"`
+	// TrustedProxies are the addresses or CIDR ranges whose forwarding headers tell the client address.
+	TrustedProxies []string `mapstructure:"trustedProxies"`
 }
 
 func (hc *HttpConfig) GetAddr() string {
 	return fmt.Sprintf("%s:%s", hc.Host, hc.Port)
 }
 
+// Auth stores keep revoked access tokens and failed sign ins either in the database or in memory.
+const (
+	AuthStoreDatabase = "database"
+	AuthStoreMemory   = "memory"
+)
+
 type AuthConfig struct {
-	PwdSalt string `mapstructure:"PASSWORD_SALT"`
+	// PwdSalt is only used to verify legacy SHA-1 password hashes.
+	PwdSalt         string `mapstructure:"PASSWORD_SALT"`
+	RevocationStore string `mapstructure:"revocationStore"`
+	AttemptStore    string `mapstructure:"attemptStore"`
+	// TotpIssuer names the application in authenticator apps.
+	TotpIssuer           string        `mapstructure:"totpIssuer"`
+	MfaChallengeTtl      time.Duration `mapstructure:"mfaChallengeTtl"`
+	PasswordResetTtl     time.Duration `mapstructure:"passwordResetTtl"`
+	EmailVerificationTtl time.Duration `mapstructure:"emailVerificationTtl"`
+	SignIn               SignInConfig  `mapstructure:"signIn"`
+}
+
+// SignInConfig holds the thresholds failed sign ins are throttled with, see service.SignInLimits.
+type SignInConfig struct {
+	Window         time.Duration `mapstructure:"window"`
+	BackoffAfter   int           `mapstructure:"backoffAfter"`
+	IpBackoffAfter int           `mapstructure:"ipBackoffAfter"`
+	BackoffBase    time.Duration `mapstructure:"backoffBase"`
+	BackoffMax     time.Duration `mapstructure:"backoffMax"`
+	LockAfter      int           `mapstructure:"lockAfter"`
+	LockDuration   time.Duration `mapstructure:"lockDuration"`
 }
 
+// JwtConfig describes the keys access tokens are signed with. New tokens are signed with the
+// SigningKey; the other keys only verify tokens until their RetiresAt. Signature is the legacy
+// HS256 secret: it signs when SigningKey is empty and verifies tokens without a kid header
+// until SignatureRetiresAt.
 type JwtConfig struct {
-	Signature string        `mapstructure:"JWT_SIGN"`
-	Ttl       time.Duration `mapstructure:"ttl"`
+	Signature          string         `mapstructure:"JWT_SIGN"`
+	SignatureRetiresAt time.Time      `mapstructure:"signatureRetiresAt"`
+	Ttl                time.Duration  `mapstructure:"ttl"`
+	RefreshTtl         time.Duration  `mapstructure:"refreshTtl"`
+	SigningKey         string         `mapstructure:"signingKey"`
+	Keys               []JwtKeyConfig `mapstructure:"keys"`
+}
+
+type JwtKeyConfig struct {
+	Id             string    `mapstructure:"id"`
+	Algorithm      string    `mapstructure:"algorithm"`
+	PrivateKeyFile string    `mapstructure:"privateKeyFile"`
+	PublicKeyFile  string    `mapstructure:"publicKeyFile"`
+	RetiresAt      time.Time `mapstructure:"retiresAt"`
+}
+
+// Mail drivers either send the emails or, for local development, keep them in files or the log.
+const (
+	MailDriverSMTP = "smtp"
+	MailDriverFile = "file"
+	MailDriverLog  = "log"
+)
+
+type MailConfig struct {
+	Driver   string `mapstructure:"driver"`
+	From     string `mapstructure:"from"`
+	Host     string `mapstructure:"host"`
+	Port     string `mapstructure:"port"`
+	Username string `mapstructure:"SMTP_USERNAME"`
+	Password string `mapstructure:"SMTP_PASSWORD"`
+	// Dir is where the file driver writes the emails to.
+	Dir string `mapstructure:"dir"`
+	// AppUrl is the address of the web app the links in the emails lead to.
+	AppUrl string `mapstructure:"appUrl"`
+}
+
+type TaskConfig struct {
+	MaxDepth           int           `mapstructure:"maxDepth"`
+	TrashRetention     time.Duration `mapstructure:"trashRetention"`
+	TrashPurgeInterval time.Duration `mapstructure:"trashPurgeInterval"`
 }
 
 type PostgresConfig struct {
@@ -86,6 +172,10 @@ func InitCfgFile(cfgFile string) error {
 func UnmarshalConfig() (Config, error) {
 	var cfg Config
 
+	if err := UnmarshalStorageCfg(&cfg); err != nil {
+		return cfg, err
+	}
+
 	if err := UnmarshalMongoCfg(&cfg); err != nil {
 		return cfg, err
 	}
@@ -106,9 +196,28 @@ func UnmarshalConfig() (Config, error) {
 		return cfg, err
 	}
 
+	if err := UnmarshalMailCfg(&cfg); err != nil {
+		return cfg, err
+	}
+
+	if err := UnmarshalTaskCfg(&cfg); err != nil {
+		return cfg, err
+	}
+
 	return cfg, nil
 }
 
+// UnmarshalStorageCfg falls back to MongoDB, the only storage before the driver was configurable.
+func UnmarshalStorageCfg(cfg *Config) error {
+	if err := viper.UnmarshalKey("storage", &cfg.Storage); err != nil {
+		return err
+	}
+	if cfg.Storage.Driver == "" {
+		cfg.Storage.Driver = StorageDriverMongo
+	}
+	return nil
+}
+
 func UnmarshalMongoCfg(cfg *Config) error {
 	return viper.Unmarshal(&cfg.Mongo)
 }
@@ -125,12 +234,29 @@ func UnmarshalHttpCfg(cfg *Config) error {
 }
 
 func UnmarshalAuthCfg(cfg *Config) error {
-	return viper.Unmarshal(&cfg.Auth)
+	if err := viper.Unmarshal(&cfg.Auth); err != nil {
+		return err
+	}
+	return viper.UnmarshalKey("auth", &cfg.Auth)
 }
 
 func UnmarshalJwtCfg(cfg *Config) error {
 	if err := viper.Unmarshal(&cfg.Jwt); err != nil {
 		return err
 	}
-	return viper.UnmarshalKey("jwt", &cfg.Jwt)
+	return viper.UnmarshalKey("jwt", &cfg.Jwt, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToTimeHookFunc(time.RFC3339),
+	)))
+}
+
+func UnmarshalMailCfg(cfg *Config) error {
+	if err := viper.Unmarshal(&cfg.Mail); err != nil {
+		return err
+	}
+	return viper.UnmarshalKey("mail", &cfg.Mail)
+}
+
+func UnmarshalTaskCfg(cfg *Config) error {
+	return viper.UnmarshalKey("task", &cfg.Task)
 }
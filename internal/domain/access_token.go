@@ -0,0 +1,66 @@
+package domain
+
+import "time"
+
+// Scope grants a personal access token a part of the API. Signed in users are not limited by scopes.
+type Scope string
+
+const (
+	ScopeTasksRead     Scope = "tasks:read"
+	ScopeTasksWrite    Scope = "tasks:write"
+	ScopeProjectsRead  Scope = "projects:read"
+	ScopeProjectsWrite Scope = "projects:write"
+	ScopeLabelsRead    Scope = "labels:read"
+	ScopeLabelsWrite   Scope = "labels:write"
+)
+
+// AccessTokenPrefix tells personal access tokens apart from JWTs in the Authorization header.
+const AccessTokenPrefix = "tdp_"
+
+// AccessToken is a long-lived personal access token for scripts and integrations.
+// Only its hash is stored, the token itself is shown once when it is created.
+type AccessToken struct {
+	Id         string     `json:"id" bson:"_id,omitempty" db:"id"`
+	UserId     string     `json:"user_id" bson:"user_id" db:"user_id"`
+	Name       string     `json:"name" bson:"name" db:"name"`
+	Scopes     []Scope    `json:"scopes" bson:"scopes" db:"-"`
+	TokenHash  string     `json:"-" bson:"token_hash" db:"token_hash"`
+	ExpiresAt  *time.Time `json:"expires_at" bson:"expires_at" db:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at" bson:"last_used_at" db:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at" bson:"created_at" db:"created_at"`
+}
+
+// HasScope tells whether the token grants the scope.
+func (t AccessToken) HasScope(scope Scope) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// CreatedAccessToken is returned once on creation and is the only time the token is readable.
+type CreatedAccessToken struct {
+	AccessToken
+	Token string `json:"token"`
+}
+
+type CreateAccessTokenInput struct {
+	Name      string     `json:"name" binding:"required,max=64"`
+	Scopes    []Scope    `json:"scopes" binding:"required,min=1,dive,oneof=tasks:read tasks:write projects:read projects:write labels:read labels:write" enums:"tasks:read,tasks:write,projects:read,projects:write,labels:read,labels:write"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (in CreateAccessTokenInput) Validate() error {
+	if in.ExpiresAt != nil && !in.ExpiresAt.After(time.Now()) {
+		return ErrAccessTokenExpiresInPast
+	}
+	return nil
+}
+
+var (
+	ErrAccessTokenNotFound      = newNotFoundError("access token not found")
+	ErrInvalidAccessToken       = newError(ErrUnauthorized, "invalid access token")
+	ErrAccessTokenExpiresInPast = NewValidationError("expires_at must be in the future")
+)
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The kinds of errors the services report to the clients. The repositories translate the
+// driver errors into them, so the handlers do not depend on the storage.
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrConflict        = errors.New("conflict")
+	ErrValidation      = errors.New("validation failed")
+	ErrBadRequest      = errors.New("bad request")
+	ErrUnauthorized    = errors.New("unauthorized")
+	ErrForbidden       = errors.New("forbidden")
+	ErrLocked          = errors.New("locked")
+	ErrTooManyRequests = errors.New("too many requests")
+)
+
+// Error is an error of one of the kinds above with a message that is safe to show to the client.
+type Error struct {
+	Kind    error
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+func newError(kind error, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+func newNotFoundError(message string) *Error {
+	return newError(ErrNotFound, message)
+}
+
+func newConflictError(message string) *Error {
+	return newError(ErrConflict, message)
+}
+
+// NewValidationError rejects an input the binding rules can not check.
+func NewValidationError(format string, args ...interface{}) *Error {
+	return newError(ErrValidation, fmt.Sprintf(format, args...))
+}
+
+var (
+	ErrTaskNotFound    = newNotFoundError("task not found")
+	ErrProjectNotFound = newNotFoundError("project not found")
+	ErrLabelNotFound   = newNotFoundError("label not found")
+
+	ErrLoginTaken = newConflictError("login is already taken")
+	ErrEmailTaken = newConflictError("email is already taken")
+	ErrLabelTaken = newConflictError("label name is already taken")
+)
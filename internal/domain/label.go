@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+type Label struct {
+	Id        string    `json:"id" bson:"_id,omitempty" db:"id"`
+	Name      string    `json:"name" bson:"name,omitempty" db:"name"`
+	Color     string    `json:"color,omitempty" bson:"color,omitempty" db:"color"`
+	UserId    string    `json:"user_id" bson:"user_id,omitempty" db:"user_id"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at" db:"created_at"`
+}
+
+type CreateLabelInput struct {
+	Name  string `json:"name" binding:"required,max=64"`
+	Color string `json:"color" binding:"omitempty,hexcolor"`
+}
+
+type UpdateLabelInput struct {
+	Name  string `json:"name" binding:"required,max=64"`
+	Color string `json:"color" binding:"omitempty,hexcolor"`
+}
+
+type TaskLabelsInput struct {
+	LabelIds []string `json:"label_ids" binding:"required,min=1,dive,required"`
+}
+
+// LabelMatch tells whether a task has to carry any or all of the filtered labels.
+type LabelMatch string
+
+const (
+	LabelMatchAny LabelMatch = "any"
+	LabelMatchAll LabelMatch = "all"
+)
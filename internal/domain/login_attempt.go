@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// LoginAttempts counts the recent failed sign ins of a login or of a client address.
+type LoginAttempts struct {
+	Key           string     `json:"key" bson:"_id" db:"key"`
+	Failures      int        `json:"failures" bson:"failures" db:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at" bson:"last_failure_at" db:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until" bson:"locked_until,omitempty" db:"locked_until"`
+}
+
+// RetryError rejects a sign in attempt that came too early, RetryAfter tells how long to wait.
+type RetryError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	ErrTooManyAttempts = newError(ErrTooManyRequests, "too many failed sign in attempts, try again later")
+	ErrAccountLocked   = newError(ErrLocked, "account is temporarily locked after too many failed sign in attempts")
+)
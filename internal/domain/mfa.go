@@ -0,0 +1,51 @@
+package domain
+
+const MfaRequired = "mfa_required"
+
+// SignInResult holds the tokens of a completed sign in, or the challenge a user with two-factor
+// authentication has to answer to get them.
+type SignInResult struct {
+	Tokens    *Tokens
+	Challenge *MfaChallenge
+}
+
+type MfaChallenge struct {
+	Status         string `json:"status" enums:"mfa_required"`
+	ChallengeToken string `json:"challenge_token"`
+}
+
+type MfaSignInInput struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	// Code is either the current authenticator code or one of the recovery codes.
+	Code string `json:"code" binding:"required"`
+}
+
+type TotpEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningUri string `json:"provisioning_uri"`
+}
+
+type TotpCodeInput struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
+
+// UpdateTotpInput replaces the whole two-factor state of a user, recovery codes are hashed.
+type UpdateTotpInput struct {
+	Secret        string
+	Enabled       bool
+	RecoveryCodes []string
+}
+
+var (
+	ErrInvalidMfaChallenge = newError(ErrUnauthorized, "invalid or expired two-factor challenge")
+	ErrInvalidMfaCode      = newError(ErrBadRequest, "invalid two-factor code")
+	// ErrInvalidMfaSignInCode fails the sign in, unlike a wrong code of a signed in user.
+	ErrInvalidMfaSignInCode = newError(ErrUnauthorized, "invalid two-factor code")
+	ErrTotpAlreadyEnabled   = newError(ErrBadRequest, "two-factor authentication is already enabled")
+	ErrTotpNotEnrolled      = newError(ErrBadRequest, "two-factor enrollment has not been started")
+	ErrTotpNotEnabled       = newError(ErrBadRequest, "two-factor authentication is not enabled")
+)
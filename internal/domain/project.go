@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// InboxProjectId selects the tasks that do not belong to any project.
+const InboxProjectId = "inbox"
+
+type Project struct {
+	Id        string    `json:"id" bson:"_id,omitempty" db:"id"`
+	Name      string    `json:"name" bson:"name,omitempty" db:"name"`
+	UserId    string    `json:"user_id" bson:"user_id,omitempty" db:"user_id"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at" db:"updated_at"`
+}
+
+type CreateProjectInput struct {
+	Name string `json:"name" binding:"required,max=255"`
+}
+
+type UpdateProjectInput struct {
+	Name string `json:"name" binding:"required,max=255"`
+}
+
+// ProjectDeleteMode tells what happens to the tasks of a deleted project.
+type ProjectDeleteMode string
+
+const (
+	// ProjectDeleteMoveToInbox keeps the tasks and detaches them from the project.
+	ProjectDeleteMoveToInbox ProjectDeleteMode = "inbox"
+	// ProjectDeleteCascade moves the tasks with their subtasks to the trash together with the project.
+	ProjectDeleteCascade ProjectDeleteMode = "cascade"
+)
@@ -0,0 +1,69 @@
+package domain
+
+// Role grants a user permissions beyond managing their own data. Users without roles are
+// regular users.
+type Role string
+
+const RoleAdmin Role = "admin"
+
+// Identity is the user a request is made by, with the roles the access token carries.
+type Identity struct {
+	UserId string
+	Roles  []Role
+}
+
+func (i Identity) HasRole(role Role) bool {
+	return hasRole(i.Roles, role)
+}
+
+func hasRole(roles []Role, role Role) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	DefaultUserPageLimit = 50
+	MaxUserPageLimit     = 100
+)
+
+// UserFilter pages through all the users, oldest first.
+type UserFilter struct {
+	Limit  int `json:"limit" form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int `json:"offset" form:"offset" binding:"omitempty,min=0"`
+}
+
+// Normalize fills in the default limit so repositories can rely on it being set.
+func (f UserFilter) Normalize() UserFilter {
+	if f.Limit <= 0 {
+		f.Limit = DefaultUserPageLimit
+	}
+	if f.Limit > MaxUserPageLimit {
+		f.Limit = MaxUserPageLimit
+	}
+	if f.Offset < 0 {
+		f.Offset = 0
+	}
+	return f
+}
+
+// AdminUpdateUserInput changes only the fields that are given.
+type AdminUpdateUserInput struct {
+	Disabled *bool   `json:"disabled"`
+	Roles    *[]Role `json:"roles" binding:"omitempty,dive,oneof=admin" enums:"admin"`
+}
+
+// TaskCounts sum up the tasks of all users. Tasks in the trash are only counted in InTrash.
+type TaskCounts struct {
+	Total    int64                `json:"total"`
+	ByStatus map[TaskStatus]int64 `json:"by_status"`
+	InTrash  int64                `json:"in_trash"`
+}
+
+var (
+	ErrUserDisabled = newError(ErrForbidden, "user is disabled")
+	ErrAdminSelf    = newError(ErrBadRequest, "admins can not disable, demote or delete themselves")
+)
@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+// Session is a refresh token issued to a user. Every rotation creates a new session in the
+// same family, so the reuse of a rotated token can revoke the whole chain at once.
+type Session struct {
+	Id        string     `json:"id" bson:"_id,omitempty" db:"id"`
+	UserId    string     `json:"user_id" bson:"user_id" db:"user_id"`
+	FamilyId  string     `json:"-" bson:"family_id" db:"family_id"`
+	TokenHash string     `json:"-" bson:"token_hash" db:"token_hash"`
+	ExpiresAt time.Time  `json:"expires_at" bson:"expires_at" db:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty" bson:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at" bson:"created_at" db:"created_at"`
+}
+
+type CreateSessionInput struct {
+	UserId    string
+	FamilyId  string
+	TokenHash string
+	ExpiresAt time.Time
+}
+
+// Tokens is the pair handed out on sign in, sign up and refresh.
+type Tokens struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type RefreshTokensInput struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// LogoutInput optionally names the refresh token to revoke along with the access token.
+type LogoutInput struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+var (
+	ErrSessionNotFound     = newNotFoundError("session not found")
+	ErrInvalidRefreshToken = newError(ErrUnauthorized, "invalid refresh token")
+	ErrTokenRevoked        = newError(ErrUnauthorized, "token has been revoked")
+)
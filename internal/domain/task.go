@@ -1,19 +1,176 @@
 package domain
 
-import "time"
+import (
+	"github.com/i-vasilkov/go-todo-app/pkg/markdown"
+	"github.com/i-vasilkov/go-todo-app/pkg/recurrence"
+	"time"
+)
+
+type TaskStatus string
+
+const (
+	TaskStatusTodo       TaskStatus = "todo"
+	TaskStatusInProgress TaskStatus = "in_progress"
+	TaskStatusDone       TaskStatus = "done"
+	TaskStatusCancelled  TaskStatus = "cancelled"
+)
+
+type TaskPriority int
+
+// Priorities go from P1 (most urgent) to P4, which is the default.
+const (
+	TaskPriorityP1 TaskPriority = 1
+	TaskPriorityP2 TaskPriority = 2
+	TaskPriorityP3 TaskPriority = 3
+	TaskPriorityP4 TaskPriority = 4
+
+	DefaultTaskPriority = TaskPriorityP4
+)
+
+// OrDefault returns the priority, or DefaultTaskPriority when none was given.
+func (p TaskPriority) OrDefault() TaskPriority {
+	if p == 0 {
+		return DefaultTaskPriority
+	}
+	return p
+}
+
+// TaskDescriptionMaxLength is the maximum size of the Markdown description of a task in bytes.
+const TaskDescriptionMaxLength = 64 * 1024
+
+// TaskPositionGap is the distance between the positions of tasks appended to a list.
+// Moving a task puts it halfway between its new neighbours, so most reorders only
+// touch the moved task.
+const TaskPositionGap = 1024.0
 
 type Task struct {
-	Id        string    `json:"id" bson:"_id,omitempty" db:"id"`
-	Name      string    `json:"name" bson:"name,omitempty" db:"name"`
-	UserId    string    `json:"user_id" bson:"user_id,omitempty" db:"user_id"`
-	CreatedAt time.Time `json:"created_at" bson:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" bson:"updated_at" db:"updated_at"`
+	Id              string       `json:"id" bson:"_id,omitempty" db:"id"`
+	Name            string       `json:"name" bson:"name,omitempty" db:"name"`
+	Description     string       `json:"description,omitempty" bson:"description,omitempty" db:"description"`
+	DescriptionHTML string       `json:"description_html,omitempty" bson:"-" db:"-"`
+	UserId          string       `json:"user_id" bson:"user_id,omitempty" db:"user_id"`
+	ProjectId       *string      `json:"project_id,omitempty" bson:"project_id,omitempty" db:"project_id"`
+	ParentId        *string      `json:"parent_id,omitempty" bson:"parent_id,omitempty" db:"parent_id"`
+	LabelIds        []string     `json:"label_ids,omitempty" bson:"label_ids,omitempty" db:"-"`
+	Recurrence      string       `json:"recurrence,omitempty" bson:"recurrence,omitempty" db:"recurrence"`
+	Status          TaskStatus   `json:"status" bson:"status" db:"status"`
+	Priority        TaskPriority `json:"priority" bson:"priority" db:"priority"`
+	Position        float64      `json:"position" bson:"position" db:"position"`
+	CompletedAt     *time.Time   `json:"completed_at,omitempty" bson:"completed_at,omitempty" db:"completed_at"`
+	DeletedAt       *time.Time   `json:"deleted_at,omitempty" bson:"deleted_at,omitempty" db:"deleted_at"`
+	StartAt         *time.Time   `json:"start_at,omitempty" bson:"start_at,omitempty" db:"start_at"`
+	DueAt           *time.Time   `json:"due_at,omitempty" bson:"due_at,omitempty" db:"due_at"`
+	CreatedAt       time.Time    `json:"created_at" bson:"created_at" db:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at" bson:"updated_at" db:"updated_at"`
 }
 
+// IsOverdue reports whether the task has a due date in the past and is still open.
+func (t Task) IsOverdue(now time.Time) bool {
+	return t.DueAt != nil && t.DueAt.Before(now) && !t.Status.IsClosed()
+}
+
+// RenderDescription fills DescriptionHTML from the Markdown description. The HTML is
+// only rendered on request and never stored.
+func (t *Task) RenderDescription() {
+	t.DescriptionHTML = markdown.ToHTML(t.Description)
+}
+
+// IsClosed reports whether the status ends the task lifecycle.
+func (s TaskStatus) IsClosed() bool {
+	return s == TaskStatusDone || s == TaskStatusCancelled
+}
+
+// UpdateTaskInput replaces the task, the fields left out are cleared. Only an empty Status keeps
+// the status the task has.
 type UpdateTaskInput struct {
-	Name string `json:"name" binding:"required"`
+	Name        string       `json:"name" binding:"required"`
+	Description string       `json:"description" example:"Steps:\n\n1. **first**\n2. second"`
+	Status      TaskStatus   `json:"status" binding:"omitempty,oneof=todo in_progress done cancelled" enums:"todo,in_progress,done,cancelled"`
+	StartAt     *time.Time   `json:"start_at"`
+	DueAt       *time.Time   `json:"due_at"`
+	ProjectId   *string      `json:"project_id"`
+	ParentId    *string      `json:"parent_id"`
+	Recurrence  string       `json:"recurrence" binding:"max=255" example:"FREQ=WEEKLY;BYDAY=MO,WE"`
+	Priority    TaskPriority `json:"priority" binding:"omitempty,min=1,max=4" enums:"1,2,3,4"`
 }
 
 type CreateTaskInput struct {
-	Name string `json:"name" binding:"required"`
+	Name        string       `json:"name" binding:"required"`
+	Description string       `json:"description" example:"Steps:\n\n1. **first**\n2. second"`
+	StartAt     *time.Time   `json:"start_at"`
+	DueAt       *time.Time   `json:"due_at"`
+	ProjectId   *string      `json:"project_id"`
+	ParentId    *string      `json:"parent_id"`
+	Recurrence  string       `json:"recurrence" binding:"max=255" example:"FREQ=WEEKLY;BYDAY=MO,WE"`
+	Priority    TaskPriority `json:"priority" binding:"omitempty,min=1,max=4" enums:"1,2,3,4"`
+}
+
+func (in UpdateTaskInput) Validate() error {
+	if err := validateTaskDates(in.StartAt, in.DueAt); err != nil {
+		return err
+	}
+	if err := validateDescription(in.Description); err != nil {
+		return err
+	}
+	return validateRecurrence(in.Recurrence)
 }
+
+func (in CreateTaskInput) Validate() error {
+	if err := validateTaskDates(in.StartAt, in.DueAt); err != nil {
+		return err
+	}
+	if err := validateDescription(in.Description); err != nil {
+		return err
+	}
+	return validateRecurrence(in.Recurrence)
+}
+
+// Sanitize returns the input with the description normalized for storage.
+func (in UpdateTaskInput) Sanitize() UpdateTaskInput {
+	in.Description = markdown.Sanitize(in.Description)
+	return in
+}
+
+// Sanitize returns the input with the description normalized for storage.
+func (in CreateTaskInput) Sanitize() CreateTaskInput {
+	in.Description = markdown.Sanitize(in.Description)
+	return in
+}
+
+func validateTaskDates(startAt, dueAt *time.Time) error {
+	if startAt != nil && dueAt != nil && startAt.After(*dueAt) {
+		return NewValidationError("start_at must not be after due_at")
+	}
+	return nil
+}
+
+func validateDescription(description string) error {
+	if len(description) > TaskDescriptionMaxLength {
+		return NewValidationError("description must not be longer than %d bytes", TaskDescriptionMaxLength)
+	}
+	return nil
+}
+
+func validateRecurrence(rule string) error {
+	if rule == "" {
+		return nil
+	}
+
+	if _, err := recurrence.Parse(rule); err != nil {
+		return NewValidationError("%s", err)
+	}
+	return nil
+}
+
+// MoveTaskInput places a task right after AfterId and/or right before BeforeId.
+type MoveTaskInput struct {
+	BeforeId *string `json:"before_id" binding:"required_without=AfterId"`
+	AfterId  *string `json:"after_id" binding:"required_without=BeforeId"`
+}
+
+var ErrInvalidMove = NewValidationError("task can not be moved next to itself, next to a task of another project or between tasks that are not adjacent")
+
+var (
+	ErrTaskAlreadyDone = newConflictError("task is already done")
+	ErrTaskNotClosed   = newConflictError("only done or cancelled tasks can be reopened")
+)
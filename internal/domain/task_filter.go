@@ -0,0 +1,176 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultTaskPageLimit = 50
+	MaxTaskPageLimit     = 100
+)
+
+type TaskSortField string
+
+const (
+	TaskSortPosition  TaskSortField = "position"
+	TaskSortPriority  TaskSortField = "priority"
+	TaskSortCreatedAt TaskSortField = "created_at"
+	TaskSortUpdatedAt TaskSortField = "updated_at"
+	TaskSortDueAt     TaskSortField = "due_at"
+	TaskSortName      TaskSortField = "name"
+)
+
+type SortDirection string
+
+const (
+	SortAsc  SortDirection = "asc"
+	SortDesc SortDirection = "desc"
+)
+
+// TaskFilter describes which tasks of a user are listed and in what order.
+// Every bound is optional; ranges are half-open: [from, to).
+// ProjectId set to InboxProjectId selects tasks without a project. Labels match
+// tasks carrying any of the given labels unless LabelMatch is LabelMatchAll.
+type TaskFilter struct {
+	Status      []TaskStatus  `json:"status" form:"status" binding:"omitempty,dive,oneof=todo in_progress done cancelled"`
+	DueFrom     *time.Time    `json:"due_from" form:"due_from"`
+	DueTo       *time.Time    `json:"due_to" form:"due_to"`
+	CreatedFrom *time.Time    `json:"created_from" form:"created_from"`
+	CreatedTo   *time.Time    `json:"created_to" form:"created_to"`
+	Name        string        `json:"name" form:"name" binding:"max=255"`
+	ProjectId   string        `json:"project_id" form:"project_id"`
+	Labels      []string      `json:"label" form:"label"`
+	LabelMatch  LabelMatch    `json:"label_match" form:"label_match" binding:"omitempty,oneof=any all" enums:"any,all"`
+	SortBy      TaskSortField `json:"sort_by" form:"sort_by" binding:"omitempty,oneof=position priority created_at updated_at due_at name" enums:"position,priority,created_at,updated_at,due_at,name"`
+	SortDir     SortDirection `json:"sort_dir" form:"sort_dir" binding:"omitempty,oneof=asc desc" enums:"asc,desc"`
+	Limit       int           `json:"limit" form:"limit" binding:"omitempty,min=1,max=100"`
+	Cursor      string        `json:"cursor" form:"cursor"`
+}
+
+// TaskPage is one page of a filtered task list. NextCursor is empty on the last page.
+type TaskPage struct {
+	Items      []Task
+	NextCursor string
+}
+
+// Normalize fills in the defaults so repositories can rely on SortBy, SortDir and Limit being set.
+func (f TaskFilter) Normalize() TaskFilter {
+	if f.SortBy == "" {
+		f.SortBy = TaskSortPosition
+	}
+	if f.SortDir == "" {
+		f.SortDir = SortAsc
+	}
+	if f.LabelMatch == "" {
+		f.LabelMatch = LabelMatchAny
+	}
+	if f.Limit <= 0 {
+		f.Limit = DefaultTaskPageLimit
+	}
+	if f.Limit > MaxTaskPageLimit {
+		f.Limit = MaxTaskPageLimit
+	}
+	return f
+}
+
+// TaskCursor points right after the last task of a page in the order given by SortBy/SortDir.
+// Value is the sort key of that task (nil when the task has no due date) and Id breaks ties.
+type TaskCursor struct {
+	SortBy  TaskSortField `json:"s"`
+	SortDir SortDirection `json:"d"`
+	Value   *string       `json:"v"`
+	Id      string        `json:"id"`
+}
+
+var (
+	ErrInvalidCursor        = newError(ErrBadRequest, "invalid cursor")
+	ErrInvalidProjectFilter = NewValidationError("invalid project_id filter")
+	ErrInvalidLabelFilter   = NewValidationError("invalid label filter")
+)
+
+// NewTaskCursor builds the opaque cursor that continues the listing after the given task.
+func NewTaskCursor(f TaskFilter, last Task) string {
+	cursor := TaskCursor{SortBy: f.SortBy, SortDir: f.SortDir, Value: last.SortValue(f.SortBy), Id: last.Id}
+
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor returns nil for the first page and ErrInvalidCursor when the cursor
+// is malformed or was issued for a different ordering.
+func (f TaskFilter) DecodeCursor() (*TaskCursor, error) {
+	if f.Cursor == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(f.Cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor TaskCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Id == "" {
+		return nil, ErrInvalidCursor
+	}
+
+	if cursor.SortBy != f.SortBy || cursor.SortDir != f.SortDir {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+// TimeValue parses the cursor value of a time based sort field.
+func (c TaskCursor) TimeValue() (time.Time, error) {
+	if c.Value == nil {
+		return time.Time{}, ErrInvalidCursor
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, *c.Value)
+	if err != nil {
+		return time.Time{}, ErrInvalidCursor
+	}
+	return t, nil
+}
+
+// FloatValue parses the cursor value of a numeric sort field.
+func (c TaskCursor) FloatValue() (float64, error) {
+	if c.Value == nil {
+		return 0, ErrInvalidCursor
+	}
+
+	f, err := strconv.ParseFloat(*c.Value, 64)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	return f, nil
+}
+
+// SortValue returns the key the task is ordered by for the given field. Time keys are
+// formatted as RFC3339 with nanoseconds, the repositories parse them back into dates.
+func (t Task) SortValue(field TaskSortField) *string {
+	var value string
+
+	switch field {
+	case TaskSortPosition:
+		value = strconv.FormatFloat(t.Position, 'g', -1, 64)
+	case TaskSortPriority:
+		value = strconv.Itoa(int(t.Priority))
+	case TaskSortName:
+		value = t.Name
+	case TaskSortUpdatedAt:
+		value = t.UpdatedAt.Format(time.RFC3339Nano)
+	case TaskSortDueAt:
+		if t.DueAt == nil {
+			return nil
+		}
+		value = t.DueAt.Format(time.RFC3339Nano)
+	default:
+		value = t.CreatedAt.Format(time.RFC3339Nano)
+	}
+
+	return &value
+}
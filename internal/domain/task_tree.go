@@ -0,0 +1,46 @@
+package domain
+
+// DefaultTaskMaxDepth is used when no maximum nesting depth is configured.
+// A top level task has depth 1, its subtasks depth 2 and so on.
+const DefaultTaskMaxDepth = 3
+
+var (
+	ErrTaskDepthExceeded = NewValidationError("maximum subtask depth exceeded")
+	ErrTaskParentCycle   = NewValidationError("task can not be nested under itself or its subtasks")
+)
+
+// TaskProgress counts the direct subtasks of a task. Cancelled subtasks are not counted.
+type TaskProgress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+// TaskTree is a task together with its subtasks down to the maximum depth.
+type TaskTree struct {
+	Task
+	Progress TaskProgress `json:"progress"`
+	Subtasks []TaskTree   `json:"subtasks"`
+}
+
+// RenderDescription renders the descriptions of the task and all of its subtasks.
+func (t *TaskTree) RenderDescription() {
+	t.Task.RenderDescription()
+	for i := range t.Subtasks {
+		t.Subtasks[i].RenderDescription()
+	}
+}
+
+// NewTaskProgress computes the progress over the given direct subtasks.
+func NewTaskProgress(subtasks []Task) TaskProgress {
+	var progress TaskProgress
+	for _, subtask := range subtasks {
+		switch subtask.Status {
+		case TaskStatusCancelled:
+			continue
+		case TaskStatusDone:
+			progress.Done++
+		}
+		progress.Total++
+	}
+	return progress
+}
@@ -3,14 +3,28 @@ package domain
 import "time"
 
 type User struct {
-	Id        string    `json:"id" bson:"_id,omitempty" db:"id"`
-	Login     string    `json:"login" bson:"login" db:"login"`
-	Password  string    `json:"-" bson:"-" db:"password"`
+	Id       string `json:"id" bson:"_id,omitempty" db:"id"`
+	Login    string `json:"login" bson:"login" db:"login"`
+	Password string `json:"-" bson:"password" db:"password"`
+	// Email is empty for the users that signed up before it was asked for.
+	Email         string `json:"email" bson:"email,omitempty" db:"email"`
+	EmailVerified bool   `json:"email_verified" bson:"email_verified" db:"email_verified"`
+	// TotpSecret is set once enrollment starts, but is only asked for when TotpEnabled.
+	TotpSecret  string `json:"-" bson:"totp_secret" db:"totp_secret"`
+	TotpEnabled bool   `json:"totp_enabled" bson:"totp_enabled" db:"totp_enabled"`
+	Roles       []Role `json:"roles" bson:"roles" db:"-"`
+	// Disabled users can not sign in, their tokens are revoked when they are disabled.
+	Disabled  bool      `json:"disabled" bson:"disabled" db:"disabled"`
 	CreatedAt time.Time `json:"created_at" bson:"created_at" db:"created_at"`
 }
 
+func (u User) HasRole(role Role) bool {
+	return hasRole(u.Roles, role)
+}
+
 type CreateUserInput struct {
 	Login    string `json:"login" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
 }
 
@@ -18,3 +32,37 @@ type LoginUserInput struct {
 	Login    string `json:"login" binding:"required"`
 	Password string `json:"password" binding:"required"`
 }
+
+// UpdateUserInput changes only the fields that are given. A new email has to be verified again.
+type UpdateUserInput struct {
+	Login *string `json:"login" binding:"omitempty,min=1,max=255"`
+	Email *string `json:"email" binding:"omitempty,email,max=320"`
+}
+
+type ChangePasswordInput struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+type ForgotPasswordInput struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordInput struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type VerifyEmailInput struct {
+	Token string `json:"token" binding:"required"`
+}
+
+var (
+	ErrInvalidCredentials       = newError(ErrUnauthorized, "invalid login or password")
+	ErrUserNotFound             = newNotFoundError("user not found")
+	ErrWrongPassword            = newError(ErrBadRequest, "current password is wrong")
+	ErrInvalidResetToken        = newError(ErrBadRequest, "invalid or expired password reset token")
+	ErrInvalidVerificationToken = newError(ErrBadRequest, "invalid or expired email verification token")
+	ErrEmailAlreadyVerified     = newError(ErrBadRequest, "email is already verified")
+	ErrEmailMissing             = newError(ErrBadRequest, "no email to verify")
+)
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"net/http"
+)
+
+func (h *Handler) InitAccessTokenRoutes(router *gin.RouterGroup) {
+	tokens := router.Group("/tokens", h.AuthMiddleware, h.RequireSession)
+	{
+		tokens.GET("/", h.accessTokenGetAll)
+		tokens.POST("/", h.accessTokenCreate)
+		tokens.DELETE("/:id", h.accessTokenDelete)
+	}
+}
+
+// @Summary Getting personal access tokens
+// @Description Get all personal access tokens of the user, newest first. The tokens themselves are never returned again
+// @Security ApiAuth
+// @Tags Token
+// @Accept json
+// @Produce json
+// @Success 200 {object} SuccessResponse{data=[]domain.AccessToken}
+// @Failure 401,403,500 {object} ErrorResponse
+// @Router /tokens [get]
+func (h *Handler) accessTokenGetAll(ctx *gin.Context) {
+	userId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	tokens, err := h.services.AccessToken.GetAll(ctx.Request.Context(), userId)
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, tokens)
+}
+
+// @Summary Creating a personal access token
+// @Description Create a personal access token limited to the given scopes. The token is only returned in this response
+// @Security ApiAuth
+// @Tags Token
+// @Accept json
+// @Produce json
+// @Param input body domain.CreateAccessTokenInput true "Token Input"
+// @Success 200 {object} SuccessResponse{data=domain.CreatedAccessToken}
+// @Failure 400,401,403,422,500 {object} ErrorResponse
+// @Router /tokens [post]
+func (h *Handler) accessTokenCreate(ctx *gin.Context) {
+	var in domain.CreateAccessTokenInput
+	if err := ctx.BindJSON(&in); err != nil {
+		NewValidatorErrorResponse(ctx, err)
+		return
+	}
+
+	if err := in.Validate(); err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	userId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	token, err := h.services.AccessToken.Create(ctx.Request.Context(), userId, in)
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, token)
+}
+
+// @Summary Revoking a personal access token
+// @Description Revoke a personal access token by id
+// @Security ApiAuth
+// @Tags Token
+// @Accept json
+// @Produce json
+// @Success 200 {object} SuccessResponse{data=object}
+// @Failure 400,401,403,404,500 {object} ErrorResponse
+// @Router /tokens/{id} [delete]
+func (h *Handler) accessTokenDelete(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		NewErrorResponseFromError(ctx, http.StatusBadRequest, errors.New("empty token id"))
+		return
+	}
+
+	userId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	if err := h.services.AccessToken.Delete(ctx.Request.Context(), id, userId); err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, nil)
+}
@@ -0,0 +1,270 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"github.com/i-vasilkov/go-todo-app/internal/service"
+	"github.com/i-vasilkov/go-todo-app/internal/service/mocks"
+	"github.com/magiconair/properties/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler_accessTokenGetAll(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAccessTokenServiceI, userId string)
+
+	testCases := []struct {
+		name           string
+		userId         string
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:   "OK",
+			userId: "userId",
+			mockBehavior: func(s *mock_service.MockAccessTokenServiceI, userId string) {
+				s.EXPECT().GetAll(context.Background(), userId).Return([]domain.AccessToken{
+					{
+						Id:        "tokenId",
+						UserId:    userId,
+						Name:      "backup",
+						Scopes:    []domain.Scope{domain.ScopeTasksRead},
+						TokenHash: "hash",
+						CreatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+					},
+				}, nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":[{"id":"tokenId","user_id":"userId","name":"backup","scopes":["tasks:read"],"expires_at":null,"last_used_at":null,"created_at":"2020-01-01T00:00:00Z"}]}`,
+		},
+		{
+			name:           "Empty UserId",
+			userId:         "",
+			mockBehavior:   func(s *mock_service.MockAccessTokenServiceI, userId string) {},
+			respStatusCode: http.StatusUnauthorized,
+			respBody:       `{"success":false,"messages":["not exists userId in context"]}`,
+		},
+		{
+			name:   "Service error",
+			userId: "userId",
+			mockBehavior: func(s *mock_service.MockAccessTokenServiceI, userId string) {
+				s.EXPECT().GetAll(context.Background(), userId).Return(nil, errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tokenService := mock_service.NewMockAccessTokenServiceI(ctrl)
+			testCase.mockBehavior(tokenService, testCase.userId)
+
+			services := &service.Services{AccessToken: tokenService}
+			handler := NewHandler(services)
+
+			router := gin.New()
+			router.GET("/tokens", func(ctx *gin.Context) {
+				if testCase.userId != "" {
+					ctx.Set(userCtx, testCase.userId)
+				}
+			}, handler.accessTokenGetAll)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/tokens", nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestHandler_accessTokenCreate(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAccessTokenServiceI, userId string, in domain.CreateAccessTokenInput)
+
+	input := domain.CreateAccessTokenInput{Name: "backup", Scopes: []domain.Scope{domain.ScopeTasksRead}}
+
+	testCases := []struct {
+		name           string
+		userId         string
+		body           string
+		input          domain.CreateAccessTokenInput
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:   "OK",
+			userId: "userId",
+			body:   `{"name":"backup","scopes":["tasks:read"]}`,
+			input:  input,
+			mockBehavior: func(s *mock_service.MockAccessTokenServiceI, userId string, in domain.CreateAccessTokenInput) {
+				s.EXPECT().Create(context.Background(), userId, in).Return(domain.CreatedAccessToken{
+					AccessToken: domain.AccessToken{
+						Id:        "tokenId",
+						UserId:    userId,
+						Name:      in.Name,
+						Scopes:    in.Scopes,
+						CreatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+					},
+					Token: "tdp_secret",
+				}, nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":{"id":"tokenId","user_id":"userId","name":"backup","scopes":["tasks:read"],"expires_at":null,"last_used_at":null,"created_at":"2020-01-01T00:00:00Z","token":"tdp_secret"}}`,
+		},
+		{
+			name:           "Unknown scope",
+			userId:         "userId",
+			body:           `{"name":"backup","scopes":["users:write"]}`,
+			mockBehavior:   func(s *mock_service.MockAccessTokenServiceI, userId string, in domain.CreateAccessTokenInput) {},
+			respStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "Without scopes",
+			userId:         "userId",
+			body:           `{"name":"backup","scopes":[]}`,
+			mockBehavior:   func(s *mock_service.MockAccessTokenServiceI, userId string, in domain.CreateAccessTokenInput) {},
+			respStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "Expires in the past",
+			userId:         "userId",
+			body:           `{"name":"backup","scopes":["tasks:read"],"expires_at":"2020-01-01T00:00:00Z"}`,
+			mockBehavior:   func(s *mock_service.MockAccessTokenServiceI, userId string, in domain.CreateAccessTokenInput) {},
+			respStatusCode: http.StatusUnprocessableEntity,
+			respBody:       `{"success":false,"messages":["` + domain.ErrAccessTokenExpiresInPast.Error() + `"]}`,
+		},
+		{
+			name:           "Empty UserId",
+			userId:         "",
+			body:           `{"name":"backup","scopes":["tasks:read"]}`,
+			mockBehavior:   func(s *mock_service.MockAccessTokenServiceI, userId string, in domain.CreateAccessTokenInput) {},
+			respStatusCode: http.StatusUnauthorized,
+			respBody:       `{"success":false,"messages":["not exists userId in context"]}`,
+		},
+		{
+			name:   "Service error",
+			userId: "userId",
+			body:   `{"name":"backup","scopes":["tasks:read"]}`,
+			input:  input,
+			mockBehavior: func(s *mock_service.MockAccessTokenServiceI, userId string, in domain.CreateAccessTokenInput) {
+				s.EXPECT().Create(context.Background(), userId, in).Return(domain.CreatedAccessToken{}, errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tokenService := mock_service.NewMockAccessTokenServiceI(ctrl)
+			testCase.mockBehavior(tokenService, testCase.userId, testCase.input)
+
+			services := &service.Services{AccessToken: tokenService}
+			handler := NewHandler(services)
+
+			router := gin.New()
+			router.POST("/tokens", func(ctx *gin.Context) {
+				if testCase.userId != "" {
+					ctx.Set(userCtx, testCase.userId)
+				}
+			}, handler.accessTokenCreate)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/tokens", bytes.NewBufferString(testCase.body))
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			if testCase.respBody != "" {
+				assert.Equal(t, w.Body.String(), testCase.respBody)
+			}
+		})
+	}
+}
+
+func TestHandler_accessTokenDelete(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAccessTokenServiceI, id, userId string)
+
+	testCases := []struct {
+		name           string
+		tokenId        string
+		userId         string
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:    "OK",
+			tokenId: "tokenId",
+			userId:  "userId",
+			mockBehavior: func(s *mock_service.MockAccessTokenServiceI, id, userId string) {
+				s.EXPECT().Delete(context.Background(), id, userId).Return(nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":null}`,
+		},
+		{
+			name:           "Empty UserId",
+			tokenId:        "tokenId",
+			userId:         "",
+			mockBehavior:   func(s *mock_service.MockAccessTokenServiceI, id, userId string) {},
+			respStatusCode: http.StatusUnauthorized,
+			respBody:       `{"success":false,"messages":["not exists userId in context"]}`,
+		},
+		{
+			name:    "Service error",
+			tokenId: "tokenId",
+			userId:  "userId",
+			mockBehavior: func(s *mock_service.MockAccessTokenServiceI, id, userId string) {
+				s.EXPECT().Delete(context.Background(), id, userId).Return(errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tokenService := mock_service.NewMockAccessTokenServiceI(ctrl)
+			testCase.mockBehavior(tokenService, testCase.tokenId, testCase.userId)
+
+			services := &service.Services{AccessToken: tokenService}
+			handler := NewHandler(services)
+
+			router := gin.New()
+			router.DELETE("/tokens/:id", func(ctx *gin.Context) {
+				if testCase.userId != "" {
+					ctx.Set(userCtx, testCase.userId)
+				}
+			}, handler.accessTokenDelete)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("DELETE", "/tokens/"+testCase.tokenId, nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"net/http"
+)
+
+func (h *Handler) InitAccountRoutes(router *gin.RouterGroup) {
+	auth := router.Group("/auth")
+	{
+		auth.POST("/password/forgot", h.accountPasswordForgot)
+		auth.POST("/password/reset", h.accountPasswordReset)
+		auth.POST("/email/verify", h.accountEmailVerify)
+		auth.POST("/email/verify/resend", h.AuthMiddleware, h.RequireSession, h.accountEmailVerifyResend)
+	}
+}
+
+// @Summary Forgot password
+// @Description Mail a link to reset the password to the given email. The response is the same whether the email belongs to a user or not
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param input body domain.ForgotPasswordInput true "Forgot Password Input"
+// @Success 200 {object} SuccessResponse{data=object}
+// @Failure 400,500 {object} ErrorResponse
+// @Router /auth/password/forgot [post]
+func (h *Handler) accountPasswordForgot(ctx *gin.Context) {
+	var in domain.ForgotPasswordInput
+	if err := ctx.BindJSON(&in); err != nil {
+		NewValidatorErrorResponse(ctx, err)
+		return
+	}
+
+	if err := h.services.Account.ForgotPassword(ctx.Request.Context(), in); err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, nil)
+}
+
+// @Summary Reset password
+// @Description Set a new password with the token from the emailed link. The token works once, and all tokens of the user are revoked
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param input body domain.ResetPasswordInput true "Reset Password Input"
+// @Success 200 {object} SuccessResponse{data=object}
+// @Failure 400,500 {object} ErrorResponse
+// @Router /auth/password/reset [post]
+func (h *Handler) accountPasswordReset(ctx *gin.Context) {
+	var in domain.ResetPasswordInput
+	if err := ctx.BindJSON(&in); err != nil {
+		NewValidatorErrorResponse(ctx, err)
+		return
+	}
+
+	err := h.services.Account.ResetPassword(ctx.Request.Context(), in)
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, nil)
+}
+
+// @Summary Verify email
+// @Description Mark the email as verified with the token from the emailed link
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param input body domain.VerifyEmailInput true "Verify Email Input"
+// @Success 200 {object} SuccessResponse{data=object}
+// @Failure 400,500 {object} ErrorResponse
+// @Router /auth/email/verify [post]
+func (h *Handler) accountEmailVerify(ctx *gin.Context) {
+	var in domain.VerifyEmailInput
+	if err := ctx.BindJSON(&in); err != nil {
+		NewValidatorErrorResponse(ctx, err)
+		return
+	}
+
+	err := h.services.Account.VerifyEmail(ctx.Request.Context(), in)
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, nil)
+}
+
+// @Summary Resend verification email
+// @Description Mail another link to verify the email of the user
+// @Security ApiAuth
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} SuccessResponse{data=object}
+// @Failure 400,401,403,500 {object} ErrorResponse
+// @Router /auth/email/verify/resend [post]
+func (h *Handler) accountEmailVerifyResend(ctx *gin.Context) {
+	userId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	err = h.services.Account.ResendVerification(ctx.Request.Context(), userId)
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, nil)
+}
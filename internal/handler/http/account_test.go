@@ -0,0 +1,266 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"github.com/i-vasilkov/go-todo-app/internal/service"
+	mock_service "github.com/i-vasilkov/go-todo-app/internal/service/mocks"
+	"github.com/magiconair/properties/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_PasswordForgot(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAccountServiceI)
+
+	testCases := []struct {
+		name           string
+		inputReqBody   string
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:         "OK",
+			inputReqBody: `{"email":"test@example.com"}`,
+			mockBehavior: func(s *mock_service.MockAccountServiceI) {
+				s.EXPECT().ForgotPassword(context.Background(), domain.ForgotPasswordInput{Email: "test@example.com"}).Return(nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":null}`,
+		},
+		{
+			name:           "Invalid email input",
+			inputReqBody:   `{"email":"test"}`,
+			mockBehavior:   func(s *mock_service.MockAccountServiceI) {},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["invalid 'Email' input"]}`,
+		},
+		{
+			name:         "Service error",
+			inputReqBody: `{"email":"test@example.com"}`,
+			mockBehavior: func(s *mock_service.MockAccountServiceI) {
+				s.EXPECT().ForgotPassword(context.Background(), domain.ForgotPasswordInput{Email: "test@example.com"}).Return(errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			account := mock_service.NewMockAccountServiceI(c)
+			testCase.mockBehavior(account)
+
+			handler := NewHandler(&service.Services{Account: account})
+
+			router := gin.New()
+			router.POST("/password/forgot", handler.accountPasswordForgot)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/password/forgot", bytes.NewBufferString(testCase.inputReqBody))
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestHandler_PasswordReset(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAccountServiceI)
+
+	input := domain.ResetPasswordInput{Token: "reset", Password: "new password"}
+
+	testCases := []struct {
+		name           string
+		inputReqBody   string
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:         "OK",
+			inputReqBody: `{"token":"reset","password":"new password"}`,
+			mockBehavior: func(s *mock_service.MockAccountServiceI) {
+				s.EXPECT().ResetPassword(context.Background(), input).Return(nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":null}`,
+		},
+		{
+			name:           "Empty password input",
+			inputReqBody:   `{"token":"reset"}`,
+			mockBehavior:   func(s *mock_service.MockAccountServiceI) {},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["invalid 'Password' input"]}`,
+		},
+		{
+			name:         "Invalid token",
+			inputReqBody: `{"token":"reset","password":"new password"}`,
+			mockBehavior: func(s *mock_service.MockAccountServiceI) {
+				s.EXPECT().ResetPassword(context.Background(), input).Return(domain.ErrInvalidResetToken)
+			},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["` + domain.ErrInvalidResetToken.Error() + `"]}`,
+		},
+		{
+			name:         "Service error",
+			inputReqBody: `{"token":"reset","password":"new password"}`,
+			mockBehavior: func(s *mock_service.MockAccountServiceI) {
+				s.EXPECT().ResetPassword(context.Background(), input).Return(errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			account := mock_service.NewMockAccountServiceI(c)
+			testCase.mockBehavior(account)
+
+			handler := NewHandler(&service.Services{Account: account})
+
+			router := gin.New()
+			router.POST("/password/reset", handler.accountPasswordReset)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/password/reset", bytes.NewBufferString(testCase.inputReqBody))
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestHandler_EmailVerify(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAccountServiceI)
+
+	input := domain.VerifyEmailInput{Token: "verification"}
+
+	testCases := []struct {
+		name           string
+		inputReqBody   string
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:         "OK",
+			inputReqBody: `{"token":"verification"}`,
+			mockBehavior: func(s *mock_service.MockAccountServiceI) {
+				s.EXPECT().VerifyEmail(context.Background(), input).Return(nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":null}`,
+		},
+		{
+			name:         "Invalid token",
+			inputReqBody: `{"token":"verification"}`,
+			mockBehavior: func(s *mock_service.MockAccountServiceI) {
+				s.EXPECT().VerifyEmail(context.Background(), input).Return(domain.ErrInvalidVerificationToken)
+			},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["` + domain.ErrInvalidVerificationToken.Error() + `"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			account := mock_service.NewMockAccountServiceI(c)
+			testCase.mockBehavior(account)
+
+			handler := NewHandler(&service.Services{Account: account})
+
+			router := gin.New()
+			router.POST("/email/verify", handler.accountEmailVerify)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/email/verify", bytes.NewBufferString(testCase.inputReqBody))
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestHandler_EmailVerifyResend(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAccountServiceI)
+
+	testCases := []struct {
+		name           string
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockAccountServiceI) {
+				s.EXPECT().ResendVerification(context.Background(), "userId").Return(nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":null}`,
+		},
+		{
+			name: "Already verified",
+			mockBehavior: func(s *mock_service.MockAccountServiceI) {
+				s.EXPECT().ResendVerification(context.Background(), "userId").Return(domain.ErrEmailAlreadyVerified)
+			},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["` + domain.ErrEmailAlreadyVerified.Error() + `"]}`,
+		},
+		{
+			name: "Service error",
+			mockBehavior: func(s *mock_service.MockAccountServiceI) {
+				s.EXPECT().ResendVerification(context.Background(), "userId").Return(errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			account := mock_service.NewMockAccountServiceI(c)
+			testCase.mockBehavior(account)
+
+			handler := NewHandler(&service.Services{Account: account})
+
+			router := gin.New()
+			router.POST("/email/verify/resend", func(ctx *gin.Context) {
+				ctx.Set(userCtx, "userId")
+			}, handler.accountEmailVerifyResend)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/email/verify/resend", nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
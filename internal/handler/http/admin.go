@@ -0,0 +1,138 @@
+package http
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"net/http"
+)
+
+func (h *Handler) InitAdminRoutes(router *gin.RouterGroup) {
+	admin := router.Group("/admin", h.AuthMiddleware, h.RequireSession, h.RequireRole(domain.RoleAdmin))
+	{
+		users := admin.Group("/users")
+		{
+			users.GET("", h.adminGetUsers)
+			users.PATCH("/:id", h.adminUpdateUser)
+			users.DELETE("/:id", h.adminDeleteUser)
+		}
+
+		admin.GET("/tasks/counts", h.adminGetTaskCounts)
+	}
+}
+
+// @Summary Getting users
+// @Description Get one page of all users, in the order they signed up
+// @Security ApiAuth
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param filter query domain.UserFilter false "pagination"
+// @Success 200 {object} SuccessResponse{data=[]domain.User}
+// @Failure 400,401,403,500 {object} ErrorResponse
+// @Router /admin/users [get]
+func (h *Handler) adminGetUsers(ctx *gin.Context) {
+	var filter domain.UserFilter
+	if err := ctx.BindQuery(&filter); err != nil {
+		NewValidatorErrorResponse(ctx, err)
+		return
+	}
+
+	users, err := h.services.Admin.GetUsers(ctx.Request.Context(), filter)
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, users)
+}
+
+// @Summary Updating a user
+// @Description Disable, enable or change the roles of a user. The user has to refresh the tokens for the change to take effect, a disabled user is signed out everywhere
+// @Security ApiAuth
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "user id"
+// @Param input body domain.AdminUpdateUserInput true "User Input"
+// @Success 200 {object} SuccessResponse{data=domain.User}
+// @Failure 400,401,403,404,500 {object} ErrorResponse
+// @Router /admin/users/{id} [patch]
+func (h *Handler) adminUpdateUser(ctx *gin.Context) {
+	var in domain.AdminUpdateUserInput
+	if err := ctx.BindJSON(&in); err != nil {
+		NewValidatorErrorResponse(ctx, err)
+		return
+	}
+
+	id := ctx.Param("id")
+	if id == "" {
+		NewErrorResponseFromError(ctx, http.StatusBadRequest, errors.New("empty user id"))
+		return
+	}
+
+	adminId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	user, err := h.services.Admin.UpdateUser(ctx.Request.Context(), adminId, id, in)
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, user)
+}
+
+// @Summary Deleting a user
+// @Description Delete a user together with the tasks, projects, labels and tokens of the user
+// @Security ApiAuth
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "user id"
+// @Success 200 {object} SuccessResponse{data=object}
+// @Failure 400,401,403,404,500 {object} ErrorResponse
+// @Router /admin/users/{id} [delete]
+func (h *Handler) adminDeleteUser(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		NewErrorResponseFromError(ctx, http.StatusBadRequest, errors.New("empty user id"))
+		return
+	}
+
+	adminId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	err = h.services.Admin.DeleteUser(ctx.Request.Context(), adminId, id)
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, nil)
+}
+
+// @Summary Counting tasks
+// @Description Count the tasks of all users by status. Tasks in the trash are counted apart
+// @Security ApiAuth
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} SuccessResponse{data=domain.TaskCounts}
+// @Failure 401,403,500 {object} ErrorResponse
+// @Router /admin/tasks/counts [get]
+func (h *Handler) adminGetTaskCounts(ctx *gin.Context) {
+	counts, err := h.services.Admin.GetTaskCounts(ctx.Request.Context())
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, counts)
+}
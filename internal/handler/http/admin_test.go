@@ -0,0 +1,244 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"github.com/i-vasilkov/go-todo-app/internal/service"
+	mock_service "github.com/i-vasilkov/go-todo-app/internal/service/mocks"
+	"github.com/magiconair/properties/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_AdminGetUsers(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAdminServiceI)
+
+	testCases := []struct {
+		name           string
+		query          string
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:  "OK",
+			query: "?limit=10&offset=20",
+			mockBehavior: func(s *mock_service.MockAdminServiceI) {
+				s.EXPECT().GetUsers(context.Background(), domain.UserFilter{Limit: 10, Offset: 20}).
+					Return([]domain.User{}, nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":[]}`,
+		},
+		{
+			name:           "Invalid limit",
+			query:          "?limit=1000",
+			mockBehavior:   func(s *mock_service.MockAdminServiceI) {},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["invalid 'Limit' input"]}`,
+		},
+		{
+			name:  "Service error",
+			query: "",
+			mockBehavior: func(s *mock_service.MockAdminServiceI) {
+				s.EXPECT().GetUsers(context.Background(), domain.UserFilter{}).Return(nil, errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			admin := mock_service.NewMockAdminServiceI(c)
+			testCase.mockBehavior(admin)
+
+			handler := NewHandler(&service.Services{Admin: admin})
+
+			router := gin.New()
+			router.GET("/admin/users", handler.adminGetUsers)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/admin/users"+testCase.query, nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestHandler_AdminUpdateUser(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAdminServiceI)
+
+	disabled := true
+
+	testCases := []struct {
+		name           string
+		body           string
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name: "OK",
+			body: `{"disabled":true}`,
+			mockBehavior: func(s *mock_service.MockAdminServiceI) {
+				s.EXPECT().UpdateUser(context.Background(), "adminId", "userId", domain.AdminUpdateUserInput{Disabled: &disabled}).
+					Return(domain.User{Id: "userId", Login: "test", Roles: []domain.Role{}, Disabled: true}, nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody: `{"success":true,"data":{"id":"userId","login":"test","email":"","email_verified":false,` +
+				`"totp_enabled":false,"roles":[],"disabled":true,"created_at":"0001-01-01T00:00:00Z"}}`,
+		},
+		{
+			name:           "Unknown role",
+			body:           `{"roles":["root"]}`,
+			mockBehavior:   func(s *mock_service.MockAdminServiceI) {},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["invalid 'Roles[0]' input"]}`,
+		},
+		{
+			name: "Self",
+			body: `{"disabled":true}`,
+			mockBehavior: func(s *mock_service.MockAdminServiceI) {
+				s.EXPECT().UpdateUser(context.Background(), "adminId", "userId", domain.AdminUpdateUserInput{Disabled: &disabled}).
+					Return(domain.User{}, domain.ErrAdminSelf)
+			},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["admins can not disable, demote or delete themselves"]}`,
+		},
+		{
+			name: "Unknown user",
+			body: `{"disabled":true}`,
+			mockBehavior: func(s *mock_service.MockAdminServiceI) {
+				s.EXPECT().UpdateUser(context.Background(), "adminId", "userId", domain.AdminUpdateUserInput{Disabled: &disabled}).
+					Return(domain.User{}, domain.ErrUserNotFound)
+			},
+			respStatusCode: http.StatusNotFound,
+			respBody:       `{"success":false,"messages":["user not found"]}`,
+		},
+		{
+			name: "Service error",
+			body: `{"disabled":true}`,
+			mockBehavior: func(s *mock_service.MockAdminServiceI) {
+				s.EXPECT().UpdateUser(context.Background(), "adminId", "userId", domain.AdminUpdateUserInput{Disabled: &disabled}).
+					Return(domain.User{}, errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			admin := mock_service.NewMockAdminServiceI(c)
+			testCase.mockBehavior(admin)
+
+			handler := NewHandler(&service.Services{Admin: admin})
+
+			router := gin.New()
+			router.PATCH("/admin/users/:id", func(ctx *gin.Context) {
+				ctx.Set(userCtx, "adminId")
+			}, handler.adminUpdateUser)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PATCH", "/admin/users/userId", bytes.NewBufferString(testCase.body))
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestHandler_AdminDeleteUser(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAdminServiceI)
+
+	testCases := []struct {
+		name           string
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockAdminServiceI) {
+				s.EXPECT().DeleteUser(context.Background(), "adminId", "userId").Return(nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":null}`,
+		},
+		{
+			name: "Unknown user",
+			mockBehavior: func(s *mock_service.MockAdminServiceI) {
+				s.EXPECT().DeleteUser(context.Background(), "adminId", "userId").Return(domain.ErrUserNotFound)
+			},
+			respStatusCode: http.StatusNotFound,
+			respBody:       `{"success":false,"messages":["user not found"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			admin := mock_service.NewMockAdminServiceI(c)
+			testCase.mockBehavior(admin)
+
+			handler := NewHandler(&service.Services{Admin: admin})
+
+			router := gin.New()
+			router.DELETE("/admin/users/:id", func(ctx *gin.Context) {
+				ctx.Set(userCtx, "adminId")
+			}, handler.adminDeleteUser)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("DELETE", "/admin/users/userId", nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestHandler_AdminGetTaskCounts(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	admin := mock_service.NewMockAdminServiceI(c)
+	admin.EXPECT().GetTaskCounts(context.Background()).Return(domain.TaskCounts{
+		Total:    3,
+		ByStatus: map[domain.TaskStatus]int64{domain.TaskStatusDone: 1, domain.TaskStatusTodo: 2},
+		InTrash:  1,
+	}, nil)
+
+	handler := NewHandler(&service.Services{Admin: admin})
+
+	router := gin.New()
+	router.GET("/admin/tasks/counts", handler.adminGetTaskCounts)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/admin/tasks/counts", nil)
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, w.Code, http.StatusOK)
+	assert.Equal(t, w.Body.String(), `{"success":true,"data":{"total":3,"by_status":{"done":1,"todo":2},"in_trash":1}}`)
+}
@@ -1,8 +1,10 @@
 package http
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"io"
 	"net/http"
 )
 
@@ -10,18 +12,29 @@ func (h *Handler) InitAuthRoutes(router *gin.RouterGroup) {
 	auth := router.Group("/auth")
 	{
 		auth.POST("/sign-in", h.authSignIn)
+		auth.POST("/sign-in/mfa", h.authSignInMfa)
 		auth.POST("/sign-up", h.authSignUp)
+		auth.POST("/refresh", h.authRefresh)
+		auth.POST("/logout", h.AuthMiddleware, h.RequireSession, h.authLogout)
+		auth.POST("/logout-all", h.AuthMiddleware, h.RequireSession, h.authLogoutAll)
+
+		totp := auth.Group("/totp", h.AuthMiddleware, h.RequireSession)
+		{
+			totp.POST("/", h.authTotpEnroll)
+			totp.POST("/confirm", h.authTotpConfirm)
+			totp.POST("/disable", h.authTotpDisable)
+		}
 	}
 }
 
 // @Summary Sign In
-// @Description Login user by credentials
+// @Description Login user by credentials. Users with two-factor authentication get a domain.MfaChallenge with the "mfa_required" status instead of tokens, to be answered at /auth/sign-in/mfa
 // @Tags Auth
 // @Accept json
 // @Produce json
 // @Param input body domain.LoginUserInput true "SignIn Input"
-// @Success 200 {object} SuccessResponse{data=string}
-// @Failure 400,422,500 {object} ErrorResponse
+// @Success 200 {object} SuccessResponse{data=domain.Tokens}
+// @Failure 400,401,403,422,423,429,500 {object} ErrorResponse
 // @Router /auth/sign-in [post]
 func (h *Handler) authSignIn(ctx *gin.Context) {
 	var in domain.LoginUserInput
@@ -31,13 +44,43 @@ func (h *Handler) authSignIn(ctx *gin.Context) {
 		return
 	}
 
-	token, err := h.services.Auth.SignIn(ctx.Request.Context(), in)
+	result, err := h.services.Auth.SignIn(ctx.Request.Context(), in, h.clientIp(ctx))
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	if result.Challenge != nil {
+		NewSuccessResponse(ctx, result.Challenge)
+		return
+	}
+
+	NewSuccessResponse(ctx, result.Tokens)
+}
+
+// @Summary Sign In with the second factor
+// @Description Answer the challenge returned by sign in with an authenticator or recovery code
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param input body domain.MfaSignInInput true "Mfa Input"
+// @Success 200 {object} SuccessResponse{data=domain.Tokens}
+// @Failure 400,401,423,429,500 {object} ErrorResponse
+// @Router /auth/sign-in/mfa [post]
+func (h *Handler) authSignInMfa(ctx *gin.Context) {
+	var in domain.MfaSignInInput
+	if err := ctx.BindJSON(&in); err != nil {
+		NewValidatorErrorResponse(ctx, err)
+		return
+	}
+
+	tokens, err := h.services.Auth.SignInMfa(ctx.Request.Context(), in, h.clientIp(ctx))
 	if err != nil {
-		NewErrorResponseFromError(ctx, http.StatusInternalServerError, err)
+		NewServiceErrorResponse(ctx, err)
 		return
 	}
 
-	NewSuccessResponse(ctx, token)
+	NewSuccessResponse(ctx, tokens)
 }
 
 // @Summary Sign Up
@@ -46,8 +89,8 @@ func (h *Handler) authSignIn(ctx *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param input body domain.CreateUserInput true "SignUp Input"
-// @Success 200 {object} SuccessResponse{data=string}
-// @Failure 400,422,500 {object} ErrorResponse
+// @Success 200 {object} SuccessResponse{data=domain.Tokens}
+// @Failure 400,409,422,500 {object} ErrorResponse
 // @Router /auth/sign-up [post]
 func (h *Handler) authSignUp(ctx *gin.Context) {
 	var in domain.CreateUserInput
@@ -56,11 +99,187 @@ func (h *Handler) authSignUp(ctx *gin.Context) {
 		return
 	}
 
-	token, err := h.services.Auth.SignUp(ctx.Request.Context(), in)
+	tokens, err := h.services.Auth.SignUp(ctx.Request.Context(), in)
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, tokens)
+}
+
+// @Summary Refresh
+// @Description Exchange a refresh token for a new token pair. Every refresh token can be used once, reusing one revokes all tokens issued after the same sign in
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param input body domain.RefreshTokensInput true "Refresh Input"
+// @Success 200 {object} SuccessResponse{data=domain.Tokens}
+// @Failure 400,401,500 {object} ErrorResponse
+// @Router /auth/refresh [post]
+func (h *Handler) authRefresh(ctx *gin.Context) {
+	var in domain.RefreshTokensInput
+	if err := ctx.BindJSON(&in); err != nil {
+		NewValidatorErrorResponse(ctx, err)
+		return
+	}
+
+	tokens, err := h.services.Auth.Refresh(ctx.Request.Context(), in.RefreshToken)
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, tokens)
+}
+
+// @Summary Logout
+// @Description Revoke the access token of the request. The refresh token, when given, is revoked together with the tokens rotated from it
+// @Security ApiAuth
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param input body domain.LogoutInput false "Logout Input"
+// @Success 200 {object} SuccessResponse{data=object}
+// @Failure 400,401,500 {object} ErrorResponse
+// @Router /auth/logout [post]
+func (h *Handler) authLogout(ctx *gin.Context) {
+	token, err := GetTokenFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	var in domain.LogoutInput
+	if err := ctx.ShouldBindJSON(&in); err != nil && !errors.Is(err, io.EOF) {
+		NewValidatorErrorResponse(ctx, err)
+		return
+	}
+
+	if err := h.services.Auth.Logout(ctx.Request.Context(), token, in); err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, nil)
+}
+
+// @Summary Logout everywhere
+// @Description Revoke all access and refresh tokens of the user
+// @Security ApiAuth
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} SuccessResponse{data=object}
+// @Failure 401,500 {object} ErrorResponse
+// @Router /auth/logout-all [post]
+func (h *Handler) authLogoutAll(ctx *gin.Context) {
+	userId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	if err := h.services.Auth.LogoutAll(ctx.Request.Context(), userId); err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, nil)
+}
+
+// authJWKS serves the public token keys in the plain JWK Set format, outside the /api prefix
+// and the response envelope, where JWT libraries expect them.
+func (h *Handler) authJWKS(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, h.services.Auth.GetJWKS(ctx.Request.Context()))
+}
+
+// @Summary Enrolling two-factor authentication
+// @Description Start the TOTP enrollment. The provisioning URI is meant for a QR code, the code it produces confirms the enrollment
+// @Security ApiAuth
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} SuccessResponse{data=domain.TotpEnrollment}
+// @Failure 400,401,403,500 {object} ErrorResponse
+// @Router /auth/totp [post]
+func (h *Handler) authTotpEnroll(ctx *gin.Context) {
+	userId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	enrollment, err := h.services.Auth.EnrollTotp(ctx.Request.Context(), userId)
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, enrollment)
+}
+
+// @Summary Confirming two-factor authentication
+// @Description Enable two-factor authentication with the first authenticator code. The recovery codes are only returned in this response
+// @Security ApiAuth
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param input body domain.TotpCodeInput true "Code Input"
+// @Success 200 {object} SuccessResponse{data=domain.RecoveryCodes}
+// @Failure 400,401,403,500 {object} ErrorResponse
+// @Router /auth/totp/confirm [post]
+func (h *Handler) authTotpConfirm(ctx *gin.Context) {
+	var in domain.TotpCodeInput
+	if err := ctx.BindJSON(&in); err != nil {
+		NewValidatorErrorResponse(ctx, err)
+		return
+	}
+
+	userId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	codes, err := h.services.Auth.ConfirmTotp(ctx.Request.Context(), userId, in.Code)
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, codes)
+}
+
+// @Summary Disabling two-factor authentication
+// @Description Disable two-factor authentication with an authenticator or recovery code
+// @Security ApiAuth
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param input body domain.TotpCodeInput true "Code Input"
+// @Success 200 {object} SuccessResponse{data=object}
+// @Failure 400,401,403,500 {object} ErrorResponse
+// @Router /auth/totp/disable [post]
+func (h *Handler) authTotpDisable(ctx *gin.Context) {
+	var in domain.TotpCodeInput
+	if err := ctx.BindJSON(&in); err != nil {
+		NewValidatorErrorResponse(ctx, err)
+		return
+	}
+
+	userId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	err = h.services.Auth.DisableTotp(ctx.Request.Context(), userId, in.Code)
 	if err != nil {
-		NewErrorResponseFromError(ctx, http.StatusInternalServerError, err)
+		NewServiceErrorResponse(ctx, err)
 		return
 	}
 
-	NewSuccessResponse(ctx, token)
+	NewSuccessResponse(ctx, nil)
 }
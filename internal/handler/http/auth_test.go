@@ -9,12 +9,17 @@ import (
 	"github.com/i-vasilkov/go-todo-app/internal/domain"
 	"github.com/i-vasilkov/go-todo-app/internal/service"
 	"github.com/i-vasilkov/go-todo-app/internal/service/mocks"
+	jwtauth "github.com/i-vasilkov/go-todo-app/pkg/auth/jwt"
 	"github.com/magiconair/properties/assert"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
+// testClientIp is the address httptest requests come from.
+const testClientIp = "192.0.2.1"
+
 func TestHandler_SignIn(t *testing.T) {
 	type mockBehavior func(s *mock_service.MockAuthServiceI, in domain.LoginUserInput)
 
@@ -25,6 +30,8 @@ func TestHandler_SignIn(t *testing.T) {
 		mockBehavior   mockBehavior
 		respStatusCode int
 		respBody       string
+		retryAfter     string
+		forwardedFor   string
 	}{
 		{
 			name:         "OK",
@@ -34,10 +41,25 @@ func TestHandler_SignIn(t *testing.T) {
 				Password: "test",
 			},
 			mockBehavior: func(s *mock_service.MockAuthServiceI, in domain.LoginUserInput) {
-				s.EXPECT().SignIn(context.Background(), in).Return("token", nil)
+				s.EXPECT().SignIn(context.Background(), in, testClientIp).Return(domain.SignInResult{Tokens: &domain.Tokens{AccessToken: "access", RefreshToken: "refresh"}}, nil)
 			},
 			respStatusCode: http.StatusOK,
-			respBody:       `{"success":true,"data":"token"}`,
+			respBody:       `{"success":true,"data":{"access_token":"access","refresh_token":"refresh"}}`,
+		},
+		{
+			name:         "Two-factor required",
+			inputReqBody: `{"login":"test","password":"test"}`,
+			inputObj: domain.LoginUserInput{
+				Login:    "test",
+				Password: "test",
+			},
+			mockBehavior: func(s *mock_service.MockAuthServiceI, in domain.LoginUserInput) {
+				s.EXPECT().SignIn(context.Background(), in, testClientIp).Return(domain.SignInResult{
+					Challenge: &domain.MfaChallenge{Status: domain.MfaRequired, ChallengeToken: "challenge"},
+				}, nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":{"status":"mfa_required","challenge_token":"challenge"}}`,
 		},
 		{
 			name:           "Empty login input",
@@ -71,6 +93,78 @@ func TestHandler_SignIn(t *testing.T) {
 			respStatusCode: http.StatusBadRequest,
 			respBody:       `{"success":false,"messages":["invalid input body"]}`,
 		},
+		{
+			name:         "Invalid credentials",
+			inputReqBody: `{"login":"test","password":"wrong"}`,
+			inputObj: domain.LoginUserInput{
+				Login:    "test",
+				Password: "wrong",
+			},
+			mockBehavior: func(s *mock_service.MockAuthServiceI, in domain.LoginUserInput) {
+				s.EXPECT().SignIn(context.Background(), in, testClientIp).Return(domain.SignInResult{}, domain.ErrInvalidCredentials)
+			},
+			respStatusCode: http.StatusUnauthorized,
+			respBody:       `{"success":false,"messages":["invalid login or password"]}`,
+		},
+		{
+			name:         "Disabled user",
+			inputReqBody: `{"login":"test","password":"test"}`,
+			inputObj: domain.LoginUserInput{
+				Login:    "test",
+				Password: "test",
+			},
+			mockBehavior: func(s *mock_service.MockAuthServiceI, in domain.LoginUserInput) {
+				s.EXPECT().SignIn(context.Background(), in, testClientIp).Return(domain.SignInResult{}, domain.ErrUserDisabled)
+			},
+			respStatusCode: http.StatusForbidden,
+			respBody:       `{"success":false,"messages":["user is disabled"]}`,
+		},
+		{
+			name:         "Too many attempts",
+			inputReqBody: `{"login":"test","password":"test"}`,
+			inputObj: domain.LoginUserInput{
+				Login:    "test",
+				Password: "test",
+			},
+			mockBehavior: func(s *mock_service.MockAuthServiceI, in domain.LoginUserInput) {
+				s.EXPECT().SignIn(context.Background(), in, testClientIp).
+					Return(domain.SignInResult{}, &domain.RetryError{Err: domain.ErrTooManyAttempts, RetryAfter: 1500 * time.Millisecond})
+			},
+			respStatusCode: http.StatusTooManyRequests,
+			respBody:       `{"success":false,"messages":["too many failed sign in attempts, try again later"]}`,
+			retryAfter:     "2",
+		},
+		{
+			name:         "Account locked",
+			inputReqBody: `{"login":"test","password":"test"}`,
+			inputObj: domain.LoginUserInput{
+				Login:    "test",
+				Password: "test",
+			},
+			mockBehavior: func(s *mock_service.MockAuthServiceI, in domain.LoginUserInput) {
+				s.EXPECT().SignIn(context.Background(), in, testClientIp).
+					Return(domain.SignInResult{}, &domain.RetryError{Err: domain.ErrAccountLocked, RetryAfter: 15 * time.Minute})
+			},
+			respStatusCode: http.StatusLocked,
+			respBody:       `{"success":false,"messages":["account is temporarily locked after too many failed sign in attempts"]}`,
+			retryAfter:     "900",
+		},
+		{
+			name:         "Spoofed X-Forwarded-For",
+			inputReqBody: `{"login":"test","password":"test"}`,
+			inputObj: domain.LoginUserInput{
+				Login:    "test",
+				Password: "test",
+			},
+			mockBehavior: func(s *mock_service.MockAuthServiceI, in domain.LoginUserInput) {
+				s.EXPECT().SignIn(context.Background(), in, testClientIp).
+					Return(domain.SignInResult{}, &domain.RetryError{Err: domain.ErrAccountLocked, RetryAfter: 15 * time.Minute})
+			},
+			respStatusCode: http.StatusLocked,
+			respBody:       `{"success":false,"messages":["account is temporarily locked after too many failed sign in attempts"]}`,
+			retryAfter:     "900",
+			forwardedFor:   "203.0.113.7",
+		},
 		{
 			name:         "Service error",
 			inputReqBody: `{"login":"test","password":"test"}`,
@@ -79,10 +173,10 @@ func TestHandler_SignIn(t *testing.T) {
 				Password: "test",
 			},
 			mockBehavior: func(s *mock_service.MockAuthServiceI, in domain.LoginUserInput) {
-				s.EXPECT().SignIn(context.Background(), in).Return("", errors.New("service error"))
+				s.EXPECT().SignIn(context.Background(), in, testClientIp).Return(domain.SignInResult{}, errors.New("service error"))
 			},
 			respStatusCode: http.StatusInternalServerError,
-			respBody:       `{"success":false,"messages":["service error"]}`,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
 		},
 	}
 
@@ -102,6 +196,308 @@ func TestHandler_SignIn(t *testing.T) {
 
 			w := httptest.NewRecorder()
 			req := httptest.NewRequest("POST", "/sign-in", bytes.NewBufferString(testCase.inputReqBody))
+			if testCase.forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", testCase.forwardedFor)
+			}
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+			assert.Equal(t, w.Header().Get("Retry-After"), testCase.retryAfter)
+		})
+	}
+}
+
+func TestHandler_SignInMfa(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAuthServiceI, in domain.MfaSignInInput)
+
+	testCases := []struct {
+		name           string
+		inputReqBody   string
+		inputObj       domain.MfaSignInInput
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:         "OK",
+			inputReqBody: `{"challenge_token":"challenge","code":"123456"}`,
+			inputObj:     domain.MfaSignInInput{ChallengeToken: "challenge", Code: "123456"},
+			mockBehavior: func(s *mock_service.MockAuthServiceI, in domain.MfaSignInInput) {
+				s.EXPECT().SignInMfa(context.Background(), in, testClientIp).Return(domain.Tokens{AccessToken: "access", RefreshToken: "refresh"}, nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":{"access_token":"access","refresh_token":"refresh"}}`,
+		},
+		{
+			name:           "Empty code",
+			inputReqBody:   `{"challenge_token":"challenge"}`,
+			inputObj:       domain.MfaSignInInput{},
+			mockBehavior:   func(s *mock_service.MockAuthServiceI, in domain.MfaSignInInput) {},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["invalid 'Code' input"]}`,
+		},
+		{
+			name:         "Wrong code",
+			inputReqBody: `{"challenge_token":"challenge","code":"123456"}`,
+			inputObj:     domain.MfaSignInInput{ChallengeToken: "challenge", Code: "123456"},
+			mockBehavior: func(s *mock_service.MockAuthServiceI, in domain.MfaSignInInput) {
+				s.EXPECT().SignInMfa(context.Background(), in, testClientIp).Return(domain.Tokens{}, domain.ErrInvalidMfaSignInCode)
+			},
+			respStatusCode: http.StatusUnauthorized,
+			respBody:       `{"success":false,"messages":["invalid two-factor code"]}`,
+		},
+		{
+			name:         "Invalid challenge",
+			inputReqBody: `{"challenge_token":"challenge","code":"123456"}`,
+			inputObj:     domain.MfaSignInInput{ChallengeToken: "challenge", Code: "123456"},
+			mockBehavior: func(s *mock_service.MockAuthServiceI, in domain.MfaSignInInput) {
+				s.EXPECT().SignInMfa(context.Background(), in, testClientIp).Return(domain.Tokens{}, domain.ErrInvalidMfaChallenge)
+			},
+			respStatusCode: http.StatusUnauthorized,
+			respBody:       `{"success":false,"messages":["invalid or expired two-factor challenge"]}`,
+		},
+		{
+			name:         "Service error",
+			inputReqBody: `{"challenge_token":"challenge","code":"123456"}`,
+			inputObj:     domain.MfaSignInInput{ChallengeToken: "challenge", Code: "123456"},
+			mockBehavior: func(s *mock_service.MockAuthServiceI, in domain.MfaSignInInput) {
+				s.EXPECT().SignInMfa(context.Background(), in, testClientIp).Return(domain.Tokens{}, errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			auth := mock_service.NewMockAuthServiceI(c)
+			testCase.mockBehavior(auth, testCase.inputObj)
+
+			s := &service.Services{Auth: auth}
+			h := NewHandler(s)
+
+			r := gin.New()
+			r.POST("/sign-in/mfa", h.authSignInMfa)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/sign-in/mfa", bytes.NewBufferString(testCase.inputReqBody))
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestHandler_TotpEnroll(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAuthServiceI)
+
+	testCases := []struct {
+		name           string
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockAuthServiceI) {
+				s.EXPECT().EnrollTotp(context.Background(), "userId").
+					Return(domain.TotpEnrollment{Secret: "SECRET", ProvisioningUri: "otpauth://totp/ToDoApp:test?secret=SECRET"}, nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":{"secret":"SECRET","provisioning_uri":"otpauth://totp/ToDoApp:test?secret=SECRET"}}`,
+		},
+		{
+			name: "Already enabled",
+			mockBehavior: func(s *mock_service.MockAuthServiceI) {
+				s.EXPECT().EnrollTotp(context.Background(), "userId").Return(domain.TotpEnrollment{}, domain.ErrTotpAlreadyEnabled)
+			},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["two-factor authentication is already enabled"]}`,
+		},
+		{
+			name: "Service error",
+			mockBehavior: func(s *mock_service.MockAuthServiceI) {
+				s.EXPECT().EnrollTotp(context.Background(), "userId").Return(domain.TotpEnrollment{}, errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			auth := mock_service.NewMockAuthServiceI(c)
+			testCase.mockBehavior(auth)
+
+			s := &service.Services{Auth: auth}
+			h := NewHandler(s)
+
+			r := gin.New()
+			r.POST("/totp", func(ctx *gin.Context) {
+				ctx.Set(userCtx, "userId")
+			}, h.authTotpEnroll)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/totp", nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestHandler_TotpConfirm(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAuthServiceI)
+
+	testCases := []struct {
+		name           string
+		inputReqBody   string
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:         "OK",
+			inputReqBody: `{"code":"123456"}`,
+			mockBehavior: func(s *mock_service.MockAuthServiceI) {
+				s.EXPECT().ConfirmTotp(context.Background(), "userId", "123456").
+					Return(domain.RecoveryCodes{Codes: []string{"aaaa-bbbb-cccc-dddd"}}, nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":{"recovery_codes":["aaaa-bbbb-cccc-dddd"]}}`,
+		},
+		{
+			name:           "Empty code",
+			inputReqBody:   `{}`,
+			mockBehavior:   func(s *mock_service.MockAuthServiceI) {},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["invalid 'Code' input"]}`,
+		},
+		{
+			name:         "Wrong code",
+			inputReqBody: `{"code":"123456"}`,
+			mockBehavior: func(s *mock_service.MockAuthServiceI) {
+				s.EXPECT().ConfirmTotp(context.Background(), "userId", "123456").Return(domain.RecoveryCodes{}, domain.ErrInvalidMfaCode)
+			},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["invalid two-factor code"]}`,
+		},
+		{
+			name:         "Not enrolled",
+			inputReqBody: `{"code":"123456"}`,
+			mockBehavior: func(s *mock_service.MockAuthServiceI) {
+				s.EXPECT().ConfirmTotp(context.Background(), "userId", "123456").Return(domain.RecoveryCodes{}, domain.ErrTotpNotEnrolled)
+			},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["two-factor enrollment has not been started"]}`,
+		},
+		{
+			name:         "Service error",
+			inputReqBody: `{"code":"123456"}`,
+			mockBehavior: func(s *mock_service.MockAuthServiceI) {
+				s.EXPECT().ConfirmTotp(context.Background(), "userId", "123456").Return(domain.RecoveryCodes{}, errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			auth := mock_service.NewMockAuthServiceI(c)
+			testCase.mockBehavior(auth)
+
+			s := &service.Services{Auth: auth}
+			h := NewHandler(s)
+
+			r := gin.New()
+			r.POST("/totp/confirm", func(ctx *gin.Context) {
+				ctx.Set(userCtx, "userId")
+			}, h.authTotpConfirm)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/totp/confirm", bytes.NewBufferString(testCase.inputReqBody))
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestHandler_TotpDisable(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAuthServiceI)
+
+	testCases := []struct {
+		name           string
+		inputReqBody   string
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:         "OK",
+			inputReqBody: `{"code":"123456"}`,
+			mockBehavior: func(s *mock_service.MockAuthServiceI) {
+				s.EXPECT().DisableTotp(context.Background(), "userId", "123456").Return(nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":null}`,
+		},
+		{
+			name:         "Not enabled",
+			inputReqBody: `{"code":"123456"}`,
+			mockBehavior: func(s *mock_service.MockAuthServiceI) {
+				s.EXPECT().DisableTotp(context.Background(), "userId", "123456").Return(domain.ErrTotpNotEnabled)
+			},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["two-factor authentication is not enabled"]}`,
+		},
+		{
+			name:         "Service error",
+			inputReqBody: `{"code":"123456"}`,
+			mockBehavior: func(s *mock_service.MockAuthServiceI) {
+				s.EXPECT().DisableTotp(context.Background(), "userId", "123456").Return(errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			auth := mock_service.NewMockAuthServiceI(c)
+			testCase.mockBehavior(auth)
+
+			s := &service.Services{Auth: auth}
+			h := NewHandler(s)
+
+			r := gin.New()
+			r.POST("/totp/disable", func(ctx *gin.Context) {
+				ctx.Set(userCtx, "userId")
+			}, h.authTotpDisable)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/totp/disable", bytes.NewBufferString(testCase.inputReqBody))
 
 			r.ServeHTTP(w, req)
 
@@ -115,29 +511,30 @@ func TestHandler_SignUp(t *testing.T) {
 	type mockBehavior func(s *mock_service.MockAuthServiceI, in domain.CreateUserInput)
 
 	testCases := []struct {
-		name          string
-		inputReqBody  string
-		inputObj      domain.CreateUserInput
+		name           string
+		inputReqBody   string
+		inputObj       domain.CreateUserInput
 		mockBehavior   mockBehavior
 		respStatusCode int
 		respBody       string
 	}{
 		{
 			name:         "OK",
-			inputReqBody: `{"login":"test","password":"test"}`,
+			inputReqBody: `{"login":"test","email":"test@example.com","password":"test"}`,
 			inputObj: domain.CreateUserInput{
 				Login:    "test",
+				Email:    "test@example.com",
 				Password: "test",
 			},
 			mockBehavior: func(s *mock_service.MockAuthServiceI, in domain.CreateUserInput) {
-				s.EXPECT().SignUp(context.Background(), in).Return("token", nil)
+				s.EXPECT().SignUp(context.Background(), in).Return(domain.Tokens{AccessToken: "access", RefreshToken: "refresh"}, nil)
 			},
 			respStatusCode: http.StatusOK,
-			respBody:       `{"success":true,"data":"token"}`,
+			respBody:       `{"success":true,"data":{"access_token":"access","refresh_token":"refresh"}}`,
 		},
 		{
 			name:           "Empty login input",
-			inputReqBody:   `{"password":"test"}`,
+			inputReqBody:   `{"email":"test@example.com","password":"test"}`,
 			inputObj:       domain.CreateUserInput{},
 			mockBehavior:   func(s *mock_service.MockAuthServiceI, in domain.CreateUserInput) {},
 			respStatusCode: http.StatusBadRequest,
@@ -145,19 +542,27 @@ func TestHandler_SignUp(t *testing.T) {
 		},
 		{
 			name:           "Empty password input",
-			inputReqBody:   `{"login":"test"}`,
+			inputReqBody:   `{"login":"test","email":"test@example.com"}`,
 			inputObj:       domain.CreateUserInput{},
 			mockBehavior:   func(s *mock_service.MockAuthServiceI, in domain.CreateUserInput) {},
 			respStatusCode: http.StatusBadRequest,
 			respBody:       `{"success":false,"messages":["invalid 'Password' input"]}`,
 		},
+		{
+			name:           "Invalid email input",
+			inputReqBody:   `{"login":"test","email":"test","password":"test"}`,
+			inputObj:       domain.CreateUserInput{},
+			mockBehavior:   func(s *mock_service.MockAuthServiceI, in domain.CreateUserInput) {},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["invalid 'Email' input"]}`,
+		},
 		{
 			name:           "Empty input",
 			inputReqBody:   `{}`,
 			inputObj:       domain.CreateUserInput{},
 			mockBehavior:   func(s *mock_service.MockAuthServiceI, in domain.CreateUserInput) {},
 			respStatusCode: http.StatusBadRequest,
-			respBody:       `{"success":false,"messages":["invalid 'Login' input","invalid 'Password' input"]}`,
+			respBody:       `{"success":false,"messages":["invalid 'Login' input","invalid 'Email' input","invalid 'Password' input"]}`,
 		},
 		{
 			name:           "Empty body",
@@ -167,18 +572,33 @@ func TestHandler_SignUp(t *testing.T) {
 			respStatusCode: http.StatusBadRequest,
 			respBody:       `{"success":false,"messages":["invalid input body"]}`,
 		},
+		{
+			name:         "Login taken",
+			inputReqBody: `{"login":"test","email":"test@example.com","password":"test"}`,
+			inputObj: domain.CreateUserInput{
+				Login:    "test",
+				Email:    "test@example.com",
+				Password: "test",
+			},
+			mockBehavior: func(s *mock_service.MockAuthServiceI, in domain.CreateUserInput) {
+				s.EXPECT().SignUp(context.Background(), in).Return(domain.Tokens{}, domain.ErrLoginTaken)
+			},
+			respStatusCode: http.StatusConflict,
+			respBody:       `{"success":false,"messages":["login is already taken"]}`,
+		},
 		{
 			name:         "Service error",
-			inputReqBody: `{"login":"test","password":"test"}`,
+			inputReqBody: `{"login":"test","email":"test@example.com","password":"test"}`,
 			inputObj: domain.CreateUserInput{
 				Login:    "test",
+				Email:    "test@example.com",
 				Password: "test",
 			},
 			mockBehavior: func(s *mock_service.MockAuthServiceI, in domain.CreateUserInput) {
-				s.EXPECT().SignUp(context.Background(), in).Return("", errors.New("service error"))
+				s.EXPECT().SignUp(context.Background(), in).Return(domain.Tokens{}, errors.New("service error"))
 			},
 			respStatusCode: http.StatusInternalServerError,
-			respBody:       `{"success":false,"messages":["service error"]}`,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
 		},
 	}
 
@@ -206,3 +626,232 @@ func TestHandler_SignUp(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_Refresh(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAuthServiceI, refreshToken string)
+
+	testCases := []struct {
+		name           string
+		inputReqBody   string
+		refreshToken   string
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:         "OK",
+			inputReqBody: `{"refresh_token":"refresh"}`,
+			refreshToken: "refresh",
+			mockBehavior: func(s *mock_service.MockAuthServiceI, refreshToken string) {
+				s.EXPECT().Refresh(context.Background(), refreshToken).
+					Return(domain.Tokens{AccessToken: "new access", RefreshToken: "new refresh"}, nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":{"access_token":"new access","refresh_token":"new refresh"}}`,
+		},
+		{
+			name:           "Empty refresh token",
+			inputReqBody:   `{}`,
+			mockBehavior:   func(s *mock_service.MockAuthServiceI, refreshToken string) {},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["invalid 'RefreshToken' input"]}`,
+		},
+		{
+			name:         "Invalid refresh token",
+			inputReqBody: `{"refresh_token":"reused"}`,
+			refreshToken: "reused",
+			mockBehavior: func(s *mock_service.MockAuthServiceI, refreshToken string) {
+				s.EXPECT().Refresh(context.Background(), refreshToken).Return(domain.Tokens{}, domain.ErrInvalidRefreshToken)
+			},
+			respStatusCode: http.StatusUnauthorized,
+			respBody:       `{"success":false,"messages":["invalid refresh token"]}`,
+		},
+		{
+			name:         "Service error",
+			inputReqBody: `{"refresh_token":"refresh"}`,
+			refreshToken: "refresh",
+			mockBehavior: func(s *mock_service.MockAuthServiceI, refreshToken string) {
+				s.EXPECT().Refresh(context.Background(), refreshToken).Return(domain.Tokens{}, errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			auth := mock_service.NewMockAuthServiceI(c)
+			testCase.mockBehavior(auth, testCase.refreshToken)
+
+			h := NewHandler(&service.Services{Auth: auth})
+
+			r := gin.New()
+			r.POST("/refresh", h.authRefresh)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/refresh", bytes.NewBufferString(testCase.inputReqBody))
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestHandler_Logout(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAuthServiceI, in domain.LogoutInput)
+
+	testCases := []struct {
+		name           string
+		inputReqBody   string
+		inputObj       domain.LogoutInput
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:         "OK",
+			inputReqBody: ``,
+			inputObj:     domain.LogoutInput{},
+			mockBehavior: func(s *mock_service.MockAuthServiceI, in domain.LogoutInput) {
+				s.EXPECT().Logout(context.Background(), "token", in).Return(nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":null}`,
+		},
+		{
+			name:         "With refresh token",
+			inputReqBody: `{"refresh_token":"refresh"}`,
+			inputObj:     domain.LogoutInput{RefreshToken: "refresh"},
+			mockBehavior: func(s *mock_service.MockAuthServiceI, in domain.LogoutInput) {
+				s.EXPECT().Logout(context.Background(), "token", in).Return(nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":null}`,
+		},
+		{
+			name:           "Invalid body",
+			inputReqBody:   `{"refresh_token":`,
+			inputObj:       domain.LogoutInput{},
+			mockBehavior:   func(s *mock_service.MockAuthServiceI, in domain.LogoutInput) {},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["invalid input body"]}`,
+		},
+		{
+			name:         "Service error",
+			inputReqBody: ``,
+			inputObj:     domain.LogoutInput{},
+			mockBehavior: func(s *mock_service.MockAuthServiceI, in domain.LogoutInput) {
+				s.EXPECT().Logout(context.Background(), "token", in).Return(errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			auth := mock_service.NewMockAuthServiceI(c)
+			testCase.mockBehavior(auth, testCase.inputObj)
+
+			h := NewHandler(&service.Services{Auth: auth})
+
+			r := gin.New()
+			r.POST("/logout", func(c *gin.Context) {
+				c.Set(tokenCtx, "token")
+			}, h.authLogout)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/logout", bytes.NewBufferString(testCase.inputReqBody))
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestHandler_LogoutAll(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAuthServiceI)
+
+	testCases := []struct {
+		name           string
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockAuthServiceI) {
+				s.EXPECT().LogoutAll(context.Background(), "userId").Return(nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":null}`,
+		},
+		{
+			name: "Service error",
+			mockBehavior: func(s *mock_service.MockAuthServiceI) {
+				s.EXPECT().LogoutAll(context.Background(), "userId").Return(errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			auth := mock_service.NewMockAuthServiceI(c)
+			testCase.mockBehavior(auth)
+
+			h := NewHandler(&service.Services{Auth: auth})
+
+			r := gin.New()
+			r.POST("/logout-all", func(c *gin.Context) {
+				c.Set(userCtx, "userId")
+			}, h.authLogoutAll)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/logout-all", nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestHandler_JWKS(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	auth := mock_service.NewMockAuthServiceI(c)
+	auth.EXPECT().GetJWKS(context.Background()).Return(jwtauth.JWKS{Keys: []jwtauth.JWK{
+		{Kty: "OKP", Use: "sig", Kid: "2021-10", Alg: "EdDSA", Crv: "Ed25519", X: "x"},
+	}})
+
+	h := NewHandler(&service.Services{Auth: auth})
+
+	r := gin.New()
+	r.GET("/.well-known/jwks.json", h.authJWKS)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/.well-known/jwks.json", nil)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, w.Code, http.StatusOK)
+	assert.Equal(t, w.Header().Get("Cache-Control"), "public, max-age=300")
+	assert.Equal(t, w.Body.String(), `{"keys":[{"kty":"OKP","use":"sig","kid":"2021-10","alg":"EdDSA","crv":"Ed25519","x":"x"}]}`)
+}
@@ -0,0 +1,82 @@
+package http
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net"
+	"strings"
+)
+
+// forwardingHeaders carry the client address when the request comes through a proxy.
+var forwardingHeaders = []string{"X-Forwarded-For", "X-Real-Ip"}
+
+// SetTrustedProxies sets the addresses or CIDR ranges of the proxies whose forwarding headers
+// are believed. Without any, the client address is always the one the connection comes from.
+func (h *Handler) SetTrustedProxies(proxies []string) error {
+	trusted := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return fmt.Errorf("invalid trusted proxy %q", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				bits = 8 * net.IPv4len
+			}
+			proxy = fmt.Sprintf("%s/%d", proxy, bits)
+		}
+
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return fmt.Errorf("invalid trusted proxy %q", proxy)
+		}
+		trusted = append(trusted, ipNet)
+	}
+
+	h.trustedProxies = trusted
+	return nil
+}
+
+// clientIp is the address sign in failures are counted for. gin's ClientIP is not used, as it
+// takes the forwarding headers of any peer, which would let a client pick a fresh address per
+// attempt. The headers are walked from the end, skipping the trusted proxies, since only the
+// addresses they appended can be relied on.
+func (h *Handler) clientIp(ctx *gin.Context) string {
+	remoteIp, _ := ctx.RemoteIP()
+	if remoteIp == nil {
+		return ""
+	}
+	if !h.isTrustedProxy(remoteIp) {
+		return remoteIp.String()
+	}
+
+	for _, header := range forwardingHeaders {
+		value := ctx.GetHeader(header)
+		if value == "" {
+			continue
+		}
+
+		addrs := strings.Split(value, ",")
+		for i := len(addrs) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(addrs[i]))
+			if ip == nil {
+				break
+			}
+			if i == 0 || !h.isTrustedProxy(ip) {
+				return ip.String()
+			}
+		}
+	}
+
+	return remoteIp.String()
+}
+
+func (h *Handler) isTrustedProxy(ip net.IP) bool {
+	for _, proxy := range h.trustedProxies {
+		if proxy.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/i-vasilkov/go-todo-app/internal/service"
+	"github.com/magiconair/properties/assert"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_clientIp(t *testing.T) {
+	testCases := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		forwardedFor   string
+		realIp         string
+		clientIp       string
+	}{
+		{
+			name:         "No trusted proxies",
+			remoteAddr:   "192.0.2.1:1234",
+			forwardedFor: "203.0.113.7",
+			realIp:       "203.0.113.8",
+			clientIp:     "192.0.2.1",
+		},
+		{
+			name:           "Untrusted peer",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "192.0.2.1:1234",
+			forwardedFor:   "203.0.113.7",
+			clientIp:       "192.0.2.1",
+		},
+		{
+			name:           "Trusted proxy",
+			trustedProxies: []string{"10.0.0.1"},
+			remoteAddr:     "10.0.0.1:1234",
+			forwardedFor:   "203.0.113.7",
+			clientIp:       "203.0.113.7",
+		},
+		{
+			name:           "Address prepended by the client",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.0.0.2:1234",
+			forwardedFor:   "198.51.100.9, 203.0.113.7, 10.0.0.1",
+			clientIp:       "203.0.113.7",
+		},
+		{
+			name:           "Real IP header",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.0.0.2:1234",
+			realIp:         "203.0.113.8",
+			clientIp:       "203.0.113.8",
+		},
+		{
+			name:           "Invalid header",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.0.0.2:1234",
+			forwardedFor:   "unknown",
+			clientIp:       "10.0.0.2",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			h := NewHandler(&service.Services{})
+			assert.Equal(t, h.SetTrustedProxies(testCase.trustedProxies), nil)
+
+			var clientIp string
+			r := gin.New()
+			r.GET("/", func(ctx *gin.Context) {
+				clientIp = h.clientIp(ctx)
+			})
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = testCase.remoteAddr
+			if testCase.forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", testCase.forwardedFor)
+			}
+			if testCase.realIp != "" {
+				req.Header.Set("X-Real-Ip", testCase.realIp)
+			}
+
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, clientIp, testCase.clientIp)
+		})
+	}
+}
+
+func TestHandler_SetTrustedProxies(t *testing.T) {
+	h := NewHandler(&service.Services{})
+
+	assert.Equal(t, h.SetTrustedProxies([]string{"10.0.0.1", "10.1.0.0/16", "::1"}), nil)
+	assert.Equal(t, len(h.trustedProxies), 3)
+	assert.Equal(t, h.SetTrustedProxies([]string{"proxy.local"}) != nil, true)
+}
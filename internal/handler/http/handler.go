@@ -3,6 +3,7 @@ package http
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/i-vasilkov/go-todo-app/internal/service"
+	"net"
 	"net/http"
 
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -12,7 +13,8 @@ import (
 )
 
 type Handler struct {
-	services *service.Services
+	services       *service.Services
+	trustedProxies []*net.IPNet
 }
 
 func NewHandler(services *service.Services) *Handler {
@@ -23,15 +25,25 @@ func NewHandler(services *service.Services) *Handler {
 
 func (h *Handler) Init() http.Handler {
 	router := gin.Default()
+	// Clients are told apart by clientIp, which only believes the configured proxies.
+	router.ForwardedByClientIP = false
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	router.GET("/.well-known/jwks.json", h.authJWKS)
 
 	api := router.Group("/api")
 	{
 		v1 := api.Group("/v1")
 		{
 			h.InitTaskRoutes(v1)
+			h.InitProjectRoutes(v1)
+			h.InitLabelRoutes(v1)
+			h.InitTrashRoutes(v1)
 			h.InitAuthRoutes(v1)
+			h.InitAccountRoutes(v1)
+			h.InitMeRoutes(v1)
+			h.InitAdminRoutes(v1)
+			h.InitAccessTokenRoutes(v1)
 		}
 	}
 
@@ -0,0 +1,178 @@
+package http
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"net/http"
+)
+
+func (h *Handler) InitLabelRoutes(router *gin.RouterGroup) {
+	read := h.RequireScope(domain.ScopeLabelsRead)
+	write := h.RequireScope(domain.ScopeLabelsWrite)
+
+	label := router.Group("/label", h.AuthMiddleware)
+	{
+		label.GET("/", read, h.labelGetAll)
+		label.POST("/", write, h.labelCreate)
+		label.GET("/:id", read, h.labelGetOne)
+		label.PUT("/:id", write, h.labelUpdate)
+		label.DELETE("/:id", write, h.labelDelete)
+	}
+}
+
+// @Summary Getting one label
+// @Description Get one label by id
+// @Security ApiAuth
+// @Tags Label
+// @Accept json
+// @Produce json
+// @Success 200 {object} SuccessResponse{data=domain.Label}
+// @Failure 400,404,422,500 {object} ErrorResponse
+// @Router /label/{id} [get]
+func (h *Handler) labelGetOne(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		NewErrorResponseFromError(ctx, http.StatusBadRequest, errors.New("empty label id"))
+		return
+	}
+
+	userId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	label, err := h.services.Label.Get(ctx.Request.Context(), id, userId)
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, label)
+}
+
+// @Summary Getting labels
+// @Description Get user labels
+// @Security ApiAuth
+// @Tags Label
+// @Accept json
+// @Produce json
+// @Success 200 {object} SuccessResponse{data=[]domain.Label}
+// @Failure 400,422,500 {object} ErrorResponse
+// @Router /label [get]
+func (h *Handler) labelGetAll(ctx *gin.Context) {
+	userId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	labels, err := h.services.Label.GetAll(ctx.Request.Context(), userId)
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, labels)
+}
+
+// @Summary Creating label
+// @Description Create label by input data
+// @Security ApiAuth
+// @Tags Label
+// @Accept json
+// @Produce json
+// @Param input body domain.CreateLabelInput true "input data"
+// @Success 200 {object} SuccessResponse{data=domain.Label}
+// @Failure 400,409,422,500 {object} ErrorResponse
+// @Router /label [post]
+func (h *Handler) labelCreate(ctx *gin.Context) {
+	var in domain.CreateLabelInput
+	if err := ctx.BindJSON(&in); err != nil {
+		NewValidatorErrorResponse(ctx, err)
+		return
+	}
+
+	userId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	label, err := h.services.Label.Create(ctx.Request.Context(), userId, in)
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, label)
+}
+
+// @Summary Updating label
+// @Description Update label by input data
+// @Security ApiAuth
+// @Tags Label
+// @Accept json
+// @Produce json
+// @Param input body domain.UpdateLabelInput true "input data"
+// @Success 200 {object} SuccessResponse{data=domain.Label}
+// @Failure 400,404,409,422,500 {object} ErrorResponse
+// @Router /label/{id} [put]
+func (h *Handler) labelUpdate(ctx *gin.Context) {
+	var in domain.UpdateLabelInput
+	if err := ctx.BindJSON(&in); err != nil {
+		NewValidatorErrorResponse(ctx, err)
+		return
+	}
+
+	id := ctx.Param("id")
+	if id == "" {
+		NewErrorResponseFromError(ctx, http.StatusBadRequest, errors.New("empty label id"))
+		return
+	}
+
+	userId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	label, err := h.services.Label.Update(ctx.Request.Context(), id, userId, in)
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, label)
+}
+
+// @Summary Deleting label
+// @Description Delete label by id and detach it from all tasks
+// @Security ApiAuth
+// @Tags Label
+// @Accept json
+// @Produce json
+// @Success 200 {object} SuccessResponse{data=object}
+// @Failure 400,404,422,500 {object} ErrorResponse
+// @Router /label/{id} [delete]
+func (h *Handler) labelDelete(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		NewErrorResponseFromError(ctx, http.StatusBadRequest, errors.New("empty label id"))
+		return
+	}
+
+	userId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	if err := h.services.Label.Delete(ctx.Request.Context(), id, userId); err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, nil)
+}
@@ -0,0 +1,119 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"github.com/i-vasilkov/go-todo-app/internal/service"
+	"github.com/i-vasilkov/go-todo-app/internal/service/mocks"
+	"github.com/magiconair/properties/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler_labelCreate(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockLabelServiceI, userId string, in domain.CreateLabelInput, label domain.Label)
+
+	testCases := []struct {
+		name           string
+		reqBody        string
+		inputObj       domain.CreateLabelInput
+		userId         string
+		label          domain.Label
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:     "OK",
+			userId:   "userId",
+			reqBody:  `{"name":"work","color":"#ff0000"}`,
+			inputObj: domain.CreateLabelInput{Name: "work", Color: "#ff0000"},
+			label: domain.Label{
+				Id:        "labelId",
+				Name:      "work",
+				Color:     "#ff0000",
+				UserId:    "userId",
+				CreatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+			},
+			mockBehavior: func(s *mock_service.MockLabelServiceI, userId string, in domain.CreateLabelInput, label domain.Label) {
+				s.EXPECT().Create(context.Background(), userId, in).Return(label, nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":{"id":"labelId","name":"work","color":"#ff0000","user_id":"userId","created_at":"2020-01-01T00:00:00Z"}}`,
+		},
+		{
+			name:    "Empty label.name",
+			userId:  "userId",
+			reqBody: `{}`,
+			mockBehavior: func(s *mock_service.MockLabelServiceI, userId string, in domain.CreateLabelInput, label domain.Label) {
+			},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["invalid 'Name' input"]}`,
+		},
+		{
+			name:    "Invalid label.color",
+			userId:  "userId",
+			reqBody: `{"name":"work","color":"red"}`,
+			mockBehavior: func(s *mock_service.MockLabelServiceI, userId string, in domain.CreateLabelInput, label domain.Label) {
+			},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["invalid 'Color' input"]}`,
+		},
+		{
+			name:     "Name taken",
+			userId:   "userId",
+			reqBody:  `{"name":"work"}`,
+			inputObj: domain.CreateLabelInput{Name: "work"},
+			mockBehavior: func(s *mock_service.MockLabelServiceI, userId string, in domain.CreateLabelInput, label domain.Label) {
+				s.EXPECT().Create(context.Background(), userId, in).Return(label, domain.ErrLabelTaken)
+			},
+			respStatusCode: http.StatusConflict,
+			respBody:       `{"success":false,"messages":["label name is already taken"]}`,
+		},
+		{
+			name:     "Service error",
+			userId:   "userId",
+			reqBody:  `{"name":"work"}`,
+			inputObj: domain.CreateLabelInput{Name: "work"},
+			mockBehavior: func(s *mock_service.MockLabelServiceI, userId string, in domain.CreateLabelInput, label domain.Label) {
+				s.EXPECT().Create(context.Background(), userId, in).Return(label, errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			labelService := mock_service.NewMockLabelServiceI(ctrl)
+			testCase.mockBehavior(labelService, testCase.userId, testCase.inputObj, testCase.label)
+
+			services := &service.Services{Label: labelService}
+			handler := NewHandler(services)
+
+			router := gin.New()
+			router.POST("/label", func(ctx *gin.Context) {
+				if testCase.userId != "" {
+					ctx.Set(userCtx, testCase.userId)
+				}
+			}, handler.labelCreate)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/label", bytes.NewBufferString(testCase.reqBody))
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
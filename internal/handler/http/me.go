@@ -0,0 +1,130 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"net/http"
+)
+
+func (h *Handler) InitMeRoutes(router *gin.RouterGroup) {
+	me := router.Group("/me", h.AuthMiddleware, h.RequireSession)
+	{
+		me.GET("", h.meGet)
+		me.PATCH("", h.meUpdate)
+		me.DELETE("", h.meDelete)
+		me.POST("/password", h.meChangePassword)
+	}
+}
+
+// @Summary Getting the profile
+// @Description Get the signed in user
+// @Security ApiAuth
+// @Tags Me
+// @Accept json
+// @Produce json
+// @Success 200 {object} SuccessResponse{data=domain.User}
+// @Failure 401,403,500 {object} ErrorResponse
+// @Router /me [get]
+func (h *Handler) meGet(ctx *gin.Context) {
+	userId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	user, err := h.services.Account.Get(ctx.Request.Context(), userId)
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, user)
+}
+
+// @Summary Updating the profile
+// @Description Change the login or the email of the signed in user. A new email has to be verified again
+// @Security ApiAuth
+// @Tags Me
+// @Accept json
+// @Produce json
+// @Param input body domain.UpdateUserInput true "Profile Input"
+// @Success 200 {object} SuccessResponse{data=domain.User}
+// @Failure 400,401,403,409,500 {object} ErrorResponse
+// @Router /me [patch]
+func (h *Handler) meUpdate(ctx *gin.Context) {
+	var in domain.UpdateUserInput
+	if err := ctx.BindJSON(&in); err != nil {
+		NewValidatorErrorResponse(ctx, err)
+		return
+	}
+
+	userId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	user, err := h.services.Account.Update(ctx.Request.Context(), userId, in)
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, user)
+}
+
+// @Summary Changing the password
+// @Description Replace the password given the current one. Every other session is signed out, the returned tokens replace the ones of the request
+// @Security ApiAuth
+// @Tags Me
+// @Accept json
+// @Produce json
+// @Param input body domain.ChangePasswordInput true "Password Input"
+// @Success 200 {object} SuccessResponse{data=domain.Tokens}
+// @Failure 400,401,403,423,429,500 {object} ErrorResponse
+// @Router /me/password [post]
+func (h *Handler) meChangePassword(ctx *gin.Context) {
+	var in domain.ChangePasswordInput
+	if err := ctx.BindJSON(&in); err != nil {
+		NewValidatorErrorResponse(ctx, err)
+		return
+	}
+
+	userId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	tokens, err := h.services.Auth.ChangePassword(ctx.Request.Context(), userId, in, h.clientIp(ctx))
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, tokens)
+}
+
+// @Summary Deleting the account
+// @Description Delete the signed in user together with all the tasks, projects, labels and tokens
+// @Security ApiAuth
+// @Tags Me
+// @Accept json
+// @Produce json
+// @Success 200 {object} SuccessResponse{data=object}
+// @Failure 401,403,500 {object} ErrorResponse
+// @Router /me [delete]
+func (h *Handler) meDelete(ctx *gin.Context) {
+	userId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	if err := h.services.Account.Delete(ctx.Request.Context(), userId); err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, nil)
+}
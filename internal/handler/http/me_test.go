@@ -0,0 +1,297 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"github.com/i-vasilkov/go-todo-app/internal/service"
+	mock_service "github.com/i-vasilkov/go-todo-app/internal/service/mocks"
+	"github.com/magiconair/properties/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler_MeGet(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAccountServiceI)
+
+	createdAt := time.Date(2021, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name           string
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockAccountServiceI) {
+				s.EXPECT().Get(context.Background(), "userId").Return(domain.User{
+					Id:            "userId",
+					Login:         "test",
+					Password:      "hash",
+					Email:         "test@example.com",
+					EmailVerified: true,
+					TotpSecret:    "secret",
+					CreatedAt:     createdAt,
+				}, nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody: `{"success":true,"data":{"id":"userId","login":"test","email":"test@example.com",` +
+				`"email_verified":true,"totp_enabled":false,"roles":null,"disabled":false,"created_at":"2021-10-18T12:00:00Z"}}`,
+		},
+		{
+			name: "Service error",
+			mockBehavior: func(s *mock_service.MockAccountServiceI) {
+				s.EXPECT().Get(context.Background(), "userId").Return(domain.User{}, errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			account := mock_service.NewMockAccountServiceI(c)
+			testCase.mockBehavior(account)
+
+			handler := NewHandler(&service.Services{Account: account})
+
+			router := gin.New()
+			router.GET("/me", func(ctx *gin.Context) {
+				ctx.Set(userCtx, "userId")
+			}, handler.meGet)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/me", nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestHandler_MeUpdate(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAccountServiceI)
+
+	login := "new"
+	createdAt := time.Date(2021, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name           string
+		inputReqBody   string
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:         "OK",
+			inputReqBody: `{"login":"new"}`,
+			mockBehavior: func(s *mock_service.MockAccountServiceI) {
+				s.EXPECT().Update(context.Background(), "userId", domain.UpdateUserInput{Login: &login}).
+					Return(domain.User{Id: "userId", Login: "new", CreatedAt: createdAt}, nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody: `{"success":true,"data":{"id":"userId","login":"new","email":"",` +
+				`"email_verified":false,"totp_enabled":false,"roles":null,"disabled":false,"created_at":"2021-10-18T12:00:00Z"}}`,
+		},
+		{
+			name:           "Invalid email input",
+			inputReqBody:   `{"email":"test"}`,
+			mockBehavior:   func(s *mock_service.MockAccountServiceI) {},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["invalid 'Email' input"]}`,
+		},
+		{
+			name:           "Empty login input",
+			inputReqBody:   `{"login":""}`,
+			mockBehavior:   func(s *mock_service.MockAccountServiceI) {},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["invalid 'Login' input"]}`,
+		},
+		{
+			name:         "Service error",
+			inputReqBody: `{"login":"new"}`,
+			mockBehavior: func(s *mock_service.MockAccountServiceI) {
+				s.EXPECT().Update(context.Background(), "userId", domain.UpdateUserInput{Login: &login}).
+					Return(domain.User{}, errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			account := mock_service.NewMockAccountServiceI(c)
+			testCase.mockBehavior(account)
+
+			handler := NewHandler(&service.Services{Account: account})
+
+			router := gin.New()
+			router.PATCH("/me", func(ctx *gin.Context) {
+				ctx.Set(userCtx, "userId")
+			}, handler.meUpdate)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PATCH", "/me", bytes.NewBufferString(testCase.inputReqBody))
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestHandler_MeChangePassword(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAuthServiceI)
+
+	input := domain.ChangePasswordInput{CurrentPassword: "old", NewPassword: "new"}
+
+	testCases := []struct {
+		name           string
+		inputReqBody   string
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:         "OK",
+			inputReqBody: `{"current_password":"old","new_password":"new"}`,
+			mockBehavior: func(s *mock_service.MockAuthServiceI) {
+				s.EXPECT().ChangePassword(context.Background(), "userId", input, testClientIp).
+					Return(domain.Tokens{AccessToken: "access", RefreshToken: "refresh"}, nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":{"access_token":"access","refresh_token":"refresh"}}`,
+		},
+		{
+			name:           "Empty new password input",
+			inputReqBody:   `{"current_password":"old"}`,
+			mockBehavior:   func(s *mock_service.MockAuthServiceI) {},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["invalid 'NewPassword' input"]}`,
+		},
+		{
+			name:         "Wrong password",
+			inputReqBody: `{"current_password":"old","new_password":"new"}`,
+			mockBehavior: func(s *mock_service.MockAuthServiceI) {
+				s.EXPECT().ChangePassword(context.Background(), "userId", input, testClientIp).
+					Return(domain.Tokens{}, domain.ErrWrongPassword)
+			},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["` + domain.ErrWrongPassword.Error() + `"]}`,
+		},
+		{
+			name:         "Account locked",
+			inputReqBody: `{"current_password":"old","new_password":"new"}`,
+			mockBehavior: func(s *mock_service.MockAuthServiceI) {
+				s.EXPECT().ChangePassword(context.Background(), "userId", input, testClientIp).
+					Return(domain.Tokens{}, &domain.RetryError{Err: domain.ErrAccountLocked, RetryAfter: time.Minute})
+			},
+			respStatusCode: http.StatusLocked,
+			respBody:       `{"success":false,"messages":["` + domain.ErrAccountLocked.Error() + `"]}`,
+		},
+		{
+			name:         "Service error",
+			inputReqBody: `{"current_password":"old","new_password":"new"}`,
+			mockBehavior: func(s *mock_service.MockAuthServiceI) {
+				s.EXPECT().ChangePassword(context.Background(), "userId", input, testClientIp).
+					Return(domain.Tokens{}, errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			auth := mock_service.NewMockAuthServiceI(c)
+			testCase.mockBehavior(auth)
+
+			handler := NewHandler(&service.Services{Auth: auth})
+
+			router := gin.New()
+			router.POST("/me/password", func(ctx *gin.Context) {
+				ctx.Set(userCtx, "userId")
+			}, handler.meChangePassword)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/me/password", bytes.NewBufferString(testCase.inputReqBody))
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestHandler_MeDelete(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAccountServiceI)
+
+	testCases := []struct {
+		name           string
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockAccountServiceI) {
+				s.EXPECT().Delete(context.Background(), "userId").Return(nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":null}`,
+		},
+		{
+			name: "Service error",
+			mockBehavior: func(s *mock_service.MockAccountServiceI) {
+				s.EXPECT().Delete(context.Background(), "userId").Return(errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			account := mock_service.NewMockAccountServiceI(c)
+			testCase.mockBehavior(account)
+
+			handler := NewHandler(&service.Services{Account: account})
+
+			router := gin.New()
+			router.DELETE("/me", func(ctx *gin.Context) {
+				ctx.Set(userCtx, "userId")
+			}, handler.meDelete)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("DELETE", "/me", nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
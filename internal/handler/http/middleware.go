@@ -4,14 +4,18 @@ import (
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
 	"net/http"
 	"strings"
 )
 
 var (
 	authHeaderName = "Authorization"
-	bearerName = "Bearer"
-	userCtx    = "userId"
+	bearerName     = "Bearer"
+	userCtx        = "userId"
+	tokenCtx       = "token"
+	rolesCtx       = "roles"
+	accessTokenCtx = "accessToken"
 )
 
 func (h *Handler) AuthMiddleware(ctx *gin.Context) {
@@ -27,13 +31,58 @@ func (h *Handler) AuthMiddleware(ctx *gin.Context) {
 		return
 	}
 
-	id, err := h.services.Auth.CheckToken(ctx.Request.Context(), headerParts[1])
+	token := headerParts[1]
+	if strings.HasPrefix(token, domain.AccessTokenPrefix) {
+		accessToken, err := h.services.AccessToken.Check(ctx.Request.Context(), token)
+		if err != nil {
+			NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+			return
+		}
+
+		ctx.Set(userCtx, accessToken.UserId)
+		ctx.Set(accessTokenCtx, accessToken)
+		return
+	}
+
+	identity, err := h.services.Auth.CheckToken(ctx.Request.Context(), token)
 	if err != nil {
 		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
 		return
 	}
 
-	ctx.Set(userCtx, id)
+	ctx.Set(userCtx, identity.UserId)
+	ctx.Set(rolesCtx, identity.Roles)
+	ctx.Set(tokenCtx, token)
+}
+
+// RequireScope lets a request authenticated with a personal access token through only when
+// the token grants the scope. Signed in users are not limited by scopes.
+func (h *Handler) RequireScope(scope domain.Scope) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accessToken, ok := getAccessTokenFromCtx(ctx)
+		if ok && !accessToken.HasScope(scope) {
+			NewErrorResponseFromError(ctx, http.StatusForbidden, fmt.Errorf("access token lacks the '%s' scope", scope))
+		}
+	}
+}
+
+// RequireSession rejects personal access tokens on the routes managing sessions and tokens,
+// so a leaked token can not be used to mint new ones.
+func (h *Handler) RequireSession(ctx *gin.Context) {
+	if _, ok := getAccessTokenFromCtx(ctx); ok {
+		NewErrorResponseFromError(ctx, http.StatusForbidden, errors.New("not allowed with a personal access token"))
+	}
+}
+
+// RequireRole lets a request through only when the access token carries the role. Personal
+// access tokens carry no roles, so they are never let through.
+func (h *Handler) RequireRole(role domain.Role) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		identity := domain.Identity{Roles: getRolesFromCtx(ctx)}
+		if !identity.HasRole(role) {
+			NewErrorResponseFromError(ctx, http.StatusForbidden, fmt.Errorf("the '%s' role is required", role))
+		}
+	}
 }
 
 func GetUserIdFromCtx(ctx *gin.Context) (string, error) {
@@ -43,4 +92,31 @@ func GetUserIdFromCtx(ctx *gin.Context) (string, error) {
 	}
 
 	return idFromCtx.(string), nil
-}
\ No newline at end of file
+}
+
+func GetTokenFromCtx(ctx *gin.Context) (string, error) {
+	tokenFromCtx, exists := ctx.Get(tokenCtx)
+	if !exists {
+		return "", fmt.Errorf("not exists token in context")
+	}
+
+	return tokenFromCtx.(string), nil
+}
+
+func getAccessTokenFromCtx(ctx *gin.Context) (domain.AccessToken, bool) {
+	accessToken, exists := ctx.Get(accessTokenCtx)
+	if !exists {
+		return domain.AccessToken{}, false
+	}
+
+	return accessToken.(domain.AccessToken), true
+}
+
+func getRolesFromCtx(ctx *gin.Context) []domain.Role {
+	roles, exists := ctx.Get(rolesCtx)
+	if !exists {
+		return nil
+	}
+
+	return roles.([]domain.Role)
+}
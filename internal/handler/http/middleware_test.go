@@ -5,6 +5,7 @@ import (
 	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
 	"github.com/i-vasilkov/go-todo-app/internal/service"
 	"github.com/i-vasilkov/go-todo-app/internal/service/mocks"
 	"github.com/magiconair/properties/assert"
@@ -17,10 +18,10 @@ func TestHandler_AuthMiddleware(t *testing.T) {
 	type mockBehaviour func(s *mock_service.MockAuthServiceI, token string)
 
 	testCases := []struct {
-		name          string
-		headerIsSet   bool
-		header        string
-		token         string
+		name           string
+		headerIsSet    bool
+		header         string
+		token          string
 		mockBehaviour  mockBehaviour
 		respStatusCode int
 		respBody       string
@@ -31,7 +32,7 @@ func TestHandler_AuthMiddleware(t *testing.T) {
 			header:      "Bearer token",
 			token:       "token",
 			mockBehaviour: func(s *mock_service.MockAuthServiceI, token string) {
-				s.EXPECT().CheckToken(context.Background(), token).Return("userId", nil)
+				s.EXPECT().CheckToken(context.Background(), token).Return(domain.Identity{UserId: "userId"}, nil)
 			},
 			respStatusCode: http.StatusOK,
 			respBody:       "userId",
@@ -82,12 +83,12 @@ func TestHandler_AuthMiddleware(t *testing.T) {
 			respBody:       `{"success":false,"messages":["not valid auth header"]}`,
 		},
 		{
-			name:          "Parse token error",
-			headerIsSet:   true,
-			header:        "Bearer token",
-			token:         "token",
+			name:        "Parse token error",
+			headerIsSet: true,
+			header:      "Bearer token",
+			token:       "token",
 			mockBehaviour: func(s *mock_service.MockAuthServiceI, token string) {
-				s.EXPECT().CheckToken(context.Background(), token).Return("", errors.New("service error"))
+				s.EXPECT().CheckToken(context.Background(), token).Return(domain.Identity{}, errors.New("service error"))
 			},
 			respStatusCode: http.StatusUnauthorized,
 			respBody:       `{"success":false,"messages":["service error"]}`,
@@ -124,3 +125,212 @@ func TestHandler_AuthMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_AuthMiddlewareAccessToken(t *testing.T) {
+	type mockBehaviour func(s *mock_service.MockAccessTokenServiceI, token string)
+
+	testCases := []struct {
+		name           string
+		token          string
+		mockBehaviour  mockBehaviour
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:  "OK",
+			token: "tdp_secret",
+			mockBehaviour: func(s *mock_service.MockAccessTokenServiceI, token string) {
+				s.EXPECT().Check(context.Background(), token).Return(domain.AccessToken{Id: "tokenId", UserId: "userId"}, nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       "userId",
+		},
+		{
+			name:  "Invalid token",
+			token: "tdp_secret",
+			mockBehaviour: func(s *mock_service.MockAccessTokenServiceI, token string) {
+				s.EXPECT().Check(context.Background(), token).Return(domain.AccessToken{}, domain.ErrInvalidAccessToken)
+			},
+			respStatusCode: http.StatusUnauthorized,
+			respBody:       `{"success":false,"messages":["` + domain.ErrInvalidAccessToken.Error() + `"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			tokens := mock_service.NewMockAccessTokenServiceI(c)
+			testCase.mockBehaviour(tokens, testCase.token)
+
+			services := &service.Services{AccessToken: tokens}
+			handler := NewHandler(services)
+
+			router := gin.New()
+			router.GET("/protected", handler.AuthMiddleware, func(c *gin.Context) {
+				id, _ := c.Get(userCtx)
+				c.String(http.StatusOK, id.(string))
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/protected", nil)
+			req.Header.Set(authHeaderName, bearerName+" "+testCase.token)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestHandler_RequireScope(t *testing.T) {
+	testCases := []struct {
+		name           string
+		accessToken    *domain.AccessToken
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:           "Session",
+			accessToken:    nil,
+			respStatusCode: http.StatusOK,
+			respBody:       "ok",
+		},
+		{
+			name:           "Access token with scope",
+			accessToken:    &domain.AccessToken{Scopes: []domain.Scope{domain.ScopeTasksRead, domain.ScopeTasksWrite}},
+			respStatusCode: http.StatusOK,
+			respBody:       "ok",
+		},
+		{
+			name:           "Access token without scope",
+			accessToken:    &domain.AccessToken{Scopes: []domain.Scope{domain.ScopeTasksRead}},
+			respStatusCode: http.StatusForbidden,
+			respBody:       `{"success":false,"messages":["access token lacks the 'tasks:write' scope"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			handler := NewHandler(&service.Services{})
+
+			router := gin.New()
+			router.POST("/tasks", func(ctx *gin.Context) {
+				if testCase.accessToken != nil {
+					ctx.Set(accessTokenCtx, *testCase.accessToken)
+				}
+			}, handler.RequireScope(domain.ScopeTasksWrite), func(c *gin.Context) {
+				c.String(http.StatusOK, "ok")
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/tasks", nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestHandler_RequireSession(t *testing.T) {
+	testCases := []struct {
+		name           string
+		accessToken    *domain.AccessToken
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:           "Session",
+			accessToken:    nil,
+			respStatusCode: http.StatusOK,
+			respBody:       "ok",
+		},
+		{
+			name:           "Access token",
+			accessToken:    &domain.AccessToken{Scopes: []domain.Scope{domain.ScopeTasksRead}},
+			respStatusCode: http.StatusForbidden,
+			respBody:       `{"success":false,"messages":["not allowed with a personal access token"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			handler := NewHandler(&service.Services{})
+
+			router := gin.New()
+			router.GET("/tokens", func(ctx *gin.Context) {
+				if testCase.accessToken != nil {
+					ctx.Set(accessTokenCtx, *testCase.accessToken)
+				}
+			}, handler.RequireSession, func(c *gin.Context) {
+				c.String(http.StatusOK, "ok")
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/tokens", nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestHandler_RequireRole(t *testing.T) {
+	testCases := []struct {
+		name           string
+		roles          []domain.Role
+		accessToken    *domain.AccessToken
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:           "Admin",
+			roles:          []domain.Role{domain.RoleAdmin},
+			respStatusCode: http.StatusOK,
+			respBody:       "ok",
+		},
+		{
+			name:           "No roles",
+			roles:          nil,
+			respStatusCode: http.StatusForbidden,
+			respBody:       `{"success":false,"messages":["the 'admin' role is required"]}`,
+		},
+		{
+			name:           "Access token",
+			accessToken:    &domain.AccessToken{Scopes: []domain.Scope{domain.ScopeTasksRead}},
+			respStatusCode: http.StatusForbidden,
+			respBody:       `{"success":false,"messages":["the 'admin' role is required"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			handler := NewHandler(&service.Services{})
+
+			router := gin.New()
+			router.GET("/admin", func(ctx *gin.Context) {
+				if testCase.accessToken != nil {
+					ctx.Set(accessTokenCtx, *testCase.accessToken)
+					return
+				}
+				ctx.Set(rolesCtx, testCase.roles)
+			}, handler.RequireRole(domain.RoleAdmin), func(c *gin.Context) {
+				c.String(http.StatusOK, "ok")
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/admin", nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
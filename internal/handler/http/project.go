@@ -0,0 +1,189 @@
+package http
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"net/http"
+)
+
+func (h *Handler) InitProjectRoutes(router *gin.RouterGroup) {
+	read := h.RequireScope(domain.ScopeProjectsRead)
+	write := h.RequireScope(domain.ScopeProjectsWrite)
+
+	project := router.Group("/project", h.AuthMiddleware)
+	{
+		project.GET("/", read, h.projectGetAll)
+		project.POST("/", write, h.projectCreate)
+		project.GET("/:id", read, h.projectGetOne)
+		project.PUT("/:id", write, h.projectUpdate)
+		project.DELETE("/:id", write, h.projectDelete)
+	}
+}
+
+// @Summary Getting one project
+// @Description Get one project by id
+// @Security ApiAuth
+// @Tags Project
+// @Accept json
+// @Produce json
+// @Success 200 {object} SuccessResponse{data=domain.Project}
+// @Failure 400,404,422,500 {object} ErrorResponse
+// @Router /project/{id} [get]
+func (h *Handler) projectGetOne(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		NewErrorResponseFromError(ctx, http.StatusBadRequest, errors.New("empty project id"))
+		return
+	}
+
+	userId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	project, err := h.services.Project.Get(ctx.Request.Context(), id, userId)
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, project)
+}
+
+// @Summary Getting projects
+// @Description Get user projects
+// @Security ApiAuth
+// @Tags Project
+// @Accept json
+// @Produce json
+// @Success 200 {object} SuccessResponse{data=[]domain.Project}
+// @Failure 400,422,500 {object} ErrorResponse
+// @Router /project [get]
+func (h *Handler) projectGetAll(ctx *gin.Context) {
+	userId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	projects, err := h.services.Project.GetAll(ctx.Request.Context(), userId)
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, projects)
+}
+
+// @Summary Creating project
+// @Description Create project by input data
+// @Security ApiAuth
+// @Tags Project
+// @Accept json
+// @Produce json
+// @Param input body domain.CreateProjectInput true "input data"
+// @Success 200 {object} SuccessResponse{data=domain.Project}
+// @Failure 400,422,500 {object} ErrorResponse
+// @Router /project [post]
+func (h *Handler) projectCreate(ctx *gin.Context) {
+	var in domain.CreateProjectInput
+	if err := ctx.BindJSON(&in); err != nil {
+		NewValidatorErrorResponse(ctx, err)
+		return
+	}
+
+	userId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	project, err := h.services.Project.Create(ctx.Request.Context(), userId, in)
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, project)
+}
+
+// @Summary Updating project
+// @Description Update project by input data
+// @Security ApiAuth
+// @Tags Project
+// @Accept json
+// @Produce json
+// @Param input body domain.UpdateProjectInput true "input data"
+// @Success 200 {object} SuccessResponse{data=domain.Project}
+// @Failure 400,404,422,500 {object} ErrorResponse
+// @Router /project/{id} [put]
+func (h *Handler) projectUpdate(ctx *gin.Context) {
+	var in domain.UpdateProjectInput
+	if err := ctx.BindJSON(&in); err != nil {
+		NewValidatorErrorResponse(ctx, err)
+		return
+	}
+
+	id := ctx.Param("id")
+	if id == "" {
+		NewErrorResponseFromError(ctx, http.StatusBadRequest, errors.New("empty project id"))
+		return
+	}
+
+	userId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	project, err := h.services.Project.Update(ctx.Request.Context(), id, userId, in)
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, project)
+}
+
+type projectDeleteQuery struct {
+	Tasks domain.ProjectDeleteMode `form:"tasks" binding:"omitempty,oneof=inbox cascade"`
+}
+
+// @Summary Deleting project
+// @Description Delete project by id. Its tasks are moved to the inbox unless tasks=cascade is passed, which moves them with their subtasks to the trash.
+// @Security ApiAuth
+// @Tags Project
+// @Accept json
+// @Produce json
+// @Param tasks query string false "what to do with project tasks" Enums(inbox, cascade)
+// @Success 200 {object} SuccessResponse{data=object}
+// @Failure 400,404,422,500 {object} ErrorResponse
+// @Router /project/{id} [delete]
+func (h *Handler) projectDelete(ctx *gin.Context) {
+	var query projectDeleteQuery
+	if err := ctx.BindQuery(&query); err != nil {
+		NewValidatorErrorResponse(ctx, err)
+		return
+	}
+
+	id := ctx.Param("id")
+	if id == "" {
+		NewErrorResponseFromError(ctx, http.StatusBadRequest, errors.New("empty project id"))
+		return
+	}
+
+	userId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	if err := h.services.Project.Delete(ctx.Request.Context(), id, userId, query.Tasks); err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, nil)
+}
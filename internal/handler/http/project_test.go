@@ -0,0 +1,421 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"github.com/i-vasilkov/go-todo-app/internal/service"
+	"github.com/i-vasilkov/go-todo-app/internal/service/mocks"
+	"github.com/magiconair/properties/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler_projectGetOne(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockProjectServiceI, id, userId string, project domain.Project)
+
+	testCases := []struct {
+		name           string
+		projectId      string
+		userId         string
+		project        domain.Project
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:      "OK",
+			projectId: "projectId",
+			userId:    "userId",
+			project: domain.Project{
+				Id:        "projectId",
+				Name:      "test",
+				UserId:    "userId",
+				CreatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+				UpdatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+			},
+			mockBehavior: func(s *mock_service.MockProjectServiceI, id, userId string, project domain.Project) {
+				s.EXPECT().Get(context.Background(), id, userId).Return(project, nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":{"id":"projectId","name":"test","user_id":"userId","created_at":"2020-01-01T00:00:00Z","updated_at":"2020-01-01T00:00:00Z"}}`,
+		},
+		{
+			name:           "Empty UserId",
+			projectId:      "projectId",
+			userId:         "",
+			project:        domain.Project{},
+			mockBehavior:   func(s *mock_service.MockProjectServiceI, id, userId string, project domain.Project) {},
+			respStatusCode: http.StatusUnauthorized,
+			respBody:       `{"success":false,"messages":["not exists userId in context"]}`,
+		},
+		{
+			name:      "Service error",
+			projectId: "projectId",
+			userId:    "userId",
+			project:   domain.Project{},
+			mockBehavior: func(s *mock_service.MockProjectServiceI, id, userId string, project domain.Project) {
+				s.EXPECT().Get(context.Background(), id, userId).Return(project, errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			projectService := mock_service.NewMockProjectServiceI(ctrl)
+			testCase.mockBehavior(projectService, testCase.projectId, testCase.userId, testCase.project)
+
+			services := &service.Services{Project: projectService}
+			handler := NewHandler(services)
+
+			router := gin.New()
+			router.GET("/project/:id", func(ctx *gin.Context) {
+				if testCase.userId != "" {
+					ctx.Set(userCtx, testCase.userId)
+				}
+			}, handler.projectGetOne)
+
+			w := httptest.NewRecorder()
+			reqUrl := fmt.Sprintf("/project/%s", testCase.projectId)
+			req := httptest.NewRequest("GET", reqUrl, nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestHandler_projectGetAll(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockProjectServiceI, userId string, projects []domain.Project)
+
+	testCases := []struct {
+		name           string
+		userId         string
+		projects       []domain.Project
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:   "OK",
+			userId: "userId",
+			projects: []domain.Project{
+				{
+					Id:        "projectId",
+					Name:      "test",
+					UserId:    "userId",
+					CreatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+					UpdatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			mockBehavior: func(s *mock_service.MockProjectServiceI, userId string, projects []domain.Project) {
+				s.EXPECT().GetAll(context.Background(), userId).Return(projects, nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":[{"id":"projectId","name":"test","user_id":"userId","created_at":"2020-01-01T00:00:00Z","updated_at":"2020-01-01T00:00:00Z"}]}`,
+		},
+		{
+			name:   "Service error",
+			userId: "userId",
+			mockBehavior: func(s *mock_service.MockProjectServiceI, userId string, projects []domain.Project) {
+				s.EXPECT().GetAll(context.Background(), userId).Return(projects, errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			projectService := mock_service.NewMockProjectServiceI(ctrl)
+			testCase.mockBehavior(projectService, testCase.userId, testCase.projects)
+
+			services := &service.Services{Project: projectService}
+			handler := NewHandler(services)
+
+			router := gin.New()
+			router.GET("/project", func(ctx *gin.Context) {
+				if testCase.userId != "" {
+					ctx.Set(userCtx, testCase.userId)
+				}
+			}, handler.projectGetAll)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/project", nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestHandler_projectCreate(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockProjectServiceI, userId string, in domain.CreateProjectInput, project domain.Project)
+
+	testCases := []struct {
+		name           string
+		reqBody        string
+		inputObj       domain.CreateProjectInput
+		userId         string
+		project        domain.Project
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:     "OK",
+			userId:   "userId",
+			reqBody:  `{"name":"test"}`,
+			inputObj: domain.CreateProjectInput{Name: "test"},
+			project: domain.Project{
+				Id:        "projectId",
+				Name:      "test",
+				UserId:    "userId",
+				CreatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+				UpdatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+			},
+			mockBehavior: func(s *mock_service.MockProjectServiceI, userId string, in domain.CreateProjectInput, project domain.Project) {
+				s.EXPECT().Create(context.Background(), userId, in).Return(project, nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":{"id":"projectId","name":"test","user_id":"userId","created_at":"2020-01-01T00:00:00Z","updated_at":"2020-01-01T00:00:00Z"}}`,
+		},
+		{
+			name:    "Empty project.name",
+			userId:  "userId",
+			reqBody: `{}`,
+			mockBehavior: func(s *mock_service.MockProjectServiceI, userId string, in domain.CreateProjectInput, project domain.Project) {
+			},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["invalid 'Name' input"]}`,
+		},
+		{
+			name:     "Service error",
+			userId:   "userId",
+			reqBody:  `{"name":"test"}`,
+			inputObj: domain.CreateProjectInput{Name: "test"},
+			mockBehavior: func(s *mock_service.MockProjectServiceI, userId string, in domain.CreateProjectInput, project domain.Project) {
+				s.EXPECT().Create(context.Background(), userId, in).Return(project, errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			projectService := mock_service.NewMockProjectServiceI(ctrl)
+			testCase.mockBehavior(projectService, testCase.userId, testCase.inputObj, testCase.project)
+
+			services := &service.Services{Project: projectService}
+			handler := NewHandler(services)
+
+			router := gin.New()
+			router.POST("/project", func(ctx *gin.Context) {
+				if testCase.userId != "" {
+					ctx.Set(userCtx, testCase.userId)
+				}
+			}, handler.projectCreate)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/project", bytes.NewBufferString(testCase.reqBody))
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestHandler_projectUpdate(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockProjectServiceI, id, userId string, in domain.UpdateProjectInput, project domain.Project)
+
+	testCases := []struct {
+		name           string
+		reqBody        string
+		inputObj       domain.UpdateProjectInput
+		projectId      string
+		userId         string
+		project        domain.Project
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:      "OK",
+			projectId: "projectId",
+			userId:    "userId",
+			reqBody:   `{"name":"updated"}`,
+			inputObj:  domain.UpdateProjectInput{Name: "updated"},
+			project: domain.Project{
+				Id:        "projectId",
+				Name:      "updated",
+				UserId:    "userId",
+				CreatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+				UpdatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+			},
+			mockBehavior: func(s *mock_service.MockProjectServiceI, id, userId string, in domain.UpdateProjectInput, project domain.Project) {
+				s.EXPECT().Update(context.Background(), id, userId, in).Return(project, nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":{"id":"projectId","name":"updated","user_id":"userId","created_at":"2020-01-01T00:00:00Z","updated_at":"2020-01-01T00:00:00Z"}}`,
+		},
+		{
+			name:      "Empty project.name",
+			projectId: "projectId",
+			userId:    "userId",
+			reqBody:   `{}`,
+			mockBehavior: func(s *mock_service.MockProjectServiceI, id, userId string, in domain.UpdateProjectInput, project domain.Project) {
+			},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["invalid 'Name' input"]}`,
+		},
+		{
+			name:      "Service error",
+			projectId: "projectId",
+			userId:    "userId",
+			reqBody:   `{"name":"updated"}`,
+			inputObj:  domain.UpdateProjectInput{Name: "updated"},
+			mockBehavior: func(s *mock_service.MockProjectServiceI, id, userId string, in domain.UpdateProjectInput, project domain.Project) {
+				s.EXPECT().Update(context.Background(), id, userId, in).Return(project, errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			projectService := mock_service.NewMockProjectServiceI(ctrl)
+			testCase.mockBehavior(projectService, testCase.projectId, testCase.userId, testCase.inputObj, testCase.project)
+
+			services := &service.Services{Project: projectService}
+			handler := NewHandler(services)
+
+			router := gin.New()
+			router.PUT("/project/:id", func(ctx *gin.Context) {
+				if testCase.userId != "" {
+					ctx.Set(userCtx, testCase.userId)
+				}
+			}, handler.projectUpdate)
+
+			w := httptest.NewRecorder()
+			reqUrl := fmt.Sprintf("/project/%s", testCase.projectId)
+			req := httptest.NewRequest("PUT", reqUrl, bytes.NewBufferString(testCase.reqBody))
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestHandler_projectDelete(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockProjectServiceI, id, userId string)
+
+	testCases := []struct {
+		name           string
+		projectId      string
+		userId         string
+		query          string
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:      "OK",
+			projectId: "projectId",
+			userId:    "userId",
+			mockBehavior: func(s *mock_service.MockProjectServiceI, id, userId string) {
+				s.EXPECT().Delete(context.Background(), id, userId, domain.ProjectDeleteMode("")).Return(nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":null}`,
+		},
+		{
+			name:      "Cascade",
+			projectId: "projectId",
+			userId:    "userId",
+			query:     "tasks=cascade",
+			mockBehavior: func(s *mock_service.MockProjectServiceI, id, userId string) {
+				s.EXPECT().Delete(context.Background(), id, userId, domain.ProjectDeleteCascade).Return(nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":null}`,
+		},
+		{
+			name:           "Invalid mode",
+			projectId:      "projectId",
+			userId:         "userId",
+			query:          "tasks=archive",
+			mockBehavior:   func(s *mock_service.MockProjectServiceI, id, userId string) {},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["invalid 'Tasks' input"]}`,
+		},
+		{
+			name:      "Service error",
+			projectId: "projectId",
+			userId:    "userId",
+			mockBehavior: func(s *mock_service.MockProjectServiceI, id, userId string) {
+				s.EXPECT().Delete(context.Background(), id, userId, domain.ProjectDeleteMode("")).Return(errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			projectService := mock_service.NewMockProjectServiceI(ctrl)
+			testCase.mockBehavior(projectService, testCase.projectId, testCase.userId)
+
+			services := &service.Services{Project: projectService}
+			handler := NewHandler(services)
+
+			router := gin.New()
+			router.DELETE("/project/:id", func(ctx *gin.Context) {
+				if testCase.userId != "" {
+					ctx.Set(userCtx, testCase.userId)
+				}
+			}, handler.projectDelete)
+
+			w := httptest.NewRecorder()
+			reqUrl := fmt.Sprintf("/project/%s?%s", testCase.projectId, testCase.query)
+			req := httptest.NewRequest("DELETE", reqUrl, nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
@@ -1,34 +1,171 @@
 package http
 
 import (
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
 	"log"
+	"math"
+	"mime"
 	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
 )
 
+// problemMIME is the media type of RFC 7807 problem details. Clients get them instead of the
+// ErrorResponse envelope by asking for this type in the Accept header.
+const problemMIME = "application/problem+json"
+
 type ErrorResponse struct {
 	Success  bool     `json:"success" example:"false"`
 	Messages []string `json:"messages"`
 }
 
+// ProblemResponse describes an error the way RFC 7807 does. Errors lists the binding rules the
+// input fields failed.
+type ProblemResponse struct {
+	Type     string         `json:"type" example:"about:blank"`
+	Title    string         `json:"title" example:"Bad Request"`
+	Status   int            `json:"status" example:"400"`
+	Detail   string         `json:"detail" example:"invalid 'Name' input"`
+	Instance string         `json:"instance" example:"/api/v1/label"`
+	Errors   []ProblemError `json:"errors,omitempty"`
+}
+
+// ProblemError names the field the way the client sent it, by its json or form key.
+type ProblemError struct {
+	Field string `json:"field" example:"name"`
+	Rule  string `json:"rule" example:"max"`
+	Param string `json:"param" example:"64"`
+}
+
 type SuccessResponse struct {
 	Success bool        `json:"success" example:"true"`
 	Data    interface{} `json:"data" extensions:"x-nullable"`
 }
 
+type ListResponse struct {
+	Success    bool        `json:"success" example:"true"`
+	Data       interface{} `json:"data"`
+	NextCursor string      `json:"next_cursor"`
+}
+
 func NewErrorResponse(ctx *gin.Context, code int, messages []string) {
+	newErrorResponse(ctx, code, messages, nil)
+}
+
+// newErrorResponse answers in the format the client negotiated, the ErrorResponse envelope
+// unless problem details are preferred.
+func newErrorResponse(ctx *gin.Context, code int, messages []string, problemErrors []ProblemError) {
 	log.Println(messages)
-	ctx.AbortWithStatusJSON(code, ErrorResponse{false, messages})
+
+	if !acceptsProblem(ctx.GetHeader("Accept")) {
+		ctx.AbortWithStatusJSON(code, ErrorResponse{false, messages})
+		return
+	}
+
+	ctx.Header("Content-Type", problemMIME)
+	ctx.AbortWithStatusJSON(code, ProblemResponse{
+		Type:     "about:blank",
+		Title:    http.StatusText(code),
+		Status:   code,
+		Detail:   strings.Join(messages, "; "),
+		Instance: ctx.Request.URL.Path,
+		Errors:   problemErrors,
+	})
+}
+
+// acceptsProblem reports whether the Accept header names problem details and does not rate
+// them below JSON. Anything else, */* included, keeps the envelope the clients already know.
+func acceptsProblem(accept string) bool {
+	problemQ, jsonQ := 0.0, 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+
+		switch mediaType {
+		case problemMIME:
+			problemQ = q
+		case gin.MIMEJSON, "application/*", "*/*":
+			jsonQ = math.Max(jsonQ, q)
+		}
+	}
+
+	return problemQ > 0 && problemQ >= jsonQ
 }
 
 func NewErrorResponseFromError(ctx *gin.Context, code int, err error) {
 	NewErrorResponse(ctx, code, []string{err.Error()})
 }
 
+// NewServiceErrorResponse reports an error returned by a service. The domain errors get the
+// status code of their kind and their own message, a domain.RetryError also tells the client
+// in the Retry-After header when to try again. Anything else is only logged, the client gets
+// a generic message instead of the details of the storage.
+func NewServiceErrorResponse(ctx *gin.Context, err error) {
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		if code, ok := errorKindStatus[domainErr.Kind]; ok {
+			var retry *domain.RetryError
+			if errors.As(err, &retry) {
+				ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(retry.RetryAfter.Seconds()))))
+			}
+			NewErrorResponse(ctx, code, []string{domainErr.Message})
+			return
+		}
+	}
+
+	log.Println(err)
+	NewErrorResponse(ctx, http.StatusInternalServerError, []string{"internal server error"})
+}
+
+var errorKindStatus = map[error]int{
+	domain.ErrNotFound:        http.StatusNotFound,
+	domain.ErrConflict:        http.StatusConflict,
+	domain.ErrValidation:      http.StatusUnprocessableEntity,
+	domain.ErrBadRequest:      http.StatusBadRequest,
+	domain.ErrUnauthorized:    http.StatusUnauthorized,
+	domain.ErrForbidden:       http.StatusForbidden,
+	domain.ErrLocked:          http.StatusLocked,
+	domain.ErrTooManyRequests: http.StatusTooManyRequests,
+}
+
+// The validator reports the fields by the keys the inputs are bound from, the struct field names
+// stay available for the messages.
+func init() {
+	if engine, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		engine.RegisterTagNameFunc(inputFieldName)
+	}
+}
+
+// inputFieldName is the json key of the field, or the form key of a query field. An empty name
+// makes the validator fall back to the struct field name.
+func inputFieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return ""
+}
+
 func NewValidatorErrorResponse(ctx *gin.Context, err error) {
 	var messages []string
+	var problemErrors []ProblemError
 
 	validatorErrors, ok := err.(validator.ValidationErrors)
 	if !ok {
@@ -37,11 +174,20 @@ func NewValidatorErrorResponse(ctx *gin.Context, err error) {
 	}
 
 	for _, fieldErr := range validatorErrors {
-		messages = append(messages, fmt.Sprintf("invalid '%v' input", fieldErr.Field()))
+		messages = append(messages, fmt.Sprintf("invalid '%v' input", fieldErr.StructField()))
+		problemErrors = append(problemErrors, ProblemError{
+			Field: fieldErr.Field(),
+			Rule:  fieldErr.Tag(),
+			Param: fieldErr.Param(),
+		})
 	}
-	NewErrorResponse(ctx, http.StatusBadRequest, messages)
+	newErrorResponse(ctx, http.StatusBadRequest, messages, problemErrors)
 }
 
 func NewSuccessResponse(ctx *gin.Context, data interface{}) {
 	ctx.JSON(http.StatusOK, SuccessResponse{true, data})
 }
+
+func NewListResponse(ctx *gin.Context, data interface{}, nextCursor string) {
+	ctx.JSON(http.StatusOK, ListResponse{true, data, nextCursor})
+}
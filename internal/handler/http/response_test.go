@@ -0,0 +1,211 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"github.com/magiconair/properties/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewValidatorErrorResponse(t *testing.T) {
+	testCases := []struct {
+		name            string
+		accept          string
+		reqBody         string
+		respStatusCode  int
+		respContentType string
+		respBody        string
+	}{
+		{
+			name:            "Envelope by default",
+			accept:          "",
+			reqBody:         `{"color":"red"}`,
+			respStatusCode:  http.StatusBadRequest,
+			respContentType: "application/json; charset=utf-8",
+			respBody:        `{"success":false,"messages":["invalid 'Name' input","invalid 'Color' input"]}`,
+		},
+		{
+			name:            "Envelope for any type",
+			accept:          "*/*",
+			reqBody:         `{"color":"red"}`,
+			respStatusCode:  http.StatusBadRequest,
+			respContentType: "application/json; charset=utf-8",
+			respBody:        `{"success":false,"messages":["invalid 'Name' input","invalid 'Color' input"]}`,
+		},
+		{
+			name:            "Envelope preferred over problem",
+			accept:          "application/json, application/problem+json;q=0.5",
+			reqBody:         `{"color":"red"}`,
+			respStatusCode:  http.StatusBadRequest,
+			respContentType: "application/json; charset=utf-8",
+			respBody:        `{"success":false,"messages":["invalid 'Name' input","invalid 'Color' input"]}`,
+		},
+		{
+			name:            "Problem",
+			accept:          "application/problem+json",
+			reqBody:         `{"name":"work","color":"red"}`,
+			respStatusCode:  http.StatusBadRequest,
+			respContentType: "application/problem+json",
+			respBody: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid 'Color' input",` +
+				`"instance":"/label","errors":[{"field":"color","rule":"hexcolor","param":""}]}`,
+		},
+		{
+			name:            "Problem with a rule param",
+			accept:          "application/problem+json, application/json",
+			reqBody:         `{"name":"` + strings.Repeat("a", 65) + `"}`,
+			respStatusCode:  http.StatusBadRequest,
+			respContentType: "application/problem+json",
+			respBody: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid 'Name' input",` +
+				`"instance":"/label","errors":[{"field":"name","rule":"max","param":"64"}]}`,
+		},
+		{
+			name:            "Problem for invalid body",
+			accept:          "application/problem+json",
+			reqBody:         `{`,
+			respStatusCode:  http.StatusBadRequest,
+			respContentType: "application/problem+json",
+			respBody:        `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid input body","instance":"/label"}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			router := gin.New()
+			router.POST("/label", func(ctx *gin.Context) {
+				var in domain.CreateLabelInput
+				if err := ctx.ShouldBindJSON(&in); err != nil {
+					NewValidatorErrorResponse(ctx, err)
+				}
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/label", bytes.NewBufferString(testCase.reqBody))
+			if testCase.accept != "" {
+				req.Header.Set("Accept", testCase.accept)
+			}
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Header().Get("Content-Type"), testCase.respContentType)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestNewValidatorErrorResponse_FieldNames(t *testing.T) {
+	type input struct {
+		ProjectId string   `json:"project_id,omitempty" binding:"required"`
+		Render    string   `form:"render" binding:"required"`
+		Status    []string `json:"status" binding:"dive,oneof=todo done"`
+		Untagged  string   `binding:"required"`
+	}
+
+	router := gin.New()
+	router.POST("/input", func(ctx *gin.Context) {
+		var in input
+		if err := ctx.ShouldBindJSON(&in); err != nil {
+			NewValidatorErrorResponse(ctx, err)
+		}
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/input", bytes.NewBufferString(`{"status":["todo","later"]}`))
+	req.Header.Set("Accept", "application/problem+json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, w.Code, http.StatusBadRequest)
+	assert.Equal(t, w.Body.String(), `{"type":"about:blank","title":"Bad Request","status":400,`+
+		`"detail":"invalid 'ProjectId' input; invalid 'Render' input; invalid 'Status[1]' input; invalid 'Untagged' input",`+
+		`"instance":"/input","errors":[{"field":"project_id","rule":"required","param":""},`+
+		`{"field":"render","rule":"required","param":""},{"field":"status[1]","rule":"oneof","param":"todo done"},`+
+		`{"field":"Untagged","rule":"required","param":""}]}`)
+}
+
+func TestNewServiceErrorResponse(t *testing.T) {
+	testCases := []struct {
+		name           string
+		accept         string
+		err            error
+		respStatusCode int
+		respRetryAfter string
+		respBody       string
+	}{
+		{
+			name:           "Not found",
+			accept:         "application/problem+json",
+			err:            domain.ErrTaskNotFound,
+			respStatusCode: http.StatusNotFound,
+			respBody:       `{"type":"about:blank","title":"Not Found","status":404,"detail":"task not found","instance":"/task/1"}`,
+		},
+		{
+			name:           "Validation",
+			accept:         "application/problem+json",
+			err:            domain.ErrTaskDepthExceeded,
+			respStatusCode: http.StatusUnprocessableEntity,
+			respBody: `{"type":"about:blank","title":"Unprocessable Entity","status":422,` +
+				`"detail":"maximum subtask depth exceeded","instance":"/task/1"}`,
+		},
+		{
+			name:           "Unauthorized",
+			accept:         "",
+			err:            domain.ErrInvalidCredentials,
+			respStatusCode: http.StatusUnauthorized,
+			respBody:       `{"success":false,"messages":["invalid login or password"]}`,
+		},
+		{
+			name:           "Bad request",
+			accept:         "",
+			err:            domain.ErrInvalidCursor,
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["invalid cursor"]}`,
+		},
+		{
+			name:           "Locked with retry",
+			accept:         "",
+			err:            &domain.RetryError{Err: domain.ErrAccountLocked, RetryAfter: 1500 * time.Millisecond},
+			respStatusCode: http.StatusLocked,
+			respRetryAfter: "2",
+			respBody:       `{"success":false,"messages":["account is temporarily locked after too many failed sign in attempts"]}`,
+		},
+		{
+			name:           "Unknown",
+			accept:         "",
+			err:            errors.New("connection refused"),
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+		{
+			name:           "Problem rated zero",
+			accept:         "application/problem+json;q=0",
+			err:            domain.ErrTaskNotFound,
+			respStatusCode: http.StatusNotFound,
+			respBody:       `{"success":false,"messages":["task not found"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/task/:id", func(ctx *gin.Context) {
+				NewServiceErrorResponse(ctx, testCase.err)
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/task/1", nil)
+			req.Header.Set("Accept", testCase.accept)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Header().Get("Retry-After"), testCase.respRetryAfter)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
@@ -5,29 +5,63 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/i-vasilkov/go-todo-app/internal/domain"
 	"net/http"
+	"time"
 )
 
 func (h *Handler) InitTaskRoutes(router *gin.RouterGroup) {
+	read := h.RequireScope(domain.ScopeTasksRead)
+	write := h.RequireScope(domain.ScopeTasksWrite)
+
 	task := router.Group("/task", h.AuthMiddleware)
 	{
-		task.GET("/", h.taskGetAll)
-		task.POST("/", h.taskCreate)
-		task.GET("/:id", h.taskGetOne)
-		task.PUT("/:id", h.taskUpdate)
-		task.DELETE("/:id", h.taskDelete)
+		task.GET("/", read, h.taskGetAll)
+		task.POST("/", write, h.taskCreate)
+		task.GET("/overdue", read, h.taskGetOverdue)
+		task.GET("/due", read, h.taskGetDueBetween)
+		task.GET("/:id", read, h.taskGetOne)
+		task.PUT("/:id", write, h.taskUpdate)
+		task.DELETE("/:id", write, h.taskDelete)
+		task.POST("/:id/complete", write, h.taskComplete)
+		task.POST("/:id/reopen", write, h.taskReopen)
+		task.POST("/:id/labels", write, h.taskAttachLabels)
+		task.DELETE("/:id/labels", write, h.taskDetachLabels)
+		task.POST("/:id/move", write, h.taskMove)
 	}
 }
 
+// taskRenderQuery selects optional representations of task descriptions in responses.
+type taskRenderQuery struct {
+	Render string `form:"render" binding:"omitempty,oneof=html"`
+}
+
+func (q taskRenderQuery) html() bool {
+	return q.Render == "html"
+}
+
+type taskGetOneQuery struct {
+	taskRenderQuery
+	Subtree bool `form:"subtree"`
+}
+
 // @Summary Getting one task
-// @Description Get one task by id
+// @Description Get one task by id. With subtree=true the task is returned as domain.TaskTree
+// @Description together with its subtasks and their completion progress.
 // @Security ApiAuth
 // @Tags Task
 // @Accept json
 // @Produce json
+// @Param subtree query bool false "include subtasks"
+// @Param render query string false "add description_html rendered from the Markdown description" Enums(html)
 // @Success 200 {object} SuccessResponse{data=domain.Task}
-// @Failure 400,422,500 {object} ErrorResponse
+// @Failure 400,404,422,500 {object} ErrorResponse
 // @Router /task/{id} [get]
 func (h *Handler) taskGetOne(ctx *gin.Context) {
+	var query taskGetOneQuery
+	if err := ctx.BindQuery(&query); err != nil {
+		NewValidatorErrorResponse(ctx, err)
+		return
+	}
+
 	id := ctx.Param("id")
 	if id == "" {
 		NewErrorResponseFromError(ctx, http.StatusBadRequest, errors.New("empty task id"))
@@ -40,38 +74,75 @@ func (h *Handler) taskGetOne(ctx *gin.Context) {
 		return
 	}
 
+	if query.Subtree {
+		tree, err := h.services.Task.GetTree(ctx.Request.Context(), id, userId)
+		if err != nil {
+			NewServiceErrorResponse(ctx, err)
+			return
+		}
+		if query.html() {
+			tree.RenderDescription()
+		}
+
+		NewSuccessResponse(ctx, tree)
+		return
+	}
+
 	task, err := h.services.Task.Get(ctx.Request.Context(), id, userId)
 	if err != nil {
-		NewErrorResponseFromError(ctx, http.StatusInternalServerError, err)
+		NewServiceErrorResponse(ctx, err)
 		return
 	}
+	if query.html() {
+		task.RenderDescription()
+	}
 
 	NewSuccessResponse(ctx, task)
 }
 
 // @Summary Getting tasks
-// @Description Get user tasks
+// @Description Get one page of user tasks. Pass next_cursor from the response as cursor to get the next page.
 // @Security ApiAuth
 // @Tags Task
 // @Accept json
 // @Produce json
-// @Success 200 {object} SuccessResponse{data=[]domain.Task}
+// @Param filter query domain.TaskFilter false "filter, sorting and pagination"
+// @Param render query string false "add description_html rendered from the Markdown description" Enums(html)
+// @Success 200 {object} ListResponse{data=[]domain.Task}
 // @Failure 400,422,500 {object} ErrorResponse
 // @Router /task [get]
 func (h *Handler) taskGetAll(ctx *gin.Context) {
+	var filter domain.TaskFilter
+	if err := ctx.BindQuery(&filter); err != nil {
+		NewValidatorErrorResponse(ctx, err)
+		return
+	}
+
+	var render taskRenderQuery
+	if err := ctx.BindQuery(&render); err != nil {
+		NewValidatorErrorResponse(ctx, err)
+		return
+	}
+
 	userId, err := GetUserIdFromCtx(ctx)
 	if err != nil {
 		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
 		return
 	}
 
-	tasks, err := h.services.Task.GetAll(ctx.Request.Context(), userId)
+	page, err := h.services.Task.GetAll(ctx.Request.Context(), userId, filter)
 	if err != nil {
-		NewErrorResponseFromError(ctx, http.StatusInternalServerError, err)
+		NewServiceErrorResponse(ctx, err)
 		return
 	}
 
-	NewSuccessResponse(ctx, tasks)
+	if render.html() {
+		for i := range page.Items {
+			page.Items[i].RenderDescription()
+		}
+	}
+
+	NewListResponse(ctx, page.Items, page.NextCursor)
 }
 
 // @Summary Creating task
@@ -81,16 +152,28 @@ func (h *Handler) taskGetAll(ctx *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param input body domain.CreateTaskInput true "input data"
+// @Param render query string false "add description_html rendered from the Markdown description" Enums(html)
 // @Success 200 {object} SuccessResponse{data=domain.Task}
-// @Failure 400,422,500 {object} ErrorResponse
+// @Failure 400,404,422,500 {object} ErrorResponse
 // @Router /task [post]
 func (h *Handler) taskCreate(ctx *gin.Context) {
+	var render taskRenderQuery
+	if err := ctx.BindQuery(&render); err != nil {
+		NewValidatorErrorResponse(ctx, err)
+		return
+	}
+
 	var in domain.CreateTaskInput
 	if err := ctx.BindJSON(&in); err != nil {
 		NewValidatorErrorResponse(ctx, err)
 		return
 	}
 
+	if err := in.Validate(); err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
 	userId, err := GetUserIdFromCtx(ctx)
 	if err != nil {
 		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
@@ -99,30 +182,45 @@ func (h *Handler) taskCreate(ctx *gin.Context) {
 
 	task, err := h.services.Task.Create(ctx.Request.Context(), userId, in)
 	if err != nil {
-		NewErrorResponseFromError(ctx, http.StatusInternalServerError, err)
+		NewServiceErrorResponse(ctx, err)
 		return
 	}
+	if render.html() {
+		task.RenderDescription()
+	}
 
 	NewSuccessResponse(ctx, task)
 }
 
 // @Summary Updating task
-// @Description Update task by input data
+// @Description Replace the task with the input data. Fields left out are cleared: description, dates, project, parent and recurrence are removed and the priority goes back to the default. Only a left out status keeps the current one. Send the whole task, as read from GET /task/{id}, to change a part of it
 // @Security ApiAuth
 // @Tags Task
 // @Accept json
 // @Produce json
 // @Param input body domain.UpdateTaskInput true "input data"
+// @Param render query string false "add description_html rendered from the Markdown description" Enums(html)
 // @Success 200 {object} SuccessResponse{data=domain.Task}
-// @Failure 400,422,500 {object} ErrorResponse
+// @Failure 400,404,422,500 {object} ErrorResponse
 // @Router /task/{id} [put]
 func (h *Handler) taskUpdate(ctx *gin.Context) {
+	var render taskRenderQuery
+	if err := ctx.BindQuery(&render); err != nil {
+		NewValidatorErrorResponse(ctx, err)
+		return
+	}
+
 	var in domain.UpdateTaskInput
 	if err := ctx.BindJSON(&in); err != nil {
 		NewValidatorErrorResponse(ctx, err)
 		return
 	}
 
+	if err := in.Validate(); err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
 	id := ctx.Param("id")
 	if id == "" {
 		NewErrorResponseFromError(ctx, http.StatusBadRequest, errors.New("empty task id"))
@@ -137,21 +235,24 @@ func (h *Handler) taskUpdate(ctx *gin.Context) {
 
 	task, err := h.services.Task.Update(ctx.Request.Context(), id, userId, in)
 	if err != nil {
-		NewErrorResponseFromError(ctx, http.StatusInternalServerError, err)
+		NewServiceErrorResponse(ctx, err)
 		return
 	}
+	if render.html() {
+		task.RenderDescription()
+	}
 
 	NewSuccessResponse(ctx, task)
 }
 
 // @Summary Deleting task
-// @Description Delete task by id
+// @Description Move task with its subtasks to the trash, see /trash
 // @Security ApiAuth
 // @Tags Task
 // @Accept json
 // @Produce json
 // @Success 200 {object} SuccessResponse{data=object}
-// @Failure 400,422,500 {object} ErrorResponse
+// @Failure 400,404,422,500 {object} ErrorResponse
 // @Router /task/{id} [delete]
 func (h *Handler) taskDelete(ctx *gin.Context) {
 	id := ctx.Param("id")
@@ -167,9 +268,264 @@ func (h *Handler) taskDelete(ctx *gin.Context) {
 	}
 
 	if err := h.services.Task.Delete(ctx.Request.Context(), id, userId); err != nil {
-		NewErrorResponseFromError(ctx, http.StatusInternalServerError, err)
+		NewServiceErrorResponse(ctx, err)
 		return
 	}
 
 	NewSuccessResponse(ctx, nil)
 }
+
+type taskCompleteQuery struct {
+	Subtasks bool `form:"subtasks"`
+}
+
+// @Summary Completing task
+// @Description Mark open task as done. With subtasks=true its open subtasks are completed too.
+// @Security ApiAuth
+// @Tags Task
+// @Accept json
+// @Produce json
+// @Param subtasks query bool false "complete open subtasks as well"
+// @Success 200 {object} SuccessResponse{data=domain.Task}
+// @Failure 400,404,409,422,500 {object} ErrorResponse
+// @Router /task/{id}/complete [post]
+func (h *Handler) taskComplete(ctx *gin.Context) {
+	var query taskCompleteQuery
+	if err := ctx.BindQuery(&query); err != nil {
+		NewValidatorErrorResponse(ctx, err)
+		return
+	}
+
+	id := ctx.Param("id")
+	if id == "" {
+		NewErrorResponseFromError(ctx, http.StatusBadRequest, errors.New("empty task id"))
+		return
+	}
+
+	userId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	task, err := h.services.Task.Complete(ctx.Request.Context(), id, userId, query.Subtasks)
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, task)
+}
+
+// @Summary Reopening task
+// @Description Move done or cancelled task back to todo
+// @Security ApiAuth
+// @Tags Task
+// @Accept json
+// @Produce json
+// @Success 200 {object} SuccessResponse{data=domain.Task}
+// @Failure 400,404,409,500 {object} ErrorResponse
+// @Router /task/{id}/reopen [post]
+func (h *Handler) taskReopen(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		NewErrorResponseFromError(ctx, http.StatusBadRequest, errors.New("empty task id"))
+		return
+	}
+
+	userId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	task, err := h.services.Task.Reopen(ctx.Request.Context(), id, userId)
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, task)
+}
+
+// @Summary Getting overdue tasks
+// @Description Get open user tasks whose due date has passed, earliest first
+// @Security ApiAuth
+// @Tags Task
+// @Accept json
+// @Produce json
+// @Success 200 {object} SuccessResponse{data=[]domain.Task}
+// @Failure 400,422,500 {object} ErrorResponse
+// @Router /task/overdue [get]
+func (h *Handler) taskGetOverdue(ctx *gin.Context) {
+	userId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	tasks, err := h.services.Task.GetOverdue(ctx.Request.Context(), userId)
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, tasks)
+}
+
+type dueBetweenQuery struct {
+	From time.Time `form:"from" binding:"required" time_format:"2006-01-02T15:04:05Z07:00"`
+	To   time.Time `form:"to" binding:"required" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// @Summary Getting tasks due in a period
+// @Description Get user tasks with due date in [from, to), earliest first
+// @Security ApiAuth
+// @Tags Task
+// @Accept json
+// @Produce json
+// @Param from query string true "period start (RFC3339)"
+// @Param to query string true "period end (RFC3339)"
+// @Success 200 {object} SuccessResponse{data=[]domain.Task}
+// @Failure 400,422,500 {object} ErrorResponse
+// @Router /task/due [get]
+func (h *Handler) taskGetDueBetween(ctx *gin.Context) {
+	var query dueBetweenQuery
+	if err := ctx.BindQuery(&query); err != nil {
+		NewValidatorErrorResponse(ctx, err)
+		return
+	}
+
+	if query.To.Before(query.From) {
+		NewErrorResponseFromError(ctx, http.StatusBadRequest, errors.New("'to' must not be before 'from'"))
+		return
+	}
+
+	userId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	tasks, err := h.services.Task.GetDueBetween(ctx.Request.Context(), userId, query.From, query.To)
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, tasks)
+}
+
+// @Summary Attaching labels
+// @Description Attach user labels to task
+// @Security ApiAuth
+// @Tags Task
+// @Accept json
+// @Produce json
+// @Param input body domain.TaskLabelsInput true "input data"
+// @Success 200 {object} SuccessResponse{data=domain.Task}
+// @Failure 400,404,422,500 {object} ErrorResponse
+// @Router /task/{id}/labels [post]
+func (h *Handler) taskAttachLabels(ctx *gin.Context) {
+	var in domain.TaskLabelsInput
+	if err := ctx.BindJSON(&in); err != nil {
+		NewValidatorErrorResponse(ctx, err)
+		return
+	}
+
+	id := ctx.Param("id")
+	if id == "" {
+		NewErrorResponseFromError(ctx, http.StatusBadRequest, errors.New("empty task id"))
+		return
+	}
+
+	userId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	task, err := h.services.Task.AttachLabels(ctx.Request.Context(), id, userId, in.LabelIds)
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, task)
+}
+
+// @Summary Detaching labels
+// @Description Detach labels from task
+// @Security ApiAuth
+// @Tags Task
+// @Accept json
+// @Produce json
+// @Param input body domain.TaskLabelsInput true "input data"
+// @Success 200 {object} SuccessResponse{data=domain.Task}
+// @Failure 400,404,422,500 {object} ErrorResponse
+// @Router /task/{id}/labels [delete]
+func (h *Handler) taskDetachLabels(ctx *gin.Context) {
+	var in domain.TaskLabelsInput
+	if err := ctx.BindJSON(&in); err != nil {
+		NewValidatorErrorResponse(ctx, err)
+		return
+	}
+
+	id := ctx.Param("id")
+	if id == "" {
+		NewErrorResponseFromError(ctx, http.StatusBadRequest, errors.New("empty task id"))
+		return
+	}
+
+	userId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	task, err := h.services.Task.DetachLabels(ctx.Request.Context(), id, userId, in.LabelIds)
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, task)
+}
+
+// @Summary Moving task
+// @Description Reorder task within its project: put it right after after_id and/or right before before_id
+// @Security ApiAuth
+// @Tags Task
+// @Accept json
+// @Produce json
+// @Param input body domain.MoveTaskInput true "new neighbours"
+// @Success 200 {object} SuccessResponse{data=domain.Task}
+// @Failure 400,404,422,500 {object} ErrorResponse
+// @Router /task/{id}/move [post]
+func (h *Handler) taskMove(ctx *gin.Context) {
+	var in domain.MoveTaskInput
+	if err := ctx.BindJSON(&in); err != nil {
+		NewValidatorErrorResponse(ctx, err)
+		return
+	}
+
+	id := ctx.Param("id")
+	if id == "" {
+		NewErrorResponseFromError(ctx, http.StatusBadRequest, errors.New("empty task id"))
+		return
+	}
+
+	userId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	task, err := h.services.Task.Move(ctx.Request.Context(), id, userId, in)
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, task)
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/magiconair/properties/assert"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 )
@@ -24,6 +25,7 @@ func TestHandler_taskGetOne(t *testing.T) {
 		name           string
 		taskId         string
 		userId         string
+		query          string
 		task           domain.Task
 		mockBehavior   mockBehavior
 		respStatusCode int
@@ -37,6 +39,9 @@ func TestHandler_taskGetOne(t *testing.T) {
 				Id:        "taskId",
 				Name:      "test",
 				UserId:    "userId",
+				Status:    domain.TaskStatusTodo,
+				Priority:  domain.TaskPriorityP4,
+				Position:  domain.TaskPositionGap,
 				CreatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
 				UpdatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
 			},
@@ -44,7 +49,39 @@ func TestHandler_taskGetOne(t *testing.T) {
 				s.EXPECT().Get(context.Background(), id, userId).Return(task, nil)
 			},
 			respStatusCode: http.StatusOK,
-			respBody:       `{"success":true,"data":{"id":"taskId","name":"test","user_id":"userId","created_at":"2020-01-01T00:00:00Z","updated_at":"2020-01-01T00:00:00Z"}}`,
+			respBody:       `{"success":true,"data":{"id":"taskId","name":"test","user_id":"userId","status":"todo","priority":4,"position":1024,"created_at":"2020-01-01T00:00:00Z","updated_at":"2020-01-01T00:00:00Z"}}`,
+		},
+		{
+			name:   "Rendered description",
+			taskId: "taskId",
+			userId: "userId",
+			query:  "?render=html",
+			task: domain.Task{
+				Id:          "taskId",
+				Name:        "test",
+				Description: "**bold** <script>alert(1)</script>",
+				UserId:      "userId",
+				Status:      domain.TaskStatusTodo,
+				Priority:    domain.TaskPriorityP4,
+				Position:    domain.TaskPositionGap,
+				CreatedAt:   time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+				UpdatedAt:   time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+			},
+			mockBehavior: func(s *mock_service.MockTaskServiceI, id, userId string, task domain.Task) {
+				s.EXPECT().Get(context.Background(), id, userId).Return(task, nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":{"id":"taskId","name":"test","description":"**bold** \u003cscript\u003ealert(1)\u003c/script\u003e","description_html":"\u003cp\u003e\u003cstrong\u003ebold\u003c/strong\u003e \u003c/p\u003e\n","user_id":"userId","status":"todo","priority":4,"position":1024,"created_at":"2020-01-01T00:00:00Z","updated_at":"2020-01-01T00:00:00Z"}}`,
+		},
+		{
+			name:           "Invalid render",
+			taskId:         "taskId",
+			userId:         "userId",
+			query:          "?render=pdf",
+			task:           domain.Task{},
+			mockBehavior:   func(s *mock_service.MockTaskServiceI, id, userId string, task domain.Task) {},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["invalid 'Render' input"]}`,
 		},
 		{
 			name:           "Empty taskId",
@@ -64,6 +101,17 @@ func TestHandler_taskGetOne(t *testing.T) {
 			respStatusCode: http.StatusUnauthorized,
 			respBody:       `{"success":false,"messages":["not exists userId in context"]}`,
 		},
+		{
+			name:   "Not found",
+			taskId: "taskId",
+			userId: "userId",
+			task:   domain.Task{},
+			mockBehavior: func(s *mock_service.MockTaskServiceI, id, userId string, task domain.Task) {
+				s.EXPECT().Get(context.Background(), id, userId).Return(task, domain.ErrTaskNotFound)
+			},
+			respStatusCode: http.StatusNotFound,
+			respBody:       `{"success":false,"messages":["task not found"]}`,
+		},
 		{
 			name:   "Service error",
 			taskId: "taskId",
@@ -73,7 +121,7 @@ func TestHandler_taskGetOne(t *testing.T) {
 				s.EXPECT().Get(context.Background(), id, userId).Return(task, errors.New("service error"))
 			},
 			respStatusCode: http.StatusInternalServerError,
-			respBody:       `{"success":false,"messages":["service error"]}`,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
 		},
 	}
 
@@ -96,7 +144,7 @@ func TestHandler_taskGetOne(t *testing.T) {
 			}, handler.taskGetOne)
 
 			w := httptest.NewRecorder()
-			reqUrl := fmt.Sprintf("/task/%s", testCase.taskId)
+			reqUrl := fmt.Sprintf("/task/%s%s", testCase.taskId, testCase.query)
 			req := httptest.NewRequest("GET", reqUrl, nil)
 
 			router.ServeHTTP(w, req)
@@ -152,7 +200,7 @@ func TestHandler_taskDelete(t *testing.T) {
 				s.EXPECT().Delete(context.Background(), id, userId).Return(errors.New("service error"))
 			},
 			respStatusCode: http.StatusInternalServerError,
-			respBody:       `{"success":false,"messages":["service error"]}`,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
 		},
 	}
 
@@ -210,6 +258,9 @@ func TestHandler_taskUpdate(t *testing.T) {
 				Id:        "taskId",
 				Name:      "updated",
 				UserId:    "userId",
+				Status:    domain.TaskStatusTodo,
+				Priority:  domain.TaskPriorityP4,
+				Position:  domain.TaskPositionGap,
 				CreatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
 				UpdatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
 			},
@@ -217,41 +268,57 @@ func TestHandler_taskUpdate(t *testing.T) {
 				s.EXPECT().Update(context.Background(), id, userId, in).Return(task, nil)
 			},
 			respStatusCode: http.StatusOK,
-			respBody:       `{"success":true,"data":{"id":"taskId","name":"updated","user_id":"userId","created_at":"2020-01-01T00:00:00Z","updated_at":"2020-01-01T00:00:00Z"}}`,
+			respBody:       `{"success":true,"data":{"id":"taskId","name":"updated","user_id":"userId","status":"todo","priority":4,"position":1024,"created_at":"2020-01-01T00:00:00Z","updated_at":"2020-01-01T00:00:00Z"}}`,
 		},
 		{
-			name:           "Empty task.name",
-			taskId:         "",
-			userId:         "",
-			reqBody:        `{}`,
-			inputObj:       domain.UpdateTaskInput{Name: "updated"},
-			task:           domain.Task{},
-			mockBehavior:   func(s *mock_service.MockTaskServiceI, id, userId string, in domain.UpdateTaskInput, task domain.Task) {},
+			name:     "Empty task.name",
+			taskId:   "",
+			userId:   "",
+			reqBody:  `{}`,
+			inputObj: domain.UpdateTaskInput{Name: "updated"},
+			task:     domain.Task{},
+			mockBehavior: func(s *mock_service.MockTaskServiceI, id, userId string, in domain.UpdateTaskInput, task domain.Task) {
+			},
 			respStatusCode: http.StatusBadRequest,
 			respBody:       `{"success":false,"messages":["invalid 'Name' input"]}`,
 		},
 		{
-			name:           "Empty taskId",
-			taskId:         "",
-			userId:         "userId",
-			reqBody:        `{"name":"updated"}`,
-			inputObj:       domain.UpdateTaskInput{Name: "updated"},
-			task:           domain.Task{},
-			mockBehavior:   func(s *mock_service.MockTaskServiceI, id, userId string, in domain.UpdateTaskInput, task domain.Task) {},
+			name:     "Empty taskId",
+			taskId:   "",
+			userId:   "userId",
+			reqBody:  `{"name":"updated"}`,
+			inputObj: domain.UpdateTaskInput{Name: "updated"},
+			task:     domain.Task{},
+			mockBehavior: func(s *mock_service.MockTaskServiceI, id, userId string, in domain.UpdateTaskInput, task domain.Task) {
+			},
 			respStatusCode: http.StatusBadRequest,
 			respBody:       `{"success":false,"messages":["empty task id"]}`,
 		},
 		{
-			name:           "Empty UserId",
-			taskId:         "taskId",
-			userId:         "",
-			reqBody:        `{"name":"updated"}`,
-			inputObj:       domain.UpdateTaskInput{Name: "updated"},
-			task:           domain.Task{},
-			mockBehavior:   func(s *mock_service.MockTaskServiceI, id, userId string, in domain.UpdateTaskInput, task domain.Task) {},
+			name:     "Empty UserId",
+			taskId:   "taskId",
+			userId:   "",
+			reqBody:  `{"name":"updated"}`,
+			inputObj: domain.UpdateTaskInput{Name: "updated"},
+			task:     domain.Task{},
+			mockBehavior: func(s *mock_service.MockTaskServiceI, id, userId string, in domain.UpdateTaskInput, task domain.Task) {
+			},
 			respStatusCode: http.StatusUnauthorized,
 			respBody:       `{"success":false,"messages":["not exists userId in context"]}`,
 		},
+		{
+			name:     "Depth exceeded",
+			taskId:   "taskId",
+			userId:   "userId",
+			reqBody:  `{"name":"updated"}`,
+			inputObj: domain.UpdateTaskInput{Name: "updated"},
+			task:     domain.Task{},
+			mockBehavior: func(s *mock_service.MockTaskServiceI, id, userId string, in domain.UpdateTaskInput, task domain.Task) {
+				s.EXPECT().Update(context.Background(), id, userId, in).Return(task, domain.ErrTaskDepthExceeded)
+			},
+			respStatusCode: http.StatusUnprocessableEntity,
+			respBody:       `{"success":false,"messages":["maximum subtask depth exceeded"]}`,
+		},
 		{
 			name:     "Service error",
 			taskId:   "taskId",
@@ -263,7 +330,7 @@ func TestHandler_taskUpdate(t *testing.T) {
 				s.EXPECT().Update(context.Background(), id, userId, in).Return(task, errors.New("service error"))
 			},
 			respStatusCode: http.StatusInternalServerError,
-			respBody:       `{"success":false,"messages":["service error"]}`,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
 		},
 	}
 
@@ -319,6 +386,9 @@ func TestHandler_taskCreate(t *testing.T) {
 				Id:        "taskId",
 				Name:      "test",
 				UserId:    "userId",
+				Status:    domain.TaskStatusTodo,
+				Priority:  domain.TaskPriorityP4,
+				Position:  domain.TaskPositionGap,
 				CreatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
 				UpdatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
 			},
@@ -326,7 +396,7 @@ func TestHandler_taskCreate(t *testing.T) {
 				s.EXPECT().Create(context.Background(), userId, in).Return(task, nil)
 			},
 			respStatusCode: http.StatusOK,
-			respBody:       `{"success":true,"data":{"id":"taskId","name":"test","user_id":"userId","created_at":"2020-01-01T00:00:00Z","updated_at":"2020-01-01T00:00:00Z"}}`,
+			respBody:       `{"success":true,"data":{"id":"taskId","name":"test","user_id":"userId","status":"todo","priority":4,"position":1024,"created_at":"2020-01-01T00:00:00Z","updated_at":"2020-01-01T00:00:00Z"}}`,
 		},
 		{
 			name:           "Empty task.name",
@@ -338,6 +408,22 @@ func TestHandler_taskCreate(t *testing.T) {
 			respStatusCode: http.StatusBadRequest,
 			respBody:       `{"success":false,"messages":["invalid 'Name' input"]}`,
 		},
+		{
+			name:           "Invalid task.recurrence",
+			userId:         "userId",
+			reqBody:        `{"name":"test","recurrence":"FREQ=YEARLY"}`,
+			mockBehavior:   func(s *mock_service.MockTaskServiceI, userId string, in domain.CreateTaskInput, task domain.Task) {},
+			respStatusCode: http.StatusUnprocessableEntity,
+			respBody:       `{"success":false,"messages":["invalid recurrence rule: unsupported FREQ \"YEARLY\""]}`,
+		},
+		{
+			name:           "Too long task.description",
+			userId:         "userId",
+			reqBody:        fmt.Sprintf(`{"name":"test","description":"%s"}`, strings.Repeat("a", domain.TaskDescriptionMaxLength+1)),
+			mockBehavior:   func(s *mock_service.MockTaskServiceI, userId string, in domain.CreateTaskInput, task domain.Task) {},
+			respStatusCode: http.StatusUnprocessableEntity,
+			respBody:       `{"success":false,"messages":["description must not be longer than 65536 bytes"]}`,
+		},
 		{
 			name:           "Empty UserId",
 			userId:         "",
@@ -358,7 +444,7 @@ func TestHandler_taskCreate(t *testing.T) {
 				s.EXPECT().Create(context.Background(), userId, in).Return(task, errors.New("service error"))
 			},
 			respStatusCode: http.StatusInternalServerError,
-			respBody:       `{"success":false,"messages":["service error"]}`,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
 		},
 	}
 
@@ -392,12 +478,16 @@ func TestHandler_taskCreate(t *testing.T) {
 }
 
 func TestHandler_taskGetAll(t *testing.T) {
-	type mockBehavior func(s *mock_service.MockTaskServiceI, userId string, tasks []domain.Task)
+	type mockBehavior func(s *mock_service.MockTaskServiceI, userId string, filter domain.TaskFilter, page domain.TaskPage)
+
+	dueFrom := time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC)
 
 	testCases := []struct {
 		name           string
 		userId         string
-		tasks          []domain.Task
+		query          string
+		filter         domain.TaskFilter
+		page           domain.TaskPage
 		mockBehavior   mockBehavior
 		respStatusCode int
 		respBody       string
@@ -405,38 +495,99 @@ func TestHandler_taskGetAll(t *testing.T) {
 		{
 			name:   "OK",
 			userId: "userId",
-			tasks: []domain.Task{
-				{
-					Id:        "taskId",
-					Name:      "test",
-					UserId:    "userId",
-					CreatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
-					UpdatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+			filter: domain.TaskFilter{},
+			page: domain.TaskPage{
+				Items: []domain.Task{
+					{
+						Id:        "taskId",
+						Name:      "test",
+						UserId:    "userId",
+						Status:    domain.TaskStatusTodo,
+						Priority:  domain.TaskPriorityP4,
+						Position:  domain.TaskPositionGap,
+						CreatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+						UpdatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+					},
 				},
+				NextCursor: "cursor",
 			},
-			mockBehavior: func(s *mock_service.MockTaskServiceI, userId string, tasks []domain.Task) {
-				s.EXPECT().GetAll(context.Background(), userId).Return(tasks, nil)
+			mockBehavior: func(s *mock_service.MockTaskServiceI, userId string, filter domain.TaskFilter, page domain.TaskPage) {
+				s.EXPECT().GetAll(context.Background(), userId, filter).Return(page, nil)
 			},
 			respStatusCode: http.StatusOK,
-			respBody:       `{"success":true,"data":[{"id":"taskId","name":"test","user_id":"userId","created_at":"2020-01-01T00:00:00Z","updated_at":"2020-01-01T00:00:00Z"}]}`,
+			respBody:       `{"success":true,"data":[{"id":"taskId","name":"test","user_id":"userId","status":"todo","priority":4,"position":1024,"created_at":"2020-01-01T00:00:00Z","updated_at":"2020-01-01T00:00:00Z"}],"next_cursor":"cursor"}`,
 		},
 		{
-			name:           "Empty UserId",
-			userId:         "",
-			tasks:          nil,
-			mockBehavior:   func(s *mock_service.MockTaskServiceI, userId string, tasks []domain.Task) {},
+			name:   "Filter params",
+			userId: "userId",
+			query:  "status=todo&status=in_progress&due_from=2020-01-01T00:00:00Z&name=milk&sort_by=due_at&sort_dir=desc&limit=10&cursor=abc",
+			filter: domain.TaskFilter{
+				Status:  []domain.TaskStatus{domain.TaskStatusTodo, domain.TaskStatusInProgress},
+				DueFrom: &dueFrom,
+				Name:    "milk",
+				SortBy:  domain.TaskSortDueAt,
+				SortDir: domain.SortDesc,
+				Limit:   10,
+				Cursor:  "abc",
+			},
+			page: domain.TaskPage{Items: []domain.Task{}},
+			mockBehavior: func(s *mock_service.MockTaskServiceI, userId string, filter domain.TaskFilter, page domain.TaskPage) {
+				s.EXPECT().GetAll(context.Background(), userId, filter).Return(page, nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":[],"next_cursor":""}`,
+		},
+		{
+			name:   "Label filter",
+			userId: "userId",
+			query:  "label=work&label=errand&label_match=all",
+			filter: domain.TaskFilter{
+				Labels:     []string{"work", "errand"},
+				LabelMatch: domain.LabelMatchAll,
+			},
+			page: domain.TaskPage{Items: []domain.Task{}},
+			mockBehavior: func(s *mock_service.MockTaskServiceI, userId string, filter domain.TaskFilter, page domain.TaskPage) {
+				s.EXPECT().GetAll(context.Background(), userId, filter).Return(page, nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":[],"next_cursor":""}`,
+		},
+		{
+			name:   "Invalid filter params",
+			userId: "userId",
+			query:  "status=unknown&limit=1000",
+			mockBehavior: func(s *mock_service.MockTaskServiceI, userId string, filter domain.TaskFilter, page domain.TaskPage) {
+			},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["invalid 'Status[0]' input","invalid 'Limit' input"]}`,
+		},
+		{
+			name:   "Invalid cursor",
+			userId: "userId",
+			query:  "cursor=abc",
+			filter: domain.TaskFilter{Cursor: "abc"},
+			mockBehavior: func(s *mock_service.MockTaskServiceI, userId string, filter domain.TaskFilter, page domain.TaskPage) {
+				s.EXPECT().GetAll(context.Background(), userId, filter).Return(page, domain.ErrInvalidCursor)
+			},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["invalid cursor"]}`,
+		},
+		{
+			name:   "Empty UserId",
+			userId: "",
+			mockBehavior: func(s *mock_service.MockTaskServiceI, userId string, filter domain.TaskFilter, page domain.TaskPage) {
+			},
 			respStatusCode: http.StatusUnauthorized,
 			respBody:       `{"success":false,"messages":["not exists userId in context"]}`,
 		},
 		{
 			name:   "Service error",
 			userId: "userId",
-			tasks:   nil,
-			mockBehavior: func(s *mock_service.MockTaskServiceI, userId string, tasks []domain.Task) {
-				s.EXPECT().GetAll(context.Background(), userId).Return(tasks, errors.New("service error"))
+			mockBehavior: func(s *mock_service.MockTaskServiceI, userId string, filter domain.TaskFilter, page domain.TaskPage) {
+				s.EXPECT().GetAll(context.Background(), userId, filter).Return(page, errors.New("service error"))
 			},
 			respStatusCode: http.StatusInternalServerError,
-			respBody:       `{"success":false,"messages":["service error"]}`,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
 		},
 	}
 
@@ -446,7 +597,7 @@ func TestHandler_taskGetAll(t *testing.T) {
 			defer ctrl.Finish()
 
 			taskService := mock_service.NewMockTaskServiceI(ctrl)
-			testCase.mockBehavior(taskService, testCase.userId, testCase.tasks)
+			testCase.mockBehavior(taskService, testCase.userId, testCase.filter, testCase.page)
 
 			services := &service.Services{Task: taskService}
 			handler := NewHandler(services)
@@ -459,7 +610,656 @@ func TestHandler_taskGetAll(t *testing.T) {
 			}, handler.taskGetAll)
 
 			w := httptest.NewRecorder()
-			req := httptest.NewRequest("GET", "/task", nil)
+			req := httptest.NewRequest("GET", "/task?"+testCase.query, nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestHandler_taskComplete(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockTaskServiceI, id, userId string, task domain.Task)
+
+	completedAt := time.Date(2020, 01, 02, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name           string
+		taskId         string
+		userId         string
+		query          string
+		task           domain.Task
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:   "OK",
+			taskId: "taskId",
+			userId: "userId",
+			task: domain.Task{
+				Id:          "taskId",
+				Name:        "test",
+				UserId:      "userId",
+				Status:      domain.TaskStatusDone,
+				Priority:    domain.TaskPriorityP4,
+				Position:    domain.TaskPositionGap,
+				CompletedAt: &completedAt,
+				CreatedAt:   time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+				UpdatedAt:   time.Date(2020, 01, 02, 0, 0, 0, 0, time.UTC),
+			},
+			mockBehavior: func(s *mock_service.MockTaskServiceI, id, userId string, task domain.Task) {
+				s.EXPECT().Complete(context.Background(), id, userId, false).Return(task, nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":{"id":"taskId","name":"test","user_id":"userId","status":"done","priority":4,"position":1024,"completed_at":"2020-01-02T00:00:00Z","created_at":"2020-01-01T00:00:00Z","updated_at":"2020-01-02T00:00:00Z"}}`,
+		},
+		{
+			name:   "With subtasks",
+			taskId: "taskId",
+			userId: "userId",
+			query:  "subtasks=true",
+			task: domain.Task{
+				Id:          "taskId",
+				Name:        "test",
+				UserId:      "userId",
+				Status:      domain.TaskStatusDone,
+				Priority:    domain.TaskPriorityP4,
+				Position:    domain.TaskPositionGap,
+				CompletedAt: &completedAt,
+				CreatedAt:   time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+				UpdatedAt:   time.Date(2020, 01, 02, 0, 0, 0, 0, time.UTC),
+			},
+			mockBehavior: func(s *mock_service.MockTaskServiceI, id, userId string, task domain.Task) {
+				s.EXPECT().Complete(context.Background(), id, userId, true).Return(task, nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":{"id":"taskId","name":"test","user_id":"userId","status":"done","priority":4,"position":1024,"completed_at":"2020-01-02T00:00:00Z","created_at":"2020-01-01T00:00:00Z","updated_at":"2020-01-02T00:00:00Z"}}`,
+		},
+		{
+			name:           "Empty UserId",
+			taskId:         "taskId",
+			userId:         "",
+			task:           domain.Task{},
+			mockBehavior:   func(s *mock_service.MockTaskServiceI, id, userId string, task domain.Task) {},
+			respStatusCode: http.StatusUnauthorized,
+			respBody:       `{"success":false,"messages":["not exists userId in context"]}`,
+		},
+		{
+			name:   "Service error",
+			taskId: "taskId",
+			userId: "userId",
+			task:   domain.Task{},
+			mockBehavior: func(s *mock_service.MockTaskServiceI, id, userId string, task domain.Task) {
+				s.EXPECT().Complete(context.Background(), id, userId, false).Return(task, errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			taskService := mock_service.NewMockTaskServiceI(ctrl)
+			testCase.mockBehavior(taskService, testCase.taskId, testCase.userId, testCase.task)
+
+			services := &service.Services{Task: taskService}
+			handler := NewHandler(services)
+
+			router := gin.New()
+			router.POST("/task/:id/complete", func(ctx *gin.Context) {
+				if testCase.userId != "" {
+					ctx.Set(userCtx, testCase.userId)
+				}
+			}, handler.taskComplete)
+
+			w := httptest.NewRecorder()
+			reqUrl := fmt.Sprintf("/task/%s/complete?%s", testCase.taskId, testCase.query)
+			req := httptest.NewRequest("POST", reqUrl, nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestHandler_taskReopen(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockTaskServiceI, id, userId string, task domain.Task)
+
+	testCases := []struct {
+		name           string
+		taskId         string
+		userId         string
+		task           domain.Task
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:   "OK",
+			taskId: "taskId",
+			userId: "userId",
+			task: domain.Task{
+				Id:        "taskId",
+				Name:      "test",
+				UserId:    "userId",
+				Status:    domain.TaskStatusTodo,
+				Priority:  domain.TaskPriorityP4,
+				Position:  domain.TaskPositionGap,
+				CreatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+				UpdatedAt: time.Date(2020, 01, 02, 0, 0, 0, 0, time.UTC),
+			},
+			mockBehavior: func(s *mock_service.MockTaskServiceI, id, userId string, task domain.Task) {
+				s.EXPECT().Reopen(context.Background(), id, userId).Return(task, nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":{"id":"taskId","name":"test","user_id":"userId","status":"todo","priority":4,"position":1024,"created_at":"2020-01-01T00:00:00Z","updated_at":"2020-01-02T00:00:00Z"}}`,
+		},
+		{
+			name:           "Empty UserId",
+			taskId:         "taskId",
+			userId:         "",
+			task:           domain.Task{},
+			mockBehavior:   func(s *mock_service.MockTaskServiceI, id, userId string, task domain.Task) {},
+			respStatusCode: http.StatusUnauthorized,
+			respBody:       `{"success":false,"messages":["not exists userId in context"]}`,
+		},
+		{
+			name:   "Service error",
+			taskId: "taskId",
+			userId: "userId",
+			task:   domain.Task{},
+			mockBehavior: func(s *mock_service.MockTaskServiceI, id, userId string, task domain.Task) {
+				s.EXPECT().Reopen(context.Background(), id, userId).Return(task, errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			taskService := mock_service.NewMockTaskServiceI(ctrl)
+			testCase.mockBehavior(taskService, testCase.taskId, testCase.userId, testCase.task)
+
+			services := &service.Services{Task: taskService}
+			handler := NewHandler(services)
+
+			router := gin.New()
+			router.POST("/task/:id/reopen", func(ctx *gin.Context) {
+				if testCase.userId != "" {
+					ctx.Set(userCtx, testCase.userId)
+				}
+			}, handler.taskReopen)
+
+			w := httptest.NewRecorder()
+			reqUrl := fmt.Sprintf("/task/%s/reopen", testCase.taskId)
+			req := httptest.NewRequest("POST", reqUrl, nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestHandler_taskGetOverdue(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockTaskServiceI, userId string, tasks []domain.Task)
+
+	dueAt := time.Date(2020, 01, 02, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name           string
+		userId         string
+		tasks          []domain.Task
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:   "OK",
+			userId: "userId",
+			tasks: []domain.Task{
+				{
+					Id:        "taskId",
+					Name:      "test",
+					UserId:    "userId",
+					Status:    domain.TaskStatusTodo,
+					Priority:  domain.TaskPriorityP4,
+					Position:  domain.TaskPositionGap,
+					DueAt:     &dueAt,
+					CreatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+					UpdatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			mockBehavior: func(s *mock_service.MockTaskServiceI, userId string, tasks []domain.Task) {
+				s.EXPECT().GetOverdue(context.Background(), userId).Return(tasks, nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":[{"id":"taskId","name":"test","user_id":"userId","status":"todo","priority":4,"position":1024,"due_at":"2020-01-02T00:00:00Z","created_at":"2020-01-01T00:00:00Z","updated_at":"2020-01-01T00:00:00Z"}]}`,
+		},
+		{
+			name:           "Empty UserId",
+			userId:         "",
+			tasks:          nil,
+			mockBehavior:   func(s *mock_service.MockTaskServiceI, userId string, tasks []domain.Task) {},
+			respStatusCode: http.StatusUnauthorized,
+			respBody:       `{"success":false,"messages":["not exists userId in context"]}`,
+		},
+		{
+			name:   "Service error",
+			userId: "userId",
+			tasks:  nil,
+			mockBehavior: func(s *mock_service.MockTaskServiceI, userId string, tasks []domain.Task) {
+				s.EXPECT().GetOverdue(context.Background(), userId).Return(tasks, errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			taskService := mock_service.NewMockTaskServiceI(ctrl)
+			testCase.mockBehavior(taskService, testCase.userId, testCase.tasks)
+
+			services := &service.Services{Task: taskService}
+			handler := NewHandler(services)
+
+			router := gin.New()
+			router.GET("/task/overdue", func(ctx *gin.Context) {
+				if testCase.userId != "" {
+					ctx.Set(userCtx, testCase.userId)
+				}
+			}, handler.taskGetOverdue)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/task/overdue", nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestHandler_taskGetDueBetween(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockTaskServiceI, userId string, from, to time.Time)
+
+	from := time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2020, 01, 8, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name           string
+		userId         string
+		query          string
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:   "OK",
+			userId: "userId",
+			query:  "from=2020-01-01T00:00:00Z&to=2020-01-08T00:00:00Z",
+			mockBehavior: func(s *mock_service.MockTaskServiceI, userId string, from, to time.Time) {
+				s.EXPECT().GetDueBetween(context.Background(), userId, from, to).Return([]domain.Task{}, nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":[]}`,
+		},
+		{
+			name:           "Missing period",
+			userId:         "userId",
+			query:          "from=2020-01-01T00:00:00Z",
+			mockBehavior:   func(s *mock_service.MockTaskServiceI, userId string, from, to time.Time) {},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["invalid 'To' input"]}`,
+		},
+		{
+			name:           "Reversed period",
+			userId:         "userId",
+			query:          "from=2020-01-08T00:00:00Z&to=2020-01-01T00:00:00Z",
+			mockBehavior:   func(s *mock_service.MockTaskServiceI, userId string, from, to time.Time) {},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["'to' must not be before 'from'"]}`,
+		},
+		{
+			name:   "Service error",
+			userId: "userId",
+			query:  "from=2020-01-01T00:00:00Z&to=2020-01-08T00:00:00Z",
+			mockBehavior: func(s *mock_service.MockTaskServiceI, userId string, from, to time.Time) {
+				s.EXPECT().GetDueBetween(context.Background(), userId, from, to).Return(nil, errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			taskService := mock_service.NewMockTaskServiceI(ctrl)
+			testCase.mockBehavior(taskService, testCase.userId, from, to)
+
+			services := &service.Services{Task: taskService}
+			handler := NewHandler(services)
+
+			router := gin.New()
+			router.GET("/task/due", func(ctx *gin.Context) {
+				if testCase.userId != "" {
+					ctx.Set(userCtx, testCase.userId)
+				}
+			}, handler.taskGetDueBetween)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/task/due?"+testCase.query, nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestHandler_taskAttachLabels(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockTaskServiceI, id, userId string, labelIds []string, task domain.Task)
+
+	testCases := []struct {
+		name           string
+		taskId         string
+		userId         string
+		reqBody        string
+		labelIds       []string
+		task           domain.Task
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:     "OK",
+			taskId:   "taskId",
+			userId:   "userId",
+			reqBody:  `{"label_ids":["work","errand"]}`,
+			labelIds: []string{"work", "errand"},
+			task: domain.Task{
+				Id:        "taskId",
+				Name:      "test",
+				UserId:    "userId",
+				LabelIds:  []string{"errand", "work"},
+				Status:    domain.TaskStatusTodo,
+				Priority:  domain.TaskPriorityP4,
+				Position:  domain.TaskPositionGap,
+				CreatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+				UpdatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+			},
+			mockBehavior: func(s *mock_service.MockTaskServiceI, id, userId string, labelIds []string, task domain.Task) {
+				s.EXPECT().AttachLabels(context.Background(), id, userId, labelIds).Return(task, nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":{"id":"taskId","name":"test","user_id":"userId","label_ids":["errand","work"],"status":"todo","priority":4,"position":1024,"created_at":"2020-01-01T00:00:00Z","updated_at":"2020-01-01T00:00:00Z"}}`,
+		},
+		{
+			name:           "Empty label_ids",
+			taskId:         "taskId",
+			userId:         "userId",
+			reqBody:        `{"label_ids":[]}`,
+			mockBehavior:   func(s *mock_service.MockTaskServiceI, id, userId string, labelIds []string, task domain.Task) {},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["invalid 'LabelIds' input"]}`,
+		},
+		{
+			name:     "Service error",
+			taskId:   "taskId",
+			userId:   "userId",
+			reqBody:  `{"label_ids":["work"]}`,
+			labelIds: []string{"work"},
+			mockBehavior: func(s *mock_service.MockTaskServiceI, id, userId string, labelIds []string, task domain.Task) {
+				s.EXPECT().AttachLabels(context.Background(), id, userId, labelIds).Return(task, errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			taskService := mock_service.NewMockTaskServiceI(ctrl)
+			testCase.mockBehavior(taskService, testCase.taskId, testCase.userId, testCase.labelIds, testCase.task)
+
+			services := &service.Services{Task: taskService}
+			handler := NewHandler(services)
+
+			router := gin.New()
+			router.POST("/task/:id/labels", func(ctx *gin.Context) {
+				if testCase.userId != "" {
+					ctx.Set(userCtx, testCase.userId)
+				}
+			}, handler.taskAttachLabels)
+
+			w := httptest.NewRecorder()
+			reqUrl := fmt.Sprintf("/task/%s/labels", testCase.taskId)
+			req := httptest.NewRequest("POST", reqUrl, bytes.NewBufferString(testCase.reqBody))
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestHandler_taskGetOneSubtree(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockTaskServiceI, id, userId string, tree domain.TaskTree)
+
+	parentId := "taskId"
+
+	testCases := []struct {
+		name           string
+		taskId         string
+		userId         string
+		tree           domain.TaskTree
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:   "OK",
+			taskId: "taskId",
+			userId: "userId",
+			tree: domain.TaskTree{
+				Task: domain.Task{
+					Id:        "taskId",
+					Name:      "test",
+					UserId:    "userId",
+					Status:    domain.TaskStatusTodo,
+					Priority:  domain.TaskPriorityP4,
+					Position:  domain.TaskPositionGap,
+					CreatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+					UpdatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+				},
+				Progress: domain.TaskProgress{Done: 1, Total: 1},
+				Subtasks: []domain.TaskTree{
+					{
+						Task: domain.Task{
+							Id:        "subtaskId",
+							Name:      "sub",
+							UserId:    "userId",
+							ParentId:  &parentId,
+							Status:    domain.TaskStatusDone,
+							Priority:  domain.TaskPriorityP4,
+							Position:  domain.TaskPositionGap,
+							CreatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+							UpdatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+						},
+						Subtasks: []domain.TaskTree{},
+					},
+				},
+			},
+			mockBehavior: func(s *mock_service.MockTaskServiceI, id, userId string, tree domain.TaskTree) {
+				s.EXPECT().GetTree(context.Background(), id, userId).Return(tree, nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody: `{"success":true,"data":{"id":"taskId","name":"test","user_id":"userId","status":"todo","priority":4,"position":1024,"created_at":"2020-01-01T00:00:00Z","updated_at":"2020-01-01T00:00:00Z",` +
+				`"progress":{"done":1,"total":1},"subtasks":[{"id":"subtaskId","name":"sub","user_id":"userId","parent_id":"taskId","status":"done","priority":4,"position":1024,` +
+				`"created_at":"2020-01-01T00:00:00Z","updated_at":"2020-01-01T00:00:00Z","progress":{"done":0,"total":0},"subtasks":[]}]}}`,
+		},
+		{
+			name:   "Service error",
+			taskId: "taskId",
+			userId: "userId",
+			mockBehavior: func(s *mock_service.MockTaskServiceI, id, userId string, tree domain.TaskTree) {
+				s.EXPECT().GetTree(context.Background(), id, userId).Return(tree, errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			taskService := mock_service.NewMockTaskServiceI(ctrl)
+			testCase.mockBehavior(taskService, testCase.taskId, testCase.userId, testCase.tree)
+
+			services := &service.Services{Task: taskService}
+			handler := NewHandler(services)
+
+			router := gin.New()
+			router.GET("/task/:id", func(ctx *gin.Context) {
+				if testCase.userId != "" {
+					ctx.Set(userCtx, testCase.userId)
+				}
+			}, handler.taskGetOne)
+
+			w := httptest.NewRecorder()
+			reqUrl := fmt.Sprintf("/task/%s?subtree=true", testCase.taskId)
+			req := httptest.NewRequest("GET", reqUrl, nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestHandler_taskMove(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockTaskServiceI, id, userId string, in domain.MoveTaskInput, task domain.Task)
+
+	afterId := "afterId"
+
+	testCases := []struct {
+		name           string
+		taskId         string
+		userId         string
+		reqBody        string
+		inputObj       domain.MoveTaskInput
+		task           domain.Task
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:     "OK",
+			taskId:   "taskId",
+			userId:   "userId",
+			reqBody:  `{"after_id":"afterId"}`,
+			inputObj: domain.MoveTaskInput{AfterId: &afterId},
+			task: domain.Task{
+				Id:        "taskId",
+				Name:      "test",
+				UserId:    "userId",
+				Status:    domain.TaskStatusTodo,
+				Priority:  domain.TaskPriorityP1,
+				Position:  1536,
+				CreatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+				UpdatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+			},
+			mockBehavior: func(s *mock_service.MockTaskServiceI, id, userId string, in domain.MoveTaskInput, task domain.Task) {
+				s.EXPECT().Move(context.Background(), id, userId, in).Return(task, nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":{"id":"taskId","name":"test","user_id":"userId","status":"todo","priority":1,"position":1536,"created_at":"2020-01-01T00:00:00Z","updated_at":"2020-01-01T00:00:00Z"}}`,
+		},
+		{
+			name:           "No neighbours",
+			taskId:         "taskId",
+			userId:         "userId",
+			reqBody:        `{}`,
+			mockBehavior:   func(s *mock_service.MockTaskServiceI, id, userId string, in domain.MoveTaskInput, task domain.Task) {},
+			respStatusCode: http.StatusBadRequest,
+			respBody:       `{"success":false,"messages":["invalid 'BeforeId' input","invalid 'AfterId' input"]}`,
+		},
+		{
+			name:     "Invalid move",
+			taskId:   "taskId",
+			userId:   "userId",
+			reqBody:  `{"after_id":"afterId"}`,
+			inputObj: domain.MoveTaskInput{AfterId: &afterId},
+			mockBehavior: func(s *mock_service.MockTaskServiceI, id, userId string, in domain.MoveTaskInput, task domain.Task) {
+				s.EXPECT().Move(context.Background(), id, userId, in).Return(task, domain.ErrInvalidMove)
+			},
+			respStatusCode: http.StatusUnprocessableEntity,
+			respBody:       `{"success":false,"messages":["task can not be moved next to itself, next to a task of another project or between tasks that are not adjacent"]}`,
+		},
+		{
+			name:     "Service error",
+			taskId:   "taskId",
+			userId:   "userId",
+			reqBody:  `{"after_id":"afterId"}`,
+			inputObj: domain.MoveTaskInput{AfterId: &afterId},
+			mockBehavior: func(s *mock_service.MockTaskServiceI, id, userId string, in domain.MoveTaskInput, task domain.Task) {
+				s.EXPECT().Move(context.Background(), id, userId, in).Return(task, errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			taskService := mock_service.NewMockTaskServiceI(ctrl)
+			testCase.mockBehavior(taskService, testCase.taskId, testCase.userId, testCase.inputObj, testCase.task)
+
+			services := &service.Services{Task: taskService}
+			handler := NewHandler(services)
+
+			router := gin.New()
+			router.POST("/task/:id/move", func(ctx *gin.Context) {
+				if testCase.userId != "" {
+					ctx.Set(userCtx, testCase.userId)
+				}
+			}, handler.taskMove)
+
+			w := httptest.NewRecorder()
+			reqUrl := fmt.Sprintf("/task/%s/move", testCase.taskId)
+			req := httptest.NewRequest("POST", reqUrl, bytes.NewBufferString(testCase.reqBody))
 
 			router.ServeHTTP(w, req)
 
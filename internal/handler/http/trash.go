@@ -0,0 +1,76 @@
+package http
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"net/http"
+)
+
+func (h *Handler) InitTrashRoutes(router *gin.RouterGroup) {
+	read := h.RequireScope(domain.ScopeTasksRead)
+	write := h.RequireScope(domain.ScopeTasksWrite)
+
+	trash := router.Group("/trash", h.AuthMiddleware)
+	{
+		trash.GET("/", read, h.trashGetAll)
+		trash.POST("/:id/restore", write, h.trashRestore)
+	}
+}
+
+// @Summary Getting trash
+// @Description Get deleted user tasks, most recently deleted first. They are purged after the retention period.
+// @Security ApiAuth
+// @Tags Trash
+// @Accept json
+// @Produce json
+// @Success 200 {object} SuccessResponse{data=[]domain.Task}
+// @Failure 400,422,500 {object} ErrorResponse
+// @Router /trash [get]
+func (h *Handler) trashGetAll(ctx *gin.Context) {
+	userId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	tasks, err := h.services.Task.GetTrash(ctx.Request.Context(), userId)
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, tasks)
+}
+
+// @Summary Restoring task
+// @Description Restore deleted task together with the subtasks deleted along with it.
+// @Description A subtask whose parent is still in the trash is restored as a top level task.
+// @Security ApiAuth
+// @Tags Trash
+// @Accept json
+// @Produce json
+// @Success 200 {object} SuccessResponse{data=domain.Task}
+// @Failure 400,404,422,500 {object} ErrorResponse
+// @Router /trash/{id}/restore [post]
+func (h *Handler) trashRestore(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		NewErrorResponseFromError(ctx, http.StatusBadRequest, errors.New("empty task id"))
+		return
+	}
+
+	userId, err := GetUserIdFromCtx(ctx)
+	if err != nil {
+		NewErrorResponseFromError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	task, err := h.services.Task.Restore(ctx.Request.Context(), id, userId)
+	if err != nil {
+		NewServiceErrorResponse(ctx, err)
+		return
+	}
+
+	NewSuccessResponse(ctx, task)
+}
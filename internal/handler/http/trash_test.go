@@ -0,0 +1,185 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"github.com/i-vasilkov/go-todo-app/internal/service"
+	"github.com/i-vasilkov/go-todo-app/internal/service/mocks"
+	"github.com/magiconair/properties/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler_trashGetAll(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockTaskServiceI, userId string, tasks []domain.Task)
+
+	deletedAt := time.Date(2020, 01, 02, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name           string
+		userId         string
+		tasks          []domain.Task
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:   "OK",
+			userId: "userId",
+			tasks: []domain.Task{
+				{
+					Id:        "taskId",
+					Name:      "test",
+					UserId:    "userId",
+					Status:    domain.TaskStatusTodo,
+					Priority:  domain.TaskPriorityP4,
+					Position:  domain.TaskPositionGap,
+					DeletedAt: &deletedAt,
+					CreatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+					UpdatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			mockBehavior: func(s *mock_service.MockTaskServiceI, userId string, tasks []domain.Task) {
+				s.EXPECT().GetTrash(context.Background(), userId).Return(tasks, nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":[{"id":"taskId","name":"test","user_id":"userId","status":"todo","priority":4,"position":1024,"deleted_at":"2020-01-02T00:00:00Z","created_at":"2020-01-01T00:00:00Z","updated_at":"2020-01-01T00:00:00Z"}]}`,
+		},
+		{
+			name:           "Empty UserId",
+			userId:         "",
+			tasks:          nil,
+			mockBehavior:   func(s *mock_service.MockTaskServiceI, userId string, tasks []domain.Task) {},
+			respStatusCode: http.StatusUnauthorized,
+			respBody:       `{"success":false,"messages":["not exists userId in context"]}`,
+		},
+		{
+			name:   "Service error",
+			userId: "userId",
+			tasks:  nil,
+			mockBehavior: func(s *mock_service.MockTaskServiceI, userId string, tasks []domain.Task) {
+				s.EXPECT().GetTrash(context.Background(), userId).Return(tasks, errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			taskService := mock_service.NewMockTaskServiceI(ctrl)
+			testCase.mockBehavior(taskService, testCase.userId, testCase.tasks)
+
+			services := &service.Services{Task: taskService}
+			handler := NewHandler(services)
+
+			router := gin.New()
+			router.GET("/trash", func(ctx *gin.Context) {
+				if testCase.userId != "" {
+					ctx.Set(userCtx, testCase.userId)
+				}
+			}, handler.trashGetAll)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/trash", nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
+
+func TestHandler_trashRestore(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockTaskServiceI, id, userId string, task domain.Task)
+
+	testCases := []struct {
+		name           string
+		taskId         string
+		userId         string
+		task           domain.Task
+		mockBehavior   mockBehavior
+		respStatusCode int
+		respBody       string
+	}{
+		{
+			name:   "OK",
+			taskId: "taskId",
+			userId: "userId",
+			task: domain.Task{
+				Id:        "taskId",
+				Name:      "test",
+				UserId:    "userId",
+				Status:    domain.TaskStatusTodo,
+				Priority:  domain.TaskPriorityP4,
+				Position:  domain.TaskPositionGap,
+				CreatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+				UpdatedAt: time.Date(2020, 01, 01, 0, 0, 0, 0, time.UTC),
+			},
+			mockBehavior: func(s *mock_service.MockTaskServiceI, id, userId string, task domain.Task) {
+				s.EXPECT().Restore(context.Background(), id, userId).Return(task, nil)
+			},
+			respStatusCode: http.StatusOK,
+			respBody:       `{"success":true,"data":{"id":"taskId","name":"test","user_id":"userId","status":"todo","priority":4,"position":1024,"created_at":"2020-01-01T00:00:00Z","updated_at":"2020-01-01T00:00:00Z"}}`,
+		},
+		{
+			name:           "Empty UserId",
+			taskId:         "taskId",
+			userId:         "",
+			task:           domain.Task{},
+			mockBehavior:   func(s *mock_service.MockTaskServiceI, id, userId string, task domain.Task) {},
+			respStatusCode: http.StatusUnauthorized,
+			respBody:       `{"success":false,"messages":["not exists userId in context"]}`,
+		},
+		{
+			name:   "Service error",
+			taskId: "taskId",
+			userId: "userId",
+			task:   domain.Task{},
+			mockBehavior: func(s *mock_service.MockTaskServiceI, id, userId string, task domain.Task) {
+				s.EXPECT().Restore(context.Background(), id, userId).Return(task, errors.New("service error"))
+			},
+			respStatusCode: http.StatusInternalServerError,
+			respBody:       `{"success":false,"messages":["internal server error"]}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			taskService := mock_service.NewMockTaskServiceI(ctrl)
+			testCase.mockBehavior(taskService, testCase.taskId, testCase.userId, testCase.task)
+
+			services := &service.Services{Task: taskService}
+			handler := NewHandler(services)
+
+			router := gin.New()
+			router.POST("/trash/:id/restore", func(ctx *gin.Context) {
+				if testCase.userId != "" {
+					ctx.Set(userCtx, testCase.userId)
+				}
+			}, handler.trashRestore)
+
+			w := httptest.NewRecorder()
+			reqUrl := fmt.Sprintf("/trash/%s/restore", testCase.taskId)
+			req := httptest.NewRequest("POST", reqUrl, nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.respStatusCode)
+			assert.Equal(t, w.Body.String(), testCase.respBody)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"github.com/i-vasilkov/go-todo-app/internal/repository/memrep"
 	"github.com/i-vasilkov/go-todo-app/internal/repository/mongorep"
 	"github.com/i-vasilkov/go-todo-app/internal/repository/postgresrep"
 	"github.com/i-vasilkov/go-todo-app/internal/service"
@@ -18,8 +19,14 @@ func NewMongoRepositoriesBuilder(db *mongo.Database) *MongoRepositoriesBuilder {
 
 func (rb *MongoRepositoriesBuilder) Build() *service.Repositories {
 	return &service.Repositories{
-		Task: mongorep.NewMongoTaskRepository(rb.db),
-		User: mongorep.NewMongoUserRepository(rb.db),
+		Task:         mongorep.NewMongoTaskRepository(rb.db),
+		User:         mongorep.NewMongoUserRepository(rb.db),
+		Session:      mongorep.NewMongoSessionRepository(rb.db),
+		Revocation:   mongorep.NewMongoRevocationRepository(rb.db),
+		LoginAttempt: mongorep.NewMongoLoginAttemptRepository(rb.db),
+		AccessToken:  mongorep.NewMongoAccessTokenRepository(rb.db),
+		Project:      mongorep.NewMongoProjectRepository(rb.db),
+		Label:        mongorep.NewMongoLabelRepository(rb.db),
 	}
 }
 
@@ -33,7 +40,36 @@ func NewPostgresRepositoriesBuilder(db *sqlx.DB) *PostgresRepositoriesBuilder {
 
 func (rb *PostgresRepositoriesBuilder) Build() *service.Repositories {
 	return &service.Repositories{
-		Task: postgresrep.NewPostgresTaskRepository(rb.db),
-		User: postgresrep.NewPostgresUserRepository(rb.db),
+		Task:         postgresrep.NewPostgresTaskRepository(rb.db),
+		User:         postgresrep.NewPostgresUserRepository(rb.db),
+		Session:      postgresrep.NewPostgresSessionRepository(rb.db),
+		Revocation:   postgresrep.NewPostgresRevocationRepository(rb.db),
+		LoginAttempt: postgresrep.NewPostgresLoginAttemptRepository(rb.db),
+		AccessToken:  postgresrep.NewPostgresAccessTokenRepository(rb.db),
+		Project:      postgresrep.NewPostgresProjectRepository(rb.db),
+		Label:        postgresrep.NewPostgresLabelRepository(rb.db),
+	}
+}
+
+// MemoryRepositoriesBuilder keeps all the data in the given store, which lives only as long
+// as the process.
+type MemoryRepositoriesBuilder struct {
+	store *memrep.Store
+}
+
+func NewMemoryRepositoriesBuilder(store *memrep.Store) *MemoryRepositoriesBuilder {
+	return &MemoryRepositoriesBuilder{store: store}
+}
+
+func (rb *MemoryRepositoriesBuilder) Build() *service.Repositories {
+	return &service.Repositories{
+		Task:         memrep.NewTaskRepository(rb.store),
+		User:         memrep.NewUserRepository(rb.store),
+		Session:      memrep.NewSessionRepository(rb.store),
+		Revocation:   memrep.NewRevocationRepository(),
+		LoginAttempt: memrep.NewLoginAttemptRepository(),
+		AccessToken:  memrep.NewAccessTokenRepository(rb.store),
+		Project:      memrep.NewProjectRepository(rb.store),
+		Label:        memrep.NewLabelRepository(rb.store),
 	}
 }
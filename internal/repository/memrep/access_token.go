@@ -0,0 +1,97 @@
+package memrep
+
+import (
+	"context"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"sort"
+	"time"
+)
+
+type AccessTokenRepository struct {
+	store *Store
+}
+
+func NewAccessTokenRepository(store *Store) *AccessTokenRepository {
+	return &AccessTokenRepository{store: store}
+}
+
+// GetAll returns the tokens of the user, the newest first.
+func (rep *AccessTokenRepository) GetAll(ctx context.Context, userId string) ([]domain.AccessToken, error) {
+	rep.store.mu.RLock()
+	defer rep.store.mu.RUnlock()
+
+	tokens := make([]domain.AccessToken, 0)
+	for _, token := range rep.store.accessTokens {
+		if token.UserId == userId {
+			tokens = append(tokens, copyAccessToken(token))
+		}
+	}
+
+	sort.Slice(tokens, func(i, j int) bool {
+		if !tokens[i].CreatedAt.Equal(tokens[j].CreatedAt) {
+			return tokens[i].CreatedAt.After(tokens[j].CreatedAt)
+		}
+		return compareIds(tokens[i].Id, tokens[j].Id) > 0
+	})
+
+	return tokens, nil
+}
+
+func (rep *AccessTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (domain.AccessToken, error) {
+	rep.store.mu.RLock()
+	defer rep.store.mu.RUnlock()
+
+	for _, token := range rep.store.accessTokens {
+		if token.TokenHash == tokenHash {
+			return copyAccessToken(token), nil
+		}
+	}
+
+	return domain.AccessToken{}, domain.ErrAccessTokenNotFound
+}
+
+func (rep *AccessTokenRepository) Create(ctx context.Context, userId, tokenHash string, in domain.CreateAccessTokenInput) (domain.AccessToken, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	token := &domain.AccessToken{
+		Id:        rep.store.nextId(),
+		UserId:    userId,
+		Name:      in.Name,
+		Scopes:    append(make([]domain.Scope, 0, len(in.Scopes)), in.Scopes...),
+		TokenHash: tokenHash,
+		ExpiresAt: copyTime(in.ExpiresAt),
+		CreatedAt: now(),
+	}
+	rep.store.accessTokens[token.Id] = token
+
+	return copyAccessToken(token), nil
+}
+
+func (rep *AccessTokenRepository) Delete(ctx context.Context, id, userId string) error {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	if token, ok := rep.store.accessTokens[id]; ok && token.UserId == userId {
+		delete(rep.store.accessTokens, id)
+	}
+	return nil
+}
+
+func (rep *AccessTokenRepository) UpdateLastUsed(ctx context.Context, id string, at time.Time) error {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	if token, ok := rep.store.accessTokens[id]; ok {
+		token.LastUsedAt = copyTime(&at)
+	}
+	return nil
+}
+
+func copyAccessToken(token *domain.AccessToken) domain.AccessToken {
+	c := *token
+	c.Scopes = append(make([]domain.Scope, 0, len(token.Scopes)), token.Scopes...)
+	c.ExpiresAt = copyTime(token.ExpiresAt)
+	c.LastUsedAt = copyTime(token.LastUsedAt)
+	return c
+}
@@ -0,0 +1,116 @@
+package memrep
+
+import (
+	"context"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"sort"
+)
+
+type LabelRepository struct {
+	store *Store
+}
+
+func NewLabelRepository(store *Store) *LabelRepository {
+	return &LabelRepository{store: store}
+}
+
+func (rep *LabelRepository) Get(ctx context.Context, id, userId string) (domain.Label, error) {
+	rep.store.mu.RLock()
+	defer rep.store.mu.RUnlock()
+
+	label, ok := rep.store.labels[id]
+	if !ok || label.UserId != userId {
+		return domain.Label{}, domain.ErrLabelNotFound
+	}
+
+	return *label, nil
+}
+
+func (rep *LabelRepository) GetAll(ctx context.Context, userId string) ([]domain.Label, error) {
+	rep.store.mu.RLock()
+	defer rep.store.mu.RUnlock()
+
+	labels := make([]domain.Label, 0)
+	for _, label := range rep.store.labels {
+		if label.UserId == userId {
+			labels = append(labels, *label)
+		}
+	}
+
+	sort.Slice(labels, func(i, j int) bool {
+		if labels[i].Name != labels[j].Name {
+			return labels[i].Name < labels[j].Name
+		}
+		return compareIds(labels[i].Id, labels[j].Id) < 0
+	})
+
+	return labels, nil
+}
+
+func (rep *LabelRepository) Create(ctx context.Context, userId string, in domain.CreateLabelInput) (domain.Label, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	if err := rep.checkUnique("", userId, in.Name); err != nil {
+		return domain.Label{}, err
+	}
+
+	label := &domain.Label{
+		Id:        rep.store.nextId(),
+		Name:      in.Name,
+		Color:     in.Color,
+		UserId:    userId,
+		CreatedAt: now(),
+	}
+	rep.store.labels[label.Id] = label
+
+	return *label, nil
+}
+
+func (rep *LabelRepository) Update(ctx context.Context, id, userId string, in domain.UpdateLabelInput) (domain.Label, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	label, ok := rep.store.labels[id]
+	if !ok || label.UserId != userId {
+		return domain.Label{}, domain.ErrLabelNotFound
+	}
+
+	if err := rep.checkUnique(id, userId, in.Name); err != nil {
+		return domain.Label{}, err
+	}
+
+	label.Name = in.Name
+	label.Color = in.Color
+
+	return *label, nil
+}
+
+// Delete removes the label and detaches it from every task.
+func (rep *LabelRepository) Delete(ctx context.Context, id, userId string) error {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	label, ok := rep.store.labels[id]
+	if !ok || label.UserId != userId {
+		return nil
+	}
+	delete(rep.store.labels, id)
+
+	for _, task := range rep.store.tasks {
+		task.LabelIds = removeStrings(task.LabelIds, []string{id})
+	}
+
+	return nil
+}
+
+// checkUnique stands in for the unique index on the user and the label name. The caller holds
+// the lock.
+func (rep *LabelRepository) checkUnique(id, userId, name string) error {
+	for _, label := range rep.store.labels {
+		if label.Id != id && label.UserId == userId && label.Name == name {
+			return domain.ErrLabelTaken
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package memrep
+
+import (
+	"context"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"sync"
+	"time"
+)
+
+type loginAttempts struct {
+	domain.LoginAttempts
+	expiresAt time.Time
+}
+
+// LoginAttemptRepository keeps failed sign ins in the process memory. Like RevocationRepository
+// it only suits a single instance.
+type LoginAttemptRepository struct {
+	mu       sync.Mutex
+	attempts map[string]*loginAttempts
+}
+
+func NewLoginAttemptRepository() *LoginAttemptRepository {
+	return &LoginAttemptRepository{
+		attempts: make(map[string]*loginAttempts),
+	}
+}
+
+func (rep *LoginAttemptRepository) Get(ctx context.Context, key string) (domain.LoginAttempts, error) {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+
+	attempts, ok := rep.attempts[key]
+	if !ok || attempts.expiresAt.Before(time.Now()) {
+		return domain.LoginAttempts{Key: key}, nil
+	}
+
+	return attempts.LoginAttempts, nil
+}
+
+// AddFailure counts a failure at the given moment. The count starts over when the previous
+// failure is older than the window. Expired entries are dropped on the way.
+func (rep *LoginAttemptRepository) AddFailure(ctx context.Context, key string, at time.Time, window time.Duration) (domain.LoginAttempts, error) {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+
+	now := time.Now()
+	for k, attempts := range rep.attempts {
+		if attempts.expiresAt.Before(now) {
+			delete(rep.attempts, k)
+		}
+	}
+
+	attempts, ok := rep.attempts[key]
+	if !ok {
+		attempts = &loginAttempts{LoginAttempts: domain.LoginAttempts{Key: key}}
+		rep.attempts[key] = attempts
+	}
+
+	if attempts.LastFailureAt.Before(at.Add(-window)) {
+		attempts.Failures = 1
+	} else {
+		attempts.Failures++
+	}
+	attempts.LastFailureAt = at
+
+	if expiresAt := at.Add(window); expiresAt.After(attempts.expiresAt) {
+		attempts.expiresAt = expiresAt
+	}
+
+	return attempts.LoginAttempts, nil
+}
+
+func (rep *LoginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+
+	attempts, ok := rep.attempts[key]
+	if !ok {
+		attempts = &loginAttempts{LoginAttempts: domain.LoginAttempts{Key: key}}
+		rep.attempts[key] = attempts
+	}
+
+	attempts.LockedUntil = &until
+	if until.After(attempts.expiresAt) {
+		attempts.expiresAt = until
+	}
+
+	return nil
+}
+
+func (rep *LoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+
+	delete(rep.attempts, key)
+	return nil
+}
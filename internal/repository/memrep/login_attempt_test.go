@@ -0,0 +1,49 @@
+package memrep
+
+import (
+	"context"
+	"github.com/magiconair/properties/assert"
+	"testing"
+	"time"
+)
+
+func TestLoginAttemptRepository(t *testing.T) {
+	ctx := context.Background()
+	rep := NewLoginAttemptRepository()
+	now := time.Now()
+
+	attempts, err := rep.Get(ctx, "login:test")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, attempts.Failures, 0)
+
+	attempts, _ = rep.AddFailure(ctx, "login:test", now.Add(-2*time.Hour), time.Hour)
+	assert.Equal(t, attempts.Failures, 1)
+	attempts, _ = rep.AddFailure(ctx, "login:test", now.Add(-time.Minute), time.Hour)
+	assert.Equal(t, attempts.Failures, 1)
+	attempts, _ = rep.AddFailure(ctx, "login:test", now, time.Hour)
+	assert.Equal(t, attempts.Failures, 2)
+	assert.Equal(t, attempts.LastFailureAt, now)
+
+	attempts, _ = rep.Get(ctx, "login:test")
+	assert.Equal(t, attempts.Failures, 2)
+	attempts, _ = rep.Get(ctx, "ip:127.0.0.1")
+	assert.Equal(t, attempts.Failures, 0)
+
+	until := now.Add(2 * time.Hour)
+	assert.Equal(t, rep.Lock(ctx, "login:test", until), nil)
+	attempts, _ = rep.Get(ctx, "login:test")
+	assert.Equal(t, *attempts.LockedUntil, until)
+	assert.Equal(t, rep.attempts["login:test"].expiresAt, until)
+
+	assert.Equal(t, rep.Reset(ctx, "login:test"), nil)
+	attempts, _ = rep.Get(ctx, "login:test")
+	assert.Equal(t, attempts.Failures, 0)
+	assert.Equal(t, attempts.LockedUntil == nil, true)
+
+	_, _ = rep.AddFailure(ctx, "login:expired", now.Add(-2*time.Hour), time.Hour)
+	attempts, _ = rep.Get(ctx, "login:expired")
+	assert.Equal(t, attempts.Failures, 0)
+	_, _ = rep.AddFailure(ctx, "login:other", now, time.Hour)
+	_, kept := rep.attempts["login:expired"]
+	assert.Equal(t, kept, false)
+}
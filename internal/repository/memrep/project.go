@@ -0,0 +1,123 @@
+package memrep
+
+import (
+	"context"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"sort"
+)
+
+type ProjectRepository struct {
+	store *Store
+}
+
+func NewProjectRepository(store *Store) *ProjectRepository {
+	return &ProjectRepository{store: store}
+}
+
+func (rep *ProjectRepository) Get(ctx context.Context, id, userId string) (domain.Project, error) {
+	rep.store.mu.RLock()
+	defer rep.store.mu.RUnlock()
+
+	project, ok := rep.store.projects[id]
+	if !ok || project.UserId != userId {
+		return domain.Project{}, domain.ErrProjectNotFound
+	}
+
+	return *project, nil
+}
+
+func (rep *ProjectRepository) GetAll(ctx context.Context, userId string) ([]domain.Project, error) {
+	rep.store.mu.RLock()
+	defer rep.store.mu.RUnlock()
+
+	projects := make([]domain.Project, 0)
+	for _, project := range rep.store.projects {
+		if project.UserId == userId {
+			projects = append(projects, *project)
+		}
+	}
+
+	sort.Slice(projects, func(i, j int) bool {
+		if !projects[i].CreatedAt.Equal(projects[j].CreatedAt) {
+			return projects[i].CreatedAt.Before(projects[j].CreatedAt)
+		}
+		return compareIds(projects[i].Id, projects[j].Id) < 0
+	})
+
+	return projects, nil
+}
+
+func (rep *ProjectRepository) Create(ctx context.Context, userId string, in domain.CreateProjectInput) (domain.Project, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	createdAt := now()
+	project := &domain.Project{
+		Id:        rep.store.nextId(),
+		Name:      in.Name,
+		UserId:    userId,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+	rep.store.projects[project.Id] = project
+
+	return *project, nil
+}
+
+func (rep *ProjectRepository) Update(ctx context.Context, id, userId string, in domain.UpdateProjectInput) (domain.Project, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	project, ok := rep.store.projects[id]
+	if !ok || project.UserId != userId {
+		return domain.Project{}, domain.ErrProjectNotFound
+	}
+
+	project.Name = in.Name
+	project.UpdatedAt = now()
+
+	return *project, nil
+}
+
+// Delete removes the project and, depending on mode, moves its tasks with their subtasks
+// to the trash or to the inbox.
+func (rep *ProjectRepository) Delete(ctx context.Context, id, userId string, mode domain.ProjectDeleteMode) error {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	project, ok := rep.store.projects[id]
+	if !ok || project.UserId != userId {
+		return domain.ErrProjectNotFound
+	}
+
+	var tasks []*domain.Task
+	for _, task := range rep.store.tasks {
+		if task.UserId == userId && task.ProjectId != nil && *task.ProjectId == id {
+			tasks = append(tasks, task)
+		}
+	}
+
+	if mode == domain.ProjectDeleteCascade {
+		var roots []*domain.Task
+		for _, task := range tasks {
+			if task.DeletedAt == nil {
+				roots = append(roots, task)
+			}
+		}
+		rep.store.trashTasks(roots)
+	}
+
+	// The tasks, trashed ones included, are left in the inbox, as the foreign key does in Postgres.
+	// They keep their order after the tasks already in the inbox.
+	sort.Slice(tasks, func(i, j int) bool {
+		return comparePositions(tasks[i], tasks[j]) < 0
+	})
+	position := rep.store.lastPosition(userId, nil)
+	for i, task := range tasks {
+		task.ProjectId = nil
+		task.Position = position + float64(i+1)*domain.TaskPositionGap
+	}
+	delete(rep.store.projects, id)
+
+	return nil
+}
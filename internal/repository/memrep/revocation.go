@@ -0,0 +1,61 @@
+package memrep
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RevocationRepository keeps revocations in the process memory. They are lost on restart and
+// not shared between instances, so it only suits a single instance.
+type RevocationRepository struct {
+	mu     sync.RWMutex
+	tokens map[string]time.Time
+	users  map[string]time.Time
+}
+
+func NewRevocationRepository() *RevocationRepository {
+	return &RevocationRepository{
+		tokens: make(map[string]time.Time),
+		users:  make(map[string]time.Time),
+	}
+}
+
+// Revoke stores the token id until the token expires. Ids of tokens that have expired in the
+// meantime are no longer needed and are dropped on the way.
+func (rep *RevocationRepository) Revoke(ctx context.Context, tokenId string, expiresAt time.Time) error {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+
+	now := time.Now()
+	for id, exp := range rep.tokens {
+		if exp.Before(now) {
+			delete(rep.tokens, id)
+		}
+	}
+
+	rep.tokens[tokenId] = expiresAt
+	return nil
+}
+
+func (rep *RevocationRepository) RevokeUser(ctx context.Context, userId string, before time.Time) error {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+
+	if before.After(rep.users[userId]) {
+		rep.users[userId] = before
+	}
+	return nil
+}
+
+func (rep *RevocationRepository) IsRevoked(ctx context.Context, tokenId, userId string, issuedAt time.Time) (bool, error) {
+	rep.mu.RLock()
+	defer rep.mu.RUnlock()
+
+	if _, ok := rep.tokens[tokenId]; ok {
+		return true, nil
+	}
+
+	before, ok := rep.users[userId]
+	return ok && before.After(issuedAt), nil
+}
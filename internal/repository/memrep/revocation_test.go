@@ -0,0 +1,38 @@
+package memrep
+
+import (
+	"context"
+	"github.com/magiconair/properties/assert"
+	"testing"
+	"time"
+)
+
+func TestRevocationRepository(t *testing.T) {
+	ctx := context.Background()
+	rep := NewRevocationRepository()
+	issuedAt := time.Now().Add(-time.Minute)
+
+	revoked, err := rep.IsRevoked(ctx, "tokenId", "userId", issuedAt)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, revoked, false)
+
+	assert.Equal(t, rep.Revoke(ctx, "tokenId", time.Now().Add(time.Hour)), nil)
+	revoked, _ = rep.IsRevoked(ctx, "tokenId", "userId", issuedAt)
+	assert.Equal(t, revoked, true)
+	revoked, _ = rep.IsRevoked(ctx, "otherId", "userId", issuedAt)
+	assert.Equal(t, revoked, false)
+
+	assert.Equal(t, rep.RevokeUser(ctx, "userId", time.Now()), nil)
+	assert.Equal(t, rep.RevokeUser(ctx, "userId", issuedAt.Add(-time.Hour)), nil)
+	revoked, _ = rep.IsRevoked(ctx, "otherId", "userId", issuedAt)
+	assert.Equal(t, revoked, true)
+	revoked, _ = rep.IsRevoked(ctx, "otherId", "userId", time.Now().Add(time.Minute))
+	assert.Equal(t, revoked, false)
+	revoked, _ = rep.IsRevoked(ctx, "otherId", "otherUserId", issuedAt)
+	assert.Equal(t, revoked, false)
+
+	assert.Equal(t, rep.Revoke(ctx, "expiredId", time.Now().Add(-time.Minute)), nil)
+	assert.Equal(t, rep.Revoke(ctx, "newId", time.Now().Add(time.Hour)), nil)
+	_, kept := rep.tokens["expiredId"]
+	assert.Equal(t, kept, false)
+}
@@ -0,0 +1,91 @@
+package memrep
+
+import (
+	"context"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+)
+
+type SessionRepository struct {
+	store *Store
+}
+
+func NewSessionRepository(store *Store) *SessionRepository {
+	return &SessionRepository{store: store}
+}
+
+func (rep *SessionRepository) Create(ctx context.Context, in domain.CreateSessionInput) (domain.Session, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	session := &domain.Session{
+		Id:        rep.store.nextId(),
+		UserId:    in.UserId,
+		FamilyId:  in.FamilyId,
+		TokenHash: in.TokenHash,
+		ExpiresAt: in.ExpiresAt.UTC(),
+		CreatedAt: now(),
+	}
+	rep.store.sessions[session.Id] = session
+
+	return copySession(session), nil
+}
+
+func (rep *SessionRepository) GetByTokenHash(ctx context.Context, tokenHash string) (domain.Session, error) {
+	rep.store.mu.RLock()
+	defer rep.store.mu.RUnlock()
+
+	for _, session := range rep.store.sessions {
+		if session.TokenHash == tokenHash {
+			return copySession(session), nil
+		}
+	}
+
+	return domain.Session{}, domain.ErrSessionNotFound
+}
+
+// Revoke marks the session as used and tells whether it was still active before the call.
+func (rep *SessionRepository) Revoke(ctx context.Context, id string) (bool, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	session, ok := rep.store.sessions[id]
+	if !ok || session.RevokedAt != nil {
+		return false, nil
+	}
+
+	revokedAt := now()
+	session.RevokedAt = &revokedAt
+	return true, nil
+}
+
+func (rep *SessionRepository) RevokeFamily(ctx context.Context, familyId string) error {
+	rep.revokeWhere(func(session *domain.Session) bool {
+		return session.FamilyId == familyId
+	})
+	return nil
+}
+
+func (rep *SessionRepository) RevokeUser(ctx context.Context, userId string) error {
+	rep.revokeWhere(func(session *domain.Session) bool {
+		return session.UserId == userId
+	})
+	return nil
+}
+
+func (rep *SessionRepository) revokeWhere(match func(session *domain.Session) bool) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	revokedAt := now()
+	for _, session := range rep.store.sessions {
+		if session.RevokedAt == nil && match(session) {
+			session.RevokedAt = copyTime(&revokedAt)
+		}
+	}
+}
+
+func copySession(session *domain.Session) domain.Session {
+	c := *session
+	c.RevokedAt = copyTime(session.RevokedAt)
+	return c
+}
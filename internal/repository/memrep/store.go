@@ -0,0 +1,164 @@
+package memrep
+
+import (
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Store holds the data of all the repositories built on it. They share one lock, so deleting
+// a user, a project or a label reaches the tasks the way the foreign keys do in Postgres.
+type Store struct {
+	mu           sync.RWMutex
+	lastId       int
+	users        map[string]*userRecord
+	tasks        map[string]*domain.Task
+	projects     map[string]*domain.Project
+	labels       map[string]*domain.Label
+	sessions     map[string]*domain.Session
+	accessTokens map[string]*domain.AccessToken
+}
+
+func NewStore() *Store {
+	return &Store{
+		users:        make(map[string]*userRecord),
+		tasks:        make(map[string]*domain.Task),
+		projects:     make(map[string]*domain.Project),
+		labels:       make(map[string]*domain.Label),
+		sessions:     make(map[string]*domain.Session),
+		accessTokens: make(map[string]*domain.AccessToken),
+	}
+}
+
+// nextId hands out increasing numeric ids, unique over all the records like the ObjectIds of
+// MongoDB. The caller holds the write lock.
+func (s *Store) nextId() string {
+	s.lastId++
+	return strconv.Itoa(s.lastId)
+}
+
+// deleteTasks removes the tasks together with their subtasks, trashed or not.
+func (s *Store) deleteTasks(ids []string) {
+	for len(ids) > 0 {
+		var children []string
+		for _, id := range ids {
+			delete(s.tasks, id)
+		}
+		for _, task := range s.tasks {
+			if task.ParentId != nil && contains(ids, *task.ParentId) {
+				children = append(children, task.Id)
+			}
+		}
+		ids = children
+	}
+}
+
+// lastPosition returns the highest task position in the project, trashed tasks included, or 0
+// for a project without tasks. The caller holds the lock.
+func (s *Store) lastPosition(userId string, projectId *string) float64 {
+	position := 0.0
+	for _, task := range s.tasks {
+		if task.UserId == userId && equalString(task.ProjectId, projectId) && task.Position > position {
+			position = task.Position
+		}
+	}
+	return position
+}
+
+// trashTasks moves the tasks to the trash together with their subtasks that are not trashed yet.
+// The caller holds the write lock.
+func (s *Store) trashTasks(roots []*domain.Task) {
+	deletedAt := time.Now().UTC()
+	for _, root := range roots {
+		subtree := s.subtree(root, func(parent, child *domain.Task) bool {
+			return child.DeletedAt == nil
+		})
+		for _, task := range subtree {
+			task.DeletedAt = copyTime(&deletedAt)
+		}
+	}
+}
+
+// subtree returns the task followed by its descendants, going down only to the children
+// the follow function accepts. The caller holds the lock.
+func (s *Store) subtree(root *domain.Task, follow func(parent, child *domain.Task) bool) []*domain.Task {
+	subtree := []*domain.Task{root}
+	for i := 0; i < len(subtree); i++ {
+		parent := subtree[i]
+		for _, child := range s.tasks {
+			if child.ParentId != nil && *child.ParentId == parent.Id && follow(parent, child) {
+				subtree = append(subtree, child)
+			}
+		}
+	}
+	return subtree
+}
+
+// compareIds orders the ids numerically, the way they were handed out.
+func compareIds(a, b string) int {
+	intA, _ := strconv.Atoi(a)
+	intB, _ := strconv.Atoi(b)
+
+	switch {
+	case intA < intB:
+		return -1
+	case intA > intB:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// now has the precision of the timestamps the database repositories store.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
+
+// copyTime keeps stored times from being changed through the pointers handed in and out.
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	u := t.UTC()
+	return &u
+}
+
+func copyString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+
+	c := *s
+	return &c
+}
+
+// equalString compares two optional ids, nil only being equal to nil.
+// comparePositions orders tasks the way the lists do, by position and then by id.
+func comparePositions(a, b *domain.Task) int {
+	switch {
+	case a.Position < b.Position:
+		return -1
+	case a.Position > b.Position:
+		return 1
+	default:
+		return compareIds(a.Id, b.Id)
+	}
+}
+
+func equalString(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
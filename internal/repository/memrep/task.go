@@ -0,0 +1,419 @@
+package memrep
+
+import (
+	"context"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"sort"
+	"time"
+)
+
+type TaskRepository struct {
+	store *Store
+}
+
+func NewTaskRepository(store *Store) *TaskRepository {
+	return &TaskRepository{store: store}
+}
+
+func (rep *TaskRepository) Get(ctx context.Context, id, userId string) (domain.Task, error) {
+	rep.store.mu.RLock()
+	defer rep.store.mu.RUnlock()
+
+	return rep.getTask(id, userId)
+}
+
+func (rep *TaskRepository) GetAll(ctx context.Context, userId string, filter domain.TaskFilter) (domain.TaskPage, error) {
+	filter = filter.Normalize()
+	cursor, err := filter.DecodeCursor()
+	if err != nil {
+		return domain.TaskPage{}, err
+	}
+
+	q, err := newTaskQuery(filter)
+	if err != nil {
+		return domain.TaskPage{}, err
+	}
+	if cursor != nil {
+		if err := q.applyCursor(cursor); err != nil {
+			return domain.TaskPage{}, err
+		}
+	}
+
+	rep.store.mu.RLock()
+	tasks := rep.selectTasks(func(task *domain.Task) bool {
+		return task.UserId == userId && task.DeletedAt == nil && q.match(task)
+	})
+	rep.store.mu.RUnlock()
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return q.compare(&tasks[i], &tasks[j]) < 0
+	})
+
+	page := domain.TaskPage{Items: tasks}
+	if len(tasks) > filter.Limit {
+		page.Items = tasks[:filter.Limit]
+		page.NextCursor = domain.NewTaskCursor(filter, page.Items[filter.Limit-1])
+	}
+
+	return page, nil
+}
+
+// Create puts the task after all the other tasks of the project, trashed ones included.
+func (rep *TaskRepository) Create(ctx context.Context, userId string, in domain.CreateTaskInput) (domain.Task, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	createdAt := now()
+	task := &domain.Task{
+		Id:          rep.store.nextId(),
+		Name:        in.Name,
+		Description: in.Description,
+		UserId:      userId,
+		ProjectId:   copyString(in.ProjectId),
+		ParentId:    copyString(in.ParentId),
+		Recurrence:  in.Recurrence,
+		Status:      domain.TaskStatusTodo,
+		Priority:    in.Priority.OrDefault(),
+		Position:    rep.store.lastPosition(userId, in.ProjectId) + domain.TaskPositionGap,
+		StartAt:     copyTime(in.StartAt),
+		DueAt:       copyTime(in.DueAt),
+		CreatedAt:   createdAt,
+		UpdatedAt:   createdAt,
+	}
+	rep.store.tasks[task.Id] = task
+
+	return copyTask(task), nil
+}
+
+func (rep *TaskRepository) Update(ctx context.Context, id, userId string, in domain.UpdateTaskInput) (domain.Task, error) {
+	return rep.update(id, userId, func(task *domain.Task) {
+		// A task moved to another project goes to the end of its list, positions are per project.
+		if !equalString(task.ProjectId, in.ProjectId) {
+			task.Position = rep.store.lastPosition(userId, in.ProjectId) + domain.TaskPositionGap
+		}
+		task.Name = in.Name
+		task.Description = in.Description
+		task.ProjectId = copyString(in.ProjectId)
+		task.ParentId = copyString(in.ParentId)
+		task.Priority = in.Priority.OrDefault()
+		task.StartAt = copyTime(in.StartAt)
+		task.DueAt = copyTime(in.DueAt)
+		task.Recurrence = in.Recurrence
+	})
+}
+
+// Delete moves the task to the trash together with its subtasks that are not trashed yet.
+func (rep *TaskRepository) Delete(ctx context.Context, id, userId string) error {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	task, ok := rep.store.tasks[id]
+	if !ok || task.UserId != userId || task.DeletedAt != nil {
+		return domain.ErrTaskNotFound
+	}
+
+	rep.store.trashTasks([]*domain.Task{task})
+	return nil
+}
+
+// GetTrash returns the trashed tasks of the user, most recently deleted first.
+func (rep *TaskRepository) GetTrash(ctx context.Context, userId string) ([]domain.Task, error) {
+	rep.store.mu.RLock()
+	tasks := rep.selectTasks(func(task *domain.Task) bool {
+		return task.UserId == userId && task.DeletedAt != nil
+	})
+	rep.store.mu.RUnlock()
+
+	sort.Slice(tasks, func(i, j int) bool {
+		if !tasks[i].DeletedAt.Equal(*tasks[j].DeletedAt) {
+			return tasks[i].DeletedAt.After(*tasks[j].DeletedAt)
+		}
+		return compareIds(tasks[i].Id, tasks[j].Id) > 0
+	})
+
+	return tasks, nil
+}
+
+// Restore takes the task out of the trash together with the subtasks that were deleted
+// along with it. A subtask whose parent is still in the trash is restored to the top level.
+func (rep *TaskRepository) Restore(ctx context.Context, id, userId string) (domain.Task, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	task, ok := rep.store.tasks[id]
+	if !ok || task.UserId != userId || task.DeletedAt == nil {
+		return domain.Task{}, domain.ErrTaskNotFound
+	}
+
+	if task.ParentId != nil {
+		if parent, ok := rep.store.tasks[*task.ParentId]; ok && parent.UserId == userId && parent.DeletedAt != nil {
+			task.ParentId = nil
+		}
+	}
+
+	subtree := rep.store.subtree(task, func(parent, child *domain.Task) bool {
+		return child.DeletedAt != nil && child.DeletedAt.Equal(*parent.DeletedAt)
+	})
+	for _, t := range subtree {
+		t.DeletedAt = nil
+	}
+
+	return rep.getTask(id, userId)
+}
+
+// PurgeTrash permanently removes the tasks of all users that were trashed before the given
+// time. Their subtasks go with them, yet only the trashed tasks are counted.
+func (rep *TaskRepository) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	var ids []string
+	for _, task := range rep.store.tasks {
+		if task.DeletedAt != nil && task.DeletedAt.Before(before) {
+			ids = append(ids, task.Id)
+		}
+	}
+	rep.store.deleteTasks(ids)
+
+	return int64(len(ids)), nil
+}
+
+// Count sums up the tasks of all users by status. Trashed tasks are only counted as such.
+func (rep *TaskRepository) Count(ctx context.Context) (domain.TaskCounts, error) {
+	rep.store.mu.RLock()
+	defer rep.store.mu.RUnlock()
+
+	counts := domain.TaskCounts{ByStatus: make(map[domain.TaskStatus]int64)}
+	for _, task := range rep.store.tasks {
+		if task.DeletedAt != nil {
+			counts.InTrash++
+			continue
+		}
+		counts.ByStatus[task.Status]++
+		counts.Total++
+	}
+
+	return counts, nil
+}
+
+func (rep *TaskRepository) UpdateStatus(
+	ctx context.Context, id, userId string, status domain.TaskStatus, completedAt *time.Time,
+) (domain.Task, error) {
+	return rep.update(id, userId, func(task *domain.Task) {
+		task.Status = status
+		task.CompletedAt = copyTime(completedAt)
+	})
+}
+
+func (rep *TaskRepository) GetOverdue(ctx context.Context, userId string, now time.Time) ([]domain.Task, error) {
+	rep.store.mu.RLock()
+	tasks := rep.selectTasks(func(task *domain.Task) bool {
+		return task.UserId == userId && task.DeletedAt == nil && task.IsOverdue(now)
+	})
+	rep.store.mu.RUnlock()
+
+	sortByDueAt(tasks)
+	return tasks, nil
+}
+
+func (rep *TaskRepository) GetDueBetween(ctx context.Context, userId string, from, to time.Time) ([]domain.Task, error) {
+	rep.store.mu.RLock()
+	tasks := rep.selectTasks(func(task *domain.Task) bool {
+		return task.UserId == userId && task.DeletedAt == nil && task.DueAt != nil &&
+			!task.DueAt.Before(from) && task.DueAt.Before(to)
+	})
+	rep.store.mu.RUnlock()
+
+	sortByDueAt(tasks)
+	return tasks, nil
+}
+
+// AttachLabels links the labels of the user to the task; labels of other users are skipped.
+func (rep *TaskRepository) AttachLabels(ctx context.Context, id, userId string, labelIds []string) (domain.Task, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	task, err := rep.findTask(id, userId)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	for _, labelId := range labelIds {
+		label, ok := rep.store.labels[labelId]
+		if ok && label.UserId == userId && !contains(task.LabelIds, labelId) {
+			task.LabelIds = append(task.LabelIds, labelId)
+		}
+	}
+	sort.Slice(task.LabelIds, func(i, j int) bool {
+		return compareIds(task.LabelIds[i], task.LabelIds[j]) < 0
+	})
+
+	return copyTask(task), nil
+}
+
+func (rep *TaskRepository) DetachLabels(ctx context.Context, id, userId string, labelIds []string) (domain.Task, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	task, err := rep.findTask(id, userId)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	task.LabelIds = removeStrings(task.LabelIds, labelIds)
+
+	return copyTask(task), nil
+}
+
+// GetSubtasks returns the direct subtasks of all given parents in creation order.
+func (rep *TaskRepository) GetSubtasks(ctx context.Context, userId string, parentIds []string) ([]domain.Task, error) {
+	rep.store.mu.RLock()
+	tasks := rep.selectTasks(func(task *domain.Task) bool {
+		return task.UserId == userId && task.DeletedAt == nil && task.ParentId != nil && contains(parentIds, *task.ParentId)
+	})
+	rep.store.mu.RUnlock()
+
+	sort.Slice(tasks, func(i, j int) bool {
+		if !tasks[i].CreatedAt.Equal(tasks[j].CreatedAt) {
+			return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+		}
+		return compareIds(tasks[i].Id, tasks[j].Id) < 0
+	})
+
+	return tasks, nil
+}
+
+func (rep *TaskRepository) UpdatePosition(ctx context.Context, id, userId string, position float64) (domain.Task, error) {
+	return rep.update(id, userId, func(task *domain.Task) {
+		task.Position = position
+	})
+}
+
+// GetAdjacentPosition returns the position of the task listed right after (next) or before the
+// given one in its project, or nil when there is no such task. Tasks at the same position are
+// listed by id, so their neighbours are found too.
+func (rep *TaskRepository) GetAdjacentPosition(
+	ctx context.Context, userId string, task domain.Task, next bool,
+) (*float64, error) {
+	rep.store.mu.RLock()
+	defer rep.store.mu.RUnlock()
+
+	var adjacent *domain.Task
+	for _, other := range rep.store.tasks {
+		if other.UserId != userId || !equalString(other.ProjectId, task.ProjectId) || other.DeletedAt != nil {
+			continue
+		}
+
+		if next && comparePositions(other, &task) > 0 && (adjacent == nil || comparePositions(other, adjacent) < 0) {
+			adjacent = other
+		}
+		if !next && comparePositions(other, &task) < 0 && (adjacent == nil || comparePositions(other, adjacent) > 0) {
+			adjacent = other
+		}
+	}
+
+	if adjacent == nil {
+		return nil, nil
+	}
+	position := adjacent.Position
+	return &position, nil
+}
+
+// RebalancePositions spreads the positions of the project tasks evenly again, keeping their order.
+// Trashed tasks keep their positions.
+func (rep *TaskRepository) RebalancePositions(ctx context.Context, userId string, projectId *string) error {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	var tasks []*domain.Task
+	for _, task := range rep.store.tasks {
+		if task.UserId == userId && equalString(task.ProjectId, projectId) && task.DeletedAt == nil {
+			tasks = append(tasks, task)
+		}
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return comparePositions(tasks[i], tasks[j]) < 0
+	})
+	for i, task := range tasks {
+		task.Position = float64(i+1) * domain.TaskPositionGap
+	}
+
+	return nil
+}
+
+// update changes a task that is not in the trash and returns it.
+func (rep *TaskRepository) update(id, userId string, change func(task *domain.Task)) (domain.Task, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	task, err := rep.findTask(id, userId)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	change(task)
+	task.UpdatedAt = now()
+
+	return copyTask(task), nil
+}
+
+// findTask returns the stored task of the user unless it is in the trash. The caller holds the lock.
+func (rep *TaskRepository) findTask(id, userId string) (*domain.Task, error) {
+	task, ok := rep.store.tasks[id]
+	if !ok || task.UserId != userId || task.DeletedAt != nil {
+		return nil, domain.ErrTaskNotFound
+	}
+	return task, nil
+}
+
+func (rep *TaskRepository) getTask(id, userId string) (domain.Task, error) {
+	task, err := rep.findTask(id, userId)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	return copyTask(task), nil
+}
+
+// selectTasks copies the matching tasks out of the store. The caller holds the lock.
+func (rep *TaskRepository) selectTasks(match func(task *domain.Task) bool) []domain.Task {
+	tasks := make([]domain.Task, 0)
+	for _, task := range rep.store.tasks {
+		if match(task) {
+			tasks = append(tasks, copyTask(task))
+		}
+	}
+	return tasks
+}
+
+func sortByDueAt(tasks []domain.Task) {
+	sort.Slice(tasks, func(i, j int) bool {
+		if !tasks[i].DueAt.Equal(*tasks[j].DueAt) {
+			return tasks[i].DueAt.Before(*tasks[j].DueAt)
+		}
+		return compareIds(tasks[i].Id, tasks[j].Id) < 0
+	})
+}
+
+func copyTask(task *domain.Task) domain.Task {
+	c := *task
+	c.ProjectId = copyString(task.ProjectId)
+	c.ParentId = copyString(task.ParentId)
+	c.LabelIds = append([]string(nil), task.LabelIds...)
+	c.CompletedAt = copyTime(task.CompletedAt)
+	c.DeletedAt = copyTime(task.DeletedAt)
+	c.StartAt = copyTime(task.StartAt)
+	c.DueAt = copyTime(task.DueAt)
+	return c
+}
+
+// removeStrings returns the values without the removed ones, keeping their order.
+func removeStrings(values, removed []string) []string {
+	var kept []string
+	for _, value := range values {
+		if !contains(removed, value) {
+			kept = append(kept, value)
+		}
+	}
+	return kept
+}
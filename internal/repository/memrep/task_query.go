@@ -0,0 +1,194 @@
+package memrep
+
+import (
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// taskQuery matches and orders tasks the way the database repositories do for a filter.
+type taskQuery struct {
+	filter domain.TaskFilter
+	// after is the task the cursor points at, only the tasks ordered after it match.
+	after *domain.Task
+}
+
+// newTaskQuery expects a normalized filter. The filtered ids have to look like the ids the
+// store gives out, the way the database repositories reject the ids they can not parse.
+func newTaskQuery(filter domain.TaskFilter) (*taskQuery, error) {
+	if filter.ProjectId != "" && filter.ProjectId != domain.InboxProjectId {
+		if _, err := strconv.Atoi(filter.ProjectId); err != nil {
+			return nil, domain.ErrInvalidProjectFilter
+		}
+	}
+	for _, label := range filter.Labels {
+		if _, err := strconv.Atoi(label); err != nil {
+			return nil, domain.ErrInvalidLabelFilter
+		}
+	}
+
+	return &taskQuery{filter: filter}, nil
+}
+
+// applyCursor rebuilds the sort key of the task the cursor points at. Tasks without
+// a due date are always listed last, so only a due_at cursor may lack a value.
+func (q *taskQuery) applyCursor(cursor *domain.TaskCursor) error {
+	if _, err := strconv.Atoi(cursor.Id); err != nil {
+		return domain.ErrInvalidCursor
+	}
+
+	after := &domain.Task{Id: cursor.Id}
+
+	if cursor.Value == nil {
+		if q.filter.SortBy != domain.TaskSortDueAt {
+			return domain.ErrInvalidCursor
+		}
+		q.after = after
+		return nil
+	}
+
+	switch q.filter.SortBy {
+	case domain.TaskSortPosition, domain.TaskSortPriority:
+		value, err := cursor.FloatValue()
+		if err != nil {
+			return err
+		}
+		after.Position = value
+		after.Priority = domain.TaskPriority(value)
+	case domain.TaskSortName:
+		after.Name = *cursor.Value
+	default:
+		value, err := cursor.TimeValue()
+		if err != nil {
+			return err
+		}
+		after.CreatedAt = value
+		after.UpdatedAt = value
+		after.DueAt = &value
+	}
+
+	q.after = after
+	return nil
+}
+
+func (q *taskQuery) match(task *domain.Task) bool {
+	f := q.filter
+
+	if len(f.Status) > 0 && !containsStatus(f.Status, task.Status) {
+		return false
+	}
+	if f.DueFrom != nil && (task.DueAt == nil || task.DueAt.Before(*f.DueFrom)) {
+		return false
+	}
+	if f.DueTo != nil && (task.DueAt == nil || !task.DueAt.Before(*f.DueTo)) {
+		return false
+	}
+	if f.CreatedFrom != nil && task.CreatedAt.Before(*f.CreatedFrom) {
+		return false
+	}
+	if f.CreatedTo != nil && !task.CreatedAt.Before(*f.CreatedTo) {
+		return false
+	}
+	if f.Name != "" && !strings.Contains(strings.ToLower(task.Name), strings.ToLower(f.Name)) {
+		return false
+	}
+	if f.ProjectId == domain.InboxProjectId && task.ProjectId != nil {
+		return false
+	}
+	if f.ProjectId != "" && f.ProjectId != domain.InboxProjectId && (task.ProjectId == nil || *task.ProjectId != f.ProjectId) {
+		return false
+	}
+	if len(f.Labels) > 0 && !q.matchLabels(task) {
+		return false
+	}
+
+	return q.after == nil || q.compare(task, q.after) > 0
+}
+
+// matchLabels keeps tasks linked to any of the filtered labels or, for LabelMatchAll,
+// to every one of them.
+func (q *taskQuery) matchLabels(task *domain.Task) bool {
+	for _, label := range q.filter.Labels {
+		linked := contains(task.LabelIds, label)
+		if linked && q.filter.LabelMatch != domain.LabelMatchAll {
+			return true
+		}
+		if !linked && q.filter.LabelMatch == domain.LabelMatchAll {
+			return false
+		}
+	}
+	return q.filter.LabelMatch == domain.LabelMatchAll
+}
+
+// compare orders by the sort field with tasks without a due date last in both directions,
+// then by id in the same direction.
+func (q *taskQuery) compare(a, b *domain.Task) int {
+	if q.filter.SortBy == domain.TaskSortDueAt && (a.DueAt == nil) != (b.DueAt == nil) {
+		if a.DueAt == nil {
+			return 1
+		}
+		return -1
+	}
+
+	result := compareSortValues(q.filter.SortBy, a, b)
+	if result == 0 {
+		result = compareIds(a.Id, b.Id)
+	}
+
+	if q.filter.SortDir == domain.SortDesc {
+		return -result
+	}
+	return result
+}
+
+func compareSortValues(field domain.TaskSortField, a, b *domain.Task) int {
+	switch field {
+	case domain.TaskSortPosition:
+		return compareFloats(a.Position, b.Position)
+	case domain.TaskSortPriority:
+		return compareFloats(float64(a.Priority), float64(b.Priority))
+	case domain.TaskSortName:
+		return strings.Compare(a.Name, b.Name)
+	case domain.TaskSortUpdatedAt:
+		return compareTimes(a.UpdatedAt, b.UpdatedAt)
+	case domain.TaskSortDueAt:
+		if a.DueAt == nil || b.DueAt == nil {
+			return 0
+		}
+		return compareTimes(*a.DueAt, *b.DueAt)
+	default:
+		return compareTimes(a.CreatedAt, b.CreatedAt)
+	}
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareTimes(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	default:
+		return 0
+	}
+}
+
+func containsStatus(statuses []domain.TaskStatus, status domain.TaskStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
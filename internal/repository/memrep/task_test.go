@@ -0,0 +1,65 @@
+package memrep
+
+import (
+	"context"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"github.com/magiconair/properties/assert"
+	"testing"
+	"time"
+)
+
+func TestTaskRepository_GetAll(t *testing.T) {
+	ctx := context.Background()
+	rep := NewTaskRepository(NewStore())
+	due := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	first, _ := rep.Create(ctx, "1", domain.CreateTaskInput{Name: "First"})
+	second, _ := rep.Create(ctx, "1", domain.CreateTaskInput{Name: "Second", DueAt: &due})
+	third, _ := rep.Create(ctx, "1", domain.CreateTaskInput{Name: "Third", DueAt: &due})
+	_, _ = rep.Create(ctx, "2", domain.CreateTaskInput{Name: "Other user"})
+
+	assert.Equal(t, third.Position, 3*domain.TaskPositionGap)
+
+	filter := domain.TaskFilter{SortBy: domain.TaskSortDueAt, Limit: 2}
+	page, err := rep.GetAll(ctx, "1", filter)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, []string{page.Items[0].Id, page.Items[1].Id}, []string{second.Id, third.Id})
+
+	filter.Cursor = page.NextCursor
+	page, _ = rep.GetAll(ctx, "1", filter)
+	assert.Equal(t, len(page.Items), 1)
+	assert.Equal(t, page.Items[0].Id, first.Id)
+	assert.Equal(t, page.NextCursor, "")
+
+	page, _ = rep.GetAll(ctx, "1", domain.TaskFilter{Name: "th", SortDir: domain.SortDesc})
+	assert.Equal(t, len(page.Items), 1)
+	assert.Equal(t, page.Items[0].Id, third.Id)
+}
+
+func TestTaskRepository_Trash(t *testing.T) {
+	ctx := context.Background()
+	rep := NewTaskRepository(NewStore())
+
+	parent, _ := rep.Create(ctx, "1", domain.CreateTaskInput{Name: "Parent"})
+	child, _ := rep.Create(ctx, "1", domain.CreateTaskInput{Name: "Child", ParentId: &parent.Id})
+
+	assert.Equal(t, rep.Delete(ctx, parent.Id, "1"), nil)
+	trash, _ := rep.GetTrash(ctx, "1")
+	assert.Equal(t, len(trash), 2)
+	_, err := rep.Get(ctx, child.Id, "1")
+	assert.Equal(t, err, domain.ErrTaskNotFound)
+
+	restored, err := rep.Restore(ctx, child.Id, "1")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, restored.ParentId, (*string)(nil))
+
+	_, err = rep.Restore(ctx, child.Id, "1")
+	assert.Equal(t, err, domain.ErrTaskNotFound)
+
+	purged, _ := rep.PurgeTrash(ctx, time.Now().Add(time.Minute))
+	assert.Equal(t, purged, int64(1))
+
+	counts, _ := rep.Count(ctx)
+	assert.Equal(t, counts.Total, int64(1))
+	assert.Equal(t, counts.InTrash, int64(0))
+}
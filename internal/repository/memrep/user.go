@@ -0,0 +1,281 @@
+package memrep
+
+import (
+	"context"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"sort"
+)
+
+// userRecord keeps the hashed recovery codes, which the domain type does not carry.
+type userRecord struct {
+	domain.User
+	recoveryCodes []string
+	totpLastStep  int64
+}
+
+type UserRepository struct {
+	store *Store
+}
+
+func NewUserRepository(store *Store) *UserRepository {
+	return &UserRepository{store: store}
+}
+
+func (rep *UserRepository) Create(ctx context.Context, in domain.CreateUserInput) (domain.User, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	if err := rep.checkUnique("", &in.Login, &in.Email); err != nil {
+		return domain.User{}, err
+	}
+
+	user := &userRecord{User: domain.User{
+		Id:        rep.store.nextId(),
+		Login:     in.Login,
+		Password:  in.Password,
+		Email:     in.Email,
+		Roles:     []domain.Role{},
+		CreatedAt: now(),
+	}}
+	rep.store.users[user.Id] = user
+
+	return user.toDomain(), nil
+}
+
+func (rep *UserRepository) GetByLogin(ctx context.Context, login string) (domain.User, error) {
+	rep.store.mu.RLock()
+	defer rep.store.mu.RUnlock()
+
+	for _, user := range rep.store.users {
+		if user.Login == login {
+			return user.toDomain(), nil
+		}
+	}
+
+	return domain.User{}, domain.ErrUserNotFound
+}
+
+func (rep *UserRepository) GetByEmail(ctx context.Context, email string) (domain.User, error) {
+	rep.store.mu.RLock()
+	defer rep.store.mu.RUnlock()
+
+	for _, user := range rep.store.users {
+		if user.Email != "" && user.Email == email {
+			return user.toDomain(), nil
+		}
+	}
+
+	return domain.User{}, domain.ErrUserNotFound
+}
+
+func (rep *UserRepository) Get(ctx context.Context, id string) (domain.User, error) {
+	rep.store.mu.RLock()
+	defer rep.store.mu.RUnlock()
+
+	user, ok := rep.store.users[id]
+	if !ok {
+		return domain.User{}, domain.ErrUserNotFound
+	}
+
+	return user.toDomain(), nil
+}
+
+// GetAll pages through the users in the order they signed up.
+func (rep *UserRepository) GetAll(ctx context.Context, filter domain.UserFilter) ([]domain.User, error) {
+	filter = filter.Normalize()
+
+	rep.store.mu.RLock()
+	defer rep.store.mu.RUnlock()
+
+	ids := make([]string, 0, len(rep.store.users))
+	for id := range rep.store.users {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return compareIds(ids[i], ids[j]) < 0
+	})
+
+	users := make([]domain.User, 0, filter.Limit)
+	for i := filter.Offset; i < len(ids) && len(users) < filter.Limit; i++ {
+		users = append(users, rep.store.users[ids[i]].toDomain())
+	}
+
+	return users, nil
+}
+
+// Update sets the given fields only. Setting an email marks it as not verified.
+func (rep *UserRepository) Update(ctx context.Context, id string, in domain.UpdateUserInput) (domain.User, error) {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	user, ok := rep.store.users[id]
+	if !ok {
+		return domain.User{}, domain.ErrUserNotFound
+	}
+
+	if err := rep.checkUnique(id, in.Login, in.Email); err != nil {
+		return domain.User{}, err
+	}
+
+	if in.Login != nil {
+		user.Login = *in.Login
+	}
+	if in.Email != nil {
+		user.Email = *in.Email
+		user.EmailVerified = false
+	}
+
+	return user.toDomain(), nil
+}
+
+// Delete removes the user together with the tasks, projects, labels, sessions and tokens of
+// the user.
+func (rep *UserRepository) Delete(ctx context.Context, id string) error {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	if _, ok := rep.store.users[id]; !ok {
+		return domain.ErrUserNotFound
+	}
+	delete(rep.store.users, id)
+
+	for taskId, task := range rep.store.tasks {
+		if task.UserId == id {
+			delete(rep.store.tasks, taskId)
+		}
+	}
+	for projectId, project := range rep.store.projects {
+		if project.UserId == id {
+			delete(rep.store.projects, projectId)
+		}
+	}
+	for labelId, label := range rep.store.labels {
+		if label.UserId == id {
+			delete(rep.store.labels, labelId)
+		}
+	}
+	for sessionId, session := range rep.store.sessions {
+		if session.UserId == id {
+			delete(rep.store.sessions, sessionId)
+		}
+	}
+	for tokenId, token := range rep.store.accessTokens {
+		if token.UserId == id {
+			delete(rep.store.accessTokens, tokenId)
+		}
+	}
+
+	return nil
+}
+
+func (rep *UserRepository) UpdatePassword(ctx context.Context, id, password string) error {
+	rep.update(id, func(user *userRecord) {
+		user.Password = password
+	})
+	return nil
+}
+
+func (rep *UserRepository) SetEmailVerified(ctx context.Context, id string) error {
+	rep.update(id, func(user *userRecord) {
+		user.EmailVerified = true
+	})
+	return nil
+}
+
+func (rep *UserRepository) SetDisabled(ctx context.Context, id string, disabled bool) error {
+	found := rep.update(id, func(user *userRecord) {
+		user.Disabled = disabled
+	})
+	if !found {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (rep *UserRepository) SetRoles(ctx context.Context, id string, roles []domain.Role) error {
+	found := rep.update(id, func(user *userRecord) {
+		user.Roles = append(make([]domain.Role, 0, len(roles)), roles...)
+	})
+	if !found {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (rep *UserRepository) UpdateTotp(ctx context.Context, id string, in domain.UpdateTotpInput) error {
+	rep.update(id, func(user *userRecord) {
+		user.TotpSecret = in.Secret
+		user.TotpEnabled = in.Enabled
+		user.recoveryCodes = append([]string(nil), in.RecoveryCodes...)
+	})
+	return nil
+}
+
+// UseRecoveryCode removes the recovery code under the same lock that finds it, so a code can
+// not be used twice by concurrent requests.
+func (rep *UserRepository) UseRecoveryCode(ctx context.Context, id, codeHash string) (bool, error) {
+	used := false
+	rep.update(id, func(user *userRecord) {
+		codes := make([]string, 0, len(user.recoveryCodes))
+		for _, code := range user.recoveryCodes {
+			if code == codeHash {
+				used = true
+				continue
+			}
+			codes = append(codes, code)
+		}
+		user.recoveryCodes = codes
+	})
+
+	return used, nil
+}
+
+// UseTotpStep records the time step of an accepted code under the same lock that compares it,
+// so a code can not be used twice by concurrent requests.
+func (rep *UserRepository) UseTotpStep(ctx context.Context, id string, step int64) (bool, error) {
+	used := false
+	rep.update(id, func(user *userRecord) {
+		if user.totpLastStep < step {
+			user.totpLastStep = step
+			used = true
+		}
+	})
+
+	return used, nil
+}
+
+// update changes the user under the write lock and tells whether the user exists.
+func (rep *UserRepository) update(id string, change func(user *userRecord)) bool {
+	rep.store.mu.Lock()
+	defer rep.store.mu.Unlock()
+
+	user, ok := rep.store.users[id]
+	if !ok {
+		return false
+	}
+
+	change(user)
+	return true
+}
+
+// checkUnique stands in for the unique indexes on login and email. The caller holds the lock.
+func (rep *UserRepository) checkUnique(id string, login, email *string) error {
+	for _, user := range rep.store.users {
+		if user.Id == id {
+			continue
+		}
+		if login != nil && user.Login == *login {
+			return domain.ErrLoginTaken
+		}
+		if email != nil && *email != "" && user.Email == *email {
+			return domain.ErrEmailTaken
+		}
+	}
+	return nil
+}
+
+func (u *userRecord) toDomain() domain.User {
+	user := u.User
+	user.Roles = append(make([]domain.Role, 0, len(u.Roles)), u.Roles...)
+	return user
+}
@@ -0,0 +1,51 @@
+package memrep
+
+import (
+	"context"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"github.com/magiconair/properties/assert"
+	"testing"
+)
+
+func TestUserRepository(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	rep := NewUserRepository(store)
+
+	user, err := rep.Create(ctx, domain.CreateUserInput{Login: "login", Email: "login@example.com", Password: "hash"})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, user.Roles, []domain.Role{})
+
+	_, err = rep.Create(ctx, domain.CreateUserInput{Login: "login", Email: "other@example.com"})
+	assert.Equal(t, err, domain.ErrLoginTaken)
+	_, err = rep.Create(ctx, domain.CreateUserInput{Login: "other", Email: "login@example.com"})
+	assert.Equal(t, err, domain.ErrEmailTaken)
+
+	found, _ := rep.GetByLogin(ctx, "login")
+	assert.Equal(t, found.Id, user.Id)
+	_, err = rep.GetByLogin(ctx, "missing")
+	assert.Equal(t, err, domain.ErrUserNotFound)
+	_, err = rep.GetByEmail(ctx, "missing@example.com")
+	assert.Equal(t, err, domain.ErrUserNotFound)
+
+	assert.Equal(t, rep.SetEmailVerified(ctx, user.Id), nil)
+	email := "new@example.com"
+	user, _ = rep.Update(ctx, user.Id, domain.UpdateUserInput{Email: &email})
+	assert.Equal(t, user.Email, email)
+	assert.Equal(t, user.EmailVerified, false)
+
+	assert.Equal(t, rep.UpdateTotp(ctx, user.Id, domain.UpdateTotpInput{RecoveryCodes: []string{"a", "b"}}), nil)
+	used, _ := rep.UseRecoveryCode(ctx, user.Id, "a")
+	assert.Equal(t, used, true)
+	used, _ = rep.UseRecoveryCode(ctx, user.Id, "a")
+	assert.Equal(t, used, false)
+
+	assert.Equal(t, rep.SetRoles(ctx, "missing", []domain.Role{domain.RoleAdmin}), domain.ErrUserNotFound)
+
+	tasks := NewTaskRepository(store)
+	task, _ := tasks.Create(ctx, user.Id, domain.CreateTaskInput{Name: "task"})
+	assert.Equal(t, rep.Delete(ctx, user.Id), nil)
+	_, err = tasks.Get(ctx, task.Id, user.Id)
+	assert.Equal(t, err, domain.ErrTaskNotFound)
+	assert.Equal(t, rep.Delete(ctx, user.Id), domain.ErrUserNotFound)
+}
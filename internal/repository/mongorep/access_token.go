@@ -0,0 +1,113 @@
+package mongorep
+
+import (
+	"context"
+	"errors"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type AccessTokenRepository struct {
+	db *mongo.Database
+}
+
+func NewMongoAccessTokenRepository(db *mongo.Database) *AccessTokenRepository {
+	return &AccessTokenRepository{
+		db: db,
+	}
+}
+
+func (rep *AccessTokenRepository) GetAll(ctx context.Context, userId string) ([]domain.AccessToken, error) {
+	userObjId, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+	cursor, err := rep.db.Collection(accessTokensCollection).Find(ctx, bson.M{"user_id": userObjId}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]domain.AccessToken, 0)
+	if err := cursor.All(ctx, &tokens); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+func (rep *AccessTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (domain.AccessToken, error) {
+	var token domain.AccessToken
+	err := rep.db.Collection(accessTokensCollection).
+		FindOne(ctx, bson.M{"token_hash": tokenHash}).
+		Decode(&token)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.AccessToken{}, domain.ErrAccessTokenNotFound
+	}
+
+	return token, err
+}
+
+func (rep *AccessTokenRepository) Create(ctx context.Context, userId, tokenHash string, in domain.CreateAccessTokenInput) (domain.AccessToken, error) {
+	userObjId, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return domain.AccessToken{}, err
+	}
+
+	var expiresAt *time.Time
+	if in.ExpiresAt != nil {
+		utc := in.ExpiresAt.UTC()
+		expiresAt = &utc
+	}
+
+	result, err := rep.db.Collection(accessTokensCollection).
+		InsertOne(ctx, bson.M{
+			"user_id":      userObjId,
+			"name":         in.Name,
+			"scopes":       in.Scopes,
+			"token_hash":   tokenHash,
+			"expires_at":   expiresAt,
+			"last_used_at": nil,
+			"created_at":   time.Now().UTC(),
+		})
+	if err != nil {
+		return domain.AccessToken{}, err
+	}
+
+	objId := result.InsertedID.(primitive.ObjectID)
+
+	var token domain.AccessToken
+	err = rep.db.Collection(accessTokensCollection).FindOne(ctx, bson.M{"_id": objId}).Decode(&token)
+	return token, err
+}
+
+func (rep *AccessTokenRepository) Delete(ctx context.Context, id, userId string) error {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrAccessTokenNotFound
+	}
+
+	userObjId, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return err
+	}
+
+	_, err = rep.db.Collection(accessTokensCollection).DeleteOne(ctx, bson.M{"_id": objId, "user_id": userObjId})
+	return err
+}
+
+func (rep *AccessTokenRepository) UpdateLastUsed(ctx context.Context, id string, at time.Time) error {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	_, err = rep.db.Collection(accessTokensCollection).
+		UpdateOne(ctx, bson.M{"_id": objId}, bson.M{"$set": bson.M{"last_used_at": at.UTC()}})
+	return err
+}
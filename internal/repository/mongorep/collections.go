@@ -1,6 +1,13 @@
 package mongorep
 
 var (
-	tasksCollection = "tasks"
-	usersCollection = "users"
+	tasksCollection           = "tasks"
+	usersCollection           = "users"
+	projectsCollection        = "projects"
+	labelsCollection          = "labels"
+	sessionsCollection        = "sessions"
+	revokedTokensCollection   = "revoked_tokens"
+	userRevocationsCollection = "user_token_revocations"
+	accessTokensCollection    = "access_tokens"
+	loginAttemptsCollection   = "login_attempts"
 )
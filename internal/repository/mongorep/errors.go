@@ -0,0 +1,24 @@
+package mongorep
+
+import (
+	"errors"
+	"go.mongodb.org/mongo-driver/mongo"
+	"strings"
+)
+
+// notFound replaces a missing document with the domain error of what was looked for.
+func notFound(err, notFoundErr error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return notFoundErr
+	}
+	return err
+}
+
+// isDuplicateKey reports whether the write broke the named unique index, or any unique index
+// when the name is empty. The driver only tells the index in the message.
+func isDuplicateKey(err error, index string) bool {
+	if !mongo.IsDuplicateKeyError(err) {
+		return false
+	}
+	return index == "" || strings.Contains(err.Error(), "index: "+index+" ")
+}
@@ -0,0 +1,146 @@
+package mongorep
+
+import (
+	"context"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type LabelRepository struct {
+	db *mongo.Database
+}
+
+func NewMongoLabelRepository(db *mongo.Database) *LabelRepository {
+	return &LabelRepository{
+		db: db,
+	}
+}
+
+func (rep *LabelRepository) Get(ctx context.Context, id, userId string) (domain.Label, error) {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.Label{}, domain.ErrLabelNotFound
+	}
+
+	userObjId, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return domain.Label{}, err
+	}
+
+	var label domain.Label
+	err = rep.db.Collection(labelsCollection).
+		FindOne(ctx, bson.M{"_id": objId, "user_id": userObjId}).
+		Decode(&label)
+
+	return label, notFound(err, domain.ErrLabelNotFound)
+}
+
+func (rep *LabelRepository) GetAll(ctx context.Context, userId string) ([]domain.Label, error) {
+	userObjId, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := rep.db.Collection(labelsCollection).Find(ctx, bson.M{"user_id": userObjId}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := make([]domain.Label, 0)
+	if err := cursor.All(ctx, &labels); err != nil {
+		return nil, err
+	}
+
+	return labels, nil
+}
+
+func (rep *LabelRepository) Create(ctx context.Context, userId string, in domain.CreateLabelInput) (domain.Label, error) {
+	userObjId, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return domain.Label{}, err
+	}
+
+	result, err := rep.db.Collection(labelsCollection).
+		InsertOne(ctx, bson.M{
+			"name":       in.Name,
+			"color":      in.Color,
+			"created_at": time.Now().UTC(),
+			"user_id":    userObjId,
+		})
+	if err != nil {
+		return domain.Label{}, labelConflict(err)
+	}
+
+	objId := result.InsertedID.(primitive.ObjectID)
+
+	var label domain.Label
+	err = rep.db.Collection(labelsCollection).
+		FindOne(ctx, bson.M{"_id": objId, "user_id": userObjId}).
+		Decode(&label)
+
+	return label, notFound(err, domain.ErrLabelNotFound)
+}
+
+func (rep *LabelRepository) Update(ctx context.Context, id, userId string, in domain.UpdateLabelInput) (domain.Label, error) {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.Label{}, domain.ErrLabelNotFound
+	}
+
+	userObjId, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return domain.Label{}, err
+	}
+
+	update := bson.M{"$set": bson.M{"name": in.Name, "color": in.Color}}
+	_, err = rep.db.Collection(labelsCollection).UpdateOne(ctx, bson.M{"_id": objId, "user_id": userObjId}, update)
+	if err != nil {
+		return domain.Label{}, labelConflict(err)
+	}
+
+	var label domain.Label
+	err = rep.db.Collection(labelsCollection).
+		FindOne(ctx, bson.M{"_id": objId, "user_id": userObjId}).
+		Decode(&label)
+
+	return label, notFound(err, domain.ErrLabelNotFound)
+}
+
+// Delete detaches the label from the user's tasks before removing it, so tasks
+// never reference a label that no longer exists.
+func (rep *LabelRepository) Delete(ctx context.Context, id, userId string) error {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrLabelNotFound
+	}
+
+	userObjId, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return err
+	}
+
+	_, err = rep.db.Collection(tasksCollection).UpdateMany(
+		ctx,
+		bson.M{"user_id": userObjId, "label_ids": objId},
+		bson.M{"$pull": bson.M{"label_ids": objId}},
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = rep.db.Collection(labelsCollection).DeleteOne(ctx, bson.M{"_id": objId, "user_id": userObjId})
+	return err
+}
+
+// labelConflict reports a name the user already gave to another label.
+func labelConflict(err error) error {
+	if isDuplicateKey(err, "") {
+		return domain.ErrLabelTaken
+	}
+	return err
+}
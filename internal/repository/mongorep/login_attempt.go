@@ -0,0 +1,78 @@
+package mongorep
+
+import (
+	"context"
+	"errors"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type LoginAttemptRepository struct {
+	db *mongo.Database
+}
+
+func NewMongoLoginAttemptRepository(db *mongo.Database) *LoginAttemptRepository {
+	return &LoginAttemptRepository{
+		db: db,
+	}
+}
+
+// Get ignores expired documents the TTL monitor has not removed yet.
+func (rep *LoginAttemptRepository) Get(ctx context.Context, key string) (domain.LoginAttempts, error) {
+	var attempts domain.LoginAttempts
+
+	err := rep.db.Collection(loginAttemptsCollection).
+		FindOne(ctx, bson.M{"_id": key, "expires_at": bson.M{"$gte": time.Now().UTC()}}).
+		Decode(&attempts)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.LoginAttempts{Key: key}, nil
+	}
+
+	return attempts, err
+}
+
+// AddFailure counts a failure at the given moment. The count starts over when the previous
+// failure is older than the window. The update is a pipeline, so the count is decided by the
+// server and concurrent failures are all counted.
+func (rep *LoginAttemptRepository) AddFailure(ctx context.Context, key string, at time.Time, window time.Duration) (domain.LoginAttempts, error) {
+	var attempts domain.LoginAttempts
+
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"failures": bson.M{"$cond": bson.A{
+			bson.M{"$lt": bson.A{"$last_failure_at", at.Add(-window).UTC()}},
+			1,
+			bson.M{"$add": bson.A{"$failures", 1}},
+		}},
+		"last_failure_at": at.UTC(),
+		"expires_at":      bson.M{"$max": bson.A{"$expires_at", at.Add(window).UTC()}},
+	}}}}
+
+	err := rep.db.Collection(loginAttemptsCollection).FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": key},
+		update,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&attempts)
+
+	return attempts, err
+}
+
+func (rep *LoginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	_, err := rep.db.Collection(loginAttemptsCollection).UpdateOne(
+		ctx,
+		bson.M{"_id": key},
+		bson.M{
+			"$set": bson.M{"locked_until": until.UTC()},
+			"$max": bson.M{"expires_at": until.UTC()},
+		},
+	)
+	return err
+}
+
+func (rep *LoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	_, err := rep.db.Collection(loginAttemptsCollection).DeleteOne(ctx, bson.M{"_id": key})
+	return err
+}
@@ -0,0 +1,117 @@
+package mongorep
+
+import (
+	"context"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Migrate brings the documents written by older versions to the shape the repositories
+// query. Documents already migrated are not matched again, so it is safe to call on every start.
+func Migrate(ctx context.Context, db *mongo.Database) error {
+	// Timestamps used to be stored as RFC3339 strings, which neither sort nor compare
+	// with the dates the queries use.
+	timestamps := map[string][]string{
+		tasksCollection:        {"created_at", "updated_at"},
+		projectsCollection:     {"created_at", "updated_at"},
+		labelsCollection:       {"created_at"},
+		usersCollection:        {"created_at"},
+		sessionsCollection:     {"created_at"},
+		accessTokensCollection: {"created_at"},
+	}
+	for collection, fields := range timestamps {
+		for _, field := range fields {
+			_, err := db.Collection(collection).UpdateMany(ctx,
+				bson.M{field: bson.M{"$type": "string"}},
+				bson.A{bson.M{"$set": bson.M{field: bson.M{"$toDate": "$" + field}}}},
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Tasks are listed by due_at with the ones without a due date last, which the stored
+	// no_due_at flag keeps indexable.
+	_, err := db.Collection(tasksCollection).UpdateMany(ctx,
+		bson.M{noDueAtField: bson.M{"$exists": false}},
+		bson.A{bson.M{"$set": bson.M{noDueAtField: bson.M{
+			"$cond": bson.A{bson.M{"$eq": bson.A{bson.M{"$ifNull": bson.A{"$due_at", nil}}, nil}}, 1, 0},
+		}}}},
+	)
+	if err != nil {
+		return err
+	}
+
+	defaults := map[string]interface{}{
+		"status":   domain.TaskStatusTodo,
+		"priority": domain.DefaultTaskPriority,
+	}
+	for field, value := range defaults {
+		_, err := db.Collection(tasksCollection).UpdateMany(ctx,
+			bson.M{field: bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{field: value}},
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return backfillPositions(ctx, db)
+}
+
+// backfillPositions appends the tasks without a position to the list of their project in the
+// order they were created, the way the Postgres migration numbers them.
+func backfillPositions(ctx context.Context, db *mongo.Database) error {
+	cursor, err := db.Collection(tasksCollection).Find(ctx,
+		bson.M{"position": bson.M{"$exists": false}},
+		options.Find().
+			SetSort(bson.D{
+				{Key: "user_id", Value: 1}, {Key: "project_id", Value: 1}, {Key: "created_at", Value: 1}, {Key: "_id", Value: 1},
+			}).
+			SetProjection(bson.M{"user_id": 1, "project_id": 1}),
+	)
+	if err != nil {
+		return err
+	}
+
+	var tasks []struct {
+		Id        primitive.ObjectID  `bson:"_id"`
+		UserId    primitive.ObjectID  `bson:"user_id"`
+		ProjectId *primitive.ObjectID `bson:"project_id"`
+	}
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return err
+	}
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(tasks))
+	var position float64
+	for i, task := range tasks {
+		if i == 0 || task.UserId != tasks[i-1].UserId || !equalObjectIds(task.ProjectId, tasks[i-1].ProjectId) {
+			if position, err = lastPosition(ctx, db, task.UserId, task.ProjectId); err != nil {
+				return err
+			}
+		}
+		position += domain.TaskPositionGap
+
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": task.Id}).
+			SetUpdate(bson.M{"$set": bson.M{"position": position}}))
+	}
+
+	_, err = db.Collection(tasksCollection).BulkWrite(ctx, models)
+	return err
+}
+
+func equalObjectIds(a, b *primitive.ObjectID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,185 @@
+package mongorep
+
+import (
+	"context"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type ProjectRepository struct {
+	db *mongo.Database
+}
+
+func NewMongoProjectRepository(db *mongo.Database) *ProjectRepository {
+	return &ProjectRepository{
+		db: db,
+	}
+}
+
+func (rep *ProjectRepository) Get(ctx context.Context, id, userId string) (domain.Project, error) {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.Project{}, domain.ErrProjectNotFound
+	}
+
+	userObjId, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return domain.Project{}, err
+	}
+
+	var project domain.Project
+	err = rep.db.Collection(projectsCollection).
+		FindOne(ctx, bson.M{"_id": objId, "user_id": userObjId}).
+		Decode(&project)
+
+	return project, notFound(err, domain.ErrProjectNotFound)
+}
+
+func (rep *ProjectRepository) GetAll(ctx context.Context, userId string) ([]domain.Project, error) {
+	userObjId, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := rep.db.Collection(projectsCollection).Find(ctx, bson.M{"user_id": userObjId}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	projects := make([]domain.Project, 0)
+	if err := cursor.All(ctx, &projects); err != nil {
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+func (rep *ProjectRepository) Create(ctx context.Context, userId string, in domain.CreateProjectInput) (domain.Project, error) {
+	userObjId, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return domain.Project{}, err
+	}
+
+	result, err := rep.db.Collection(projectsCollection).
+		InsertOne(ctx, bson.M{
+			"name":       in.Name,
+			"created_at": time.Now().UTC(),
+			"updated_at": time.Now().UTC(),
+			"user_id":    userObjId,
+		})
+	if err != nil {
+		return domain.Project{}, err
+	}
+
+	objId := result.InsertedID.(primitive.ObjectID)
+
+	var project domain.Project
+	err = rep.db.Collection(projectsCollection).
+		FindOne(ctx, bson.M{"_id": objId, "user_id": userObjId}).
+		Decode(&project)
+
+	return project, notFound(err, domain.ErrProjectNotFound)
+}
+
+func (rep *ProjectRepository) Update(ctx context.Context, id, userId string, in domain.UpdateProjectInput) (domain.Project, error) {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.Project{}, domain.ErrProjectNotFound
+	}
+
+	userObjId, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return domain.Project{}, err
+	}
+
+	update := bson.M{"$set": bson.M{"name": in.Name, "updated_at": time.Now().UTC()}}
+	_, err = rep.db.Collection(projectsCollection).UpdateOne(ctx, bson.M{"_id": objId, "user_id": userObjId}, update)
+	if err != nil {
+		return domain.Project{}, err
+	}
+
+	var project domain.Project
+	err = rep.db.Collection(projectsCollection).
+		FindOne(ctx, bson.M{"_id": objId, "user_id": userObjId}).
+		Decode(&project)
+
+	return project, notFound(err, domain.ErrProjectNotFound)
+}
+
+// Delete moves the project tasks with their subtasks to the trash or to the inbox first,
+// so an interrupted delete never leaves tasks pointing to a project that no longer exists.
+func (rep *ProjectRepository) Delete(ctx context.Context, id, userId string, mode domain.ProjectDeleteMode) error {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrProjectNotFound
+	}
+
+	userObjId, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": objId, "user_id": userObjId}
+	count, err := rep.db.Collection(projectsCollection).CountDocuments(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return domain.ErrProjectNotFound
+	}
+
+	if mode == domain.ProjectDeleteCascade {
+		if _, err := trashTasks(ctx, rep.db, userObjId, bson.M{"project_id": objId}); err != nil {
+			return err
+		}
+	}
+
+	if err := rep.moveTasksToInbox(ctx, userObjId, objId); err != nil {
+		return err
+	}
+
+	_, err = rep.db.Collection(projectsCollection).DeleteOne(ctx, filter)
+	return err
+}
+
+// moveTasksToInbox leaves the project tasks, trashed ones included, in the inbox, as the foreign
+// key does in Postgres. They keep their order after the tasks already in the inbox.
+func (rep *ProjectRepository) moveTasksToInbox(ctx context.Context, userObjId, projectObjId primitive.ObjectID) error {
+	cursor, err := rep.db.Collection(tasksCollection).Find(ctx,
+		bson.M{"project_id": projectObjId, "user_id": userObjId},
+		options.Find().SetSort(bson.D{{Key: "position", Value: 1}, {Key: "_id", Value: 1}}).SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return err
+	}
+
+	var tasks []struct {
+		Id primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return err
+	}
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	position, err := lastPosition(ctx, rep.db, userObjId, nil)
+	if err != nil {
+		return err
+	}
+
+	models := make([]mongo.WriteModel, 0, len(tasks))
+	for i, task := range tasks {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": task.Id}).
+			SetUpdate(bson.M{"$set": bson.M{"project_id": nil, "position": position + float64(i+1)*domain.TaskPositionGap}}))
+	}
+
+	_, err = rep.db.Collection(tasksCollection).BulkWrite(ctx, models)
+	return err
+}
@@ -0,0 +1,66 @@
+package mongorep
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type RevocationRepository struct {
+	db *mongo.Database
+}
+
+func NewMongoRevocationRepository(db *mongo.Database) *RevocationRepository {
+	return &RevocationRepository{
+		db: db,
+	}
+}
+
+// Revoke stores the token id until the token expires, the TTL index removes it afterwards.
+func (rep *RevocationRepository) Revoke(ctx context.Context, tokenId string, expiresAt time.Time) error {
+	_, err := rep.db.Collection(revokedTokensCollection).UpdateOne(
+		ctx,
+		bson.M{"_id": tokenId},
+		bson.M{"$set": bson.M{"expires_at": expiresAt.UTC()}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+func (rep *RevocationRepository) RevokeUser(ctx context.Context, userId string, before time.Time) error {
+	userObjId, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return err
+	}
+
+	_, err = rep.db.Collection(userRevocationsCollection).UpdateOne(
+		ctx,
+		bson.M{"_id": userObjId},
+		bson.M{"$max": bson.M{"revoked_before": before.UTC()}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+func (rep *RevocationRepository) IsRevoked(ctx context.Context, tokenId, userId string, issuedAt time.Time) (bool, error) {
+	userObjId, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return false, err
+	}
+
+	count, err := rep.db.Collection(revokedTokensCollection).
+		CountDocuments(ctx, bson.M{"_id": tokenId}, options.Count().SetLimit(1))
+	if err != nil || count > 0 {
+		return count > 0, err
+	}
+
+	count, err = rep.db.Collection(userRevocationsCollection).CountDocuments(
+		ctx,
+		bson.M{"_id": userObjId, "revoked_before": bson.M{"$gt": issuedAt.UTC()}},
+		options.Count().SetLimit(1),
+	)
+	return count > 0, err
+}
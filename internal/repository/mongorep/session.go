@@ -0,0 +1,100 @@
+package mongorep
+
+import (
+	"context"
+	"errors"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"time"
+)
+
+type SessionRepository struct {
+	db *mongo.Database
+}
+
+func NewMongoSessionRepository(db *mongo.Database) *SessionRepository {
+	return &SessionRepository{
+		db: db,
+	}
+}
+
+func (rep *SessionRepository) Create(ctx context.Context, in domain.CreateSessionInput) (domain.Session, error) {
+	userObjId, err := primitive.ObjectIDFromHex(in.UserId)
+	if err != nil {
+		return domain.Session{}, err
+	}
+
+	result, err := rep.db.Collection(sessionsCollection).
+		InsertOne(ctx, bson.M{
+			"user_id":    userObjId,
+			"family_id":  in.FamilyId,
+			"token_hash": in.TokenHash,
+			"expires_at": in.ExpiresAt.UTC(),
+			"created_at": time.Now().UTC(),
+		})
+	if err != nil {
+		return domain.Session{}, err
+	}
+
+	objId := result.InsertedID.(primitive.ObjectID)
+
+	var session domain.Session
+	err = rep.db.Collection(sessionsCollection).FindOne(ctx, bson.M{"_id": objId}).Decode(&session)
+	return session, err
+}
+
+func (rep *SessionRepository) GetByTokenHash(ctx context.Context, tokenHash string) (domain.Session, error) {
+	var session domain.Session
+	err := rep.db.Collection(sessionsCollection).
+		FindOne(ctx, bson.M{"token_hash": tokenHash}).
+		Decode(&session)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.Session{}, domain.ErrSessionNotFound
+	}
+
+	return session, err
+}
+
+// Revoke marks the session as used and tells whether it was still active before the call.
+func (rep *SessionRepository) Revoke(ctx context.Context, id string) (bool, error) {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := rep.db.Collection(sessionsCollection).UpdateOne(
+		ctx,
+		bson.M{"_id": objId, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": time.Now().UTC()}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+func (rep *SessionRepository) RevokeFamily(ctx context.Context, familyId string) error {
+	_, err := rep.db.Collection(sessionsCollection).UpdateMany(
+		ctx,
+		bson.M{"family_id": familyId, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": time.Now().UTC()}},
+	)
+	return err
+}
+
+func (rep *SessionRepository) RevokeUser(ctx context.Context, userId string) error {
+	userObjId, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return err
+	}
+
+	_, err = rep.db.Collection(sessionsCollection).UpdateMany(
+		ctx,
+		bson.M{"user_id": userObjId, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": time.Now().UTC()}},
+	)
+	return err
+}
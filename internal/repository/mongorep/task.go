@@ -2,10 +2,12 @@ package mongorep
 
 import (
 	"context"
+	"errors"
 	"github.com/i-vasilkov/go-todo-app/internal/domain"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"time"
 )
 
@@ -22,7 +24,7 @@ func NewMongoTaskRepository(db *mongo.Database) *TaskRepository {
 func (rep *TaskRepository) Get(ctx context.Context, id, userId string) (domain.Task, error) {
 	objId, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return domain.Task{}, err
+		return domain.Task{}, domain.ErrTaskNotFound
 	}
 
 	userObjId, err := primitive.ObjectIDFromHex(userId)
@@ -32,33 +34,45 @@ func (rep *TaskRepository) Get(ctx context.Context, id, userId string) (domain.T
 
 	var task domain.Task
 	err = rep.db.Collection(tasksCollection).
-		FindOne(ctx, bson.M{"_id": objId, "user_id": userObjId}).
+		FindOne(ctx, activeTask(objId, userObjId)).
 		Decode(&task)
 
 	if err != nil {
-		return task, err
+		return task, notFound(err, domain.ErrTaskNotFound)
 	}
 
 	return task, nil
 }
 
-func (rep *TaskRepository) GetAll(ctx context.Context, userId string) ([]domain.Task, error) {
+func (rep *TaskRepository) GetAll(ctx context.Context, userId string, filter domain.TaskFilter) (domain.TaskPage, error) {
 	userObjId, err := primitive.ObjectIDFromHex(userId)
 	if err != nil {
-		return nil, err
+		return domain.TaskPage{}, err
 	}
 
-	cursor, err := rep.db.Collection(tasksCollection).Find(ctx, bson.M{"user_id": userObjId})
+	filter = filter.Normalize()
+	cursor, err := filter.DecodeCursor()
 	if err != nil {
-		return nil, err
+		return domain.TaskPage{}, err
+	}
+
+	query, err := newTaskQuery(userObjId, filter, cursor)
+	if err != nil {
+		return domain.TaskPage{}, err
 	}
 
 	tasks := make([]domain.Task, 0)
-	if err := cursor.All(ctx, &tasks); err != nil {
-		return nil, err
+	if err := query.find(ctx, rep.db.Collection(tasksCollection), &tasks); err != nil {
+		return domain.TaskPage{}, err
 	}
 
-	return tasks, nil
+	page := domain.TaskPage{Items: tasks}
+	if len(tasks) > filter.Limit {
+		page.Items = tasks[:filter.Limit]
+		page.NextCursor = domain.NewTaskCursor(filter, page.Items[filter.Limit-1])
+	}
+
+	return page, nil
 }
 
 func (rep *TaskRepository) Create(ctx context.Context, userId string, in domain.CreateTaskInput) (domain.Task, error) {
@@ -67,12 +81,37 @@ func (rep *TaskRepository) Create(ctx context.Context, userId string, in domain.
 		return domain.Task{}, err
 	}
 
+	projectObjId, err := optionalObjectID(in.ProjectId)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	parentObjId, err := optionalObjectID(in.ParentId)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	position, err := lastPosition(ctx, rep.db, userObjId, projectObjId)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
 	result, err := rep.db.Collection(tasksCollection).
 		InsertOne(ctx, bson.M{
-			"name":       in.Name,
-			"created_at": time.Now().Format(time.RFC3339),
-			"updated_at": time.Now().Format(time.RFC3339),
-			"user_id":    userObjId,
+			"name":        in.Name,
+			"description": in.Description,
+			"project_id":  projectObjId,
+			"parent_id":   parentObjId,
+			"status":      domain.TaskStatusTodo,
+			"priority":    in.Priority.OrDefault(),
+			"position":    position + domain.TaskPositionGap,
+			"start_at":    in.StartAt,
+			"due_at":      in.DueAt,
+			"no_due_at":   noDueAt(in.DueAt),
+			"recurrence":  in.Recurrence,
+			"created_at":  time.Now().UTC(),
+			"updated_at":  time.Now().UTC(),
+			"user_id":     userObjId,
 		})
 
 	if err != nil {
@@ -83,11 +122,11 @@ func (rep *TaskRepository) Create(ctx context.Context, userId string, in domain.
 
 	var task domain.Task
 	err = rep.db.Collection(tasksCollection).
-		FindOne(ctx, bson.M{"_id": objId}).
+		FindOne(ctx, bson.M{"_id": objId, "user_id": userObjId}).
 		Decode(&task)
 
 	if err != nil {
-		return task, err
+		return task, notFound(err, domain.ErrTaskNotFound)
 	}
 
 	return task, nil
@@ -96,7 +135,7 @@ func (rep *TaskRepository) Create(ctx context.Context, userId string, in domain.
 func (rep *TaskRepository) Update(ctx context.Context, id, userId string, in domain.UpdateTaskInput) (domain.Task, error) {
 	objId, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return domain.Task{}, err
+		return domain.Task{}, domain.ErrTaskNotFound
 	}
 
 	userObjId, err := primitive.ObjectIDFromHex(userId)
@@ -104,35 +143,595 @@ func (rep *TaskRepository) Update(ctx context.Context, id, userId string, in dom
 		return domain.Task{}, err
 	}
 
-	update := bson.M{"$set": bson.M{"name": in.Name, "updated_at": time.Now().Format(time.RFC3339)}}
-	_, err = rep.db.Collection(tasksCollection).UpdateOne(ctx, bson.M{"_id": objId, "user_id": userObjId}, update)
+	projectObjId, err := optionalObjectID(in.ProjectId)
 	if err != nil {
 		return domain.Task{}, err
 	}
 
-	var tasks domain.Task
+	parentObjId, err := optionalObjectID(in.ParentId)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	// The task is read back with the same filter, so a task of another user is never returned.
+	filter := activeTask(objId, userObjId)
+
+	var current struct {
+		ProjectId *primitive.ObjectID `bson:"project_id"`
+	}
 	err = rep.db.Collection(tasksCollection).
-		FindOne(ctx, bson.M{"_id": objId}).
-		Decode(&tasks)
+		FindOne(ctx, filter, options.FindOne().SetProjection(bson.M{"project_id": 1})).
+		Decode(&current)
+	if err != nil {
+		return domain.Task{}, notFound(err, domain.ErrTaskNotFound)
+	}
+
+	set := bson.M{
+		"name":        in.Name,
+		"description": in.Description,
+		"project_id":  projectObjId,
+		"parent_id":   parentObjId,
+		"priority":    in.Priority.OrDefault(),
+		"start_at":    in.StartAt,
+		"due_at":      in.DueAt,
+		"no_due_at":   noDueAt(in.DueAt),
+		"recurrence":  in.Recurrence,
+		"updated_at":  time.Now().UTC(),
+	}
+	// A task moved to another project goes to the end of its list, positions are per project.
+	if !equalObjectIds(current.ProjectId, projectObjId) {
+		position, err := lastPosition(ctx, rep.db, userObjId, projectObjId)
+		if err != nil {
+			return domain.Task{}, err
+		}
+		set["position"] = position + domain.TaskPositionGap
+	}
 
+	result, err := rep.db.Collection(tasksCollection).UpdateOne(ctx, filter, bson.M{"$set": set})
 	if err != nil {
-		return tasks, err
+		return domain.Task{}, err
+	}
+	if result.MatchedCount == 0 {
+		return domain.Task{}, domain.ErrTaskNotFound
 	}
 
-	return tasks, nil
+	var task domain.Task
+	err = rep.db.Collection(tasksCollection).FindOne(ctx, filter).Decode(&task)
+
+	return task, notFound(err, domain.ErrTaskNotFound)
 }
 
+// Delete moves the task together with all its subtasks to the trash.
 func (rep *TaskRepository) Delete(ctx context.Context, id, userId string) error {
 	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrTaskNotFound
+	}
+
+	userObjId, err := primitive.ObjectIDFromHex(userId)
 	if err != nil {
 		return err
 	}
 
+	trashed, err := trashTasks(ctx, rep.db, userObjId, bson.M{"_id": objId})
+	if err != nil {
+		return err
+	}
+	if trashed == 0 {
+		return domain.ErrTaskNotFound
+	}
+
+	return nil
+}
+
+// trashTasks moves the active tasks of the user that match the filter to the trash together
+// with their subtasks that are not trashed yet. It returns how many tasks matched the filter.
+func trashTasks(ctx context.Context, db *mongo.Database, userObjId primitive.ObjectID, filter bson.M) (int, error) {
+	active := bson.M{"user_id": userObjId, "deleted_at": nil}
+	rootFilter := bson.M{}
+	for key, value := range active {
+		rootFilter[key] = value
+	}
+	for key, value := range filter {
+		rootFilter[key] = value
+	}
+
+	cursor, err := db.Collection(tasksCollection).Find(ctx, rootFilter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return 0, err
+	}
+
+	var roots []struct {
+		Id primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &roots); err != nil {
+		return 0, err
+	}
+	if len(roots) == 0 {
+		return 0, nil
+	}
+
+	rootIds := make([]primitive.ObjectID, 0, len(roots))
+	for _, root := range roots {
+		rootIds = append(rootIds, root.Id)
+	}
+
+	ids, err := subtree(ctx, db, rootIds, active)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = db.Collection(tasksCollection).UpdateMany(
+		ctx,
+		bson.M{"_id": bson.M{"$in": ids}, "user_id": userObjId, "deleted_at": nil},
+		bson.M{"$set": bson.M{"deleted_at": time.Now().UTC()}},
+	)
+	return len(roots), err
+}
+
+// GetTrash returns the trashed tasks of the user, most recently deleted first.
+func (rep *TaskRepository) GetTrash(ctx context.Context, userId string) ([]domain.Task, error) {
+	userObjId, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "deleted_at", Value: -1}, {Key: "_id", Value: -1}})
+	cursor, err := rep.db.Collection(tasksCollection).
+		Find(ctx, bson.M{"user_id": userObjId, "deleted_at": bson.M{"$ne": nil}}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]domain.Task, 0)
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// Restore takes the task out of the trash together with the subtasks that were deleted
+// along with it. A subtask whose parent is still in the trash is restored to the top level.
+func (rep *TaskRepository) Restore(ctx context.Context, id, userId string) (domain.Task, error) {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.Task{}, domain.ErrTaskNotFound
+	}
+
+	userObjId, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	var trashed domain.Task
+	err = rep.db.Collection(tasksCollection).
+		FindOne(ctx, bson.M{"_id": objId, "user_id": userObjId, "deleted_at": bson.M{"$ne": nil}}).
+		Decode(&trashed)
+	if err != nil {
+		return domain.Task{}, notFound(err, domain.ErrTaskNotFound)
+	}
+
+	update := bson.M{"$unset": bson.M{"deleted_at": ""}}
+	if trashed.ParentId != nil {
+		parentObjId, err := primitive.ObjectIDFromHex(*trashed.ParentId)
+		if err != nil {
+			return domain.Task{}, err
+		}
+
+		trashedParents, err := rep.db.Collection(tasksCollection).
+			CountDocuments(ctx, bson.M{"_id": parentObjId, "deleted_at": bson.M{"$ne": nil}})
+		if err != nil {
+			return domain.Task{}, err
+		}
+		if trashedParents > 0 {
+			update["$set"] = bson.M{"parent_id": nil}
+		}
+	}
+
+	ids, err := subtree(ctx, rep.db, []primitive.ObjectID{objId}, bson.M{"user_id": userObjId, "deleted_at": *trashed.DeletedAt})
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	_, err = rep.db.Collection(tasksCollection).UpdateMany(
+		ctx,
+		bson.M{"_id": bson.M{"$in": ids[1:]}, "user_id": userObjId},
+		bson.M{"$unset": bson.M{"deleted_at": ""}},
+	)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	// The task itself goes last, so an interrupted restore can be repeated.
+	if _, err := rep.db.Collection(tasksCollection).UpdateOne(ctx, bson.M{"_id": objId, "user_id": userObjId}, update); err != nil {
+		return domain.Task{}, err
+	}
+
+	return rep.Get(ctx, id, userId)
+}
+
+// PurgeTrash permanently removes the tasks of all users that were trashed before the given time.
+func (rep *TaskRepository) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	result, err := rep.db.Collection(tasksCollection).DeleteMany(ctx, bson.M{"deleted_at": bson.M{"$lt": before}})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+// Count sums up the tasks of all users by status. Trashed tasks are only counted as such.
+func (rep *TaskRepository) Count(ctx context.Context) (domain.TaskCounts, error) {
+	cursor, err := rep.db.Collection(tasksCollection).Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{"$cond": bson.A{
+				bson.M{"$eq": bson.A{bson.M{"$ifNull": bson.A{"$deleted_at", nil}}, nil}}, "$status", "",
+			}},
+			"count": bson.M{"$sum": 1},
+		}}},
+	})
+	if err != nil {
+		return domain.TaskCounts{}, err
+	}
+
+	var groups []struct {
+		Status domain.TaskStatus `bson:"_id"`
+		Count  int64             `bson:"count"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return domain.TaskCounts{}, err
+	}
+
+	counts := domain.TaskCounts{ByStatus: make(map[domain.TaskStatus]int64)}
+	for _, group := range groups {
+		if group.Status == "" {
+			counts.InTrash += group.Count
+			continue
+		}
+		counts.ByStatus[group.Status] += group.Count
+		counts.Total += group.Count
+	}
+
+	return counts, nil
+}
+
+func (rep *TaskRepository) UpdateStatus(
+	ctx context.Context, id, userId string, status domain.TaskStatus, completedAt *time.Time,
+) (domain.Task, error) {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.Task{}, domain.ErrTaskNotFound
+	}
+
+	userObjId, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	update := bson.M{"$set": bson.M{
+		"status":       status,
+		"completed_at": completedAt,
+		"updated_at":   time.Now().UTC(),
+	}}
+	_, err = rep.db.Collection(tasksCollection).UpdateOne(ctx, activeTask(objId, userObjId), update)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	var task domain.Task
+	err = rep.db.Collection(tasksCollection).
+		FindOne(ctx, activeTask(objId, userObjId)).
+		Decode(&task)
+
+	return task, notFound(err, domain.ErrTaskNotFound)
+}
+
+func (rep *TaskRepository) GetOverdue(ctx context.Context, userId string, now time.Time) ([]domain.Task, error) {
+	filter := bson.M{
+		"due_at": bson.M{"$lt": now},
+		"status": bson.M{"$nin": []domain.TaskStatus{domain.TaskStatusDone, domain.TaskStatusCancelled}},
+	}
+
+	return rep.findByDueAt(ctx, userId, filter)
+}
+
+func (rep *TaskRepository) GetDueBetween(ctx context.Context, userId string, from, to time.Time) ([]domain.Task, error) {
+	filter := bson.M{
+		"due_at": bson.M{"$gte": from, "$lt": to},
+	}
+
+	return rep.findByDueAt(ctx, userId, filter)
+}
+
+// AttachLabels adds the labels to the task; labels the task already carries are kept once.
+func (rep *TaskRepository) AttachLabels(ctx context.Context, id, userId string, labelIds []string) (domain.Task, error) {
+	labelObjIds, err := objectIDs(labelIds)
+	if err != nil {
+		return domain.Task{}, domain.ErrLabelNotFound
+	}
+
+	return rep.updateLabels(ctx, id, userId, bson.M{"$addToSet": bson.M{"label_ids": bson.M{"$each": labelObjIds}}})
+}
+
+func (rep *TaskRepository) DetachLabels(ctx context.Context, id, userId string, labelIds []string) (domain.Task, error) {
+	labelObjIds, err := objectIDs(labelIds)
+	if err != nil {
+		return domain.Task{}, domain.ErrLabelNotFound
+	}
+
+	return rep.updateLabels(ctx, id, userId, bson.M{"$pull": bson.M{"label_ids": bson.M{"$in": labelObjIds}}})
+}
+
+func (rep *TaskRepository) updateLabels(ctx context.Context, id, userId string, update bson.M) (domain.Task, error) {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.Task{}, domain.ErrTaskNotFound
+	}
+
+	userObjId, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	filter := activeTask(objId, userObjId)
+	if _, err := rep.db.Collection(tasksCollection).UpdateOne(ctx, filter, update); err != nil {
+		return domain.Task{}, err
+	}
+
+	var task domain.Task
+	err = rep.db.Collection(tasksCollection).FindOne(ctx, filter).Decode(&task)
+
+	return task, notFound(err, domain.ErrTaskNotFound)
+}
+
+// GetSubtasks returns the direct subtasks of all given parents in creation order.
+func (rep *TaskRepository) GetSubtasks(ctx context.Context, userId string, parentIds []string) ([]domain.Task, error) {
+	userObjId, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	parentObjIds, err := objectIDs(parentIds)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := rep.db.Collection(tasksCollection).
+		Find(ctx, bson.M{"user_id": userObjId, "parent_id": bson.M{"$in": parentObjIds}, "deleted_at": nil}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]domain.Task, 0)
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+func (rep *TaskRepository) UpdatePosition(ctx context.Context, id, userId string, position float64) (domain.Task, error) {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.Task{}, domain.ErrTaskNotFound
+	}
+
 	userObjId, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	filter := activeTask(objId, userObjId)
+	update := bson.M{"$set": bson.M{"position": position, "updated_at": time.Now().UTC()}}
+	if _, err := rep.db.Collection(tasksCollection).UpdateOne(ctx, filter, update); err != nil {
+		return domain.Task{}, err
+	}
+
+	var task domain.Task
+	err = rep.db.Collection(tasksCollection).FindOne(ctx, filter).Decode(&task)
+
+	return task, notFound(err, domain.ErrTaskNotFound)
+}
+
+// GetAdjacentPosition returns the position of the task listed right after (next) or before the
+// given one in its project, or nil when there is no such task. Tasks at the same position are
+// listed by id, so their neighbours are found too.
+func (rep *TaskRepository) GetAdjacentPosition(
+	ctx context.Context, userId string, task domain.Task, next bool,
+) (*float64, error) {
+	userObjId, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	objId, err := primitive.ObjectIDFromHex(task.Id)
+	if err != nil {
+		return nil, domain.ErrTaskNotFound
+	}
+
+	projectObjId, err := optionalObjectID(task.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+
+	op, dir := "$lt", -1
+	if next {
+		op, dir = "$gt", 1
+	}
+
+	var adjacent struct {
+		Position float64 `bson:"position"`
+	}
+	err = rep.db.Collection(tasksCollection).FindOne(
+		ctx,
+		bson.M{
+			"user_id":    userObjId,
+			"project_id": projectObjId,
+			"deleted_at": nil,
+			"$or": bson.A{
+				bson.M{"position": bson.M{op: task.Position}},
+				bson.M{"position": task.Position, "_id": bson.M{op: objId}},
+			},
+		},
+		options.FindOne().
+			SetSort(bson.D{{Key: "position", Value: dir}, {Key: "_id", Value: dir}}).
+			SetProjection(bson.M{"position": 1}),
+	).Decode(&adjacent)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &adjacent.Position, nil
+}
+
+// RebalancePositions spreads the positions of the project tasks evenly again, keeping their order.
+// Trashed tasks keep their positions.
+func (rep *TaskRepository) RebalancePositions(ctx context.Context, userId string, projectId *string) error {
+	userObjId, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return err
+	}
+
+	projectObjId, err := optionalObjectID(projectId)
+	if err != nil {
+		return err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "position", Value: 1}, {Key: "_id", Value: 1}}).
+		SetProjection(bson.M{"_id": 1})
+	filter := bson.M{"user_id": userObjId, "project_id": projectObjId, "deleted_at": nil}
+	cursor, err := rep.db.Collection(tasksCollection).Find(ctx, filter, opts)
 	if err != nil {
 		return err
 	}
 
-	_, err = rep.db.Collection(tasksCollection).DeleteOne(ctx, bson.M{"_id": objId, "user_id": userObjId})
+	var tasks []struct {
+		Id primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return err
+	}
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(tasks))
+	for i, task := range tasks {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": task.Id}).
+			SetUpdate(bson.M{"$set": bson.M{"position": float64(i+1) * domain.TaskPositionGap}}))
+	}
+
+	_, err = rep.db.Collection(tasksCollection).BulkWrite(ctx, models)
 	return err
 }
+
+// lastPosition returns the highest task position in the project, or 0 for a project without tasks.
+func lastPosition(
+	ctx context.Context, db *mongo.Database, userObjId primitive.ObjectID, projectObjId *primitive.ObjectID,
+) (float64, error) {
+	var last struct {
+		Position float64 `bson:"position"`
+	}
+	err := db.Collection(tasksCollection).FindOne(
+		ctx,
+		bson.M{"user_id": userObjId, "project_id": projectObjId},
+		options.FindOne().SetSort(bson.D{{Key: "position", Value: -1}}).SetProjection(bson.M{"position": 1}),
+	).Decode(&last)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+
+	return last.Position, err
+}
+
+func (rep *TaskRepository) findByDueAt(ctx context.Context, userId string, filter bson.M) ([]domain.Task, error) {
+	userObjId, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return nil, err
+	}
+	filter["user_id"] = userObjId
+	filter["deleted_at"] = nil
+
+	opts := options.Find().SetSort(bson.D{{Key: "due_at", Value: 1}})
+	cursor, err := rep.db.Collection(tasksCollection).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]domain.Task, 0)
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// subtree returns the ids of the tasks followed by the ids of all their subtasks on every
+// level. Only subtasks matching the filter are followed.
+func subtree(ctx context.Context, db *mongo.Database, rootIds []primitive.ObjectID, filter bson.M) ([]primitive.ObjectID, error) {
+	ids := rootIds
+	for parentIds := ids; len(parentIds) > 0; {
+		childFilter := bson.M{"parent_id": bson.M{"$in": parentIds}}
+		for key, value := range filter {
+			childFilter[key] = value
+		}
+
+		cursor, err := db.Collection(tasksCollection).
+			Find(ctx, childFilter, options.Find().SetProjection(bson.M{"_id": 1}))
+		if err != nil {
+			return nil, err
+		}
+
+		var children []struct {
+			Id primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.All(ctx, &children); err != nil {
+			return nil, err
+		}
+
+		parentIds = make([]primitive.ObjectID, 0, len(children))
+		for _, child := range children {
+			parentIds = append(parentIds, child.Id)
+		}
+		ids = append(ids, parentIds...)
+	}
+
+	return ids, nil
+}
+
+// activeTask matches the task of the user unless it is in the trash.
+func activeTask(objId, userObjId primitive.ObjectID) bson.M {
+	return bson.M{"_id": objId, "user_id": userObjId, "deleted_at": nil}
+}
+
+// optionalObjectID converts an optional hex id; a missing id is stored as null.
+func optionalObjectID(id *string) (*primitive.ObjectID, error) {
+	if id == nil {
+		return nil, nil
+	}
+
+	objId, err := primitive.ObjectIDFromHex(*id)
+	if err != nil {
+		return nil, err
+	}
+	return &objId, nil
+}
+
+func objectIDs(ids []string) ([]primitive.ObjectID, error) {
+	objIds := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		objId, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, err
+		}
+		objIds = append(objIds, objId)
+	}
+	return objIds, nil
+}
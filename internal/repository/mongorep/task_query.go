@@ -0,0 +1,167 @@
+package mongorep
+
+import (
+	"context"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"regexp"
+	"time"
+)
+
+// noDueAtField is stored next to due_at and sorted on first, so that tasks without
+// a due date are listed last, matching the Postgres "NULLS LAST" order.
+const noDueAtField = "no_due_at"
+
+type taskQuery struct {
+	filter bson.M
+	field  string
+	dir    int
+	limit  int64
+}
+
+func newTaskQuery(userObjId primitive.ObjectID, filter domain.TaskFilter, cursor *domain.TaskCursor) (*taskQuery, error) {
+	q := &taskQuery{
+		filter: bson.M{"user_id": userObjId, "deleted_at": nil},
+		field:  string(filter.SortBy),
+		dir:    1,
+		limit:  int64(filter.Limit + 1),
+	}
+	if filter.SortDir == domain.SortDesc {
+		q.dir = -1
+	}
+
+	if len(filter.Status) > 0 {
+		q.filter["status"] = bson.M{"$in": filter.Status}
+	}
+	if dueAt := timeRange(filter.DueFrom, filter.DueTo); dueAt != nil {
+		q.filter["due_at"] = dueAt
+	}
+	if createdAt := timeRange(filter.CreatedFrom, filter.CreatedTo); createdAt != nil {
+		q.filter["created_at"] = createdAt
+	}
+	if filter.Name != "" {
+		q.filter["name"] = primitive.Regex{Pattern: regexp.QuoteMeta(filter.Name), Options: "i"}
+	}
+	if filter.ProjectId == domain.InboxProjectId {
+		q.filter["project_id"] = nil
+	} else if filter.ProjectId != "" {
+		projectObjId, err := primitive.ObjectIDFromHex(filter.ProjectId)
+		if err != nil {
+			return nil, domain.ErrInvalidProjectFilter
+		}
+		q.filter["project_id"] = projectObjId
+	}
+	if len(filter.Labels) > 0 {
+		labelObjIds, err := objectIDs(filter.Labels)
+		if err != nil {
+			return nil, domain.ErrInvalidLabelFilter
+		}
+		op := "$in"
+		if filter.LabelMatch == domain.LabelMatchAll {
+			op = "$all"
+		}
+		q.filter["label_ids"] = bson.M{op: labelObjIds}
+	}
+
+	if cursor != nil {
+		after, err := q.after(cursor)
+		if err != nil {
+			return nil, err
+		}
+		q.filter = bson.M{"$and": bson.A{q.filter, after}}
+	}
+
+	return q, nil
+}
+
+// after matches the tasks that follow the cursor position in the list order.
+func (q *taskQuery) after(cursor *domain.TaskCursor) (bson.M, error) {
+	id, err := primitive.ObjectIDFromHex(cursor.Id)
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+
+	op := "$gt"
+	if q.dir < 0 {
+		op = "$lt"
+	}
+
+	if cursor.Value == nil {
+		if q.field != string(domain.TaskSortDueAt) {
+			return nil, domain.ErrInvalidCursor
+		}
+		return bson.M{"due_at": nil, "_id": bson.M{op: id}}, nil
+	}
+
+	var value interface{} = *cursor.Value
+	switch q.field {
+	case string(domain.TaskSortDueAt), string(domain.TaskSortCreatedAt), string(domain.TaskSortUpdatedAt):
+		t, err := cursor.TimeValue()
+		if err != nil {
+			return nil, err
+		}
+		value = t.UTC()
+	case string(domain.TaskSortPosition):
+		f, err := cursor.FloatValue()
+		if err != nil {
+			return nil, err
+		}
+		value = f
+	case string(domain.TaskSortPriority):
+		f, err := cursor.FloatValue()
+		if err != nil {
+			return nil, err
+		}
+		value = int(f)
+	}
+
+	or := bson.A{
+		bson.M{q.field: bson.M{op: value}},
+		bson.M{q.field: value, "_id": bson.M{op: id}},
+	}
+	if q.field == string(domain.TaskSortDueAt) {
+		or = append(or, bson.M{"due_at": nil})
+	}
+
+	return bson.M{"$or": or}, nil
+}
+
+func (q *taskQuery) find(ctx context.Context, collection *mongo.Collection, tasks *[]domain.Task) error {
+	sort := bson.D{{Key: q.field, Value: q.dir}, {Key: "_id", Value: q.dir}}
+	if q.field == string(domain.TaskSortDueAt) {
+		sort = append(bson.D{{Key: noDueAtField, Value: 1}}, sort...)
+	}
+
+	cursor, err := collection.Find(ctx, q.filter, options.Find().SetSort(sort).SetLimit(q.limit))
+	if err != nil {
+		return err
+	}
+
+	return cursor.All(ctx, tasks)
+}
+
+// noDueAt is the value of noDueAtField for the given due date.
+func noDueAt(dueAt *time.Time) int {
+	if dueAt == nil {
+		return 1
+	}
+	return 0
+}
+
+func timeRange(from, to *time.Time) bson.M {
+	if from == nil && to == nil {
+		return nil
+	}
+
+	cond := bson.M{}
+	if from != nil {
+		cond["$gte"] = from.UTC()
+	}
+	if to != nil {
+		cond["$lt"] = to.UTC()
+	}
+	return cond
+}
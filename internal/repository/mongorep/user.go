@@ -2,10 +2,12 @@ package mongorep
 
 import (
 	"context"
+	"errors"
 	"github.com/i-vasilkov/go-todo-app/internal/domain"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"time"
 )
 
@@ -22,14 +24,22 @@ func NewMongoUserRepository(db *mongo.Database) *UserRepository {
 func (rep *UserRepository) Create(ctx context.Context, in domain.CreateUserInput) (domain.User, error) {
 	var user domain.User
 
-	result, err := rep.db.Collection(usersCollection).
-		InsertOne(ctx, bson.M{
-			"password":   in.Password,
-			"login":      in.Login,
-			"created_at": time.Now().Format(time.RFC3339),
-		})
+	doc := bson.M{
+		"password":       in.Password,
+		"login":          in.Login,
+		"email_verified": false,
+		"roles":          []domain.Role{},
+		"disabled":       false,
+		"created_at":     time.Now().UTC(),
+	}
+	// The unique index only covers the users with an email.
+	if in.Email != "" {
+		doc["email"] = in.Email
+	}
+
+	result, err := rep.db.Collection(usersCollection).InsertOne(ctx, doc)
 	if err != nil {
-		return user, err
+		return user, userConflict(err)
 	}
 
 	objId := result.InsertedID.(primitive.ObjectID)
@@ -38,16 +48,26 @@ func (rep *UserRepository) Create(ctx context.Context, in domain.CreateUserInput
 	return user, err
 }
 
-func (rep *UserRepository) GetByCredentials(ctx context.Context, in domain.LoginUserInput) (domain.User, error) {
+func (rep *UserRepository) GetByLogin(ctx context.Context, login string) (domain.User, error) {
 	var user domain.User
 
 	err := rep.db.Collection(usersCollection).
-		FindOne(ctx, bson.M{
-			"login":    in.Login,
-			"password": in.Password,
-		}).
+		FindOne(ctx, bson.M{"login": login}).
 		Decode(&user)
 
+	return user, notFound(err, domain.ErrUserNotFound)
+}
+
+func (rep *UserRepository) GetByEmail(ctx context.Context, email string) (domain.User, error) {
+	var user domain.User
+
+	err := rep.db.Collection(usersCollection).
+		FindOne(ctx, bson.M{"email": email}).
+		Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.User{}, domain.ErrUserNotFound
+	}
+
 	return user, err
 }
 
@@ -56,9 +76,222 @@ func (rep *UserRepository) Get(ctx context.Context, id string) (domain.User, err
 
 	objId, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return user, err
+		return user, domain.ErrUserNotFound
 	}
 
 	err = rep.db.Collection(usersCollection).FindOne(ctx, bson.M{"_id": objId}).Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.User{}, domain.ErrUserNotFound
+	}
 	return user, err
 }
+
+// GetAll pages through the users in the order they signed up.
+func (rep *UserRepository) GetAll(ctx context.Context, filter domain.UserFilter) ([]domain.User, error) {
+	filter = filter.Normalize()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetSkip(int64(filter.Offset)).
+		SetLimit(int64(filter.Limit))
+	cursor, err := rep.db.Collection(usersCollection).Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]domain.User, 0)
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// Update sets the given fields only. Setting an email marks it as not verified.
+func (rep *UserRepository) Update(ctx context.Context, id string, in domain.UpdateUserInput) (domain.User, error) {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.User{}, domain.ErrUserNotFound
+	}
+
+	set := bson.M{}
+	if in.Login != nil {
+		set["login"] = *in.Login
+	}
+	if in.Email != nil {
+		set["email"] = *in.Email
+		set["email_verified"] = false
+	}
+
+	if len(set) > 0 {
+		_, err = rep.db.Collection(usersCollection).UpdateOne(ctx, bson.M{"_id": objId}, bson.M{"$set": set})
+		if err != nil {
+			return domain.User{}, userConflict(err)
+		}
+	}
+
+	return rep.Get(ctx, id)
+}
+
+// userDataCollections hold the documents owned by a user, by their user_id.
+var userDataCollections = []string{
+	tasksCollection,
+	projectsCollection,
+	labelsCollection,
+	sessionsCollection,
+	accessTokensCollection,
+}
+
+// Delete removes the user together with the tasks and everything else the user owns. All of it
+// happens in one transaction, which needs MongoDB to run as a replica set.
+func (rep *UserRepository) Delete(ctx context.Context, id string) error {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrUserNotFound
+	}
+
+	return rep.db.Client().UseSession(ctx, func(sc mongo.SessionContext) error {
+		_, err := sc.WithTransaction(sc, func(sc mongo.SessionContext) (interface{}, error) {
+			result, err := rep.db.Collection(usersCollection).DeleteOne(sc, bson.M{"_id": objId})
+			if err != nil {
+				return nil, err
+			}
+			if result.DeletedCount == 0 {
+				return nil, domain.ErrUserNotFound
+			}
+
+			for _, collection := range userDataCollections {
+				if _, err := rep.db.Collection(collection).DeleteMany(sc, bson.M{"user_id": objId}); err != nil {
+					return nil, err
+				}
+			}
+
+			return nil, nil
+		})
+		return err
+	})
+}
+
+func (rep *UserRepository) UpdatePassword(ctx context.Context, id, password string) error {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrUserNotFound
+	}
+
+	_, err = rep.db.Collection(usersCollection).
+		UpdateOne(ctx, bson.M{"_id": objId}, bson.M{"$set": bson.M{"password": password}})
+	return err
+}
+
+func (rep *UserRepository) SetEmailVerified(ctx context.Context, id string) error {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrUserNotFound
+	}
+
+	_, err = rep.db.Collection(usersCollection).
+		UpdateOne(ctx, bson.M{"_id": objId}, bson.M{"$set": bson.M{"email_verified": true}})
+	return err
+}
+
+func (rep *UserRepository) SetDisabled(ctx context.Context, id string, disabled bool) error {
+	return rep.set(ctx, id, bson.M{"disabled": disabled})
+}
+
+func (rep *UserRepository) SetRoles(ctx context.Context, id string, roles []domain.Role) error {
+	if roles == nil {
+		roles = []domain.Role{}
+	}
+
+	return rep.set(ctx, id, bson.M{"roles": roles})
+}
+
+func (rep *UserRepository) set(ctx context.Context, id string, set bson.M) error {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrUserNotFound
+	}
+
+	result, err := rep.db.Collection(usersCollection).UpdateOne(ctx, bson.M{"_id": objId}, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (rep *UserRepository) UpdateTotp(ctx context.Context, id string, in domain.UpdateTotpInput) error {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrUserNotFound
+	}
+
+	recoveryCodes := in.RecoveryCodes
+	if recoveryCodes == nil {
+		recoveryCodes = []string{}
+	}
+
+	_, err = rep.db.Collection(usersCollection).
+		UpdateOne(ctx, bson.M{"_id": objId}, bson.M{"$set": bson.M{
+			"totp_secret":    in.Secret,
+			"totp_enabled":   in.Enabled,
+			"recovery_codes": recoveryCodes,
+		}})
+	return err
+}
+
+// UseRecoveryCode removes the recovery code in the same update that finds it, so a code can
+// not be used twice by concurrent requests.
+func (rep *UserRepository) UseRecoveryCode(ctx context.Context, id, codeHash string) (bool, error) {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, domain.ErrUserNotFound
+	}
+
+	result, err := rep.db.Collection(usersCollection).
+		UpdateOne(
+			ctx,
+			bson.M{"_id": objId, "recovery_codes": codeHash},
+			bson.M{"$pull": bson.M{"recovery_codes": codeHash}},
+		)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// UseTotpStep records the time step of an accepted code in the same update that compares it,
+// so a code can not be used twice by concurrent requests.
+func (rep *UserRepository) UseTotpStep(ctx context.Context, id string, step int64) (bool, error) {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, domain.ErrUserNotFound
+	}
+
+	result, err := rep.db.Collection(usersCollection).
+		UpdateOne(
+			ctx,
+			bson.M{"_id": objId, "totp_last_step": bson.M{"$not": bson.M{"$gte": step}}},
+			bson.M{"$set": bson.M{"totp_last_step": step}},
+		)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// userConflict tells which of the unique login and email is already taken.
+func userConflict(err error) error {
+	if isDuplicateKey(err, "email_1") {
+		return domain.ErrEmailTaken
+	}
+	if isDuplicateKey(err, "login_1") {
+		return domain.ErrLoginTaken
+	}
+	return err
+}
@@ -0,0 +1,139 @@
+package postgresrep
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"strconv"
+	"time"
+)
+
+type PostgresAccessTokenRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresAccessTokenRepository(db *sqlx.DB) *PostgresAccessTokenRepository {
+	return &PostgresAccessTokenRepository{db: db}
+}
+
+func (rep *PostgresAccessTokenRepository) GetAll(ctx context.Context, userId string) ([]domain.AccessToken, error) {
+	intUserID, err := strconv.Atoi(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf("SELECT * FROM %s WHERE user_id = $1 ORDER BY created_at DESC, id DESC", accessTokensTable)
+
+	rows := make([]accessTokenRow, 0)
+	if err := rep.db.Select(&rows, query, intUserID); err != nil {
+		return nil, err
+	}
+
+	tokens := make([]domain.AccessToken, 0, len(rows))
+	for _, row := range rows {
+		tokens = append(tokens, row.toDomain())
+	}
+
+	return tokens, nil
+}
+
+func (rep *PostgresAccessTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (domain.AccessToken, error) {
+	query := fmt.Sprintf("SELECT * FROM %s WHERE token_hash = $1", accessTokensTable)
+
+	var row accessTokenRow
+	err := rep.db.Get(&row, query, tokenHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.AccessToken{}, domain.ErrAccessTokenNotFound
+	}
+	if err != nil {
+		return domain.AccessToken{}, err
+	}
+
+	return row.toDomain(), nil
+}
+
+func (rep *PostgresAccessTokenRepository) Create(ctx context.Context, userId, tokenHash string, in domain.CreateAccessTokenInput) (domain.AccessToken, error) {
+	intUserID, err := strconv.Atoi(userId)
+	if err != nil {
+		return domain.AccessToken{}, err
+	}
+
+	scopes := make([]string, 0, len(in.Scopes))
+	for _, scope := range in.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+
+	var expiresAt *time.Time
+	if in.ExpiresAt != nil {
+		utc := in.ExpiresAt.UTC()
+		expiresAt = &utc
+	}
+
+	query := fmt.Sprintf(
+		"INSERT INTO %s (user_id, name, scopes, token_hash, expires_at, created_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
+		accessTokensTable,
+	)
+
+	now := time.Now().Format(time.RFC3339)
+	row := rep.db.QueryRow(query, intUserID, in.Name, pq.Array(scopes), tokenHash, expiresAt, now)
+
+	var id int
+	if err := row.Scan(&id); err != nil {
+		return domain.AccessToken{}, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s WHERE id = $1", accessTokensTable)
+	var created accessTokenRow
+	if err := rep.db.Get(&created, query, id); err != nil {
+		return domain.AccessToken{}, err
+	}
+
+	return created.toDomain(), nil
+}
+
+func (rep *PostgresAccessTokenRepository) Delete(ctx context.Context, id, userId string) error {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return domain.ErrAccessTokenNotFound
+	}
+
+	intUserID, err := strconv.Atoi(userId)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1 AND user_id = $2", accessTokensTable)
+
+	_, err = rep.db.Exec(query, intID, intUserID)
+	return err
+}
+
+func (rep *PostgresAccessTokenRepository) UpdateLastUsed(ctx context.Context, id string, at time.Time) error {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET last_used_at = $1 WHERE id = $2", accessTokensTable)
+	_, err = rep.db.Exec(query, at.UTC(), intID)
+	return err
+}
+
+// accessTokenRow reads the scopes array, which sqlx can not scan into the domain type.
+type accessTokenRow struct {
+	domain.AccessToken
+	Scopes pq.StringArray `db:"scopes"`
+}
+
+func (r accessTokenRow) toDomain() domain.AccessToken {
+	token := r.AccessToken
+	token.Scopes = make([]domain.Scope, 0, len(r.Scopes))
+	for _, scope := range r.Scopes {
+		token.Scopes = append(token.Scopes, domain.Scope(scope))
+	}
+	return token
+}
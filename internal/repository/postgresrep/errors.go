@@ -0,0 +1,28 @@
+package postgresrep
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/lib/pq"
+)
+
+// uniqueViolation is the Postgres error code of a broken unique constraint.
+const uniqueViolation = "23505"
+
+// notFound replaces a missing row with the domain error of what was looked for.
+func notFound(err, notFoundErr error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFoundErr
+	}
+	return err
+}
+
+// isUniqueViolation reports whether the statement broke the named unique constraint, or any
+// unique constraint when the name is empty.
+func isUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != uniqueViolation {
+		return false
+	}
+	return constraint == "" || pqErr.Constraint == constraint
+}
@@ -0,0 +1,126 @@
+package postgresrep
+
+import (
+	"context"
+	"fmt"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"strconv"
+	"time"
+)
+
+type PostgresLabelRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresLabelRepository(db *sqlx.DB) *PostgresLabelRepository {
+	return &PostgresLabelRepository{db: db}
+}
+
+func (rep *PostgresLabelRepository) Get(ctx context.Context, id, userId string) (domain.Label, error) {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return domain.Label{}, domain.ErrLabelNotFound
+	}
+
+	intUserID, err := strconv.Atoi(userId)
+	if err != nil {
+		return domain.Label{}, err
+	}
+
+	query := fmt.Sprintf("SELECT * FROM %s WHERE id = $1 AND user_id = $2", labelsTable)
+
+	var label domain.Label
+	err = rep.db.Get(&label, query, intID, intUserID)
+
+	return label, notFound(err, domain.ErrLabelNotFound)
+}
+
+func (rep *PostgresLabelRepository) GetAll(ctx context.Context, userId string) ([]domain.Label, error) {
+	intUserID, err := strconv.Atoi(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf("SELECT * FROM %s WHERE user_id = $1 ORDER BY name, id", labelsTable)
+
+	labels := make([]domain.Label, 0)
+	err = rep.db.Select(&labels, query, intUserID)
+
+	return labels, err
+}
+
+func (rep *PostgresLabelRepository) Create(ctx context.Context, userId string, in domain.CreateLabelInput) (domain.Label, error) {
+	intUserID, err := strconv.Atoi(userId)
+	if err != nil {
+		return domain.Label{}, err
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (name, color, user_id, created_at) VALUES ($1, $2, $3, $4) RETURNING id", labelsTable)
+
+	now := time.Now().Format(time.RFC3339)
+	row := rep.db.QueryRow(query, in.Name, in.Color, intUserID, now)
+
+	var id int
+	if err := row.Scan(&id); err != nil {
+		return domain.Label{}, labelConflict(err)
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s WHERE id = $1 AND user_id = $2", labelsTable)
+
+	var label domain.Label
+	err = rep.db.Get(&label, query, id, intUserID)
+
+	return label, err
+}
+
+func (rep *PostgresLabelRepository) Update(ctx context.Context, id, userId string, in domain.UpdateLabelInput) (domain.Label, error) {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return domain.Label{}, domain.ErrLabelNotFound
+	}
+
+	intUserID, err := strconv.Atoi(userId)
+	if err != nil {
+		return domain.Label{}, err
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET name = $1, color = $2 WHERE id = $3 AND user_id = $4", labelsTable)
+	_, err = rep.db.Exec(query, in.Name, in.Color, intID, intUserID)
+	if err != nil {
+		return domain.Label{}, labelConflict(err)
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s WHERE id = $1 AND user_id = $2", labelsTable)
+
+	var label domain.Label
+	err = rep.db.Get(&label, query, intID, intUserID)
+
+	return label, notFound(err, domain.ErrLabelNotFound)
+}
+
+// Delete removes the label; the foreign key detaches it from every task.
+func (rep *PostgresLabelRepository) Delete(ctx context.Context, id, userId string) error {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return domain.ErrLabelNotFound
+	}
+
+	intUserID, err := strconv.Atoi(userId)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1 AND user_id = $2", labelsTable)
+
+	_, err = rep.db.Exec(query, intID, intUserID)
+	return err
+}
+
+// labelConflict reports a name the user already gave to another label.
+func labelConflict(err error) error {
+	if isUniqueViolation(err, "") {
+		return domain.ErrLabelTaken
+	}
+	return err
+}
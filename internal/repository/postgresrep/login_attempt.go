@@ -0,0 +1,95 @@
+package postgresrep
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+type PostgresLoginAttemptRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresLoginAttemptRepository(db *sqlx.DB) *PostgresLoginAttemptRepository {
+	return &PostgresLoginAttemptRepository{db: db}
+}
+
+type loginAttemptsRow struct {
+	Key           string     `db:"key"`
+	Failures      int        `db:"failures"`
+	LastFailureAt time.Time  `db:"last_failure_at"`
+	LockedUntil   *time.Time `db:"locked_until"`
+}
+
+func (row loginAttemptsRow) toDomain() domain.LoginAttempts {
+	return domain.LoginAttempts{
+		Key:           row.Key,
+		Failures:      row.Failures,
+		LastFailureAt: row.LastFailureAt,
+		LockedUntil:   row.LockedUntil,
+	}
+}
+
+func (rep *PostgresLoginAttemptRepository) Get(ctx context.Context, key string) (domain.LoginAttempts, error) {
+	query := fmt.Sprintf(
+		"SELECT key, failures, last_failure_at, locked_until FROM %s WHERE key = $1 AND expires_at >= $2",
+		loginAttemptsTable,
+	)
+
+	var row loginAttemptsRow
+	err := rep.db.Get(&row, query, key, time.Now().UTC())
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.LoginAttempts{Key: key}, nil
+	}
+	if err != nil {
+		return domain.LoginAttempts{}, err
+	}
+
+	return row.toDomain(), nil
+}
+
+// AddFailure counts a failure at the given moment. The count starts over when the previous
+// failure is older than the window. Expired rows are dropped on the way.
+func (rep *PostgresLoginAttemptRepository) AddFailure(ctx context.Context, key string, at time.Time, window time.Duration) (domain.LoginAttempts, error) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE expires_at < $1", loginAttemptsTable)
+	if _, err := rep.db.Exec(query, time.Now().UTC()); err != nil {
+		return domain.LoginAttempts{}, err
+	}
+
+	query = fmt.Sprintf(
+		"INSERT INTO %[1]s (key, failures, last_failure_at, expires_at) VALUES ($1, 1, $2, $3) "+
+			"ON CONFLICT (key) DO UPDATE SET "+
+			"failures = CASE WHEN %[1]s.last_failure_at < $4 THEN 1 ELSE %[1]s.failures + 1 END, "+
+			"last_failure_at = EXCLUDED.last_failure_at, "+
+			"expires_at = GREATEST(%[1]s.expires_at, EXCLUDED.expires_at) "+
+			"RETURNING key, failures, last_failure_at, locked_until",
+		loginAttemptsTable,
+	)
+
+	var row loginAttemptsRow
+	err := rep.db.Get(&row, query, key, at.UTC(), at.Add(window).UTC(), at.Add(-window).UTC())
+	if err != nil {
+		return domain.LoginAttempts{}, err
+	}
+
+	return row.toDomain(), nil
+}
+
+func (rep *PostgresLoginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	query := fmt.Sprintf(
+		"UPDATE %s SET locked_until = $1, expires_at = GREATEST(expires_at, $1) WHERE key = $2",
+		loginAttemptsTable,
+	)
+	_, err := rep.db.Exec(query, until.UTC(), key)
+	return err
+}
+
+func (rep *PostgresLoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE key = $1", loginAttemptsTable)
+	_, err := rep.db.Exec(query, key)
+	return err
+}
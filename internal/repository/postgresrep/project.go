@@ -0,0 +1,155 @@
+package postgresrep
+
+import (
+	"context"
+	"fmt"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"strconv"
+	"time"
+)
+
+type PostgresProjectRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresProjectRepository(db *sqlx.DB) *PostgresProjectRepository {
+	return &PostgresProjectRepository{db: db}
+}
+
+func (rep *PostgresProjectRepository) Get(ctx context.Context, id, userId string) (domain.Project, error) {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return domain.Project{}, domain.ErrProjectNotFound
+	}
+
+	intUserID, err := strconv.Atoi(userId)
+	if err != nil {
+		return domain.Project{}, err
+	}
+
+	query := fmt.Sprintf("SELECT * FROM %s WHERE id = $1 AND user_id = $2", projectsTable)
+
+	var project domain.Project
+	err = rep.db.Get(&project, query, intID, intUserID)
+
+	return project, notFound(err, domain.ErrProjectNotFound)
+}
+
+func (rep *PostgresProjectRepository) GetAll(ctx context.Context, userId string) ([]domain.Project, error) {
+	intUserID, err := strconv.Atoi(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf("SELECT * FROM %s WHERE user_id = $1 ORDER BY created_at, id", projectsTable)
+
+	projects := make([]domain.Project, 0)
+	err = rep.db.Select(&projects, query, intUserID)
+
+	return projects, err
+}
+
+func (rep *PostgresProjectRepository) Create(ctx context.Context, userId string, in domain.CreateProjectInput) (domain.Project, error) {
+	intUserID, err := strconv.Atoi(userId)
+	if err != nil {
+		return domain.Project{}, err
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (name, user_id, created_at, updated_at) VALUES ($1, $2, $3, $4) RETURNING id", projectsTable)
+
+	now := time.Now().Format(time.RFC3339)
+	row := rep.db.QueryRow(query, in.Name, intUserID, now, now)
+
+	var id int
+	if err := row.Scan(&id); err != nil {
+		return domain.Project{}, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s WHERE id = $1 AND user_id = $2", projectsTable)
+
+	var project domain.Project
+	err = rep.db.Get(&project, query, id, intUserID)
+
+	return project, err
+}
+
+func (rep *PostgresProjectRepository) Update(ctx context.Context, id, userId string, in domain.UpdateProjectInput) (domain.Project, error) {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return domain.Project{}, domain.ErrProjectNotFound
+	}
+
+	intUserID, err := strconv.Atoi(userId)
+	if err != nil {
+		return domain.Project{}, err
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET name = $1, updated_at = $2 WHERE id = $3 AND user_id = $4", projectsTable)
+	now := time.Now().Format(time.RFC3339)
+	_, err = rep.db.Exec(query, in.Name, now, intID, intUserID)
+	if err != nil {
+		return domain.Project{}, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s WHERE id = $1 AND user_id = $2", projectsTable)
+
+	var project domain.Project
+	err = rep.db.Get(&project, query, intID, intUserID)
+
+	return project, notFound(err, domain.ErrProjectNotFound)
+}
+
+// Delete removes the project and, depending on mode, moves its tasks with their subtasks
+// to the trash or to the inbox. Both steps run in one transaction.
+func (rep *PostgresProjectRepository) Delete(ctx context.Context, id, userId string, mode domain.ProjectDeleteMode) error {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return domain.ErrProjectNotFound
+	}
+
+	intUserID, err := strconv.Atoi(userId)
+	if err != nil {
+		return err
+	}
+
+	tx, err := rep.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	if mode == domain.ProjectDeleteCascade {
+		if _, err := tx.Exec(trashTasksQuery("project_id = $1"), intID, intUserID, time.Now().UTC()); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	// The tasks, trashed ones included, are left in the inbox after the tasks already there.
+	inboxQuery := fmt.Sprintf(
+		"WITH inbox AS (SELECT COALESCE(MAX(position), 0) AS last FROM %[1]s WHERE user_id = $2 AND project_id IS NULL), "+
+			"moved AS (SELECT id, row_number() OVER (ORDER BY position, id) AS rn FROM %[1]s WHERE project_id = $1 AND user_id = $2) "+
+			"UPDATE %[1]s SET project_id = NULL, position = inbox.last + moved.rn * $3 FROM inbox, moved WHERE %[1]s.id = moved.id",
+		tasksTable,
+	)
+	if _, err := tx.Exec(inboxQuery, intID, intUserID, domain.TaskPositionGap); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1 AND user_id = $2", projectsTable)
+	result, err := tx.Exec(query, intID, intUserID)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		_ = tx.Rollback()
+		if err != nil {
+			return err
+		}
+		return domain.ErrProjectNotFound
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,65 @@
+package postgresrep
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"strconv"
+	"time"
+)
+
+type PostgresRevocationRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresRevocationRepository(db *sqlx.DB) *PostgresRevocationRepository {
+	return &PostgresRevocationRepository{db: db}
+}
+
+// Revoke stores the token id until the token expires. Ids of tokens that have expired in the
+// meantime are no longer needed and are dropped on the way.
+func (rep *PostgresRevocationRepository) Revoke(ctx context.Context, tokenId string, expiresAt time.Time) error {
+	query := fmt.Sprintf(
+		"INSERT INTO %s (token_id, expires_at) VALUES ($1, $2) ON CONFLICT (token_id) DO NOTHING",
+		revokedTokensTable,
+	)
+	if _, err := rep.db.Exec(query, tokenId, expiresAt.UTC()); err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf("DELETE FROM %s WHERE expires_at < $1", revokedTokensTable)
+	_, err := rep.db.Exec(query, time.Now().UTC())
+	return err
+}
+
+func (rep *PostgresRevocationRepository) RevokeUser(ctx context.Context, userId string, before time.Time) error {
+	intUserID, err := strconv.Atoi(userId)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(
+		"INSERT INTO %[1]s (user_id, revoked_before) VALUES ($1, $2) "+
+			"ON CONFLICT (user_id) DO UPDATE SET revoked_before = GREATEST(%[1]s.revoked_before, EXCLUDED.revoked_before)",
+		userRevocationsTable,
+	)
+	_, err = rep.db.Exec(query, intUserID, before.UTC())
+	return err
+}
+
+func (rep *PostgresRevocationRepository) IsRevoked(ctx context.Context, tokenId, userId string, issuedAt time.Time) (bool, error) {
+	intUserID, err := strconv.Atoi(userId)
+	if err != nil {
+		return false, err
+	}
+
+	query := fmt.Sprintf(
+		"SELECT EXISTS (SELECT 1 FROM %s WHERE token_id = $1) "+
+			"OR EXISTS (SELECT 1 FROM %s WHERE user_id = $2 AND revoked_before > $3)",
+		revokedTokensTable, userRevocationsTable,
+	)
+
+	var revoked bool
+	err = rep.db.Get(&revoked, query, tokenId, intUserID, issuedAt.UTC())
+	return revoked, err
+}
@@ -0,0 +1,91 @@
+package postgresrep
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"strconv"
+	"time"
+)
+
+type PostgresSessionRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresSessionRepository(db *sqlx.DB) *PostgresSessionRepository {
+	return &PostgresSessionRepository{db: db}
+}
+
+func (rep *PostgresSessionRepository) Create(ctx context.Context, in domain.CreateSessionInput) (domain.Session, error) {
+	intUserID, err := strconv.Atoi(in.UserId)
+	if err != nil {
+		return domain.Session{}, err
+	}
+
+	query := fmt.Sprintf(
+		"INSERT INTO %s (user_id, family_id, token_hash, expires_at, created_at) VALUES ($1, $2, $3, $4, $5) RETURNING id",
+		sessionsTable,
+	)
+
+	now := time.Now().Format(time.RFC3339)
+	row := rep.db.QueryRow(query, intUserID, in.FamilyId, in.TokenHash, in.ExpiresAt.UTC(), now)
+
+	var id int
+	if err := row.Scan(&id); err != nil {
+		return domain.Session{}, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s WHERE id = $1", sessionsTable)
+	var session domain.Session
+	err = rep.db.Get(&session, query, id)
+	return session, err
+}
+
+func (rep *PostgresSessionRepository) GetByTokenHash(ctx context.Context, tokenHash string) (domain.Session, error) {
+	query := fmt.Sprintf("SELECT * FROM %s WHERE token_hash = $1", sessionsTable)
+
+	var session domain.Session
+	err := rep.db.Get(&session, query, tokenHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Session{}, domain.ErrSessionNotFound
+	}
+
+	return session, err
+}
+
+// Revoke marks the session as used and tells whether it was still active before the call.
+func (rep *PostgresSessionRepository) Revoke(ctx context.Context, id string) (bool, error) {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return false, err
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL", sessionsTable)
+	result, err := rep.db.Exec(query, time.Now().UTC(), intID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+func (rep *PostgresSessionRepository) RevokeFamily(ctx context.Context, familyId string) error {
+	query := fmt.Sprintf("UPDATE %s SET revoked_at = $1 WHERE family_id = $2 AND revoked_at IS NULL", sessionsTable)
+	_, err := rep.db.Exec(query, time.Now().UTC(), familyId)
+	return err
+}
+
+func (rep *PostgresSessionRepository) RevokeUser(ctx context.Context, userId string) error {
+	intUserID, err := strconv.Atoi(userId)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL", sessionsTable)
+	_, err = rep.db.Exec(query, time.Now().UTC(), intUserID)
+	return err
+}
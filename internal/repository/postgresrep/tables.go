@@ -1,6 +1,22 @@
 package postgresrep
 
+import "fmt"
+
 var (
-	usersTable = "users"
-	tasksTable = "tasks"
-)
\ No newline at end of file
+	usersTable           = "users"
+	tasksTable           = "tasks"
+	projectsTable        = "projects"
+	labelsTable          = "labels"
+	taskLabelsTable      = "task_labels"
+	sessionsTable        = "sessions"
+	revokedTokensTable   = "revoked_tokens"
+	userRevocationsTable = "user_token_revocations"
+	accessTokensTable    = "access_tokens"
+	loginAttemptsTable   = "login_attempts"
+)
+
+// selectTasks reads task rows together with the ids of their labels.
+var selectTasks = fmt.Sprintf(
+	"SELECT %[1]s.*, ARRAY(SELECT label_id::text FROM %[2]s WHERE task_id = %[1]s.id ORDER BY label_id) AS label_ids FROM %[1]s",
+	tasksTable, taskLabelsTable,
+)
@@ -2,10 +2,14 @@ package postgresrep
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/i-vasilkov/go-todo-app/internal/domain"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -20,7 +24,7 @@ func NewPostgresTaskRepository(db *sqlx.DB) *PostgresTaskRepository {
 func (rep *PostgresTaskRepository) Get(ctx context.Context, id, userId string) (domain.Task, error) {
 	intID, err := strconv.Atoi(id)
 	if err != nil {
-		return domain.Task{}, err
+		return domain.Task{}, domain.ErrTaskNotFound
 	}
 
 	intUserID, err := strconv.Atoi(userId)
@@ -28,26 +32,40 @@ func (rep *PostgresTaskRepository) Get(ctx context.Context, id, userId string) (
 		return domain.Task{}, err
 	}
 
-	query := fmt.Sprintf("SELECT * FROM %s WHERE id = $1 AND user_id = $2", tasksTable)
-
-	var task domain.Task
-	err = rep.db.Get(&task, query, intID, intUserID)
-
-	return task, err
+	return rep.getTask(intID, intUserID)
 }
 
-func (rep *PostgresTaskRepository) GetAll(ctx context.Context, userId string) ([]domain.Task, error) {
+func (rep *PostgresTaskRepository) GetAll(ctx context.Context, userId string, filter domain.TaskFilter) (domain.TaskPage, error) {
 	intUserID, err := strconv.Atoi(userId)
 	if err != nil {
-		return nil, err
+		return domain.TaskPage{}, err
+	}
+
+	filter = filter.Normalize()
+	cursor, err := filter.DecodeCursor()
+	if err != nil {
+		return domain.TaskPage{}, err
+	}
+
+	q := newTaskQuery(intUserID)
+	if err := q.applyFilter(filter, cursor); err != nil {
+		return domain.TaskPage{}, err
 	}
 
-	query := fmt.Sprintf("SELECT * FROM %s WHERE user_id = $1", tasksTable)
+	query := fmt.Sprintf("%s WHERE %s ORDER BY %s LIMIT %d", selectTasks, q.where(), q.orderBy(filter), filter.Limit+1)
+
+	tasks, err := rep.selectTasks(query, q.args...)
+	if err != nil {
+		return domain.TaskPage{}, err
+	}
 
-	tasks := make([]domain.Task, 0)
-	err = rep.db.Select(&tasks, query, intUserID)
+	page := domain.TaskPage{Items: tasks}
+	if len(tasks) > filter.Limit {
+		page.Items = tasks[:filter.Limit]
+		page.NextCursor = domain.NewTaskCursor(filter, page.Items[filter.Limit-1])
+	}
 
-	return tasks, err
+	return page, nil
 }
 
 func (rep *PostgresTaskRepository) Create(ctx context.Context, userId string, in domain.CreateTaskInput) (domain.Task, error) {
@@ -56,63 +74,523 @@ func (rep *PostgresTaskRepository) Create(ctx context.Context, userId string, in
 		return domain.Task{}, err
 	}
 
-	query := fmt.Sprintf("INSERT INTO %s (name, user_id, created_at, updated_at) VALUES ($1, $2, $3, $4) RETURNING id", tasksTable)
+	intProjectID, err := optionalID(in.ProjectId)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	intParentID, err := optionalID(in.ParentId)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	query := fmt.Sprintf(
+		"INSERT INTO %[1]s (name, description, user_id, project_id, parent_id, status, priority, position, start_at, due_at, recurrence, created_at, updated_at) "+
+			"VALUES ($1, $2, $3, $4, $5, $6, $7, (SELECT COALESCE(MAX(position), 0) + $8 FROM %[1]s WHERE user_id = $3 AND project_id IS NOT DISTINCT FROM $4), $9, $10, $11, $12, $13) "+
+			"RETURNING id",
+		tasksTable,
+	)
 
 	now := time.Now().Format(time.RFC3339)
-	row := rep.db.QueryRow(query, in.Name, intUserID, now, now)
+	row := rep.db.QueryRow(
+		query, in.Name, in.Description, intUserID, intProjectID, intParentID, domain.TaskStatusTodo, in.Priority.OrDefault(), domain.TaskPositionGap,
+		utc(in.StartAt), utc(in.DueAt), in.Recurrence, now, now,
+	)
 
 	var id int
 	if err := row.Scan(&id); err != nil {
 		return domain.Task{}, err
 	}
 
-	query = fmt.Sprintf("SELECT * FROM %s WHERE id = $1 AND user_id = $2", tasksTable)
+	return rep.getTask(id, intUserID)
+}
+
+func (rep *PostgresTaskRepository) Update(ctx context.Context, id, userId string, in domain.UpdateTaskInput) (domain.Task, error) {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return domain.Task{}, domain.ErrTaskNotFound
+	}
 
-	var task domain.Task
-	err = rep.db.Get(&task, query, id, intUserID)
+	intUserID, err := strconv.Atoi(userId)
+	if err != nil {
+		return domain.Task{}, err
+	}
 
-	return task, err
+	intProjectID, err := optionalID(in.ProjectId)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	intParentID, err := optionalID(in.ParentId)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	// A task moved to another project goes to the end of its list, positions are per project.
+	query := fmt.Sprintf(
+		"UPDATE %[1]s SET name = $1, description = $2, project_id = $3, parent_id = $4, priority = $5, start_at = $6, due_at = $7, "+
+			"recurrence = $8, updated_at = $9, position = CASE WHEN project_id IS NOT DISTINCT FROM $3 THEN position "+
+			"ELSE (SELECT COALESCE(MAX(position), 0) + $12 FROM %[1]s WHERE user_id = $11 AND project_id IS NOT DISTINCT FROM $3) END "+
+			"WHERE id = $10 AND user_id = $11 AND deleted_at IS NULL",
+		tasksTable,
+	)
+	now := time.Now().Format(time.RFC3339)
+	result, err := rep.db.Exec(
+		query, in.Name, in.Description, intProjectID, intParentID, in.Priority.OrDefault(), utc(in.StartAt), utc(in.DueAt), in.Recurrence,
+		now, intID, intUserID, domain.TaskPositionGap,
+	)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if updated == 0 {
+		return domain.Task{}, domain.ErrTaskNotFound
+	}
+
+	return rep.getTask(intID, intUserID)
 }
 
-func (rep *PostgresTaskRepository) Update(ctx context.Context, id, userId string, in domain.UpdateTaskInput) (domain.Task, error) {
+func (rep *PostgresTaskRepository) Delete(ctx context.Context, id, userId string) error {
 	intID, err := strconv.Atoi(id)
 	if err != nil {
+		return domain.ErrTaskNotFound
+	}
+
+	intUserID, err := strconv.Atoi(userId)
+	if err != nil {
+		return err
+	}
+
+	result, err := rep.db.Exec(trashTasksQuery("id = $1"), intID, intUserID, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrTaskNotFound
+	}
+
+	return nil
+}
+
+// trashTasksQuery moves the tasks of user $2 that match the condition to the trash at $3,
+// together with their subtasks that are not trashed yet.
+func trashTasksQuery(condition string) string {
+	return fmt.Sprintf(
+		"WITH RECURSIVE subtree AS ("+
+			"SELECT id FROM %[1]s WHERE %[2]s AND user_id = $2 AND deleted_at IS NULL "+
+			"UNION SELECT t.id FROM %[1]s t JOIN subtree s ON t.parent_id = s.id WHERE t.deleted_at IS NULL"+
+			") UPDATE %[1]s SET deleted_at = $3 WHERE id IN (SELECT id FROM subtree)",
+		tasksTable, condition,
+	)
+}
+
+// GetTrash returns the trashed tasks of the user, most recently deleted first.
+func (rep *PostgresTaskRepository) GetTrash(ctx context.Context, userId string) ([]domain.Task, error) {
+	intUserID, err := strconv.Atoi(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	query := selectTasks + " WHERE user_id = $1 AND deleted_at IS NOT NULL ORDER BY deleted_at DESC, id DESC"
+
+	return rep.selectTasks(query, intUserID)
+}
+
+// Restore takes the task out of the trash together with the subtasks that were deleted
+// along with it. A subtask whose parent is still in the trash is restored to the top level.
+func (rep *PostgresTaskRepository) Restore(ctx context.Context, id, userId string) (domain.Task, error) {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return domain.Task{}, domain.ErrTaskNotFound
+	}
+
+	intUserID, err := strconv.Atoi(userId)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	tx, err := rep.db.Beginx()
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	query := fmt.Sprintf(
+		"UPDATE %[1]s SET parent_id = NULL WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL "+
+			"AND parent_id IN (SELECT id FROM %[1]s WHERE user_id = $2 AND deleted_at IS NOT NULL)",
+		tasksTable,
+	)
+	if _, err := tx.Exec(query, intID, intUserID); err != nil {
+		_ = tx.Rollback()
+		return domain.Task{}, err
+	}
+
+	query = fmt.Sprintf(
+		"WITH RECURSIVE subtree AS ("+
+			"SELECT id, deleted_at FROM %[1]s WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL "+
+			"UNION ALL SELECT t.id, t.deleted_at FROM %[1]s t JOIN subtree s ON t.parent_id = s.id WHERE t.deleted_at = s.deleted_at"+
+			") UPDATE %[1]s SET deleted_at = NULL WHERE id IN (SELECT id FROM subtree)",
+		tasksTable,
+	)
+	result, err := tx.Exec(query, intID, intUserID)
+	if err != nil {
+		_ = tx.Rollback()
+		return domain.Task{}, err
+	}
+
+	restored, err := result.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return domain.Task{}, err
+	}
+	if restored == 0 {
+		_ = tx.Rollback()
+		return domain.Task{}, domain.ErrTaskNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
 		return domain.Task{}, err
 	}
 
+	return rep.getTask(intID, intUserID)
+}
+
+// PurgeTrash permanently removes the tasks of all users that were trashed before the given
+// time. Their subtasks go with them through the cascading parent_id foreign key.
+func (rep *PostgresTaskRepository) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE deleted_at < $1", tasksTable)
+
+	result, err := rep.db.Exec(query, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// Count sums up the tasks of all users by status. Trashed tasks are only counted as such.
+func (rep *PostgresTaskRepository) Count(ctx context.Context) (domain.TaskCounts, error) {
+	query := fmt.Sprintf(
+		"SELECT CASE WHEN deleted_at IS NULL THEN status ELSE '' END AS status, COUNT(*) AS count "+
+			"FROM %s GROUP BY 1",
+		tasksTable,
+	)
+
+	var rows []struct {
+		Status domain.TaskStatus `db:"status"`
+		Count  int64             `db:"count"`
+	}
+	if err := rep.db.Select(&rows, query); err != nil {
+		return domain.TaskCounts{}, err
+	}
+
+	counts := domain.TaskCounts{ByStatus: make(map[domain.TaskStatus]int64)}
+	for _, row := range rows {
+		if row.Status == "" {
+			counts.InTrash += row.Count
+			continue
+		}
+		counts.ByStatus[row.Status] += row.Count
+		counts.Total += row.Count
+	}
+
+	return counts, nil
+}
+
+func (rep *PostgresTaskRepository) UpdateStatus(
+	ctx context.Context, id, userId string, status domain.TaskStatus, completedAt *time.Time,
+) (domain.Task, error) {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return domain.Task{}, domain.ErrTaskNotFound
+	}
+
 	intUserID, err := strconv.Atoi(userId)
 	if err != nil {
 		return domain.Task{}, err
 	}
 
-	query := fmt.Sprintf("UPDATE %s SET name = $1, updated_at = $2 WHERE id = $3 AND user_id = $4", tasksTable)
+	query := fmt.Sprintf(
+		"UPDATE %s SET status = $1, completed_at = $2, updated_at = $3 WHERE id = $4 AND user_id = $5 AND deleted_at IS NULL",
+		tasksTable,
+	)
 	now := time.Now().Format(time.RFC3339)
-	_, err = rep.db.Exec(query, in.Name, now, intID, intUserID)
+	_, err = rep.db.Exec(query, status, utc(completedAt), now, intID, intUserID)
 	if err != nil {
 		return domain.Task{}, err
 	}
 
-	query = fmt.Sprintf("SELECT * FROM %s WHERE id = $1 AND user_id = $2", tasksTable)
+	return rep.getTask(intID, intUserID)
+}
 
-	var task domain.Task
-	err = rep.db.Get(&task, query, id, intUserID)
+func (rep *PostgresTaskRepository) GetOverdue(ctx context.Context, userId string, now time.Time) ([]domain.Task, error) {
+	intUserID, err := strconv.Atoi(userId)
+	if err != nil {
+		return nil, err
+	}
 
-	return task, err
+	query := selectTasks + " WHERE user_id = $1 AND deleted_at IS NULL AND due_at < $2 AND status NOT IN ($3, $4) ORDER BY due_at"
+
+	return rep.selectTasks(query, intUserID, now.UTC(), domain.TaskStatusDone, domain.TaskStatusCancelled)
 }
 
-func (rep *PostgresTaskRepository) Delete(ctx context.Context, id, userId string) error {
+func (rep *PostgresTaskRepository) GetDueBetween(ctx context.Context, userId string, from, to time.Time) ([]domain.Task, error) {
+	intUserID, err := strconv.Atoi(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	query := selectTasks + " WHERE user_id = $1 AND deleted_at IS NULL AND due_at >= $2 AND due_at < $3 ORDER BY due_at"
+
+	return rep.selectTasks(query, intUserID, from.UTC(), to.UTC())
+}
+
+func (rep *PostgresTaskRepository) AttachLabels(ctx context.Context, id, userId string, labelIds []string) (domain.Task, error) {
 	intID, err := strconv.Atoi(id)
 	if err != nil {
-		return err
+		return domain.Task{}, domain.ErrTaskNotFound
+	}
+
+	intUserID, err := strconv.Atoi(userId)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	tx, err := rep.db.Beginx()
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	// The select from tasks and labels keeps both sides of the link owned by the user.
+	query := fmt.Sprintf(
+		"INSERT INTO %s (task_id, label_id) SELECT t.id, l.id FROM %s t, %s l "+
+			"WHERE t.id = $1 AND t.user_id = $2 AND t.deleted_at IS NULL AND l.id = $3 AND l.user_id = $2 ON CONFLICT DO NOTHING",
+		taskLabelsTable, tasksTable, labelsTable,
+	)
+	for _, labelId := range labelIds {
+		intLabelID, err := strconv.Atoi(labelId)
+		if err != nil {
+			_ = tx.Rollback()
+			return domain.Task{}, domain.ErrLabelNotFound
+		}
+
+		if _, err := tx.Exec(query, intID, intUserID, intLabelID); err != nil {
+			_ = tx.Rollback()
+			return domain.Task{}, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domain.Task{}, err
+	}
+
+	return rep.getTask(intID, intUserID)
+}
+
+func (rep *PostgresTaskRepository) DetachLabels(ctx context.Context, id, userId string, labelIds []string) (domain.Task, error) {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return domain.Task{}, domain.ErrTaskNotFound
+	}
+
+	intUserID, err := strconv.Atoi(userId)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	q := &taskQuery{}
+	placeholders := make([]string, 0, len(labelIds))
+	for _, labelId := range labelIds {
+		intLabelID, err := strconv.Atoi(labelId)
+		if err != nil {
+			return domain.Task{}, domain.ErrLabelNotFound
+		}
+		placeholders = append(placeholders, q.arg(intLabelID))
+	}
+
+	query := fmt.Sprintf(
+		"DELETE FROM %s WHERE task_id IN (SELECT id FROM %s WHERE id = %s AND user_id = %s AND deleted_at IS NULL) AND label_id IN (%s)",
+		taskLabelsTable, tasksTable, q.arg(intID), q.arg(intUserID), strings.Join(placeholders, ", "),
+	)
+	if _, err := rep.db.Exec(query, q.args...); err != nil {
+		return domain.Task{}, err
+	}
+
+	return rep.getTask(intID, intUserID)
+}
+
+// GetSubtasks returns the direct subtasks of all given parents in creation order.
+func (rep *PostgresTaskRepository) GetSubtasks(ctx context.Context, userId string, parentIds []string) ([]domain.Task, error) {
+	intUserID, err := strconv.Atoi(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	intParentIDs := make(pq.Int64Array, 0, len(parentIds))
+	for _, parentId := range parentIds {
+		intParentID, err := strconv.ParseInt(parentId, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		intParentIDs = append(intParentIDs, intParentID)
+	}
+
+	query := selectTasks + " WHERE user_id = $1 AND deleted_at IS NULL AND parent_id = ANY($2) ORDER BY created_at, id"
+
+	return rep.selectTasks(query, intUserID, intParentIDs)
+}
+
+func (rep *PostgresTaskRepository) UpdatePosition(ctx context.Context, id, userId string, position float64) (domain.Task, error) {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return domain.Task{}, domain.ErrTaskNotFound
 	}
 
 	intUserID, err := strconv.Atoi(userId)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET position = $1, updated_at = $2 WHERE id = $3 AND user_id = $4 AND deleted_at IS NULL", tasksTable)
+	now := time.Now().Format(time.RFC3339)
+	_, err = rep.db.Exec(query, position, now, intID, intUserID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	return rep.getTask(intID, intUserID)
+}
+
+// GetAdjacentPosition returns the position of the task listed right after (next) or before the
+// given one in its project, or nil when there is no such task. Tasks at the same position are
+// listed by id, so their neighbours are found too.
+func (rep *PostgresTaskRepository) GetAdjacentPosition(
+	ctx context.Context, userId string, task domain.Task, next bool,
+) (*float64, error) {
+	intUserID, err := strconv.Atoi(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	intID, err := strconv.Atoi(task.Id)
+	if err != nil {
+		return nil, domain.ErrTaskNotFound
+	}
+
+	intProjectID, err := optionalID(task.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+
+	op, dir := "<", "DESC"
+	if next {
+		op, dir = ">", "ASC"
+	}
+	query := fmt.Sprintf(
+		"SELECT position FROM %s WHERE user_id = $1 AND project_id IS NOT DISTINCT FROM $2 AND deleted_at IS NULL "+
+			"AND (position, id) %s ($3, $4) ORDER BY position %s, id %s LIMIT 1",
+		tasksTable, op, dir, dir,
+	)
+
+	var adjacent float64
+	err = rep.db.Get(&adjacent, query, intUserID, intProjectID, task.Position, intID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &adjacent, nil
+}
+
+// RebalancePositions spreads the positions of the project tasks evenly again, keeping their order.
+// Trashed tasks keep their positions.
+func (rep *PostgresTaskRepository) RebalancePositions(ctx context.Context, userId string, projectId *string) error {
+	intUserID, err := strconv.Atoi(userId)
+	if err != nil {
+		return err
+	}
+
+	intProjectID, err := optionalID(projectId)
 	if err != nil {
 		return err
 	}
 
-	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1 AND user_id = $2", tasksTable)
+	query := fmt.Sprintf(
+		"UPDATE %[1]s SET position = ranked.rn * $1 "+
+			"FROM (SELECT id, row_number() OVER (ORDER BY position, id) AS rn FROM %[1]s "+
+			"WHERE user_id = $2 AND project_id IS NOT DISTINCT FROM $3 AND deleted_at IS NULL) AS ranked "+
+			"WHERE %[1]s.id = ranked.id",
+		tasksTable,
+	)
 
-	_, err = rep.db.Exec(query, intID, intUserID)
+	_, err = rep.db.Exec(query, domain.TaskPositionGap, intUserID, intProjectID)
 	return err
 }
+
+// taskRow adds the aggregated label ids, which have no column in the tasks table.
+type taskRow struct {
+	domain.Task
+	LabelIds pq.StringArray `db:"label_ids"`
+}
+
+func (r taskRow) toDomain() domain.Task {
+	task := r.Task
+	task.LabelIds = r.LabelIds
+	return task
+}
+
+func (rep *PostgresTaskRepository) getTask(id, userId int) (domain.Task, error) {
+	var row taskRow
+	if err := rep.db.Get(&row, selectTasks+" WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL", id, userId); err != nil {
+		return domain.Task{}, notFound(err, domain.ErrTaskNotFound)
+	}
+
+	return row.toDomain(), nil
+}
+
+func (rep *PostgresTaskRepository) selectTasks(query string, args ...interface{}) ([]domain.Task, error) {
+	rows := make([]taskRow, 0)
+	if err := rep.db.Select(&rows, query, args...); err != nil {
+		return nil, err
+	}
+
+	tasks := make([]domain.Task, 0, len(rows))
+	for _, row := range rows {
+		tasks = append(tasks, row.toDomain())
+	}
+	return tasks, nil
+}
+
+// utc normalizes optional timestamps before they are written into
+// "timestamp without time zone" columns, which silently drop the offset.
+func utc(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	u := t.UTC()
+	return &u
+}
+
+// optionalID converts an optional string id into the integer form used by the tables.
+func optionalID(id *string) (*int, error) {
+	if id == nil {
+		return nil, nil
+	}
+
+	intID, err := strconv.Atoi(*id)
+	if err != nil {
+		return nil, err
+	}
+	return &intID, nil
+}
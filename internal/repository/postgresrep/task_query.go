@@ -0,0 +1,174 @@
+package postgresrep
+
+import (
+	"fmt"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"strconv"
+	"strings"
+)
+
+// taskSortColumns whitelists the columns a task list may be ordered by
+// together with the type the cursor value has to be cast to.
+var taskSortColumns = map[domain.TaskSortField]struct{ column, cast string }{
+	domain.TaskSortPosition:  {"position", "double precision"},
+	domain.TaskSortPriority:  {"priority", "smallint"},
+	domain.TaskSortCreatedAt: {"created_at", "timestamp"},
+	domain.TaskSortUpdatedAt: {"updated_at", "timestamp"},
+	domain.TaskSortDueAt:     {"due_at", "timestamp"},
+	domain.TaskSortName:      {"name", "varchar"},
+}
+
+// taskQuery collects WHERE conditions and their positional arguments.
+type taskQuery struct {
+	conditions []string
+	args       []interface{}
+}
+
+// newTaskQuery starts a query over the tasks of the user that are not in the trash.
+func newTaskQuery(userId int) *taskQuery {
+	q := &taskQuery{}
+	q.add("user_id = %s", userId)
+	q.conditions = append(q.conditions, "deleted_at IS NULL")
+	return q
+}
+
+// arg registers a query argument and returns its placeholder.
+func (q *taskQuery) arg(value interface{}) string {
+	q.args = append(q.args, value)
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+func (q *taskQuery) add(condition string, values ...interface{}) {
+	placeholders := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		placeholders = append(placeholders, q.arg(value))
+	}
+	q.conditions = append(q.conditions, fmt.Sprintf(condition, placeholders...))
+}
+
+func (q *taskQuery) where() string {
+	return strings.Join(q.conditions, " AND ")
+}
+
+func (q *taskQuery) applyFilter(filter domain.TaskFilter, cursor *domain.TaskCursor) error {
+	if len(filter.Status) > 0 {
+		placeholders := make([]string, 0, len(filter.Status))
+		for _, status := range filter.Status {
+			placeholders = append(placeholders, q.arg(status))
+		}
+		q.conditions = append(q.conditions, fmt.Sprintf("status IN (%s)", strings.Join(placeholders, ", ")))
+	}
+	if filter.DueFrom != nil {
+		q.add("due_at >= %s", filter.DueFrom.UTC())
+	}
+	if filter.DueTo != nil {
+		q.add("due_at < %s", filter.DueTo.UTC())
+	}
+	if filter.CreatedFrom != nil {
+		q.add("created_at >= %s", filter.CreatedFrom.UTC())
+	}
+	if filter.CreatedTo != nil {
+		q.add("created_at < %s", filter.CreatedTo.UTC())
+	}
+	if filter.Name != "" {
+		q.add("name ILIKE %s", "%"+escapeLike(filter.Name)+"%")
+	}
+	if filter.ProjectId == domain.InboxProjectId {
+		q.conditions = append(q.conditions, "project_id IS NULL")
+	} else if filter.ProjectId != "" {
+		projectId, err := strconv.Atoi(filter.ProjectId)
+		if err != nil {
+			return domain.ErrInvalidProjectFilter
+		}
+		q.add("project_id = %s", projectId)
+	}
+	if len(filter.Labels) > 0 {
+		if err := q.applyLabels(filter); err != nil {
+			return err
+		}
+	}
+
+	if cursor == nil {
+		return nil
+	}
+	return q.applyCursor(filter, cursor)
+}
+
+// applyLabels keeps tasks linked to any of the filtered labels or, for LabelMatchAll,
+// to every one of them.
+func (q *taskQuery) applyLabels(filter domain.TaskFilter) error {
+	placeholders := make([]string, 0, len(filter.Labels))
+	for _, label := range filter.Labels {
+		labelId, err := strconv.Atoi(label)
+		if err != nil {
+			return domain.ErrInvalidLabelFilter
+		}
+		placeholders = append(placeholders, q.arg(labelId))
+	}
+
+	subquery := fmt.Sprintf("SELECT task_id FROM %s WHERE label_id IN (%s)", taskLabelsTable, strings.Join(placeholders, ", "))
+	if filter.LabelMatch == domain.LabelMatchAll {
+		subquery += fmt.Sprintf(" GROUP BY task_id HAVING COUNT(DISTINCT label_id) = %s", q.arg(len(uniqueStrings(filter.Labels))))
+	}
+	q.conditions = append(q.conditions, fmt.Sprintf("id IN (%s)", subquery))
+
+	return nil
+}
+
+// applyCursor continues the keyset right after the cursor position. Tasks without
+// a due date are always listed last, so due_at needs explicit NULL handling.
+func (q *taskQuery) applyCursor(filter domain.TaskFilter, cursor *domain.TaskCursor) error {
+	id, err := strconv.Atoi(cursor.Id)
+	if err != nil {
+		return domain.ErrInvalidCursor
+	}
+
+	sort := taskSortColumns[filter.SortBy]
+	op := ">"
+	if filter.SortDir == domain.SortDesc {
+		op = "<"
+	}
+
+	if cursor.Value == nil {
+		if filter.SortBy != domain.TaskSortDueAt {
+			return domain.ErrInvalidCursor
+		}
+		q.add(fmt.Sprintf("(due_at IS NULL AND id %s %%s)", op), id)
+		return nil
+	}
+
+	rowCondition := fmt.Sprintf("(%s, id) %s (%%s::%s, %%s)", sort.column, op, sort.cast)
+	if filter.SortBy == domain.TaskSortDueAt {
+		rowCondition = "(due_at IS NULL OR " + rowCondition + ")"
+	}
+	q.add(rowCondition, *cursor.Value, id)
+
+	return nil
+}
+
+func (q *taskQuery) orderBy(filter domain.TaskFilter) string {
+	sort := taskSortColumns[filter.SortBy]
+	dir := "ASC"
+	if filter.SortDir == domain.SortDesc {
+		dir = "DESC"
+	}
+
+	return fmt.Sprintf("%s %s NULLS LAST, id %s", sort.column, dir, dir)
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		seen[value] = struct{}{}
+		unique = append(unique, value)
+	}
+	return unique
+}
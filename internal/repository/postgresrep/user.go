@@ -2,13 +2,21 @@ package postgresrep
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/i-vasilkov/go-todo-app/internal/domain"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"strconv"
+	"strings"
 	"time"
 )
 
+// userColumns leaves out the hashed recovery codes, they are only ever matched in the database.
+const userColumns = "id, login, password, COALESCE(email, '') AS email, email_verified, " +
+	"totp_secret, totp_enabled, roles, disabled, created_at"
+
 type PostgresUserRepository struct {
 	db *sqlx.DB
 }
@@ -18,37 +26,283 @@ func NewPostgresUserRepository(db *sqlx.DB) *PostgresUserRepository {
 }
 
 func (rep *PostgresUserRepository) Create(ctx context.Context, in domain.CreateUserInput) (domain.User, error) {
-	query := fmt.Sprintf("INSERT INTO %s (login, password, created_at) VALUES ($1, $2, $3) RETURNING id", usersTable)
+	query := fmt.Sprintf(
+		"INSERT INTO %s (login, email, password, created_at) VALUES ($1, NULLIF($2, ''), $3, $4) RETURNING id",
+		usersTable,
+	)
 
 	now := time.Now().Format(time.RFC3339)
-	row := rep.db.QueryRow(query, in.Login, in.Password, now)
+	row := rep.db.QueryRow(query, in.Login, in.Email, in.Password, now)
 
 	var id int
 	if err := row.Scan(&id); err != nil {
-		return domain.User{}, err
+		return domain.User{}, userConflict(err)
 	}
 
-	query = fmt.Sprintf("SELECT * FROM %s WHERE id = $1", usersTable)
-	var user domain.User
-	err := rep.db.Get(&user, query, id)
-	return user, err
+	query = fmt.Sprintf("SELECT %s FROM %s WHERE id = $1", userColumns, usersTable)
+	return rep.getUser(query, id)
+}
+
+func (rep *PostgresUserRepository) GetByLogin(ctx context.Context, login string) (domain.User, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE login = $1", userColumns, usersTable)
+	user, err := rep.getUser(query, login)
+	return user, notFound(err, domain.ErrUserNotFound)
 }
 
-func (rep *PostgresUserRepository) GetByCredentials(ctx context.Context, in domain.LoginUserInput) (domain.User, error) {
-	query := fmt.Sprintf("SELECT * FROM %s WHERE login = $1 AND password = $2", usersTable)
-	var user domain.User
-	err := rep.db.Get(&user, query, in.Login, in.Password)
+func (rep *PostgresUserRepository) GetByEmail(ctx context.Context, email string) (domain.User, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE email = $1", userColumns, usersTable)
+	user, err := rep.getUser(query, email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.User{}, domain.ErrUserNotFound
+	}
 	return user, err
 }
 
 func (rep *PostgresUserRepository) Get(ctx context.Context, id string) (domain.User, error) {
 	intID, err := strconv.Atoi(id)
 	if err != nil {
-		return domain.User{}, err
+		return domain.User{}, domain.ErrUserNotFound
 	}
 
-	query := fmt.Sprintf("SELECT * FROM %s WHERE id = $1", usersTable)
-	var user domain.User
-	err = rep.db.Get(&user, query, intID)
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = $1", userColumns, usersTable)
+	user, err := rep.getUser(query, intID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.User{}, domain.ErrUserNotFound
+	}
 	return user, err
 }
+
+// GetAll pages through the users in the order they signed up.
+func (rep *PostgresUserRepository) GetAll(ctx context.Context, filter domain.UserFilter) ([]domain.User, error) {
+	filter = filter.Normalize()
+
+	query := fmt.Sprintf("SELECT %s FROM %s ORDER BY id LIMIT $1 OFFSET $2", userColumns, usersTable)
+
+	rows := make([]userRow, 0)
+	if err := rep.db.Select(&rows, query, filter.Limit, filter.Offset); err != nil {
+		return nil, err
+	}
+
+	users := make([]domain.User, 0, len(rows))
+	for _, row := range rows {
+		users = append(users, row.toDomain())
+	}
+
+	return users, nil
+}
+
+// Update sets the given fields only. Setting an email marks it as not verified.
+func (rep *PostgresUserRepository) Update(ctx context.Context, id string, in domain.UpdateUserInput) (domain.User, error) {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return domain.User{}, domain.ErrUserNotFound
+	}
+
+	setValues := make([]string, 0, 3)
+	args := make([]interface{}, 0, 3)
+
+	if in.Login != nil {
+		args = append(args, *in.Login)
+		setValues = append(setValues, fmt.Sprintf("login = $%d", len(args)))
+	}
+	if in.Email != nil {
+		args = append(args, *in.Email)
+		setValues = append(setValues, fmt.Sprintf("email = $%d", len(args)), "email_verified = false")
+	}
+
+	if len(setValues) > 0 {
+		args = append(args, intID)
+		query := fmt.Sprintf(
+			"UPDATE %s SET %s WHERE id = $%d",
+			usersTable, strings.Join(setValues, ", "), len(args),
+		)
+		if _, err := rep.db.Exec(query, args...); err != nil {
+			return domain.User{}, userConflict(err)
+		}
+	}
+
+	return rep.Get(ctx, id)
+}
+
+// Delete removes the user. The tasks, projects, labels, sessions and tokens of the user go
+// with it, by the cascading foreign keys of the same statement.
+func (rep *PostgresUserRepository) Delete(ctx context.Context, id string) error {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return domain.ErrUserNotFound
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", usersTable)
+	result, err := rep.db.Exec(query, intID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (rep *PostgresUserRepository) UpdatePassword(ctx context.Context, id, password string) error {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return domain.ErrUserNotFound
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET password = $1 WHERE id = $2", usersTable)
+	_, err = rep.db.Exec(query, password, intID)
+	return err
+}
+
+func (rep *PostgresUserRepository) SetEmailVerified(ctx context.Context, id string) error {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return domain.ErrUserNotFound
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET email_verified = true WHERE id = $1", usersTable)
+	_, err = rep.db.Exec(query, intID)
+	return err
+}
+
+func (rep *PostgresUserRepository) SetDisabled(ctx context.Context, id string, disabled bool) error {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return domain.ErrUserNotFound
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET disabled = $1 WHERE id = $2", usersTable)
+	return rep.execUpdate(query, disabled, intID)
+}
+
+func (rep *PostgresUserRepository) SetRoles(ctx context.Context, id string, roles []domain.Role) error {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return domain.ErrUserNotFound
+	}
+
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, string(role))
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET roles = $1 WHERE id = $2", usersTable)
+	return rep.execUpdate(query, pq.Array(names), intID)
+}
+
+func (rep *PostgresUserRepository) execUpdate(query string, args ...interface{}) error {
+	result, err := rep.db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (rep *PostgresUserRepository) UpdateTotp(ctx context.Context, id string, in domain.UpdateTotpInput) error {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return domain.ErrUserNotFound
+	}
+
+	recoveryCodes := in.RecoveryCodes
+	if recoveryCodes == nil {
+		recoveryCodes = []string{}
+	}
+
+	query := fmt.Sprintf(
+		"UPDATE %s SET totp_secret = $1, totp_enabled = $2, recovery_codes = $3 WHERE id = $4",
+		usersTable,
+	)
+	_, err = rep.db.Exec(query, in.Secret, in.Enabled, pq.Array(recoveryCodes), intID)
+	return err
+}
+
+// UseRecoveryCode removes the recovery code in the same statement that finds it, so a code can
+// not be used twice by concurrent requests.
+func (rep *PostgresUserRepository) UseRecoveryCode(ctx context.Context, id, codeHash string) (bool, error) {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return false, domain.ErrUserNotFound
+	}
+
+	query := fmt.Sprintf(
+		"UPDATE %s SET recovery_codes = array_remove(recovery_codes, $1) "+
+			"WHERE id = $2 AND $1 = ANY(recovery_codes)",
+		usersTable,
+	)
+	result, err := rep.db.Exec(query, codeHash, intID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+// UseTotpStep records the time step of an accepted code in the same statement that compares it,
+// so a code can not be used twice by concurrent requests.
+func (rep *PostgresUserRepository) UseTotpStep(ctx context.Context, id string, step int64) (bool, error) {
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return false, domain.ErrUserNotFound
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET totp_last_step = $1 WHERE id = $2 AND totp_last_step < $1", usersTable)
+	result, err := rep.db.Exec(query, step, intID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+func (rep *PostgresUserRepository) getUser(query string, args ...interface{}) (domain.User, error) {
+	var row userRow
+	if err := rep.db.Get(&row, query, args...); err != nil {
+		return domain.User{}, err
+	}
+
+	return row.toDomain(), nil
+}
+
+// userConflict tells which of the unique login and email is already taken.
+func userConflict(err error) error {
+	if isUniqueViolation(err, "users_email_idx") {
+		return domain.ErrEmailTaken
+	}
+	if isUniqueViolation(err, "users_login_key") {
+		return domain.ErrLoginTaken
+	}
+	return err
+}
+
+// userRow reads the roles array, which sqlx can not scan into the domain type.
+type userRow struct {
+	domain.User
+	Roles pq.StringArray `db:"roles"`
+}
+
+func (r userRow) toDomain() domain.User {
+	user := r.User
+	user.Roles = make([]domain.Role, 0, len(r.Roles))
+	for _, role := range r.Roles {
+		user.Roles = append(user.Roles, domain.Role(role))
+	}
+	return user
+}
@@ -0,0 +1,54 @@
+package reptest
+
+import (
+	"context"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"github.com/i-vasilkov/go-todo-app/internal/service"
+	"github.com/magiconair/properties/assert"
+	"testing"
+	"time"
+)
+
+func testAccessTokens(t *testing.T, reps *service.Repositories) {
+	ctx := context.Background()
+	owner := createUser(t, reps, "owner")
+	other := createUser(t, reps, "other")
+	start := time.Now()
+
+	in := domain.CreateAccessTokenInput{Name: "CI", Scopes: []domain.Scope{domain.ScopeTasksRead}}
+	first, err := reps.AccessToken.Create(ctx, owner.Id, "first", in)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, first.UserId, owner.Id)
+	assert.Equal(t, first.Scopes, []domain.Scope{domain.ScopeTasksRead})
+	assert.Equal(t, first.LastUsedAt, (*time.Time)(nil))
+	assertCreatedNow(t, "created_at", first.CreatedAt, start)
+	second, _ := reps.AccessToken.Create(ctx, owner.Id, "second", in)
+	_, _ = reps.AccessToken.Create(ctx, other.Id, "third", in)
+
+	// The newest token comes first.
+	tokens, err := reps.AccessToken.GetAll(ctx, owner.Id)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(tokens), 2)
+	assert.Equal(t, tokens[0].Id, second.Id)
+	assert.Equal(t, tokens[1].Id, first.Id)
+
+	token, err := reps.AccessToken.GetByTokenHash(ctx, "first")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, token.Id, first.Id)
+	_, err = reps.AccessToken.GetByTokenHash(ctx, "missing")
+	assert.Equal(t, err, domain.ErrAccessTokenNotFound)
+
+	usedAt := time.Now().UTC().Truncate(time.Second)
+	assert.Equal(t, reps.AccessToken.UpdateLastUsed(ctx, first.Id, usedAt), nil)
+	token, _ = reps.AccessToken.GetByTokenHash(ctx, "first")
+	assert.Equal(t, token.LastUsedAt.Equal(usedAt), true)
+
+	assert.Equal(t, reps.AccessToken.Delete(ctx, first.Id, other.Id), nil)
+	_, err = reps.AccessToken.GetByTokenHash(ctx, "first")
+	assert.Equal(t, err, nil)
+
+	assert.Equal(t, reps.AccessToken.Delete(ctx, first.Id, owner.Id), nil)
+	_, err = reps.AccessToken.GetByTokenHash(ctx, "first")
+	assert.Equal(t, err, domain.ErrAccessTokenNotFound)
+	assert.Equal(t, reps.AccessToken.Delete(ctx, first.Id, owner.Id), nil)
+}
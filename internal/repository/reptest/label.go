@@ -0,0 +1,59 @@
+package reptest
+
+import (
+	"context"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"github.com/i-vasilkov/go-todo-app/internal/service"
+	"github.com/magiconair/properties/assert"
+	"testing"
+	"time"
+)
+
+func testLabels(t *testing.T, reps *service.Repositories) {
+	ctx := context.Background()
+	owner := createUser(t, reps, "owner")
+	other := createUser(t, reps, "other")
+	start := time.Now()
+
+	work, err := reps.Label.Create(ctx, owner.Id, domain.CreateLabelInput{Name: "work", Color: "#ff0000"})
+	assert.Equal(t, err, nil)
+	assertCreatedNow(t, "created_at", work.CreatedAt, start)
+	home, _ := reps.Label.Create(ctx, owner.Id, domain.CreateLabelInput{Name: "home"})
+
+	// Names are unique per user only.
+	_, err = reps.Label.Create(ctx, owner.Id, domain.CreateLabelInput{Name: "work"})
+	assert.Equal(t, err, domain.ErrLabelTaken)
+	_, err = reps.Label.Create(ctx, other.Id, domain.CreateLabelInput{Name: "work"})
+	assert.Equal(t, err, nil)
+	_, err = reps.Label.Update(ctx, home.Id, owner.Id, domain.UpdateLabelInput{Name: "work"})
+	assert.Equal(t, err, domain.ErrLabelTaken)
+
+	labels, err := reps.Label.GetAll(ctx, owner.Id)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(labels), 2)
+	assert.Equal(t, labels[0].Id, home.Id)
+	assert.Equal(t, labels[1].Id, work.Id)
+
+	updated, err := reps.Label.Update(ctx, work.Id, owner.Id, domain.UpdateLabelInput{Name: "job", Color: "#00ff00"})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, updated.Name, "job")
+	assert.Equal(t, updated.Color, "#00ff00")
+
+	_, err = reps.Label.Get(ctx, work.Id, other.Id)
+	assert.Equal(t, err, domain.ErrLabelNotFound)
+	_, err = reps.Label.Update(ctx, work.Id, other.Id, domain.UpdateLabelInput{Name: "stolen"})
+	assert.Equal(t, err, domain.ErrLabelNotFound)
+	assert.Equal(t, reps.Label.Delete(ctx, work.Id, other.Id), nil)
+	_, err = reps.Label.Get(ctx, work.Id, owner.Id)
+	assert.Equal(t, err, nil)
+
+	task := createTask(t, reps, owner.Id, domain.CreateTaskInput{Name: "Task"})
+	_, err = reps.Task.AttachLabels(ctx, task.Id, owner.Id, []string{work.Id, home.Id})
+	assert.Equal(t, err, nil)
+
+	assert.Equal(t, reps.Label.Delete(ctx, work.Id, owner.Id), nil)
+	_, err = reps.Label.Get(ctx, work.Id, owner.Id)
+	assert.Equal(t, err, domain.ErrLabelNotFound)
+	task, _ = reps.Task.Get(ctx, task.Id, owner.Id)
+	assertIds(t, task.LabelIds, home.Id)
+}
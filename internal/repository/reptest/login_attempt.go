@@ -0,0 +1,51 @@
+package reptest
+
+import (
+	"context"
+	"github.com/i-vasilkov/go-todo-app/internal/service"
+	"github.com/magiconair/properties/assert"
+	"testing"
+	"time"
+)
+
+func testLoginAttempts(t *testing.T, reps *service.Repositories) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	attempts, err := reps.LoginAttempt.Get(ctx, "login:test")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, attempts.Key, "login:test")
+	assert.Equal(t, attempts.Failures, 0)
+
+	// The count starts over when the previous failure is older than the window.
+	attempts, err = reps.LoginAttempt.AddFailure(ctx, "login:test", now.Add(-2*time.Hour), time.Hour)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, attempts.Failures, 1)
+	attempts, _ = reps.LoginAttempt.AddFailure(ctx, "login:test", now.Add(-time.Minute), time.Hour)
+	assert.Equal(t, attempts.Failures, 1)
+	attempts, _ = reps.LoginAttempt.AddFailure(ctx, "login:test", now, time.Hour)
+	assert.Equal(t, attempts.Failures, 2)
+	assert.Equal(t, attempts.LastFailureAt.Equal(now), true)
+
+	attempts, _ = reps.LoginAttempt.Get(ctx, "login:test")
+	assert.Equal(t, attempts.Failures, 2)
+	attempts, _ = reps.LoginAttempt.Get(ctx, "ip:127.0.0.1")
+	assert.Equal(t, attempts.Failures, 0)
+
+	// A lock keeps the entry past the window.
+	until := now.Add(2 * time.Hour)
+	assert.Equal(t, reps.LoginAttempt.Lock(ctx, "login:test", until), nil)
+	attempts, _ = reps.LoginAttempt.Get(ctx, "login:test")
+	assert.Equal(t, attempts.LockedUntil != nil && attempts.LockedUntil.Equal(until), true)
+	assert.Equal(t, attempts.Failures, 2)
+
+	assert.Equal(t, reps.LoginAttempt.Reset(ctx, "login:test"), nil)
+	attempts, _ = reps.LoginAttempt.Get(ctx, "login:test")
+	assert.Equal(t, attempts.Failures, 0)
+	assert.Equal(t, attempts.LockedUntil == nil, true)
+
+	// Failures that left the window are not counted anymore.
+	_, _ = reps.LoginAttempt.AddFailure(ctx, "login:expired", now.Add(-2*time.Hour), time.Hour)
+	attempts, _ = reps.LoginAttempt.Get(ctx, "login:expired")
+	assert.Equal(t, attempts.Failures, 0)
+}
@@ -0,0 +1,79 @@
+package reptest
+
+import (
+	"context"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"github.com/i-vasilkov/go-todo-app/internal/service"
+	"github.com/magiconair/properties/assert"
+	"testing"
+	"time"
+)
+
+func testProjects(t *testing.T, reps *service.Repositories) {
+	ctx := context.Background()
+	owner := createUser(t, reps, "owner")
+	other := createUser(t, reps, "other")
+	start := time.Now()
+
+	home, err := reps.Project.Create(ctx, owner.Id, domain.CreateProjectInput{Name: "Home"})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, home.UserId, owner.Id)
+	assertCreatedNow(t, "created_at", home.CreatedAt, start)
+	work, _ := reps.Project.Create(ctx, owner.Id, domain.CreateProjectInput{Name: "Work"})
+	_, _ = reps.Project.Create(ctx, other.Id, domain.CreateProjectInput{Name: "Other"})
+
+	projects, err := reps.Project.GetAll(ctx, owner.Id)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(projects), 2)
+	assert.Equal(t, projects[0].Id, home.Id)
+	assert.Equal(t, projects[1].Id, work.Id)
+
+	updated, err := reps.Project.Update(ctx, home.Id, owner.Id, domain.UpdateProjectInput{Name: "House"})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, updated.Name, "House")
+	assert.Equal(t, updated.UpdatedAt.Before(home.UpdatedAt), false)
+
+	_, err = reps.Project.Get(ctx, home.Id, other.Id)
+	assert.Equal(t, err, domain.ErrProjectNotFound)
+	_, err = reps.Project.Update(ctx, home.Id, other.Id, domain.UpdateProjectInput{Name: "Stolen"})
+	assert.Equal(t, err, domain.ErrProjectNotFound)
+	assert.Equal(t, reps.Project.Delete(ctx, home.Id, other.Id, domain.ProjectDeleteCascade), domain.ErrProjectNotFound)
+	project, err := reps.Project.Get(ctx, home.Id, owner.Id)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, project.Name, "House")
+
+	inbox := createTask(t, reps, owner.Id, domain.CreateTaskInput{Name: "Inbox"})
+	kept := createTask(t, reps, owner.Id, domain.CreateTaskInput{Name: "Kept", ProjectId: &home.Id})
+	dropped := createTask(t, reps, owner.Id, domain.CreateTaskInput{Name: "Dropped", ProjectId: &work.Id})
+
+	assert.Equal(t, reps.Project.Delete(ctx, home.Id, owner.Id, domain.ProjectDeleteMoveToInbox), nil)
+	task, err := reps.Task.Get(ctx, kept.Id, owner.Id)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, task.ProjectId, (*string)(nil))
+	// The moved tasks go after the tasks already in the inbox, positions are per project.
+	assert.Equal(t, task.Position, inbox.Position+domain.TaskPositionGap)
+	_, err = reps.Project.Get(ctx, home.Id, owner.Id)
+	assert.Equal(t, err, domain.ErrProjectNotFound)
+
+	// Cascading moves the project tasks to the trash with all their subtasks, wherever those live.
+	subtask := createTask(t, reps, owner.Id, domain.CreateTaskInput{Name: "Subtask", ParentId: &dropped.Id})
+	assert.Equal(t, reps.Project.Delete(ctx, work.Id, owner.Id, domain.ProjectDeleteCascade), nil)
+	_, err = reps.Task.Get(ctx, dropped.Id, owner.Id)
+	assert.Equal(t, err, domain.ErrTaskNotFound)
+	_, err = reps.Task.Get(ctx, subtask.Id, owner.Id)
+	assert.Equal(t, err, domain.ErrTaskNotFound)
+	trash, err := reps.Task.GetTrash(ctx, owner.Id)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(trash), 2)
+
+	// Restored tasks of a deleted project land in the inbox.
+	restored, err := reps.Task.Restore(ctx, dropped.Id, owner.Id)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, restored.ProjectId, (*string)(nil))
+	_, err = reps.Task.Get(ctx, subtask.Id, owner.Id)
+	assert.Equal(t, err, nil)
+
+	_, err = reps.Project.Update(ctx, work.Id, owner.Id, domain.UpdateProjectInput{Name: "Missing"})
+	assert.Equal(t, err, domain.ErrProjectNotFound)
+	assert.Equal(t, reps.Project.Delete(ctx, work.Id, owner.Id, domain.ProjectDeleteCascade), domain.ErrProjectNotFound)
+}
@@ -0,0 +1,92 @@
+// Package reptest checks that a storage backend behaves the way the services expect from it, so
+// every backend can be run through the same contract: ownership, not found, timestamps and order.
+package reptest
+
+import (
+	"context"
+	"fmt"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"github.com/i-vasilkov/go-todo-app/internal/service"
+	"testing"
+	"time"
+)
+
+// Factory returns the repositories of a backend over an empty storage. It is called once for
+// every test of the suite.
+type Factory func(t *testing.T) *service.Repositories
+
+// Run runs the whole suite against the backend the factory builds.
+func Run(t *testing.T, newRepositories Factory) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, reps *service.Repositories)
+	}{
+		{name: "Users", run: testUsers},
+		{name: "Tasks", run: testTasks},
+		{name: "TaskList", run: testTaskList},
+		{name: "TaskTrash", run: testTaskTrash},
+		{name: "Projects", run: testProjects},
+		{name: "Labels", run: testLabels},
+		{name: "Sessions", run: testSessions},
+		{name: "AccessTokens", run: testAccessTokens},
+		{name: "Revocations", run: testRevocations},
+		{name: "LoginAttempts", run: testLoginAttempts},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newRepositories(t))
+		})
+	}
+}
+
+// createUser signs up a user with a login and email made unique by the name.
+func createUser(t *testing.T, reps *service.Repositories, name string) domain.User {
+	t.Helper()
+
+	user, err := reps.User.Create(context.Background(), domain.CreateUserInput{
+		Login:    name,
+		Email:    fmt.Sprintf("%s@example.com", name),
+		Password: "hash",
+	})
+	if err != nil {
+		t.Fatalf("creating user %q: %s", name, err)
+	}
+	return user
+}
+
+func createTask(t *testing.T, reps *service.Repositories, userId string, in domain.CreateTaskInput) domain.Task {
+	t.Helper()
+
+	task, err := reps.Task.Create(context.Background(), userId, in)
+	if err != nil {
+		t.Fatalf("creating task %q: %s", in.Name, err)
+	}
+	return task
+}
+
+// assertCreatedNow checks a timestamp is set by the backend at the time of the call. The
+// backends store whole seconds, so the lower bound is rounded down.
+func assertCreatedNow(t *testing.T, field string, value, start time.Time) {
+	t.Helper()
+
+	if value.Before(start.Truncate(time.Second)) || value.After(time.Now()) {
+		t.Errorf("%s = %s, want a time between %s and now", field, value, start)
+	}
+}
+
+func assertIds(t *testing.T, got []string, want ...string) {
+	t.Helper()
+
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("ids = %v, want %v", got, want)
+	}
+}
+
+func taskIds(tasks []domain.Task) []string {
+	ids := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.Id)
+	}
+	return ids
+}
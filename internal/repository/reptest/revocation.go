@@ -0,0 +1,45 @@
+package reptest
+
+import (
+	"context"
+	"github.com/i-vasilkov/go-todo-app/internal/service"
+	"github.com/magiconair/properties/assert"
+	"testing"
+	"time"
+)
+
+func testRevocations(t *testing.T, reps *service.Repositories) {
+	ctx := context.Background()
+	owner := createUser(t, reps, "owner")
+	other := createUser(t, reps, "other")
+	now := time.Now().UTC().Truncate(time.Second)
+
+	revoked, err := reps.Revocation.IsRevoked(ctx, "token", owner.Id, now)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, revoked, false)
+
+	assert.Equal(t, reps.Revocation.Revoke(ctx, "token", now.Add(time.Hour)), nil)
+	revoked, _ = reps.Revocation.IsRevoked(ctx, "token", owner.Id, now)
+	assert.Equal(t, revoked, true)
+	revoked, _ = reps.Revocation.IsRevoked(ctx, "another", owner.Id, now)
+	assert.Equal(t, revoked, false)
+
+	// Revoking a user rejects the tokens issued before the moment, of that user only.
+	assert.Equal(t, reps.Revocation.RevokeUser(ctx, owner.Id, now), nil)
+	revoked, _ = reps.Revocation.IsRevoked(ctx, "another", owner.Id, now.Add(-time.Minute))
+	assert.Equal(t, revoked, true)
+	revoked, _ = reps.Revocation.IsRevoked(ctx, "another", owner.Id, now)
+	assert.Equal(t, revoked, false)
+	revoked, _ = reps.Revocation.IsRevoked(ctx, "another", other.Id, now.Add(-time.Minute))
+	assert.Equal(t, revoked, false)
+
+	// An earlier moment does not move the revocation back.
+	assert.Equal(t, reps.Revocation.RevokeUser(ctx, owner.Id, now.Add(-time.Hour)), nil)
+	revoked, _ = reps.Revocation.IsRevoked(ctx, "another", owner.Id, now.Add(-time.Minute))
+	assert.Equal(t, revoked, true)
+
+	// The revocation outlives the user, the tokens of a deleted account stay rejected.
+	assert.Equal(t, reps.User.Delete(ctx, owner.Id), nil)
+	revoked, _ = reps.Revocation.IsRevoked(ctx, "another", owner.Id, now.Add(-time.Minute))
+	assert.Equal(t, revoked, true)
+}
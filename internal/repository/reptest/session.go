@@ -0,0 +1,49 @@
+package reptest
+
+import (
+	"context"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"github.com/i-vasilkov/go-todo-app/internal/service"
+	"github.com/magiconair/properties/assert"
+	"testing"
+	"time"
+)
+
+func testSessions(t *testing.T, reps *service.Repositories) {
+	ctx := context.Background()
+	owner := createUser(t, reps, "owner")
+	other := createUser(t, reps, "other")
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	first, err := reps.Session.Create(ctx, domain.CreateSessionInput{UserId: owner.Id, FamilyId: "family", TokenHash: "first", ExpiresAt: expiresAt})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, first.ExpiresAt.Equal(expiresAt), true)
+	second, _ := reps.Session.Create(ctx, domain.CreateSessionInput{UserId: owner.Id, FamilyId: "family", TokenHash: "second", ExpiresAt: expiresAt})
+	third, _ := reps.Session.Create(ctx, domain.CreateSessionInput{UserId: owner.Id, FamilyId: "other", TokenHash: "third", ExpiresAt: expiresAt})
+	_, _ = reps.Session.Create(ctx, domain.CreateSessionInput{UserId: other.Id, FamilyId: "another", TokenHash: "fourth", ExpiresAt: expiresAt})
+
+	session, err := reps.Session.GetByTokenHash(ctx, "first")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, session.Id, first.Id)
+	assert.Equal(t, session.UserId, owner.Id)
+	_, err = reps.Session.GetByTokenHash(ctx, "missing")
+	assert.Equal(t, err, domain.ErrSessionNotFound)
+
+	revoked, err := reps.Session.Revoke(ctx, first.Id)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, revoked, true)
+	revoked, _ = reps.Session.Revoke(ctx, first.Id)
+	assert.Equal(t, revoked, false)
+
+	assert.Equal(t, reps.Session.RevokeFamily(ctx, "family"), nil)
+	session, _ = reps.Session.GetByTokenHash(ctx, second.TokenHash)
+	assert.Equal(t, session.RevokedAt != nil, true)
+	session, _ = reps.Session.GetByTokenHash(ctx, third.TokenHash)
+	assert.Equal(t, session.RevokedAt, (*time.Time)(nil))
+
+	assert.Equal(t, reps.Session.RevokeUser(ctx, owner.Id), nil)
+	session, _ = reps.Session.GetByTokenHash(ctx, third.TokenHash)
+	assert.Equal(t, session.RevokedAt != nil, true)
+	session, _ = reps.Session.GetByTokenHash(ctx, "fourth")
+	assert.Equal(t, session.RevokedAt, (*time.Time)(nil))
+}
@@ -0,0 +1,273 @@
+package reptest
+
+import (
+	"context"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"github.com/i-vasilkov/go-todo-app/internal/service"
+	"github.com/magiconair/properties/assert"
+	"testing"
+	"time"
+)
+
+func testTasks(t *testing.T, reps *service.Repositories) {
+	ctx := context.Background()
+	owner := createUser(t, reps, "owner")
+	other := createUser(t, reps, "other")
+	missingId := missingTaskId(t, reps, owner.Id)
+	start := time.Now()
+
+	task := createTask(t, reps, owner.Id, domain.CreateTaskInput{Name: "Task", Description: "Text"})
+	assert.Equal(t, task.UserId, owner.Id)
+	assert.Equal(t, task.Status, domain.TaskStatusTodo)
+	assert.Equal(t, task.Priority, domain.DefaultTaskPriority)
+	assertCreatedNow(t, "created_at", task.CreatedAt, start)
+	assert.Equal(t, task.UpdatedAt.Equal(task.CreatedAt), true)
+
+	found, err := reps.Task.Get(ctx, task.Id, owner.Id)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, found.Name, "Task")
+	assert.Equal(t, found.Description, "Text")
+
+	due := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	updated, err := reps.Task.Update(ctx, task.Id, owner.Id, domain.UpdateTaskInput{Name: "Updated", DueAt: &due, Priority: domain.TaskPriorityP1})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, updated.Name, "Updated")
+	assert.Equal(t, updated.DueAt.Equal(due), true)
+	assert.Equal(t, updated.Priority, domain.TaskPriorityP1)
+	assert.Equal(t, updated.CreatedAt.Equal(task.CreatedAt), true)
+	assert.Equal(t, updated.UpdatedAt.Before(task.UpdatedAt), false)
+
+	completedAt := time.Now().UTC().Truncate(time.Second)
+	updated, err = reps.Task.UpdateStatus(ctx, task.Id, owner.Id, domain.TaskStatusDone, &completedAt)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, updated.Status, domain.TaskStatusDone)
+	assert.Equal(t, updated.CompletedAt.Equal(completedAt), true)
+
+	// Another user sees none of it and changes nothing.
+	_, err = reps.Task.Get(ctx, task.Id, other.Id)
+	assert.Equal(t, err, domain.ErrTaskNotFound)
+	_, err = reps.Task.Update(ctx, task.Id, other.Id, domain.UpdateTaskInput{Name: "Stolen"})
+	assert.Equal(t, err, domain.ErrTaskNotFound)
+	_, err = reps.Task.UpdateStatus(ctx, task.Id, other.Id, domain.TaskStatusTodo, nil)
+	assert.Equal(t, err, domain.ErrTaskNotFound)
+	_, err = reps.Task.UpdatePosition(ctx, task.Id, other.Id, 1)
+	assert.Equal(t, err, domain.ErrTaskNotFound)
+	assert.Equal(t, reps.Task.Delete(ctx, task.Id, other.Id), domain.ErrTaskNotFound)
+	page, _ := reps.Task.GetAll(ctx, other.Id, domain.TaskFilter{})
+	assert.Equal(t, len(page.Items), 0)
+
+	found, _ = reps.Task.Get(ctx, task.Id, owner.Id)
+	assert.Equal(t, found.Name, "Updated")
+	assert.Equal(t, found.Status, domain.TaskStatusDone)
+
+	_, err = reps.Task.Get(ctx, missingId, owner.Id)
+	assert.Equal(t, err, domain.ErrTaskNotFound)
+	_, err = reps.Task.Update(ctx, missingId, owner.Id, domain.UpdateTaskInput{Name: "Missing"})
+	assert.Equal(t, err, domain.ErrTaskNotFound)
+	_, err = reps.Task.UpdateStatus(ctx, missingId, owner.Id, domain.TaskStatusDone, nil)
+	assert.Equal(t, err, domain.ErrTaskNotFound)
+	assert.Equal(t, reps.Task.Delete(ctx, missingId, owner.Id), domain.ErrTaskNotFound)
+	// An id in a format the backend does not use can not be found either.
+	_, err = reps.Task.Get(ctx, "not-an-id", owner.Id)
+	assert.Equal(t, err, domain.ErrTaskNotFound)
+
+	label, err := reps.Label.Create(ctx, owner.Id, domain.CreateLabelInput{Name: "Label"})
+	assert.Equal(t, err, nil)
+	labeled, err := reps.Task.AttachLabels(ctx, task.Id, owner.Id, []string{label.Id})
+	assert.Equal(t, err, nil)
+	assertIds(t, labeled.LabelIds, label.Id)
+	labeled, _ = reps.Task.AttachLabels(ctx, task.Id, owner.Id, []string{label.Id})
+	assertIds(t, labeled.LabelIds, label.Id)
+	_, err = reps.Task.AttachLabels(ctx, task.Id, other.Id, []string{label.Id})
+	assert.Equal(t, err, domain.ErrTaskNotFound)
+	labeled, err = reps.Task.DetachLabels(ctx, task.Id, owner.Id, []string{label.Id})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(labeled.LabelIds), 0)
+
+	subtask := createTask(t, reps, owner.Id, domain.CreateTaskInput{Name: "Subtask", ParentId: &task.Id})
+	second := createTask(t, reps, owner.Id, domain.CreateTaskInput{Name: "Second subtask", ParentId: &task.Id})
+	subtasks, err := reps.Task.GetSubtasks(ctx, owner.Id, []string{task.Id})
+	assert.Equal(t, err, nil)
+	assertIds(t, taskIds(subtasks), subtask.Id, second.Id)
+	subtasks, _ = reps.Task.GetSubtasks(ctx, other.Id, []string{task.Id})
+	assert.Equal(t, len(subtasks), 0)
+
+	counts, err := reps.Task.Count(ctx)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, counts.Total, int64(3))
+	assert.Equal(t, counts.ByStatus[domain.TaskStatusDone], int64(1))
+	assert.Equal(t, counts.ByStatus[domain.TaskStatusTodo], int64(2))
+}
+
+func testTaskList(t *testing.T, reps *service.Repositories) {
+	ctx := context.Background()
+	owner := createUser(t, reps, "owner")
+	other := createUser(t, reps, "other")
+	early := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	late := early.Add(24 * time.Hour)
+
+	first := createTask(t, reps, owner.Id, domain.CreateTaskInput{Name: "Buy milk"})
+	second := createTask(t, reps, owner.Id, domain.CreateTaskInput{Name: "Call mom", DueAt: &late})
+	third := createTask(t, reps, owner.Id, domain.CreateTaskInput{Name: "Buy bread", DueAt: &early})
+	createTask(t, reps, other.Id, domain.CreateTaskInput{Name: "Buy tea"})
+
+	// New tasks go to the end of the list.
+	assert.Equal(t, first.Position < second.Position, true)
+	assert.Equal(t, second.Position < third.Position, true)
+
+	page, err := reps.Task.GetAll(ctx, owner.Id, domain.TaskFilter{})
+	assert.Equal(t, err, nil)
+	assertIds(t, taskIds(page.Items), first.Id, second.Id, third.Id)
+	assert.Equal(t, page.NextCursor, "")
+
+	// Tasks without a due date come last in both directions.
+	page, _ = reps.Task.GetAll(ctx, owner.Id, domain.TaskFilter{SortBy: domain.TaskSortDueAt})
+	assertIds(t, taskIds(page.Items), third.Id, second.Id, first.Id)
+	page, _ = reps.Task.GetAll(ctx, owner.Id, domain.TaskFilter{SortBy: domain.TaskSortDueAt, SortDir: domain.SortDesc})
+	assertIds(t, taskIds(page.Items), second.Id, third.Id, first.Id)
+
+	var ids []string
+	filter := domain.TaskFilter{SortBy: domain.TaskSortDueAt, Limit: 1}
+	for i := 0; i < 3; i++ {
+		page, err = reps.Task.GetAll(ctx, owner.Id, filter)
+		assert.Equal(t, err, nil)
+		ids = append(ids, taskIds(page.Items)...)
+		filter.Cursor = page.NextCursor
+	}
+	assertIds(t, ids, third.Id, second.Id, first.Id)
+	assert.Equal(t, page.NextCursor, "")
+
+	page, _ = reps.Task.GetAll(ctx, owner.Id, domain.TaskFilter{Name: "BUY"})
+	assertIds(t, taskIds(page.Items), first.Id, third.Id)
+	page, _ = reps.Task.GetAll(ctx, owner.Id, domain.TaskFilter{DueFrom: &early, DueTo: &late})
+	assertIds(t, taskIds(page.Items), third.Id)
+
+	// Filtered ids the storage can not parse are rejected instead of failing the query.
+	_, err = reps.Task.GetAll(ctx, owner.Id, domain.TaskFilter{ProjectId: "work"})
+	assert.Equal(t, err, domain.ErrInvalidProjectFilter)
+	_, err = reps.Task.GetAll(ctx, owner.Id, domain.TaskFilter{Labels: []string{"urgent"}})
+	assert.Equal(t, err, domain.ErrInvalidLabelFilter)
+
+	overdue, err := reps.Task.GetOverdue(ctx, owner.Id, late.Add(time.Hour))
+	assert.Equal(t, err, nil)
+	assertIds(t, taskIds(overdue), third.Id, second.Id)
+	due, err := reps.Task.GetDueBetween(ctx, owner.Id, early, late)
+	assert.Equal(t, err, nil)
+	assertIds(t, taskIds(due), third.Id)
+
+	// Positions are kept per project, the trash is left out of moving and rebalancing.
+	project, err := reps.Project.Create(ctx, owner.Id, domain.CreateProjectInput{Name: "Work"})
+	assert.Equal(t, err, nil)
+	work := createTask(t, reps, owner.Id, domain.CreateTaskInput{Name: "Write report", ProjectId: &project.Id})
+	assert.Equal(t, work.Position, domain.TaskPositionGap)
+	review := createTask(t, reps, owner.Id, domain.CreateTaskInput{Name: "Review report", ProjectId: &project.Id})
+	trashed := createTask(t, reps, owner.Id, domain.CreateTaskInput{Name: "Buy juice"})
+	assert.Equal(t, reps.Task.Delete(ctx, trashed.Id, owner.Id), nil)
+
+	next, err := reps.Task.GetAdjacentPosition(ctx, owner.Id, first, true)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, *next, second.Position)
+	previous, _ := reps.Task.GetAdjacentPosition(ctx, owner.Id, first, false)
+	assert.Equal(t, previous, (*float64)(nil))
+	next, _ = reps.Task.GetAdjacentPosition(ctx, owner.Id, third, true)
+	assert.Equal(t, next, (*float64)(nil))
+	next, _ = reps.Task.GetAdjacentPosition(ctx, owner.Id, work, true)
+	assert.Equal(t, *next, review.Position)
+	previous, _ = reps.Task.GetAdjacentPosition(ctx, owner.Id, work, false)
+	assert.Equal(t, previous, (*float64)(nil))
+
+	// Tasks at the same position are ordered by id, so they still find each other as neighbours.
+	tied, err := reps.Task.UpdatePosition(ctx, second.Id, owner.Id, first.Position)
+	assert.Equal(t, err, nil)
+	next, _ = reps.Task.GetAdjacentPosition(ctx, owner.Id, first, true)
+	assert.Equal(t, *next, first.Position)
+	previous, _ = reps.Task.GetAdjacentPosition(ctx, owner.Id, tied, false)
+	assert.Equal(t, *previous, first.Position)
+
+	_, err = reps.Task.UpdatePosition(ctx, third.Id, owner.Id, first.Position/2)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, reps.Task.RebalancePositions(ctx, owner.Id, nil), nil)
+	page, _ = reps.Task.GetAll(ctx, owner.Id, domain.TaskFilter{ProjectId: domain.InboxProjectId})
+	assertIds(t, taskIds(page.Items), third.Id, first.Id, second.Id)
+	assert.Equal(t, page.Items[0].Position, domain.TaskPositionGap)
+	assert.Equal(t, page.Items[2].Position, 3*domain.TaskPositionGap)
+
+	got, _ := reps.Task.Get(ctx, work.Id, owner.Id)
+	assert.Equal(t, got.Position, work.Position)
+	trash, _ := reps.Task.GetTrash(ctx, owner.Id)
+	assertIds(t, taskIds(trash), trashed.Id)
+	assert.Equal(t, trash[0].Position, trashed.Position)
+
+	// A task moved to another project goes to the end of its list.
+	moved, err := reps.Task.Update(ctx, first.Id, owner.Id, domain.UpdateTaskInput{Name: "Buy milk", ProjectId: &project.Id})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, moved.Position, review.Position+domain.TaskPositionGap)
+	renamed, err := reps.Task.Update(ctx, first.Id, owner.Id, domain.UpdateTaskInput{Name: "Buy oat milk", ProjectId: &project.Id})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, renamed.Position, moved.Position)
+}
+
+func testTaskTrash(t *testing.T, reps *service.Repositories) {
+	ctx := context.Background()
+	owner := createUser(t, reps, "owner")
+	other := createUser(t, reps, "other")
+
+	parent := createTask(t, reps, owner.Id, domain.CreateTaskInput{Name: "Parent"})
+	child := createTask(t, reps, owner.Id, domain.CreateTaskInput{Name: "Child", ParentId: &parent.Id})
+	single := createTask(t, reps, owner.Id, domain.CreateTaskInput{Name: "Single"})
+
+	assert.Equal(t, reps.Task.Delete(ctx, parent.Id, owner.Id), nil)
+	assert.Equal(t, reps.Task.Delete(ctx, single.Id, owner.Id), nil)
+
+	_, err := reps.Task.Get(ctx, child.Id, owner.Id)
+	assert.Equal(t, err, domain.ErrTaskNotFound)
+	_, err = reps.Task.Update(ctx, parent.Id, owner.Id, domain.UpdateTaskInput{Name: "Trashed"})
+	assert.Equal(t, err, domain.ErrTaskNotFound)
+
+	trash, err := reps.Task.GetTrash(ctx, owner.Id)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, trash[0].Id, single.Id)
+	assert.Equal(t, len(trash), 3)
+	trash, _ = reps.Task.GetTrash(ctx, other.Id)
+	assert.Equal(t, len(trash), 0)
+
+	counts, _ := reps.Task.Count(ctx)
+	assert.Equal(t, counts.Total, int64(0))
+	assert.Equal(t, counts.InTrash, int64(3))
+
+	_, err = reps.Task.Restore(ctx, child.Id, other.Id)
+	assert.Equal(t, err, domain.ErrTaskNotFound)
+
+	// The parent is still in the trash, so the child comes back on the top level.
+	restored, err := reps.Task.Restore(ctx, child.Id, owner.Id)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, restored.ParentId, (*string)(nil))
+	_, err = reps.Task.Restore(ctx, child.Id, owner.Id)
+	assert.Equal(t, err, domain.ErrTaskNotFound)
+
+	purged, err := reps.Task.PurgeTrash(ctx, time.Now().Add(-time.Hour))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, purged, int64(0))
+	purged, _ = reps.Task.PurgeTrash(ctx, time.Now().Add(time.Hour))
+	assert.Equal(t, purged, int64(2))
+
+	trash, _ = reps.Task.GetTrash(ctx, owner.Id)
+	assert.Equal(t, len(trash), 0)
+	_, err = reps.Task.Get(ctx, child.Id, owner.Id)
+	assert.Equal(t, err, nil)
+}
+
+// missingTaskId returns an id in the format of the backend that no task has.
+func missingTaskId(t *testing.T, reps *service.Repositories, userId string) string {
+	t.Helper()
+	ctx := context.Background()
+
+	task := createTask(t, reps, userId, domain.CreateTaskInput{Name: "Missing"})
+	if err := reps.Task.Delete(ctx, task.Id, userId); err != nil {
+		t.Fatalf("deleting task: %s", err)
+	}
+	if _, err := reps.Task.PurgeTrash(ctx, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("purging trash: %s", err)
+	}
+	return task.Id
+}
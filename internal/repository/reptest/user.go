@@ -0,0 +1,91 @@
+package reptest
+
+import (
+	"context"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"github.com/i-vasilkov/go-todo-app/internal/service"
+	"github.com/magiconair/properties/assert"
+	"testing"
+	"time"
+)
+
+func testUsers(t *testing.T, reps *service.Repositories) {
+	ctx := context.Background()
+	start := time.Now()
+
+	first := createUser(t, reps, "first")
+	second := createUser(t, reps, "second")
+	assertCreatedNow(t, "created_at", first.CreatedAt, start)
+	assert.Equal(t, first.EmailVerified, false)
+	assert.Equal(t, first.Disabled, false)
+	assert.Equal(t, len(first.Roles), 0)
+
+	_, err := reps.User.Create(ctx, domain.CreateUserInput{Login: "first", Email: "other@example.com", Password: "hash"})
+	assert.Equal(t, err, domain.ErrLoginTaken)
+	_, err = reps.User.Create(ctx, domain.CreateUserInput{Login: "other", Email: "first@example.com", Password: "hash"})
+	assert.Equal(t, err, domain.ErrEmailTaken)
+
+	user, err := reps.User.GetByLogin(ctx, "first")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, user.Id, first.Id)
+	user, err = reps.User.GetByEmail(ctx, "second@example.com")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, user.Id, second.Id)
+	_, err = reps.User.GetByLogin(ctx, "missing")
+	assert.Equal(t, err, domain.ErrUserNotFound)
+	_, err = reps.User.GetByEmail(ctx, "missing@example.com")
+	assert.Equal(t, err, domain.ErrUserNotFound)
+
+	users, err := reps.User.GetAll(ctx, domain.UserFilter{})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(users), 2)
+	assert.Equal(t, users[0].Id, first.Id)
+	users, _ = reps.User.GetAll(ctx, domain.UserFilter{Limit: 1, Offset: 1})
+	assert.Equal(t, len(users), 1)
+	assert.Equal(t, users[0].Id, second.Id)
+
+	assert.Equal(t, reps.User.SetEmailVerified(ctx, first.Id), nil)
+	email := "changed@example.com"
+	user, err = reps.User.Update(ctx, first.Id, domain.UpdateUserInput{Email: &email})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, user.Email, email)
+	assert.Equal(t, user.EmailVerified, false)
+
+	assert.Equal(t, reps.User.SetRoles(ctx, first.Id, []domain.Role{domain.RoleAdmin}), nil)
+	assert.Equal(t, reps.User.SetDisabled(ctx, first.Id, true), nil)
+	user, _ = reps.User.Get(ctx, first.Id)
+	assert.Equal(t, user.HasRole(domain.RoleAdmin), true)
+	assert.Equal(t, user.Disabled, true)
+
+	assert.Equal(t, reps.User.UpdateTotp(ctx, first.Id, domain.UpdateTotpInput{Secret: "secret", Enabled: true, RecoveryCodes: []string{"a", "b"}}), nil)
+	used, _ := reps.User.UseRecoveryCode(ctx, first.Id, "a")
+	assert.Equal(t, used, true)
+	used, _ = reps.User.UseRecoveryCode(ctx, first.Id, "a")
+	assert.Equal(t, used, false)
+	used, _ = reps.User.UseRecoveryCode(ctx, second.Id, "b")
+	assert.Equal(t, used, false)
+
+	// A time step is accepted once and only after the last accepted one.
+	used, _ = reps.User.UseTotpStep(ctx, first.Id, 100)
+	assert.Equal(t, used, true)
+	used, _ = reps.User.UseTotpStep(ctx, first.Id, 100)
+	assert.Equal(t, used, false)
+	used, _ = reps.User.UseTotpStep(ctx, first.Id, 99)
+	assert.Equal(t, used, false)
+	used, _ = reps.User.UseTotpStep(ctx, first.Id, 101)
+	assert.Equal(t, used, true)
+	used, _ = reps.User.UseTotpStep(ctx, second.Id, 100)
+	assert.Equal(t, used, true)
+
+	// Deleting a user takes what the user owns along and leaves a valid id that is not found.
+	task := createTask(t, reps, second.Id, domain.CreateTaskInput{Name: "Task"})
+	assert.Equal(t, reps.User.Delete(ctx, second.Id), nil)
+	_, err = reps.Task.Get(ctx, task.Id, second.Id)
+	assert.Equal(t, err, domain.ErrTaskNotFound)
+
+	_, err = reps.User.Get(ctx, second.Id)
+	assert.Equal(t, err, domain.ErrUserNotFound)
+	assert.Equal(t, reps.User.SetRoles(ctx, second.Id, nil), domain.ErrUserNotFound)
+	assert.Equal(t, reps.User.SetDisabled(ctx, second.Id, true), domain.ErrUserNotFound)
+	assert.Equal(t, reps.User.Delete(ctx, second.Id), domain.ErrUserNotFound)
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"github.com/i-vasilkov/go-todo-app/pkg/auth/opaque"
+	"time"
+)
+
+// lastUsedResolution limits how often the last use of a token is written back.
+const lastUsedResolution = time.Minute
+
+type AccessTokenService struct {
+	rep    AccessTokenRepositoryI
+	users  UserRepositoryI
+	tokens opaque.TokenManagerI
+}
+
+func NewAccessTokenService(rep AccessTokenRepositoryI, users UserRepositoryI, tokens opaque.TokenManagerI) *AccessTokenService {
+	return &AccessTokenService{
+		rep:    rep,
+		users:  users,
+		tokens: tokens,
+	}
+}
+
+func (s *AccessTokenService) GetAll(ctx context.Context, userId string) ([]domain.AccessToken, error) {
+	return s.rep.GetAll(ctx, userId)
+}
+
+func (s *AccessTokenService) Create(ctx context.Context, userId string, in domain.CreateAccessTokenInput) (domain.CreatedAccessToken, error) {
+	token, err := s.tokens.NewToken()
+	if err != nil {
+		return domain.CreatedAccessToken{}, err
+	}
+	token = domain.AccessTokenPrefix + token
+
+	accessToken, err := s.rep.Create(ctx, userId, s.tokens.Hash(token), in)
+	if err != nil {
+		return domain.CreatedAccessToken{}, err
+	}
+
+	return domain.CreatedAccessToken{AccessToken: accessToken, Token: token}, nil
+}
+
+func (s *AccessTokenService) Delete(ctx context.Context, id, userId string) error {
+	return s.rep.Delete(ctx, id, userId)
+}
+
+// Check finds the token a request is authenticated with and records its use. The tokens of
+// disabled users are kept, but rejected until the user is enabled again.
+func (s *AccessTokenService) Check(ctx context.Context, token string) (domain.AccessToken, error) {
+	accessToken, err := s.rep.GetByTokenHash(ctx, s.tokens.Hash(token))
+	if errors.Is(err, domain.ErrAccessTokenNotFound) {
+		return domain.AccessToken{}, domain.ErrInvalidAccessToken
+	}
+	if err != nil {
+		return domain.AccessToken{}, err
+	}
+
+	now := time.Now()
+	if accessToken.ExpiresAt != nil && !accessToken.ExpiresAt.After(now) {
+		return domain.AccessToken{}, domain.ErrInvalidAccessToken
+	}
+
+	user, err := s.users.Get(ctx, accessToken.UserId)
+	if err != nil {
+		return domain.AccessToken{}, err
+	}
+	if user.Disabled {
+		return domain.AccessToken{}, domain.ErrInvalidAccessToken
+	}
+
+	if accessToken.LastUsedAt == nil || now.Sub(*accessToken.LastUsedAt) >= lastUsedResolution {
+		if err := s.rep.UpdateLastUsed(ctx, accessToken.Id, now); err != nil {
+			return domain.AccessToken{}, err
+		}
+	}
+
+	return accessToken, nil
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	mock_service "github.com/i-vasilkov/go-todo-app/internal/service/mocks"
+	mock_opaque "github.com/i-vasilkov/go-todo-app/pkg/auth/opaque/mocks"
+	"github.com/magiconair/properties/assert"
+	"testing"
+	"time"
+)
+
+func TestAccessTokenService_Create(t *testing.T) {
+	type mockBehaviour func(r *mock_service.MockAccessTokenRepositoryI, tm *mock_opaque.MockTokenManagerI, in domain.CreateAccessTokenInput)
+
+	input := domain.CreateAccessTokenInput{Name: "backup", Scopes: []domain.Scope{domain.ScopeTasksRead}}
+	created := domain.AccessToken{Id: "tokenId", UserId: "userId", Name: "backup", Scopes: input.Scopes}
+
+	testCases := []struct {
+		name  string
+		mock  mockBehaviour
+		token domain.CreatedAccessToken
+		err   error
+	}{
+		{
+			name: "OK",
+			mock: func(r *mock_service.MockAccessTokenRepositoryI, tm *mock_opaque.MockTokenManagerI, in domain.CreateAccessTokenInput) {
+				tm.EXPECT().NewToken().Return("secret", nil)
+				tm.EXPECT().Hash("tdp_secret").Return("hash")
+				r.EXPECT().Create(context.Background(), "userId", "hash", in).Return(created, nil)
+			},
+			token: domain.CreatedAccessToken{AccessToken: created, Token: "tdp_secret"},
+			err:   nil,
+		},
+		{
+			name: "Token manager error",
+			mock: func(r *mock_service.MockAccessTokenRepositoryI, tm *mock_opaque.MockTokenManagerI, in domain.CreateAccessTokenInput) {
+				tm.EXPECT().NewToken().Return("", errors.New("token manager error"))
+			},
+			token: domain.CreatedAccessToken{},
+			err:   errors.New("token manager error"),
+		},
+		{
+			name: "Repository error",
+			mock: func(r *mock_service.MockAccessTokenRepositoryI, tm *mock_opaque.MockTokenManagerI, in domain.CreateAccessTokenInput) {
+				tm.EXPECT().NewToken().Return("secret", nil)
+				tm.EXPECT().Hash("tdp_secret").Return("hash")
+				r.EXPECT().Create(context.Background(), "userId", "hash", in).Return(domain.AccessToken{}, errors.New("repository error"))
+			},
+			token: domain.CreatedAccessToken{},
+			err:   errors.New("repository error"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			rep := mock_service.NewMockAccessTokenRepositoryI(ctrl)
+			tokenManager := mock_opaque.NewMockTokenManagerI(ctrl)
+			testCase.mock(rep, tokenManager, input)
+
+			service := NewAccessTokenService(rep, nil, tokenManager)
+			token, err := service.Create(context.Background(), "userId", input)
+
+			assert.Equal(t, token, testCase.token)
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
+
+func TestAccessTokenService_Check(t *testing.T) {
+	type mockBehaviour func(r *mock_service.MockAccessTokenRepositoryI, u *mock_service.MockUserRepositoryI, tm *mock_opaque.MockTokenManagerI)
+
+	recently := time.Now().Add(-time.Second)
+	longAgo := time.Now().Add(-time.Hour)
+	expiresAt := time.Now().Add(-time.Minute)
+
+	fresh := domain.AccessToken{Id: "tokenId", UserId: "userId", Scopes: []domain.Scope{domain.ScopeTasksRead}}
+	usedRecently := fresh
+	usedRecently.LastUsedAt = &recently
+	usedLongAgo := fresh
+	usedLongAgo.LastUsedAt = &longAgo
+	expired := fresh
+	expired.ExpiresAt = &expiresAt
+
+	testCases := []struct {
+		name  string
+		mock  mockBehaviour
+		token domain.AccessToken
+		err   error
+	}{
+		{
+			name: "First use",
+			mock: func(r *mock_service.MockAccessTokenRepositoryI, u *mock_service.MockUserRepositoryI, tm *mock_opaque.MockTokenManagerI) {
+				tm.EXPECT().Hash("tdp_secret").Return("hash")
+				r.EXPECT().GetByTokenHash(context.Background(), "hash").Return(fresh, nil)
+				u.EXPECT().Get(context.Background(), "userId").Return(domain.User{Id: "userId"}, nil)
+				r.EXPECT().UpdateLastUsed(context.Background(), "tokenId", gomock.Any()).Return(nil)
+			},
+			token: fresh,
+			err:   nil,
+		},
+		{
+			name: "Used recently",
+			mock: func(r *mock_service.MockAccessTokenRepositoryI, u *mock_service.MockUserRepositoryI, tm *mock_opaque.MockTokenManagerI) {
+				tm.EXPECT().Hash("tdp_secret").Return("hash")
+				r.EXPECT().GetByTokenHash(context.Background(), "hash").Return(usedRecently, nil)
+				u.EXPECT().Get(context.Background(), "userId").Return(domain.User{Id: "userId"}, nil)
+			},
+			token: usedRecently,
+			err:   nil,
+		},
+		{
+			name: "Used long ago",
+			mock: func(r *mock_service.MockAccessTokenRepositoryI, u *mock_service.MockUserRepositoryI, tm *mock_opaque.MockTokenManagerI) {
+				tm.EXPECT().Hash("tdp_secret").Return("hash")
+				r.EXPECT().GetByTokenHash(context.Background(), "hash").Return(usedLongAgo, nil)
+				u.EXPECT().Get(context.Background(), "userId").Return(domain.User{Id: "userId"}, nil)
+				r.EXPECT().UpdateLastUsed(context.Background(), "tokenId", gomock.Any()).Return(nil)
+			},
+			token: usedLongAgo,
+			err:   nil,
+		},
+		{
+			name: "Unknown token",
+			mock: func(r *mock_service.MockAccessTokenRepositoryI, u *mock_service.MockUserRepositoryI, tm *mock_opaque.MockTokenManagerI) {
+				tm.EXPECT().Hash("tdp_secret").Return("hash")
+				r.EXPECT().GetByTokenHash(context.Background(), "hash").Return(domain.AccessToken{}, domain.ErrAccessTokenNotFound)
+			},
+			token: domain.AccessToken{},
+			err:   domain.ErrInvalidAccessToken,
+		},
+		{
+			name: "Expired token",
+			mock: func(r *mock_service.MockAccessTokenRepositoryI, u *mock_service.MockUserRepositoryI, tm *mock_opaque.MockTokenManagerI) {
+				tm.EXPECT().Hash("tdp_secret").Return("hash")
+				r.EXPECT().GetByTokenHash(context.Background(), "hash").Return(expired, nil)
+			},
+			token: domain.AccessToken{},
+			err:   domain.ErrInvalidAccessToken,
+		},
+		{
+			name: "Disabled user",
+			mock: func(r *mock_service.MockAccessTokenRepositoryI, u *mock_service.MockUserRepositoryI, tm *mock_opaque.MockTokenManagerI) {
+				tm.EXPECT().Hash("tdp_secret").Return("hash")
+				r.EXPECT().GetByTokenHash(context.Background(), "hash").Return(fresh, nil)
+				u.EXPECT().Get(context.Background(), "userId").Return(domain.User{Id: "userId", Disabled: true}, nil)
+			},
+			token: domain.AccessToken{},
+			err:   domain.ErrInvalidAccessToken,
+		},
+		{
+			name: "Repository error",
+			mock: func(r *mock_service.MockAccessTokenRepositoryI, u *mock_service.MockUserRepositoryI, tm *mock_opaque.MockTokenManagerI) {
+				tm.EXPECT().Hash("tdp_secret").Return("hash")
+				r.EXPECT().GetByTokenHash(context.Background(), "hash").Return(domain.AccessToken{}, errors.New("repository error"))
+			},
+			token: domain.AccessToken{},
+			err:   errors.New("repository error"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			rep := mock_service.NewMockAccessTokenRepositoryI(ctrl)
+			users := mock_service.NewMockUserRepositoryI(ctrl)
+			tokenManager := mock_opaque.NewMockTokenManagerI(ctrl)
+			testCase.mock(rep, users, tokenManager)
+
+			service := NewAccessTokenService(rep, users, tokenManager)
+			token, err := service.Check(context.Background(), "tdp_secret")
+
+			assert.Equal(t, token, testCase.token)
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
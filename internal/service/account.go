@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	jwtauth "github.com/i-vasilkov/go-todo-app/pkg/auth/jwt"
+	"github.com/i-vasilkov/go-todo-app/pkg/hash"
+	"github.com/i-vasilkov/go-todo-app/pkg/mailer"
+	"log"
+	"time"
+)
+
+// passwordResetAudience marks the tokens mailed to reset a forgotten password.
+const passwordResetAudience = "password-reset"
+
+// AccountService manages the profile of the user and recovers accounts through their email.
+type AccountService struct {
+	rep         UserRepositoryI
+	sessions    SessionRepositoryI
+	revocations RevocationRepositoryI
+	hasher      hash.Hasher
+	jwt         jwtauth.TokenManagerI
+	mailer      mailer.Mailer
+	appUrl      string
+	resetTtl    time.Duration
+	verifier    *EmailVerifier
+}
+
+func NewAccountService(
+	rep UserRepositoryI,
+	sessions SessionRepositoryI,
+	revocations RevocationRepositoryI,
+	hasher hash.Hasher,
+	jwt jwtauth.TokenManagerI,
+	mailer mailer.Mailer,
+	appUrl string,
+	resetTtl time.Duration,
+	verifier *EmailVerifier,
+) *AccountService {
+	return &AccountService{
+		rep:         rep,
+		sessions:    sessions,
+		revocations: revocations,
+		hasher:      hasher,
+		jwt:         jwt,
+		mailer:      mailer,
+		appUrl:      appUrl,
+		resetTtl:    resetTtl,
+		verifier:    verifier,
+	}
+}
+
+func (s *AccountService) Get(ctx context.Context, userId string) (domain.User, error) {
+	return s.rep.Get(ctx, userId)
+}
+
+// Update changes the login and the email. A new email has to be verified again, so the link is
+// mailed to it right away.
+func (s *AccountService) Update(ctx context.Context, userId string, in domain.UpdateUserInput) (domain.User, error) {
+	user, err := s.rep.Get(ctx, userId)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	if in.Login != nil && *in.Login == user.Login {
+		in.Login = nil
+	}
+	if in.Email != nil {
+		email := normalizeEmail(*in.Email)
+		if email == user.Email {
+			in.Email = nil
+		} else {
+			in.Email = &email
+		}
+	}
+
+	user, err = s.rep.Update(ctx, userId, in)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	if in.Email != nil {
+		if err := s.verifier.Send(ctx, user); err != nil {
+			log.Printf("sending the verification email to user %s: %s", user.Id, err)
+		}
+	}
+
+	return user, nil
+}
+
+// Delete removes the account with all the data of the user. The access tokens still out there
+// are revoked first, so they can not be used in the meantime. The revocation outlives the user,
+// the repositories keep it when the user is deleted.
+func (s *AccountService) Delete(ctx context.Context, userId string) error {
+	if err := s.revocations.RevokeUser(ctx, userId, time.Now()); err != nil {
+		return err
+	}
+
+	return s.rep.Delete(ctx, userId)
+}
+
+// ForgotPassword mails a password reset link to the user with the given email. An unknown email
+// is not reported, so the endpoint can not be used to find out who has an account.
+func (s *AccountService) ForgotPassword(ctx context.Context, in domain.ForgotPasswordInput) error {
+	user, err := s.rep.GetByEmail(ctx, normalizeEmail(in.Email))
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	token, err := s.jwt.NewAudienceToken(user.Id, passwordResetAudience, s.resetTtl)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nsomeone asked to reset your password. Follow the link below to choose "+
+				"a new one:\n%s\n\nIf it was not you, just ignore this email.\n",
+			user.Login, mailLink(s.appUrl, "/reset-password", token),
+		),
+	})
+}
+
+// ResetPassword sets the new password given a token from ForgotPassword. The token is used up,
+// and every access and refresh token of the user is revoked, signing out whoever knew the old
+// password.
+func (s *AccountService) ResetPassword(ctx context.Context, in domain.ResetPasswordInput) error {
+	claims, err := s.jwt.ParseAudience(in.Token, passwordResetAudience)
+	if err != nil {
+		return domain.ErrInvalidResetToken
+	}
+
+	revoked, err := s.revocations.IsRevoked(ctx, claims.Id, claims.Subject, claims.IssuedAt)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return domain.ErrInvalidResetToken
+	}
+
+	if err := s.revocations.Revoke(ctx, claims.Id, claims.ExpiresAt); err != nil {
+		return err
+	}
+
+	password, err := s.hasher.Hash(in.Password)
+	if err != nil {
+		return err
+	}
+
+	if err := s.rep.UpdatePassword(ctx, claims.Subject, password); err != nil {
+		return err
+	}
+
+	if err := s.revocations.RevokeUser(ctx, claims.Subject, time.Now()); err != nil {
+		return err
+	}
+
+	return s.sessions.RevokeUser(ctx, claims.Subject)
+}
+
+func (s *AccountService) VerifyEmail(ctx context.Context, in domain.VerifyEmailInput) error {
+	return s.verifier.Verify(ctx, in.Token)
+}
+
+// ResendVerification mails another verification link, e.g. when the first one has expired.
+func (s *AccountService) ResendVerification(ctx context.Context, userId string) error {
+	user, err := s.rep.Get(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	return s.verifier.Send(ctx, user)
+}
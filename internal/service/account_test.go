@@ -0,0 +1,444 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	mock_service "github.com/i-vasilkov/go-todo-app/internal/service/mocks"
+	jwtauth "github.com/i-vasilkov/go-todo-app/pkg/auth/jwt"
+	mock_jwt "github.com/i-vasilkov/go-todo-app/pkg/auth/jwt/mocks"
+	mock_hash "github.com/i-vasilkov/go-todo-app/pkg/hash/mocks"
+	"github.com/i-vasilkov/go-todo-app/pkg/mailer"
+	mock_mailer "github.com/i-vasilkov/go-todo-app/pkg/mailer/mocks"
+	"github.com/magiconair/properties/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testAppUrl          = "http://localhost:3000"
+	testResetTtl        = time.Hour
+	testVerificationTtl = 72 * time.Hour
+)
+
+// mailMessageMatcher matches a message to the recipient that carries the link.
+type mailMessageMatcher struct {
+	to   string
+	link string
+}
+
+func (m mailMessageMatcher) Matches(x interface{}) bool {
+	msg, ok := x.(mailer.Message)
+	if !ok {
+		return false
+	}
+
+	return msg.To == m.to && strings.Contains(msg.Body, m.link)
+}
+
+func (m mailMessageMatcher) String() string {
+	return fmt.Sprintf("is message to %q with link %q", m.to, m.link)
+}
+
+func TestAccountService_ForgotPassword(t *testing.T) {
+	type mockBehaviour func(r *mock_service.MockUserRepositoryI, tm *mock_jwt.MockTokenManagerI, m *mock_mailer.MockMailer)
+
+	user := domain.User{Id: "userId", Login: "test", Email: "test@example.com"}
+
+	testCases := []struct {
+		name  string
+		input domain.ForgotPasswordInput
+		mock  mockBehaviour
+		err   error
+	}{
+		{
+			name:  "OK",
+			input: domain.ForgotPasswordInput{Email: " Test@Example.com"},
+			mock: func(r *mock_service.MockUserRepositoryI, tm *mock_jwt.MockTokenManagerI, m *mock_mailer.MockMailer) {
+				r.EXPECT().GetByEmail(context.Background(), "test@example.com").Return(user, nil)
+				tm.EXPECT().NewAudienceToken("userId", passwordResetAudience, testResetTtl).Return("reset", nil)
+				m.EXPECT().
+					Send(context.Background(), mailMessageMatcher{to: "test@example.com", link: testAppUrl + "/reset-password?token=reset"}).
+					Return(nil)
+			},
+			err: nil,
+		},
+		{
+			name:  "Unknown email",
+			input: domain.ForgotPasswordInput{Email: "other@example.com"},
+			mock: func(r *mock_service.MockUserRepositoryI, tm *mock_jwt.MockTokenManagerI, m *mock_mailer.MockMailer) {
+				r.EXPECT().GetByEmail(context.Background(), "other@example.com").Return(domain.User{}, domain.ErrUserNotFound)
+			},
+			err: nil,
+		},
+		{
+			name:  "Repository error",
+			input: domain.ForgotPasswordInput{Email: "test@example.com"},
+			mock: func(r *mock_service.MockUserRepositoryI, tm *mock_jwt.MockTokenManagerI, m *mock_mailer.MockMailer) {
+				r.EXPECT().GetByEmail(context.Background(), "test@example.com").Return(domain.User{}, errors.New("repository error"))
+			},
+			err: errors.New("repository error"),
+		},
+		{
+			name:  "Mailer error",
+			input: domain.ForgotPasswordInput{Email: "test@example.com"},
+			mock: func(r *mock_service.MockUserRepositoryI, tm *mock_jwt.MockTokenManagerI, m *mock_mailer.MockMailer) {
+				r.EXPECT().GetByEmail(context.Background(), "test@example.com").Return(user, nil)
+				tm.EXPECT().NewAudienceToken("userId", passwordResetAudience, testResetTtl).Return("reset", nil)
+				m.EXPECT().Send(context.Background(), gomock.Any()).Return(errors.New("mailer error"))
+			},
+			err: errors.New("mailer error"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepository := mock_service.NewMockUserRepositoryI(ctrl)
+			tokenManager := mock_jwt.NewMockTokenManagerI(ctrl)
+			mailer := mock_mailer.NewMockMailer(ctrl)
+			testCase.mock(userRepository, tokenManager, mailer)
+
+			account := NewAccountService(userRepository, nil, nil, nil, tokenManager, mailer, testAppUrl, testResetTtl, nil)
+			err := account.ForgotPassword(context.Background(), testCase.input)
+
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
+
+func TestAccountService_ResetPassword(t *testing.T) {
+	type mockBehaviour func(
+		r *mock_service.MockUserRepositoryI,
+		rv *mock_service.MockRevocationRepositoryI,
+		sr *mock_service.MockSessionRepositoryI,
+		h *mock_hash.MockHasher,
+		tm *mock_jwt.MockTokenManagerI,
+	)
+
+	input := domain.ResetPasswordInput{Token: "reset", Password: "new password"}
+	issuedAt := time.Now().Add(-time.Minute)
+	claims := jwtauth.Claims{Id: "jti", Subject: "userId", IssuedAt: issuedAt, ExpiresAt: issuedAt.Add(testResetTtl)}
+
+	testCases := []struct {
+		name string
+		mock mockBehaviour
+		err  error
+	}{
+		{
+			name: "OK",
+			mock: func(r *mock_service.MockUserRepositoryI, rv *mock_service.MockRevocationRepositoryI, sr *mock_service.MockSessionRepositoryI, h *mock_hash.MockHasher, tm *mock_jwt.MockTokenManagerI) {
+				tm.EXPECT().ParseAudience("reset", passwordResetAudience).Return(claims, nil)
+				rv.EXPECT().IsRevoked(context.Background(), "jti", "userId", issuedAt).Return(false, nil)
+				rv.EXPECT().Revoke(context.Background(), "jti", claims.ExpiresAt).Return(nil)
+				h.EXPECT().Hash("new password").Return("hash", nil)
+				r.EXPECT().UpdatePassword(context.Background(), "userId", "hash").Return(nil)
+				rv.EXPECT().RevokeUser(context.Background(), "userId", gomock.Any()).Return(nil)
+				sr.EXPECT().RevokeUser(context.Background(), "userId").Return(nil)
+			},
+			err: nil,
+		},
+		{
+			name: "Invalid token",
+			mock: func(r *mock_service.MockUserRepositoryI, rv *mock_service.MockRevocationRepositoryI, sr *mock_service.MockSessionRepositoryI, h *mock_hash.MockHasher, tm *mock_jwt.MockTokenManagerI) {
+				tm.EXPECT().ParseAudience("reset", passwordResetAudience).Return(jwtauth.Claims{}, errors.New("token is expired"))
+			},
+			err: domain.ErrInvalidResetToken,
+		},
+		{
+			name: "Used token",
+			mock: func(r *mock_service.MockUserRepositoryI, rv *mock_service.MockRevocationRepositoryI, sr *mock_service.MockSessionRepositoryI, h *mock_hash.MockHasher, tm *mock_jwt.MockTokenManagerI) {
+				tm.EXPECT().ParseAudience("reset", passwordResetAudience).Return(claims, nil)
+				rv.EXPECT().IsRevoked(context.Background(), "jti", "userId", issuedAt).Return(true, nil)
+			},
+			err: domain.ErrInvalidResetToken,
+		},
+		{
+			name: "Repository error",
+			mock: func(r *mock_service.MockUserRepositoryI, rv *mock_service.MockRevocationRepositoryI, sr *mock_service.MockSessionRepositoryI, h *mock_hash.MockHasher, tm *mock_jwt.MockTokenManagerI) {
+				tm.EXPECT().ParseAudience("reset", passwordResetAudience).Return(claims, nil)
+				rv.EXPECT().IsRevoked(context.Background(), "jti", "userId", issuedAt).Return(false, nil)
+				rv.EXPECT().Revoke(context.Background(), "jti", claims.ExpiresAt).Return(nil)
+				h.EXPECT().Hash("new password").Return("hash", nil)
+				r.EXPECT().UpdatePassword(context.Background(), "userId", "hash").Return(errors.New("repository error"))
+			},
+			err: errors.New("repository error"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepository := mock_service.NewMockUserRepositoryI(ctrl)
+			revocationRepository := mock_service.NewMockRevocationRepositoryI(ctrl)
+			sessionRepository := mock_service.NewMockSessionRepositoryI(ctrl)
+			hasher := mock_hash.NewMockHasher(ctrl)
+			tokenManager := mock_jwt.NewMockTokenManagerI(ctrl)
+			testCase.mock(userRepository, revocationRepository, sessionRepository, hasher, tokenManager)
+
+			account := NewAccountService(userRepository, sessionRepository, revocationRepository, hasher, tokenManager, nil, testAppUrl, testResetTtl, nil)
+			err := account.ResetPassword(context.Background(), input)
+
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
+
+func TestAccountService_VerifyEmail(t *testing.T) {
+	type mockBehaviour func(r *mock_service.MockUserRepositoryI, tm *mock_jwt.MockTokenManagerI)
+
+	claims := jwtauth.Claims{Id: "jti", Subject: "test@example.com"}
+
+	testCases := []struct {
+		name string
+		mock mockBehaviour
+		err  error
+	}{
+		{
+			name: "OK",
+			mock: func(r *mock_service.MockUserRepositoryI, tm *mock_jwt.MockTokenManagerI) {
+				tm.EXPECT().ParseAudience("verification", emailVerificationAudience).Return(claims, nil)
+				r.EXPECT().GetByEmail(context.Background(), "test@example.com").Return(domain.User{Id: "userId"}, nil)
+				r.EXPECT().SetEmailVerified(context.Background(), "userId").Return(nil)
+			},
+			err: nil,
+		},
+		{
+			name: "Already verified",
+			mock: func(r *mock_service.MockUserRepositoryI, tm *mock_jwt.MockTokenManagerI) {
+				tm.EXPECT().ParseAudience("verification", emailVerificationAudience).Return(claims, nil)
+				r.EXPECT().GetByEmail(context.Background(), "test@example.com").Return(domain.User{Id: "userId", EmailVerified: true}, nil)
+			},
+			err: nil,
+		},
+		{
+			name: "Invalid token",
+			mock: func(r *mock_service.MockUserRepositoryI, tm *mock_jwt.MockTokenManagerI) {
+				tm.EXPECT().ParseAudience("verification", emailVerificationAudience).Return(jwtauth.Claims{}, errors.New("token is expired"))
+			},
+			err: domain.ErrInvalidVerificationToken,
+		},
+		{
+			name: "Email changed",
+			mock: func(r *mock_service.MockUserRepositoryI, tm *mock_jwt.MockTokenManagerI) {
+				tm.EXPECT().ParseAudience("verification", emailVerificationAudience).Return(claims, nil)
+				r.EXPECT().GetByEmail(context.Background(), "test@example.com").Return(domain.User{}, domain.ErrUserNotFound)
+			},
+			err: domain.ErrInvalidVerificationToken,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepository := mock_service.NewMockUserRepositoryI(ctrl)
+			tokenManager := mock_jwt.NewMockTokenManagerI(ctrl)
+			testCase.mock(userRepository, tokenManager)
+
+			verifier := NewEmailVerifier(userRepository, tokenManager, nil, testAppUrl, testVerificationTtl)
+			account := NewAccountService(userRepository, nil, nil, nil, tokenManager, nil, testAppUrl, testResetTtl, verifier)
+			err := account.VerifyEmail(context.Background(), domain.VerifyEmailInput{Token: "verification"})
+
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
+
+func TestAccountService_ResendVerification(t *testing.T) {
+	type mockBehaviour func(r *mock_service.MockUserRepositoryI, tm *mock_jwt.MockTokenManagerI, m *mock_mailer.MockMailer)
+
+	testCases := []struct {
+		name string
+		mock mockBehaviour
+		err  error
+	}{
+		{
+			name: "OK",
+			mock: func(r *mock_service.MockUserRepositoryI, tm *mock_jwt.MockTokenManagerI, m *mock_mailer.MockMailer) {
+				r.EXPECT().Get(context.Background(), "userId").Return(domain.User{Id: "userId", Email: "test@example.com"}, nil)
+				tm.EXPECT().NewAudienceToken("test@example.com", emailVerificationAudience, testVerificationTtl).Return("verification", nil)
+				m.EXPECT().
+					Send(context.Background(), mailMessageMatcher{to: "test@example.com", link: testAppUrl + "/verify-email?token=verification"}).
+					Return(nil)
+			},
+			err: nil,
+		},
+		{
+			name: "Already verified",
+			mock: func(r *mock_service.MockUserRepositoryI, tm *mock_jwt.MockTokenManagerI, m *mock_mailer.MockMailer) {
+				r.EXPECT().Get(context.Background(), "userId").Return(domain.User{Id: "userId", Email: "test@example.com", EmailVerified: true}, nil)
+			},
+			err: domain.ErrEmailAlreadyVerified,
+		},
+		{
+			name: "No email",
+			mock: func(r *mock_service.MockUserRepositoryI, tm *mock_jwt.MockTokenManagerI, m *mock_mailer.MockMailer) {
+				r.EXPECT().Get(context.Background(), "userId").Return(domain.User{Id: "userId"}, nil)
+			},
+			err: domain.ErrEmailMissing,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepository := mock_service.NewMockUserRepositoryI(ctrl)
+			tokenManager := mock_jwt.NewMockTokenManagerI(ctrl)
+			mailer := mock_mailer.NewMockMailer(ctrl)
+			testCase.mock(userRepository, tokenManager, mailer)
+
+			verifier := NewEmailVerifier(userRepository, tokenManager, mailer, testAppUrl, testVerificationTtl)
+			account := NewAccountService(userRepository, nil, nil, nil, tokenManager, mailer, testAppUrl, testResetTtl, verifier)
+			err := account.ResendVerification(context.Background(), "userId")
+
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
+
+func TestAccountService_Update(t *testing.T) {
+	type mockBehaviour func(r *mock_service.MockUserRepositoryI, tm *mock_jwt.MockTokenManagerI, m *mock_mailer.MockMailer)
+
+	login := "new"
+	sameLogin := "test"
+	email := " New@Example.com"
+	normalizedEmail := "new@example.com"
+	sameEmail := "TEST@example.com"
+	user := domain.User{Id: "userId", Login: "test", Email: "test@example.com", EmailVerified: true}
+
+	testCases := []struct {
+		name  string
+		input domain.UpdateUserInput
+		mock  mockBehaviour
+		user  domain.User
+		err   error
+	}{
+		{
+			name:  "Login",
+			input: domain.UpdateUserInput{Login: &login},
+			mock: func(r *mock_service.MockUserRepositoryI, tm *mock_jwt.MockTokenManagerI, m *mock_mailer.MockMailer) {
+				r.EXPECT().Get(context.Background(), "userId").Return(user, nil)
+				r.EXPECT().Update(context.Background(), "userId", domain.UpdateUserInput{Login: &login}).
+					Return(domain.User{Id: "userId", Login: "new", Email: "test@example.com", EmailVerified: true}, nil)
+			},
+			user: domain.User{Id: "userId", Login: "new", Email: "test@example.com", EmailVerified: true},
+			err:  nil,
+		},
+		{
+			name:  "Email",
+			input: domain.UpdateUserInput{Email: &email},
+			mock: func(r *mock_service.MockUserRepositoryI, tm *mock_jwt.MockTokenManagerI, m *mock_mailer.MockMailer) {
+				r.EXPECT().Get(context.Background(), "userId").Return(user, nil)
+				r.EXPECT().Update(context.Background(), "userId", domain.UpdateUserInput{Email: &normalizedEmail}).
+					Return(domain.User{Id: "userId", Login: "test", Email: normalizedEmail}, nil)
+				tm.EXPECT().NewAudienceToken(normalizedEmail, emailVerificationAudience, testVerificationTtl).Return("verification", nil)
+				m.EXPECT().
+					Send(context.Background(), mailMessageMatcher{to: normalizedEmail, link: testAppUrl + "/verify-email?token=verification"}).
+					Return(nil)
+			},
+			user: domain.User{Id: "userId", Login: "test", Email: normalizedEmail},
+			err:  nil,
+		},
+		{
+			name:  "Unchanged",
+			input: domain.UpdateUserInput{Login: &sameLogin, Email: &sameEmail},
+			mock: func(r *mock_service.MockUserRepositoryI, tm *mock_jwt.MockTokenManagerI, m *mock_mailer.MockMailer) {
+				r.EXPECT().Get(context.Background(), "userId").Return(user, nil)
+				r.EXPECT().Update(context.Background(), "userId", domain.UpdateUserInput{}).Return(user, nil)
+			},
+			user: user,
+			err:  nil,
+		},
+		{
+			name:  "Repository error",
+			input: domain.UpdateUserInput{Login: &login},
+			mock: func(r *mock_service.MockUserRepositoryI, tm *mock_jwt.MockTokenManagerI, m *mock_mailer.MockMailer) {
+				r.EXPECT().Get(context.Background(), "userId").Return(user, nil)
+				r.EXPECT().Update(context.Background(), "userId", domain.UpdateUserInput{Login: &login}).
+					Return(domain.User{}, errors.New("repository error"))
+			},
+			user: domain.User{},
+			err:  errors.New("repository error"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepository := mock_service.NewMockUserRepositoryI(ctrl)
+			tokenManager := mock_jwt.NewMockTokenManagerI(ctrl)
+			mailer := mock_mailer.NewMockMailer(ctrl)
+			testCase.mock(userRepository, tokenManager, mailer)
+
+			verifier := NewEmailVerifier(userRepository, tokenManager, mailer, testAppUrl, testVerificationTtl)
+			account := NewAccountService(userRepository, nil, nil, nil, tokenManager, mailer, testAppUrl, testResetTtl, verifier)
+			user, err := account.Update(context.Background(), "userId", testCase.input)
+
+			assert.Equal(t, user, testCase.user)
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
+
+func TestAccountService_Delete(t *testing.T) {
+	type mockBehaviour func(r *mock_service.MockUserRepositoryI, rv *mock_service.MockRevocationRepositoryI)
+
+	testCases := []struct {
+		name string
+		mock mockBehaviour
+		err  error
+	}{
+		{
+			name: "OK",
+			mock: func(r *mock_service.MockUserRepositoryI, rv *mock_service.MockRevocationRepositoryI) {
+				rv.EXPECT().RevokeUser(context.Background(), "userId", gomock.Any()).Return(nil)
+				r.EXPECT().Delete(context.Background(), "userId").Return(nil)
+			},
+			err: nil,
+		},
+		{
+			name: "Revocation error",
+			mock: func(r *mock_service.MockUserRepositoryI, rv *mock_service.MockRevocationRepositoryI) {
+				rv.EXPECT().RevokeUser(context.Background(), "userId", gomock.Any()).Return(errors.New("revocation error"))
+			},
+			err: errors.New("revocation error"),
+		},
+		{
+			name: "Repository error",
+			mock: func(r *mock_service.MockUserRepositoryI, rv *mock_service.MockRevocationRepositoryI) {
+				rv.EXPECT().RevokeUser(context.Background(), "userId", gomock.Any()).Return(nil)
+				r.EXPECT().Delete(context.Background(), "userId").Return(errors.New("repository error"))
+			},
+			err: errors.New("repository error"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepository := mock_service.NewMockUserRepositoryI(ctrl)
+			revocationRepository := mock_service.NewMockRevocationRepositoryI(ctrl)
+			testCase.mock(userRepository, revocationRepository)
+
+			account := NewAccountService(userRepository, nil, revocationRepository, nil, nil, nil, testAppUrl, testResetTtl, nil)
+			err := account.Delete(context.Background(), "userId")
+
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"time"
+)
+
+// AdminService lets admins manage the accounts of all users.
+type AdminService struct {
+	users       UserRepositoryI
+	tasks       TaskRepositoryI
+	sessions    SessionRepositoryI
+	revocations RevocationRepositoryI
+}
+
+func NewAdminService(
+	users UserRepositoryI,
+	tasks TaskRepositoryI,
+	sessions SessionRepositoryI,
+	revocations RevocationRepositoryI,
+) *AdminService {
+	return &AdminService{
+		users:       users,
+		tasks:       tasks,
+		sessions:    sessions,
+		revocations: revocations,
+	}
+}
+
+func (s *AdminService) GetUsers(ctx context.Context, filter domain.UserFilter) ([]domain.User, error) {
+	return s.users.GetAll(ctx, filter.Normalize())
+}
+
+// UpdateUser disables, enables and changes the roles of a user. The access tokens of the user are
+// revoked, so the new roles take effect on the next refresh; disabling signs the user out
+// everywhere. Admins can not lock themselves out.
+func (s *AdminService) UpdateUser(ctx context.Context, adminId, id string, in domain.AdminUpdateUserInput) (domain.User, error) {
+	if id == adminId {
+		if in.Disabled != nil && *in.Disabled {
+			return domain.User{}, domain.ErrAdminSelf
+		}
+		if in.Roles != nil && !(domain.Identity{Roles: *in.Roles}).HasRole(domain.RoleAdmin) {
+			return domain.User{}, domain.ErrAdminSelf
+		}
+	}
+
+	if in.Roles != nil {
+		if err := s.users.SetRoles(ctx, id, *in.Roles); err != nil {
+			return domain.User{}, err
+		}
+	}
+
+	if in.Disabled != nil {
+		if err := s.users.SetDisabled(ctx, id, *in.Disabled); err != nil {
+			return domain.User{}, err
+		}
+	}
+
+	if in.Roles != nil || (in.Disabled != nil && *in.Disabled) {
+		if err := s.revocations.RevokeUser(ctx, id, time.Now()); err != nil {
+			return domain.User{}, err
+		}
+	}
+
+	if in.Disabled != nil && *in.Disabled {
+		if err := s.sessions.RevokeUser(ctx, id); err != nil {
+			return domain.User{}, err
+		}
+	}
+
+	return s.users.Get(ctx, id)
+}
+
+// DeleteUser removes the user with everything the user owns, like the user would.
+func (s *AdminService) DeleteUser(ctx context.Context, adminId, id string) error {
+	if id == adminId {
+		return domain.ErrAdminSelf
+	}
+
+	if err := s.revocations.RevokeUser(ctx, id, time.Now()); err != nil {
+		return err
+	}
+
+	return s.users.Delete(ctx, id)
+}
+
+// GetTaskCounts sums up the tasks of all users.
+func (s *AdminService) GetTaskCounts(ctx context.Context) (domain.TaskCounts, error) {
+	return s.tasks.Count(ctx)
+}
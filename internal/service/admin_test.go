@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	mock_service "github.com/i-vasilkov/go-todo-app/internal/service/mocks"
+	"github.com/magiconair/properties/assert"
+	"testing"
+)
+
+func TestAdminService_UpdateUser(t *testing.T) {
+	type mockBehaviour func(
+		r *mock_service.MockUserRepositoryI,
+		sr *mock_service.MockSessionRepositoryI,
+		rv *mock_service.MockRevocationRepositoryI,
+	)
+
+	enabled, disabled := false, true
+	admin := []domain.Role{domain.RoleAdmin}
+	none := []domain.Role{}
+
+	testCases := []struct {
+		name    string
+		adminId string
+		in      domain.AdminUpdateUserInput
+		mock    mockBehaviour
+		user    domain.User
+		err     error
+	}{
+		{
+			name:    "Disable",
+			adminId: "adminId",
+			in:      domain.AdminUpdateUserInput{Disabled: &disabled},
+			mock: func(r *mock_service.MockUserRepositoryI, sr *mock_service.MockSessionRepositoryI, rv *mock_service.MockRevocationRepositoryI) {
+				r.EXPECT().SetDisabled(context.Background(), "userId", true).Return(nil)
+				rv.EXPECT().RevokeUser(context.Background(), "userId", gomock.Any()).Return(nil)
+				sr.EXPECT().RevokeUser(context.Background(), "userId").Return(nil)
+				r.EXPECT().Get(context.Background(), "userId").Return(domain.User{Id: "userId", Disabled: true}, nil)
+			},
+			user: domain.User{Id: "userId", Disabled: true},
+			err:  nil,
+		},
+		{
+			name:    "Enable",
+			adminId: "adminId",
+			in:      domain.AdminUpdateUserInput{Disabled: &enabled},
+			mock: func(r *mock_service.MockUserRepositoryI, sr *mock_service.MockSessionRepositoryI, rv *mock_service.MockRevocationRepositoryI) {
+				r.EXPECT().SetDisabled(context.Background(), "userId", false).Return(nil)
+				r.EXPECT().Get(context.Background(), "userId").Return(domain.User{Id: "userId"}, nil)
+			},
+			user: domain.User{Id: "userId"},
+			err:  nil,
+		},
+		{
+			name:    "Grant admin",
+			adminId: "adminId",
+			in:      domain.AdminUpdateUserInput{Roles: &admin},
+			mock: func(r *mock_service.MockUserRepositoryI, sr *mock_service.MockSessionRepositoryI, rv *mock_service.MockRevocationRepositoryI) {
+				r.EXPECT().SetRoles(context.Background(), "userId", admin).Return(nil)
+				rv.EXPECT().RevokeUser(context.Background(), "userId", gomock.Any()).Return(nil)
+				r.EXPECT().Get(context.Background(), "userId").Return(domain.User{Id: "userId", Roles: admin}, nil)
+			},
+			user: domain.User{Id: "userId", Roles: admin},
+			err:  nil,
+		},
+		{
+			name:    "Disable self",
+			adminId: "userId",
+			in:      domain.AdminUpdateUserInput{Disabled: &disabled},
+			mock: func(r *mock_service.MockUserRepositoryI, sr *mock_service.MockSessionRepositoryI, rv *mock_service.MockRevocationRepositoryI) {
+			},
+			user: domain.User{},
+			err:  domain.ErrAdminSelf,
+		},
+		{
+			name:    "Demote self",
+			adminId: "userId",
+			in:      domain.AdminUpdateUserInput{Roles: &none},
+			mock: func(r *mock_service.MockUserRepositoryI, sr *mock_service.MockSessionRepositoryI, rv *mock_service.MockRevocationRepositoryI) {
+			},
+			user: domain.User{},
+			err:  domain.ErrAdminSelf,
+		},
+		{
+			name:    "Unknown user",
+			adminId: "adminId",
+			in:      domain.AdminUpdateUserInput{Disabled: &disabled},
+			mock: func(r *mock_service.MockUserRepositoryI, sr *mock_service.MockSessionRepositoryI, rv *mock_service.MockRevocationRepositoryI) {
+				r.EXPECT().SetDisabled(context.Background(), "userId", true).Return(domain.ErrUserNotFound)
+			},
+			user: domain.User{},
+			err:  domain.ErrUserNotFound,
+		},
+		{
+			name:    "Revocation error",
+			adminId: "adminId",
+			in:      domain.AdminUpdateUserInput{Disabled: &disabled},
+			mock: func(r *mock_service.MockUserRepositoryI, sr *mock_service.MockSessionRepositoryI, rv *mock_service.MockRevocationRepositoryI) {
+				r.EXPECT().SetDisabled(context.Background(), "userId", true).Return(nil)
+				rv.EXPECT().RevokeUser(context.Background(), "userId", gomock.Any()).Return(errors.New("revocation error"))
+			},
+			user: domain.User{},
+			err:  errors.New("revocation error"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepository := mock_service.NewMockUserRepositoryI(ctrl)
+			sessionRepository := mock_service.NewMockSessionRepositoryI(ctrl)
+			revocationRepository := mock_service.NewMockRevocationRepositoryI(ctrl)
+			testCase.mock(userRepository, sessionRepository, revocationRepository)
+
+			admin := NewAdminService(userRepository, nil, sessionRepository, revocationRepository)
+			user, err := admin.UpdateUser(context.Background(), testCase.adminId, "userId", testCase.in)
+
+			assert.Equal(t, user, testCase.user)
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
+
+func TestAdminService_DeleteUser(t *testing.T) {
+	type mockBehaviour func(r *mock_service.MockUserRepositoryI, rv *mock_service.MockRevocationRepositoryI)
+
+	testCases := []struct {
+		name    string
+		adminId string
+		mock    mockBehaviour
+		err     error
+	}{
+		{
+			name:    "OK",
+			adminId: "adminId",
+			mock: func(r *mock_service.MockUserRepositoryI, rv *mock_service.MockRevocationRepositoryI) {
+				rv.EXPECT().RevokeUser(context.Background(), "userId", gomock.Any()).Return(nil)
+				r.EXPECT().Delete(context.Background(), "userId").Return(nil)
+			},
+			err: nil,
+		},
+		{
+			name:    "Self",
+			adminId: "userId",
+			mock:    func(r *mock_service.MockUserRepositoryI, rv *mock_service.MockRevocationRepositoryI) {},
+			err:     domain.ErrAdminSelf,
+		},
+		{
+			name:    "Unknown user",
+			adminId: "adminId",
+			mock: func(r *mock_service.MockUserRepositoryI, rv *mock_service.MockRevocationRepositoryI) {
+				rv.EXPECT().RevokeUser(context.Background(), "userId", gomock.Any()).Return(nil)
+				r.EXPECT().Delete(context.Background(), "userId").Return(domain.ErrUserNotFound)
+			},
+			err: domain.ErrUserNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepository := mock_service.NewMockUserRepositoryI(ctrl)
+			revocationRepository := mock_service.NewMockRevocationRepositoryI(ctrl)
+			testCase.mock(userRepository, revocationRepository)
+
+			admin := NewAdminService(userRepository, nil, nil, revocationRepository)
+			err := admin.DeleteUser(context.Background(), testCase.adminId, "userId")
+
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
+
+func TestAdminService_GetUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	users := []domain.User{{Id: "userId"}}
+
+	userRepository := mock_service.NewMockUserRepositoryI(ctrl)
+	userRepository.EXPECT().
+		GetAll(context.Background(), domain.UserFilter{Limit: domain.DefaultUserPageLimit, Offset: 10}).
+		Return(users, nil)
+
+	admin := NewAdminService(userRepository, nil, nil, nil)
+	result, err := admin.GetUsers(context.Background(), domain.UserFilter{Offset: 10})
+
+	assert.Equal(t, result, users)
+	assert.Equal(t, err, nil)
+}
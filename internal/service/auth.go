@@ -2,54 +2,526 @@ package service
 
 import (
 	"context"
+	"errors"
 	"github.com/i-vasilkov/go-todo-app/internal/domain"
 	jwtauth "github.com/i-vasilkov/go-todo-app/pkg/auth/jwt"
+	"github.com/i-vasilkov/go-todo-app/pkg/auth/opaque"
+	"github.com/i-vasilkov/go-todo-app/pkg/auth/totp"
 	"github.com/i-vasilkov/go-todo-app/pkg/hash"
+	"log"
+	"time"
 )
 
+// mfaAudience marks the challenge tokens a two-factor sign in is finished with.
+const mfaAudience = "mfa"
+
 type AuthService struct {
-	rep    UserRepositoryI
-	hasher hash.Hasher
-	jwt    jwtauth.TokenManagerI
+	rep          UserRepositoryI
+	sessions     SessionRepositoryI
+	revocations  RevocationRepositoryI
+	hasher       hash.Hasher
+	jwt          jwtauth.TokenManagerI
+	refresh      opaque.TokenManagerI
+	refreshTtl   time.Duration
+	totp         totp.ManagerI
+	challengeTtl time.Duration
+	guard        *SignInGuard
+	verifier     *EmailVerifier
 }
 
-func NewAuthService(rep UserRepositoryI, hasher hash.Hasher, jwt jwtauth.TokenManagerI) *AuthService {
-	return &AuthService{rep: rep, hasher: hasher, jwt: jwt}
+func NewAuthService(
+	rep UserRepositoryI,
+	sessions SessionRepositoryI,
+	revocations RevocationRepositoryI,
+	hasher hash.Hasher,
+	jwt jwtauth.TokenManagerI,
+	refresh opaque.TokenManagerI,
+	refreshTtl time.Duration,
+	totp totp.ManagerI,
+	challengeTtl time.Duration,
+	guard *SignInGuard,
+	verifier *EmailVerifier,
+) *AuthService {
+	return &AuthService{
+		rep:          rep,
+		sessions:     sessions,
+		revocations:  revocations,
+		hasher:       hasher,
+		jwt:          jwt,
+		refresh:      refresh,
+		refreshTtl:   refreshTtl,
+		totp:         totp,
+		challengeTtl: challengeTtl,
+		guard:        guard,
+		verifier:     verifier,
+	}
 }
 
-func (as *AuthService) SignUp(ctx context.Context, in domain.CreateUserInput) (string, error) {
+// SignUp creates the user and mails the link to verify the email. The account is usable right
+// away; when the email can not be sent, the user can ask for another one later.
+func (as *AuthService) SignUp(ctx context.Context, in domain.CreateUserInput) (domain.Tokens, error) {
 	var err error
 	var user domain.User
 
+	in.Email = normalizeEmail(in.Email)
 	in.Password, err = as.hasher.Hash(in.Password)
 	if err != nil {
-		return "", err
+		return domain.Tokens{}, err
 	}
 
 	user, err = as.rep.Create(ctx, in)
 	if err != nil {
-		return "", err
+		return domain.Tokens{}, err
+	}
+
+	if err := as.verifier.Send(ctx, user); err != nil {
+		log.Printf("sending the verification email to user %s: %s", user.Id, err)
 	}
 
-	return as.jwt.NewToken(user.Id)
+	return as.newTokens(ctx, user, "")
 }
 
-func (as *AuthService) SignIn(ctx context.Context, in domain.LoginUserInput) (string, error) {
-	var err error
+// dummyPasswordHash is an argon2id hash made with the default parameters of no real password.
+const dummyPasswordHash = "$argon2id$v=19$m=65536,t=3,p=4$AayvhwrC+7YMPh2n+rjZew$CG7FvtxSnQFEaL2wK/fJ2IdJKD++NxTgK/dLpXz5O18"
 
-	in.Password, err = as.hasher.Hash(in.Password)
+// SignIn verifies the password against the stored hash. A hash made with an outdated
+// scheme is replaced right away, while the plain password is at hand. Users with two-factor
+// authentication get a short-lived challenge instead of tokens, to be answered with SignInMfa.
+// Failed attempts are counted per login and client address by the guard, which turns further
+// attempts away for a while. An unknown login fails the same way as a wrong password, and as
+// slowly, since the password is checked against a dummy hash. Disabled users are told so only
+// once the password is right.
+func (as *AuthService) SignIn(ctx context.Context, in domain.LoginUserInput, clientIp string) (domain.SignInResult, error) {
+	if err := as.guard.Check(ctx, in.Login, clientIp); err != nil {
+		return domain.SignInResult{}, err
+	}
+
+	user, err := as.rep.GetByLogin(ctx, in.Login)
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		return domain.SignInResult{}, err
+	}
+
+	ok := false
+	if err == nil {
+		ok, err = as.hasher.Verify(in.Password, user.Password)
+		if err != nil {
+			return domain.SignInResult{}, err
+		}
+	} else {
+		_, _ = as.hasher.Verify(in.Password, dummyPasswordHash)
+	}
+	if !ok {
+		if err := as.guard.Fail(ctx, in.Login, clientIp); err != nil {
+			return domain.SignInResult{}, err
+		}
+		return domain.SignInResult{}, domain.ErrInvalidCredentials
+	}
+	if user.Disabled {
+		return domain.SignInResult{}, domain.ErrUserDisabled
+	}
+
+	if as.hasher.NeedsRehash(user.Password) {
+		if err := as.rehash(ctx, user.Id, in.Password); err != nil {
+			return domain.SignInResult{}, err
+		}
+	}
+
+	// The failures are kept until the second factor is verified as well, otherwise the right
+	// password would reset the limit for guessing the codes.
+	if user.TotpEnabled {
+		challengeToken, err := as.jwt.NewAudienceToken(user.Id, mfaAudience, as.challengeTtl)
+		if err != nil {
+			return domain.SignInResult{}, err
+		}
+
+		return domain.SignInResult{
+			Challenge: &domain.MfaChallenge{Status: domain.MfaRequired, ChallengeToken: challengeToken},
+		}, nil
+	}
+
+	if err := as.guard.Succeed(ctx, user.Login); err != nil {
+		return domain.SignInResult{}, err
+	}
+
+	tokens, err := as.newTokens(ctx, user, "")
+	if err != nil {
+		return domain.SignInResult{}, err
+	}
+
+	return domain.SignInResult{Tokens: &tokens}, nil
+}
+
+// SignInMfa finishes a sign in with the second factor. The challenge can be answered once, wrong
+// codes count as failed attempts of the login.
+func (as *AuthService) SignInMfa(ctx context.Context, in domain.MfaSignInInput, clientIp string) (domain.Tokens, error) {
+	claims, err := as.jwt.ParseAudience(in.ChallengeToken, mfaAudience)
+	if err != nil {
+		return domain.Tokens{}, domain.ErrInvalidMfaChallenge
+	}
+
+	revoked, err := as.revocations.IsRevoked(ctx, claims.Id, claims.Subject, claims.IssuedAt)
+	if err != nil {
+		return domain.Tokens{}, err
+	}
+	if revoked {
+		return domain.Tokens{}, domain.ErrInvalidMfaChallenge
+	}
+
+	user, err := as.rep.Get(ctx, claims.Subject)
+	if err != nil {
+		return domain.Tokens{}, err
+	}
+	if !user.TotpEnabled || user.Disabled {
+		return domain.Tokens{}, domain.ErrInvalidMfaChallenge
+	}
+
+	if err := as.guard.Check(ctx, user.Login, clientIp); err != nil {
+		return domain.Tokens{}, err
+	}
+
+	err = as.verifySecondFactor(ctx, user, in.Code)
+	if errors.Is(err, domain.ErrInvalidMfaCode) {
+		if err := as.guard.Fail(ctx, user.Login, clientIp); err != nil {
+			return domain.Tokens{}, err
+		}
+		return domain.Tokens{}, domain.ErrInvalidMfaSignInCode
+	}
+	if err != nil {
+		return domain.Tokens{}, err
+	}
+
+	if err := as.revocations.Revoke(ctx, claims.Id, claims.ExpiresAt); err != nil {
+		return domain.Tokens{}, err
+	}
+
+	if err := as.guard.Succeed(ctx, user.Login); err != nil {
+		return domain.Tokens{}, err
+	}
+
+	return as.newTokens(ctx, user, "")
+}
+
+// EnrollTotp starts the enrollment with a new secret. It is not asked for on sign in until
+// ConfirmTotp proves the authenticator app has it; enrolling again replaces a pending secret.
+func (as *AuthService) EnrollTotp(ctx context.Context, userId string) (domain.TotpEnrollment, error) {
+	user, err := as.rep.Get(ctx, userId)
+	if err != nil {
+		return domain.TotpEnrollment{}, err
+	}
+	if user.TotpEnabled {
+		return domain.TotpEnrollment{}, domain.ErrTotpAlreadyEnabled
+	}
+
+	secret, err := as.totp.NewSecret()
+	if err != nil {
+		return domain.TotpEnrollment{}, err
+	}
+
+	if err := as.rep.UpdateTotp(ctx, userId, domain.UpdateTotpInput{Secret: secret}); err != nil {
+		return domain.TotpEnrollment{}, err
+	}
+
+	return domain.TotpEnrollment{
+		Secret:          secret,
+		ProvisioningUri: as.totp.ProvisioningURI(user.Login, secret),
+	}, nil
+}
+
+// ConfirmTotp enables two-factor authentication once the first code matches the pending secret.
+// The recovery codes are returned in plain text this time only.
+func (as *AuthService) ConfirmTotp(ctx context.Context, userId, code string) (domain.RecoveryCodes, error) {
+	user, err := as.rep.Get(ctx, userId)
+	if err != nil {
+		return domain.RecoveryCodes{}, err
+	}
+	if user.TotpEnabled {
+		return domain.RecoveryCodes{}, domain.ErrTotpAlreadyEnabled
+	}
+	if user.TotpSecret == "" {
+		return domain.RecoveryCodes{}, domain.ErrTotpNotEnrolled
+	}
+
+	step, ok := as.totp.Validate(code, user.TotpSecret)
+	if !ok {
+		return domain.RecoveryCodes{}, domain.ErrInvalidMfaCode
+	}
+	used, err := as.rep.UseTotpStep(ctx, userId, step)
+	if err != nil {
+		return domain.RecoveryCodes{}, err
+	}
+	if !used {
+		return domain.RecoveryCodes{}, domain.ErrInvalidMfaCode
+	}
+
+	codes, err := as.totp.NewRecoveryCodes()
+	if err != nil {
+		return domain.RecoveryCodes{}, err
+	}
+
+	hashes := make([]string, 0, len(codes))
+	for _, recoveryCode := range codes {
+		hashes = append(hashes, as.totp.HashRecoveryCode(recoveryCode))
+	}
+
+	err = as.rep.UpdateTotp(ctx, userId, domain.UpdateTotpInput{
+		Secret:        user.TotpSecret,
+		Enabled:       true,
+		RecoveryCodes: hashes,
+	})
+	if err != nil {
+		return domain.RecoveryCodes{}, err
+	}
+
+	return domain.RecoveryCodes{Codes: codes}, nil
+}
+
+// DisableTotp turns two-factor authentication off, given a code or a recovery code.
+func (as *AuthService) DisableTotp(ctx context.Context, userId, code string) error {
+	user, err := as.rep.Get(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if !user.TotpEnabled {
+		return domain.ErrTotpNotEnabled
+	}
+
+	if err := as.verifySecondFactor(ctx, user, code); err != nil {
+		return err
+	}
+
+	return as.rep.UpdateTotp(ctx, userId, domain.UpdateTotpInput{})
+}
+
+// verifySecondFactor accepts the current authenticator code or, failing that, uses up one of
+// the recovery codes. An authenticator code is accepted once, a replayed one is rejected.
+func (as *AuthService) verifySecondFactor(ctx context.Context, user domain.User, code string) error {
+	if step, ok := as.totp.Validate(code, user.TotpSecret); ok {
+		used, err := as.rep.UseTotpStep(ctx, user.Id, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return domain.ErrInvalidMfaCode
+		}
+		return nil
+	}
+
+	used, err := as.rep.UseRecoveryCode(ctx, user.Id, as.totp.HashRecoveryCode(code))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return domain.ErrInvalidMfaCode
+	}
+
+	return nil
+}
+
+// ChangePassword replaces the password given the current one, which is guessed at the same pace
+// as on sign in. All the other sessions of the user are signed out, while the caller goes on with
+// the returned tokens.
+func (as *AuthService) ChangePassword(ctx context.Context, userId string, in domain.ChangePasswordInput, clientIp string) (domain.Tokens, error) {
+	user, err := as.rep.Get(ctx, userId)
+	if err != nil {
+		return domain.Tokens{}, err
+	}
+
+	if err := as.guard.Check(ctx, user.Login, clientIp); err != nil {
+		return domain.Tokens{}, err
+	}
+
+	ok, err := as.hasher.Verify(in.CurrentPassword, user.Password)
+	if err != nil {
+		return domain.Tokens{}, err
+	}
+	if !ok {
+		if err := as.guard.Fail(ctx, user.Login, clientIp); err != nil {
+			return domain.Tokens{}, err
+		}
+		return domain.Tokens{}, domain.ErrWrongPassword
+	}
+
+	if err := as.rehash(ctx, userId, in.NewPassword); err != nil {
+		return domain.Tokens{}, err
+	}
+
+	// Tokens carry their issue time in whole seconds, the new access token would be revoked
+	// together with the old ones otherwise.
+	if err := as.revocations.RevokeUser(ctx, userId, time.Now().Truncate(time.Second)); err != nil {
+		return domain.Tokens{}, err
+	}
+
+	if err := as.sessions.RevokeUser(ctx, userId); err != nil {
+		return domain.Tokens{}, err
+	}
+
+	return as.newTokens(ctx, user, "")
+}
+
+func (as *AuthService) rehash(ctx context.Context, userId, password string) error {
+	hash, err := as.hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	return as.rep.UpdatePassword(ctx, userId, hash)
+}
+
+// Refresh exchanges a refresh token for a new pair. Every refresh token can be used once:
+// presenting one that was already rotated means it leaked, so its whole family is revoked
+// and both the attacker and the legitimate client have to sign in again. The user is read again,
+// so the new access token carries the current roles.
+func (as *AuthService) Refresh(ctx context.Context, refreshToken string) (domain.Tokens, error) {
+	session, err := as.sessions.GetByTokenHash(ctx, as.refresh.Hash(refreshToken))
+	if errors.Is(err, domain.ErrSessionNotFound) {
+		return domain.Tokens{}, domain.ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return domain.Tokens{}, err
+	}
+
+	if session.RevokedAt != nil {
+		return domain.Tokens{}, as.revokeFamily(ctx, session.FamilyId)
+	}
+
+	if !session.ExpiresAt.After(time.Now()) {
+		return domain.Tokens{}, domain.ErrInvalidRefreshToken
+	}
+
+	revoked, err := as.sessions.Revoke(ctx, session.Id)
+	if err != nil {
+		return domain.Tokens{}, err
+	}
+	if !revoked {
+		// A concurrent request has rotated the same token first.
+		return domain.Tokens{}, as.revokeFamily(ctx, session.FamilyId)
+	}
+
+	user, err := as.rep.Get(ctx, session.UserId)
+	if err != nil {
+		return domain.Tokens{}, err
+	}
+	if user.Disabled {
+		return domain.Tokens{}, domain.ErrInvalidRefreshToken
+	}
+
+	return as.newTokens(ctx, user, session.FamilyId)
+}
+
+func (as *AuthService) revokeFamily(ctx context.Context, familyId string) error {
+	if err := as.sessions.RevokeFamily(ctx, familyId); err != nil {
+		return err
+	}
+
+	return domain.ErrInvalidRefreshToken
+}
+
+// newTokens issues an access token with the roles of the user together with a refresh token
+// continuing the given family. An empty family starts a new one, named after the hash of its
+// first token.
+func (as *AuthService) newTokens(ctx context.Context, user domain.User, familyId string) (domain.Tokens, error) {
+	var roles []string
+	for _, role := range user.Roles {
+		roles = append(roles, string(role))
+	}
+
+	accessToken, err := as.jwt.NewToken(user.Id, roles)
+	if err != nil {
+		return domain.Tokens{}, err
+	}
+
+	refreshToken, err := as.refresh.NewToken()
+	if err != nil {
+		return domain.Tokens{}, err
+	}
+
+	tokenHash := as.refresh.Hash(refreshToken)
+	if familyId == "" {
+		familyId = tokenHash
+	}
+
+	_, err = as.sessions.Create(ctx, domain.CreateSessionInput{
+		UserId:    user.Id,
+		FamilyId:  familyId,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(as.refreshTtl).UTC(),
+	})
+	if err != nil {
+		return domain.Tokens{}, err
+	}
+
+	return domain.Tokens{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+// Logout revokes the access token until it expires. The refresh token, when given, is revoked
+// together with its whole family; an unknown one or one of another user is left alone.
+func (as *AuthService) Logout(ctx context.Context, accessToken string, in domain.LogoutInput) error {
+	claims, err := as.jwt.Parse(accessToken)
+	if err != nil {
+		return err
+	}
+
+	// Tokens issued before ids were introduced can only be revoked along with all the others.
+	if claims.Id == "" {
+		if err := as.revocations.RevokeUser(ctx, claims.Subject, time.Now()); err != nil {
+			return err
+		}
+	} else if err := as.revocations.Revoke(ctx, claims.Id, claims.ExpiresAt); err != nil {
+		return err
+	}
+
+	if in.RefreshToken == "" {
+		return nil
+	}
+
+	session, err := as.sessions.GetByTokenHash(ctx, as.refresh.Hash(in.RefreshToken))
+	if errors.Is(err, domain.ErrSessionNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if session.UserId != claims.Subject {
+		return nil
+	}
+
+	return as.sessions.RevokeFamily(ctx, session.FamilyId)
+}
+
+// LogoutAll revokes every access and refresh token the user holds.
+func (as *AuthService) LogoutAll(ctx context.Context, userId string) error {
+	if err := as.revocations.RevokeUser(ctx, userId, time.Now()); err != nil {
+		return err
+	}
+
+	return as.sessions.RevokeUser(ctx, userId)
+}
+
+// CheckToken returns the user the access token was issued to, with the roles it carries. Tokens
+// are revoked whenever the roles of a user change, so they can be trusted until it expires.
+func (as *AuthService) CheckToken(ctx context.Context, token string) (domain.Identity, error) {
+	claims, err := as.jwt.Parse(token)
 	if err != nil {
-		return "", err
+		return domain.Identity{}, err
 	}
 
-	user, err := as.rep.GetByCredentials(ctx, in)
+	revoked, err := as.revocations.IsRevoked(ctx, claims.Id, claims.Subject, claims.IssuedAt)
 	if err != nil {
-		return "", err
+		return domain.Identity{}, err
+	}
+	if revoked {
+		return domain.Identity{}, domain.ErrTokenRevoked
+	}
+
+	identity := domain.Identity{UserId: claims.Subject}
+	for _, role := range claims.Roles {
+		identity.Roles = append(identity.Roles, domain.Role(role))
 	}
 
-	return as.jwt.NewToken(user.Id)
+	return identity, nil
 }
 
-func (as *AuthService) CheckToken(ctx context.Context, token string) (string, error) {
-	return as.jwt.Parse(token)
+// GetJWKS returns the public keys other services verify access tokens with.
+func (as *AuthService) GetJWKS(ctx context.Context) jwtauth.JWKS {
+	return as.jwt.JWKS()
 }
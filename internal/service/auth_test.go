@@ -3,21 +3,100 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/golang/mock/gomock"
 	"github.com/i-vasilkov/go-todo-app/internal/domain"
-	"github.com/i-vasilkov/go-todo-app/internal/repository/mongorep"
 	mock_service "github.com/i-vasilkov/go-todo-app/internal/service/mocks"
+	jwtauth "github.com/i-vasilkov/go-todo-app/pkg/auth/jwt"
 	mock_jwt "github.com/i-vasilkov/go-todo-app/pkg/auth/jwt/mocks"
+	mock_opaque "github.com/i-vasilkov/go-todo-app/pkg/auth/opaque/mocks"
+	mock_totp "github.com/i-vasilkov/go-todo-app/pkg/auth/totp/mocks"
 	"github.com/i-vasilkov/go-todo-app/pkg/hash"
 	mock_hash "github.com/i-vasilkov/go-todo-app/pkg/hash/mocks"
+	mock_mailer "github.com/i-vasilkov/go-todo-app/pkg/mailer/mocks"
 	"github.com/magiconair/properties/assert"
 	"testing"
+	"time"
 )
 
+const (
+	testRefreshTtl   = time.Hour
+	testChallengeTtl = 5 * time.Minute
+	testClientIp     = "192.0.2.1"
+)
+
+var testSignInLimits = SignInLimits{
+	Window:         time.Hour,
+	BackoffAfter:   3,
+	IpBackoffAfter: 20,
+	BackoffBase:    time.Second,
+	BackoffMax:     time.Minute,
+	LockAfter:      10,
+	LockDuration:   time.Hour,
+}
+
+var testTokens = domain.Tokens{AccessToken: "token", RefreshToken: "refresh"}
+
+// sessionInputMatcher matches a session input regardless of the moment it was created at,
+// as long as it expires within the refresh TTL.
+type sessionInputMatcher struct {
+	in domain.CreateSessionInput
+}
+
+func (m sessionInputMatcher) Matches(x interface{}) bool {
+	in, ok := x.(domain.CreateSessionInput)
+	if !ok {
+		return false
+	}
+
+	now := time.Now()
+	return in.UserId == m.in.UserId &&
+		in.FamilyId == m.in.FamilyId &&
+		in.TokenHash == m.in.TokenHash &&
+		in.ExpiresAt.After(now) &&
+		!in.ExpiresAt.After(now.Add(testRefreshTtl))
+}
+
+func (m sessionInputMatcher) String() string {
+	return fmt.Sprintf("is session for user %q in family %q", m.in.UserId, m.in.FamilyId)
+}
+
+// expectNewSession sets up the mocks for a refresh token issued to the user in the family.
+func expectNewSession(rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI, userId, familyId string) {
+	rm.EXPECT().NewToken().Return("refresh", nil)
+	rm.EXPECT().Hash("refresh").Return("refresh hash")
+	if familyId == "" {
+		familyId = "refresh hash"
+	}
+	sr.EXPECT().
+		Create(context.Background(), sessionInputMatcher{domain.CreateSessionInput{UserId: userId, FamilyId: familyId, TokenHash: "refresh hash"}}).
+		Return(domain.Session{Id: "sessionId", UserId: userId, FamilyId: familyId, TokenHash: "refresh hash"}, nil)
+}
+
+// expectSignInAllowed sets up the guard checks of a login without recent failures.
+func expectSignInAllowed(a *mock_service.MockLoginAttemptRepositoryI, login string) {
+	a.EXPECT().Get(context.Background(), "login:"+login).Return(domain.LoginAttempts{Key: "login:" + login}, nil)
+	a.EXPECT().Get(context.Background(), "ip:"+testClientIp).Return(domain.LoginAttempts{Key: "ip:" + testClientIp}, nil)
+}
+
+// expectSignInFailed sets up the failure counting of a login without recent failures.
+func expectSignInFailed(a *mock_service.MockLoginAttemptRepositoryI, login string) {
+	a.EXPECT().AddFailure(context.Background(), "login:"+login, gomock.Any(), testSignInLimits.Window).
+		Return(domain.LoginAttempts{Key: "login:" + login, Failures: 1}, nil)
+	a.EXPECT().AddFailure(context.Background(), "ip:"+testClientIp, gomock.Any(), testSignInLimits.Window).
+		Return(domain.LoginAttempts{Key: "ip:" + testClientIp, Failures: 1}, nil)
+}
+
 func TestAuthService_SignIn(t *testing.T) {
 	type tokenManagerMockBehaviour func(tm *mock_jwt.MockTokenManagerI, in domain.LoginUserInput)
 	type hasherMockBehaviour func(h *mock_hash.MockHasher, in domain.LoginUserInput)
 	type repositoryMockBehaviour func(r *mock_service.MockUserRepositoryI, in domain.LoginUserInput)
+	type sessionMockBehaviour func(rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI)
+	type guardMockBehaviour func(a *mock_service.MockLoginAttemptRepositoryI)
+
+	user := domain.User{Id: "userId", Login: "test", Password: "hash"}
+	totpUser := domain.User{Id: "userId", Login: "test", Password: "hash", TotpSecret: "secret", TotpEnabled: true}
+	disabledUser := domain.User{Id: "userId", Login: "test", Password: "hash", Disabled: true}
 
 	testCases := []struct {
 		name             string
@@ -25,7 +104,9 @@ func TestAuthService_SignIn(t *testing.T) {
 		hasherMock       hasherMockBehaviour
 		repositoryMock   repositoryMockBehaviour
 		tokenManagerMock tokenManagerMockBehaviour
-		token            string
+		sessionMock      sessionMockBehaviour
+		guardMock        guardMockBehaviour
+		result           domain.SignInResult
 		err              error
 	}{
 		{
@@ -35,46 +116,177 @@ func TestAuthService_SignIn(t *testing.T) {
 				Password: "test",
 			},
 			hasherMock: func(h *mock_hash.MockHasher, in domain.LoginUserInput) {
-				h.EXPECT().Hash(in.Password).Return(in.Password, nil)
+				h.EXPECT().Verify(in.Password, user.Password).Return(true, nil)
+				h.EXPECT().NeedsRehash(user.Password).Return(false)
 			},
 			repositoryMock: func(r *mock_service.MockUserRepositoryI, in domain.LoginUserInput) {
-				r.EXPECT().GetByCredentials(context.Background(), in).Return(domain.User{Id: "userId"}, nil)
+				r.EXPECT().GetByLogin(context.Background(), in.Login).Return(user, nil)
 			},
 			tokenManagerMock: func(tm *mock_jwt.MockTokenManagerI, in domain.LoginUserInput) {
-				tm.EXPECT().NewToken("userId").Return("token", nil)
+				tm.EXPECT().NewToken("userId", nil).Return("token", nil)
 			},
-			token: "token",
-			err:   nil,
+			sessionMock: func(rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI) {
+				expectNewSession(rm, sr, "userId", "")
+			},
+			guardMock: func(a *mock_service.MockLoginAttemptRepositoryI) {
+				expectSignInAllowed(a, "test")
+				a.EXPECT().Reset(context.Background(), "login:test").Return(nil)
+			},
+			result: domain.SignInResult{Tokens: &testTokens},
+			err:    nil,
 		},
 		{
-			name: "Hasher error",
+			name: "Disabled user",
 			input: domain.LoginUserInput{
 				Login:    "test",
 				Password: "test",
 			},
 			hasherMock: func(h *mock_hash.MockHasher, in domain.LoginUserInput) {
-				h.EXPECT().Hash(in.Password).Return("", errors.New("hasher error"))
+				h.EXPECT().Verify(in.Password, disabledUser.Password).Return(true, nil)
+			},
+			repositoryMock: func(r *mock_service.MockUserRepositoryI, in domain.LoginUserInput) {
+				r.EXPECT().GetByLogin(context.Background(), in.Login).Return(disabledUser, nil)
 			},
-			repositoryMock:   func(r *mock_service.MockUserRepositoryI, in domain.LoginUserInput) {},
 			tokenManagerMock: func(tm *mock_jwt.MockTokenManagerI, in domain.LoginUserInput) {},
-			token:            "",
-			err:              errors.New("hasher error"),
+			sessionMock:      func(rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI) {},
+			guardMock: func(a *mock_service.MockLoginAttemptRepositoryI) {
+				expectSignInAllowed(a, "test")
+			},
+			result: domain.SignInResult{},
+			err:    domain.ErrUserDisabled,
 		},
 		{
-			name: "Repository error",
+			name: "Legacy hash",
 			input: domain.LoginUserInput{
 				Login:    "test",
 				Password: "test",
 			},
 			hasherMock: func(h *mock_hash.MockHasher, in domain.LoginUserInput) {
-				h.EXPECT().Hash(in.Password).Return(in.Password, nil)
+				h.EXPECT().Verify(in.Password, user.Password).Return(true, nil)
+				h.EXPECT().NeedsRehash(user.Password).Return(true)
+				h.EXPECT().Hash(in.Password).Return("new hash", nil)
 			},
 			repositoryMock: func(r *mock_service.MockUserRepositoryI, in domain.LoginUserInput) {
-				r.EXPECT().GetByCredentials(context.Background(), in).Return(domain.User{}, errors.New("repository error"))
+				r.EXPECT().GetByLogin(context.Background(), in.Login).Return(user, nil)
+				r.EXPECT().UpdatePassword(context.Background(), user.Id, "new hash").Return(nil)
+			},
+			tokenManagerMock: func(tm *mock_jwt.MockTokenManagerI, in domain.LoginUserInput) {
+				tm.EXPECT().NewToken("userId", nil).Return("token", nil)
+			},
+			sessionMock: func(rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI) {
+				expectNewSession(rm, sr, "userId", "")
+			},
+			guardMock: func(a *mock_service.MockLoginAttemptRepositoryI) {
+				expectSignInAllowed(a, "test")
+				a.EXPECT().Reset(context.Background(), "login:test").Return(nil)
+			},
+			result: domain.SignInResult{Tokens: &testTokens},
+			err:    nil,
+		},
+		{
+			name: "Two-factor enabled",
+			input: domain.LoginUserInput{
+				Login:    "test",
+				Password: "test",
+			},
+			hasherMock: func(h *mock_hash.MockHasher, in domain.LoginUserInput) {
+				h.EXPECT().Verify(in.Password, totpUser.Password).Return(true, nil)
+				h.EXPECT().NeedsRehash(totpUser.Password).Return(false)
+			},
+			repositoryMock: func(r *mock_service.MockUserRepositoryI, in domain.LoginUserInput) {
+				r.EXPECT().GetByLogin(context.Background(), in.Login).Return(totpUser, nil)
+			},
+			tokenManagerMock: func(tm *mock_jwt.MockTokenManagerI, in domain.LoginUserInput) {
+				tm.EXPECT().NewAudienceToken("userId", mfaAudience, testChallengeTtl).Return("challenge", nil)
+			},
+			sessionMock: func(rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI) {},
+			guardMock: func(a *mock_service.MockLoginAttemptRepositoryI) {
+				expectSignInAllowed(a, "test")
+			},
+			result: domain.SignInResult{
+				Challenge: &domain.MfaChallenge{Status: domain.MfaRequired, ChallengeToken: "challenge"},
+			},
+			err: nil,
+		},
+		{
+			name: "Wrong password",
+			input: domain.LoginUserInput{
+				Login:    "test",
+				Password: "wrong",
+			},
+			hasherMock: func(h *mock_hash.MockHasher, in domain.LoginUserInput) {
+				h.EXPECT().Verify(in.Password, user.Password).Return(false, nil)
+			},
+			repositoryMock: func(r *mock_service.MockUserRepositoryI, in domain.LoginUserInput) {
+				r.EXPECT().GetByLogin(context.Background(), in.Login).Return(user, nil)
 			},
 			tokenManagerMock: func(tm *mock_jwt.MockTokenManagerI, in domain.LoginUserInput) {},
-			token:            "",
-			err:              errors.New("repository error"),
+			sessionMock:      func(rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI) {},
+			guardMock: func(a *mock_service.MockLoginAttemptRepositoryI) {
+				expectSignInAllowed(a, "test")
+				expectSignInFailed(a, "test")
+			},
+			result: domain.SignInResult{},
+			err:    domain.ErrInvalidCredentials,
+		},
+		{
+			name: "Unknown login",
+			input: domain.LoginUserInput{
+				Login:    "test",
+				Password: "test",
+			},
+			hasherMock: func(h *mock_hash.MockHasher, in domain.LoginUserInput) {
+				h.EXPECT().Verify(in.Password, dummyPasswordHash).Return(false, nil)
+			},
+			repositoryMock: func(r *mock_service.MockUserRepositoryI, in domain.LoginUserInput) {
+				r.EXPECT().GetByLogin(context.Background(), in.Login).Return(domain.User{}, domain.ErrUserNotFound)
+			},
+			tokenManagerMock: func(tm *mock_jwt.MockTokenManagerI, in domain.LoginUserInput) {},
+			sessionMock:      func(rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI) {},
+			guardMock: func(a *mock_service.MockLoginAttemptRepositoryI) {
+				expectSignInAllowed(a, "test")
+				expectSignInFailed(a, "test")
+			},
+			result: domain.SignInResult{},
+			err:    domain.ErrInvalidCredentials,
+		},
+		{
+			name: "Hasher error",
+			input: domain.LoginUserInput{
+				Login:    "test",
+				Password: "test",
+			},
+			hasherMock: func(h *mock_hash.MockHasher, in domain.LoginUserInput) {
+				h.EXPECT().Verify(in.Password, user.Password).Return(false, errors.New("hasher error"))
+			},
+			repositoryMock: func(r *mock_service.MockUserRepositoryI, in domain.LoginUserInput) {
+				r.EXPECT().GetByLogin(context.Background(), in.Login).Return(user, nil)
+			},
+			tokenManagerMock: func(tm *mock_jwt.MockTokenManagerI, in domain.LoginUserInput) {},
+			sessionMock:      func(rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI) {},
+			guardMock: func(a *mock_service.MockLoginAttemptRepositoryI) {
+				expectSignInAllowed(a, "test")
+			},
+			result: domain.SignInResult{},
+			err:    errors.New("hasher error"),
+		},
+		{
+			name: "Repository error",
+			input: domain.LoginUserInput{
+				Login:    "test",
+				Password: "test",
+			},
+			hasherMock: func(h *mock_hash.MockHasher, in domain.LoginUserInput) {},
+			repositoryMock: func(r *mock_service.MockUserRepositoryI, in domain.LoginUserInput) {
+				r.EXPECT().GetByLogin(context.Background(), in.Login).Return(domain.User{}, errors.New("repository error"))
+			},
+			tokenManagerMock: func(tm *mock_jwt.MockTokenManagerI, in domain.LoginUserInput) {},
+			sessionMock:      func(rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI) {},
+			guardMock: func(a *mock_service.MockLoginAttemptRepositoryI) {
+				expectSignInAllowed(a, "test")
+			},
+			result: domain.SignInResult{},
+			err:    errors.New("repository error"),
 		},
 		{
 			name: "TokenManager error",
@@ -83,16 +295,38 @@ func TestAuthService_SignIn(t *testing.T) {
 				Password: "test",
 			},
 			hasherMock: func(h *mock_hash.MockHasher, in domain.LoginUserInput) {
-				h.EXPECT().Hash(in.Password).Return(in.Password, nil)
+				h.EXPECT().Verify(in.Password, user.Password).Return(true, nil)
+				h.EXPECT().NeedsRehash(user.Password).Return(false)
 			},
 			repositoryMock: func(r *mock_service.MockUserRepositoryI, in domain.LoginUserInput) {
-				r.EXPECT().GetByCredentials(context.Background(), in).Return(domain.User{Id: "userId"}, nil)
+				r.EXPECT().GetByLogin(context.Background(), in.Login).Return(user, nil)
 			},
 			tokenManagerMock: func(tm *mock_jwt.MockTokenManagerI, in domain.LoginUserInput) {
-				tm.EXPECT().NewToken("userId").Return("", errors.New("token manager error"))
+				tm.EXPECT().NewToken("userId", nil).Return("", errors.New("token manager error"))
+			},
+			sessionMock: func(rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI) {},
+			guardMock: func(a *mock_service.MockLoginAttemptRepositoryI) {
+				expectSignInAllowed(a, "test")
+				a.EXPECT().Reset(context.Background(), "login:test").Return(nil)
+			},
+			result: domain.SignInResult{},
+			err:    errors.New("token manager error"),
+		},
+		{
+			name: "Guard error",
+			input: domain.LoginUserInput{
+				Login:    "test",
+				Password: "test",
+			},
+			hasherMock:       func(h *mock_hash.MockHasher, in domain.LoginUserInput) {},
+			repositoryMock:   func(r *mock_service.MockUserRepositoryI, in domain.LoginUserInput) {},
+			tokenManagerMock: func(tm *mock_jwt.MockTokenManagerI, in domain.LoginUserInput) {},
+			sessionMock:      func(rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI) {},
+			guardMock: func(a *mock_service.MockLoginAttemptRepositoryI) {
+				a.EXPECT().Get(context.Background(), "login:test").Return(domain.LoginAttempts{}, errors.New("attempts error"))
 			},
-			token: "",
-			err:   errors.New("token manager error"),
+			result: domain.SignInResult{},
+			err:    errors.New("attempts error"),
 		},
 	}
 
@@ -110,19 +344,36 @@ func TestAuthService_SignIn(t *testing.T) {
 			userRepository := mock_service.NewMockUserRepositoryI(ctrl)
 			testCase.repositoryMock(userRepository, testCase.input)
 
-			auth := NewAuthService(userRepository, hasher, tokenManager)
-			token, err := auth.SignIn(context.Background(), testCase.input)
+			refreshManager := mock_opaque.NewMockTokenManagerI(ctrl)
+			sessionRepository := mock_service.NewMockSessionRepositoryI(ctrl)
+			testCase.sessionMock(refreshManager, sessionRepository)
 
-			assert.Equal(t, token, testCase.token)
+			attemptRepository := mock_service.NewMockLoginAttemptRepositoryI(ctrl)
+			testCase.guardMock(attemptRepository)
+			guard := NewSignInGuard(attemptRepository, testSignInLimits)
+
+			auth := NewAuthService(userRepository, sessionRepository, nil, hasher, tokenManager, refreshManager, testRefreshTtl, nil, testChallengeTtl, guard, nil)
+			result, err := auth.SignIn(context.Background(), testCase.input, testClientIp)
+
+			assert.Equal(t, result, testCase.result)
 			assert.Equal(t, err, testCase.err)
 		})
 	}
 }
 
+// The dummy hash only costs as much as a real one while it uses the parameters passwords are hashed with.
+func TestDummyPasswordHash(t *testing.T) {
+	hasher := hash.NewArgon2idHasher(hash.DefaultArgon2idParams, nil)
+
+	assert.Equal(t, hasher.NeedsRehash(dummyPasswordHash), false)
+}
+
 func TestAuthService_SignUp(t *testing.T) {
 	type tokenManagerMockBehaviour func(tm *mock_jwt.MockTokenManagerI, in domain.CreateUserInput)
 	type hasherMockBehaviour func(h *mock_hash.MockHasher, in domain.CreateUserInput)
 	type repositoryMockBehaviour func(r *mock_service.MockUserRepositoryI, in domain.CreateUserInput)
+	type sessionMockBehaviour func(rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI)
+	type mailerMockBehaviour func(m *mock_mailer.MockMailer)
 
 	testCases := []struct {
 		name             string
@@ -130,31 +381,42 @@ func TestAuthService_SignUp(t *testing.T) {
 		hasherMock       hasherMockBehaviour
 		repositoryMock   repositoryMockBehaviour
 		tokenManagerMock tokenManagerMockBehaviour
-		token            string
+		sessionMock      sessionMockBehaviour
+		mailerMock       mailerMockBehaviour
+		tokens           domain.Tokens
 		err              error
 	}{
 		{
 			name: "OK",
 			input: domain.CreateUserInput{
 				Login:    "test",
+				Email:    "test@example.com",
 				Password: "test",
 			},
 			hasherMock: func(h *mock_hash.MockHasher, in domain.CreateUserInput) {
 				h.EXPECT().Hash(in.Password).Return(in.Password, nil)
 			},
 			repositoryMock: func(r *mock_service.MockUserRepositoryI, in domain.CreateUserInput) {
-				r.EXPECT().Create(context.Background(), in).Return(domain.User{Id: "userId"}, nil)
+				r.EXPECT().Create(context.Background(), in).Return(domain.User{Id: "userId", Email: in.Email}, nil)
 			},
 			tokenManagerMock: func(tm *mock_jwt.MockTokenManagerI, in domain.CreateUserInput) {
-				tm.EXPECT().NewToken("userId").Return("token", nil)
+				tm.EXPECT().NewAudienceToken(in.Email, emailVerificationAudience, testVerificationTtl).Return("verification", nil)
+				tm.EXPECT().NewToken("userId", nil).Return("token", nil)
 			},
-			token: "token",
-			err:   nil,
+			sessionMock: func(rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI) {
+				expectNewSession(rm, sr, "userId", "")
+			},
+			mailerMock: func(m *mock_mailer.MockMailer) {
+				m.EXPECT().Send(context.Background(), mailMessageMatcher{to: "test@example.com", link: testAppUrl + "/verify-email?token=verification"}).Return(nil)
+			},
+			tokens: testTokens,
+			err:    nil,
 		},
 		{
 			name: "Hasher error",
 			input: domain.CreateUserInput{
 				Login:    "test",
+				Email:    "test@example.com",
 				Password: "test",
 			},
 			hasherMock: func(h *mock_hash.MockHasher, in domain.CreateUserInput) {
@@ -162,13 +424,16 @@ func TestAuthService_SignUp(t *testing.T) {
 			},
 			repositoryMock:   func(r *mock_service.MockUserRepositoryI, in domain.CreateUserInput) {},
 			tokenManagerMock: func(tm *mock_jwt.MockTokenManagerI, in domain.CreateUserInput) {},
-			token:            "",
+			sessionMock:      func(rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI) {},
+			mailerMock:       func(m *mock_mailer.MockMailer) {},
+			tokens:           domain.Tokens{},
 			err:              errors.New("hasher error"),
 		},
 		{
 			name: "Repository error",
 			input: domain.CreateUserInput{
 				Login:    "test",
+				Email:    "test@example.com",
 				Password: "test",
 			},
 			hasherMock: func(h *mock_hash.MockHasher, in domain.CreateUserInput) {
@@ -178,26 +443,115 @@ func TestAuthService_SignUp(t *testing.T) {
 				r.EXPECT().Create(context.Background(), in).Return(domain.User{}, errors.New("repository error"))
 			},
 			tokenManagerMock: func(tm *mock_jwt.MockTokenManagerI, in domain.CreateUserInput) {},
-			token:            "",
+			sessionMock:      func(rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI) {},
+			mailerMock:       func(m *mock_mailer.MockMailer) {},
+			tokens:           domain.Tokens{},
 			err:              errors.New("repository error"),
 		},
 		{
 			name: "TokenManager error",
 			input: domain.CreateUserInput{
 				Login:    "test",
+				Email:    "test@example.com",
 				Password: "test",
 			},
 			hasherMock: func(h *mock_hash.MockHasher, in domain.CreateUserInput) {
 				h.EXPECT().Hash(in.Password).Return(in.Password, nil)
 			},
 			repositoryMock: func(r *mock_service.MockUserRepositoryI, in domain.CreateUserInput) {
-				r.EXPECT().Create(context.Background(), in).Return(domain.User{Id: "userId"}, nil)
+				r.EXPECT().Create(context.Background(), in).Return(domain.User{Id: "userId", Email: in.Email}, nil)
 			},
 			tokenManagerMock: func(tm *mock_jwt.MockTokenManagerI, in domain.CreateUserInput) {
-				tm.EXPECT().NewToken("userId").Return("", errors.New("token manager error"))
+				tm.EXPECT().NewAudienceToken(in.Email, emailVerificationAudience, testVerificationTtl).Return("verification", nil)
+				tm.EXPECT().NewToken("userId", nil).Return("", errors.New("token manager error"))
+			},
+			sessionMock: func(rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI) {},
+			mailerMock: func(m *mock_mailer.MockMailer) {
+				m.EXPECT().Send(context.Background(), gomock.Any()).Return(nil)
 			},
-			token: "",
-			err:   errors.New("token manager error"),
+			tokens: domain.Tokens{},
+			err:    errors.New("token manager error"),
+		},
+		{
+			name: "Session repository error",
+			input: domain.CreateUserInput{
+				Login:    "test",
+				Email:    "test@example.com",
+				Password: "test",
+			},
+			hasherMock: func(h *mock_hash.MockHasher, in domain.CreateUserInput) {
+				h.EXPECT().Hash(in.Password).Return(in.Password, nil)
+			},
+			repositoryMock: func(r *mock_service.MockUserRepositoryI, in domain.CreateUserInput) {
+				r.EXPECT().Create(context.Background(), in).Return(domain.User{Id: "userId", Email: in.Email}, nil)
+			},
+			tokenManagerMock: func(tm *mock_jwt.MockTokenManagerI, in domain.CreateUserInput) {
+				tm.EXPECT().NewAudienceToken(in.Email, emailVerificationAudience, testVerificationTtl).Return("verification", nil)
+				tm.EXPECT().NewToken("userId", nil).Return("token", nil)
+			},
+			sessionMock: func(rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI) {
+				rm.EXPECT().NewToken().Return("refresh", nil)
+				rm.EXPECT().Hash("refresh").Return("refresh hash")
+				sr.EXPECT().Create(context.Background(), gomock.Any()).Return(domain.Session{}, errors.New("session repository error"))
+			},
+			mailerMock: func(m *mock_mailer.MockMailer) {
+				m.EXPECT().Send(context.Background(), gomock.Any()).Return(nil)
+			},
+			tokens: domain.Tokens{},
+			err:    errors.New("session repository error"),
+		},
+		{
+			name: "Email normalized",
+			input: domain.CreateUserInput{
+				Login:    "test",
+				Email:    " Test@Example.COM ",
+				Password: "test",
+			},
+			hasherMock: func(h *mock_hash.MockHasher, in domain.CreateUserInput) {
+				h.EXPECT().Hash(in.Password).Return(in.Password, nil)
+			},
+			repositoryMock: func(r *mock_service.MockUserRepositoryI, in domain.CreateUserInput) {
+				in.Email = "test@example.com"
+				r.EXPECT().Create(context.Background(), in).Return(domain.User{Id: "userId", Email: in.Email}, nil)
+			},
+			tokenManagerMock: func(tm *mock_jwt.MockTokenManagerI, in domain.CreateUserInput) {
+				tm.EXPECT().NewAudienceToken("test@example.com", emailVerificationAudience, testVerificationTtl).Return("verification", nil)
+				tm.EXPECT().NewToken("userId", nil).Return("token", nil)
+			},
+			sessionMock: func(rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI) {
+				expectNewSession(rm, sr, "userId", "")
+			},
+			mailerMock: func(m *mock_mailer.MockMailer) {
+				m.EXPECT().Send(context.Background(), mailMessageMatcher{to: "test@example.com", link: testAppUrl + "/verify-email?token=verification"}).Return(nil)
+			},
+			tokens: testTokens,
+			err:    nil,
+		},
+		{
+			name: "Mailer error",
+			input: domain.CreateUserInput{
+				Login:    "test",
+				Email:    "test@example.com",
+				Password: "test",
+			},
+			hasherMock: func(h *mock_hash.MockHasher, in domain.CreateUserInput) {
+				h.EXPECT().Hash(in.Password).Return(in.Password, nil)
+			},
+			repositoryMock: func(r *mock_service.MockUserRepositoryI, in domain.CreateUserInput) {
+				r.EXPECT().Create(context.Background(), in).Return(domain.User{Id: "userId", Email: in.Email}, nil)
+			},
+			tokenManagerMock: func(tm *mock_jwt.MockTokenManagerI, in domain.CreateUserInput) {
+				tm.EXPECT().NewAudienceToken(in.Email, emailVerificationAudience, testVerificationTtl).Return("verification", nil)
+				tm.EXPECT().NewToken("userId", nil).Return("token", nil)
+			},
+			sessionMock: func(rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI) {
+				expectNewSession(rm, sr, "userId", "")
+			},
+			mailerMock: func(m *mock_mailer.MockMailer) {
+				m.EXPECT().Send(context.Background(), gomock.Any()).Return(errors.New("mailer error"))
+			},
+			tokens: testTokens,
+			err:    nil,
 		},
 	}
 
@@ -215,10 +569,765 @@ func TestAuthService_SignUp(t *testing.T) {
 			userRepository := mock_service.NewMockUserRepositoryI(ctrl)
 			testCase.repositoryMock(userRepository, testCase.input)
 
-			auth := NewAuthService(userRepository, hasher, tokenManager)
-			token, err := auth.SignUp(context.Background(), testCase.input)
+			refreshManager := mock_opaque.NewMockTokenManagerI(ctrl)
+			sessionRepository := mock_service.NewMockSessionRepositoryI(ctrl)
+			testCase.sessionMock(refreshManager, sessionRepository)
+
+			mailer := mock_mailer.NewMockMailer(ctrl)
+			testCase.mailerMock(mailer)
+
+			verifier := NewEmailVerifier(userRepository, tokenManager, mailer, testAppUrl, testVerificationTtl)
+			auth := NewAuthService(userRepository, sessionRepository, nil, hasher, tokenManager, refreshManager, testRefreshTtl, nil, testChallengeTtl, nil, verifier)
+			tokens, err := auth.SignUp(context.Background(), testCase.input)
+
+			assert.Equal(t, tokens, testCase.tokens)
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
+
+func TestAuthService_SignInMfa(t *testing.T) {
+	type mockBehaviour func(
+		r *mock_service.MockUserRepositoryI,
+		rv *mock_service.MockRevocationRepositoryI,
+		tm *mock_jwt.MockTokenManagerI,
+		tp *mock_totp.MockManagerI,
+		rm *mock_opaque.MockTokenManagerI,
+		sr *mock_service.MockSessionRepositoryI,
+		a *mock_service.MockLoginAttemptRepositoryI,
+	)
+
+	input := domain.MfaSignInInput{ChallengeToken: "challenge", Code: "123456"}
+	issuedAt := time.Now().Add(-time.Minute)
+	claims := jwtauth.Claims{Id: "jti", Subject: "userId", IssuedAt: issuedAt, ExpiresAt: issuedAt.Add(testChallengeTtl)}
+	user := domain.User{Id: "userId", Login: "test", TotpSecret: "secret", TotpEnabled: true}
+
+	testCases := []struct {
+		name   string
+		mock   mockBehaviour
+		tokens domain.Tokens
+		err    error
+	}{
+		{
+			name: "OK",
+			mock: func(r *mock_service.MockUserRepositoryI, rv *mock_service.MockRevocationRepositoryI, tm *mock_jwt.MockTokenManagerI, tp *mock_totp.MockManagerI, rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI, a *mock_service.MockLoginAttemptRepositoryI) {
+				tm.EXPECT().ParseAudience("challenge", mfaAudience).Return(claims, nil)
+				rv.EXPECT().IsRevoked(context.Background(), "jti", "userId", issuedAt).Return(false, nil)
+				r.EXPECT().Get(context.Background(), "userId").Return(user, nil)
+				expectSignInAllowed(a, "test")
+				tp.EXPECT().Validate("123456", "secret").Return(int64(42), true)
+				r.EXPECT().UseTotpStep(context.Background(), "userId", int64(42)).Return(true, nil)
+				rv.EXPECT().Revoke(context.Background(), "jti", claims.ExpiresAt).Return(nil)
+				a.EXPECT().Reset(context.Background(), "login:test").Return(nil)
+				tm.EXPECT().NewToken("userId", nil).Return("token", nil)
+				expectNewSession(rm, sr, "userId", "")
+			},
+			tokens: testTokens,
+			err:    nil,
+		},
+		{
+			name: "Recovery code",
+			mock: func(r *mock_service.MockUserRepositoryI, rv *mock_service.MockRevocationRepositoryI, tm *mock_jwt.MockTokenManagerI, tp *mock_totp.MockManagerI, rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI, a *mock_service.MockLoginAttemptRepositoryI) {
+				tm.EXPECT().ParseAudience("challenge", mfaAudience).Return(claims, nil)
+				rv.EXPECT().IsRevoked(context.Background(), "jti", "userId", issuedAt).Return(false, nil)
+				r.EXPECT().Get(context.Background(), "userId").Return(user, nil)
+				expectSignInAllowed(a, "test")
+				tp.EXPECT().Validate("123456", "secret").Return(int64(0), false)
+				tp.EXPECT().HashRecoveryCode("123456").Return("code hash")
+				r.EXPECT().UseRecoveryCode(context.Background(), "userId", "code hash").Return(true, nil)
+				rv.EXPECT().Revoke(context.Background(), "jti", claims.ExpiresAt).Return(nil)
+				a.EXPECT().Reset(context.Background(), "login:test").Return(nil)
+				tm.EXPECT().NewToken("userId", nil).Return("token", nil)
+				expectNewSession(rm, sr, "userId", "")
+			},
+			tokens: testTokens,
+			err:    nil,
+		},
+		{
+			name: "Wrong code",
+			mock: func(r *mock_service.MockUserRepositoryI, rv *mock_service.MockRevocationRepositoryI, tm *mock_jwt.MockTokenManagerI, tp *mock_totp.MockManagerI, rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI, a *mock_service.MockLoginAttemptRepositoryI) {
+				tm.EXPECT().ParseAudience("challenge", mfaAudience).Return(claims, nil)
+				rv.EXPECT().IsRevoked(context.Background(), "jti", "userId", issuedAt).Return(false, nil)
+				r.EXPECT().Get(context.Background(), "userId").Return(user, nil)
+				expectSignInAllowed(a, "test")
+				tp.EXPECT().Validate("123456", "secret").Return(int64(0), false)
+				tp.EXPECT().HashRecoveryCode("123456").Return("code hash")
+				r.EXPECT().UseRecoveryCode(context.Background(), "userId", "code hash").Return(false, nil)
+				expectSignInFailed(a, "test")
+			},
+			tokens: domain.Tokens{},
+			err:    domain.ErrInvalidMfaSignInCode,
+		},
+		{
+			name: "Replayed code",
+			mock: func(r *mock_service.MockUserRepositoryI, rv *mock_service.MockRevocationRepositoryI, tm *mock_jwt.MockTokenManagerI, tp *mock_totp.MockManagerI, rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI, a *mock_service.MockLoginAttemptRepositoryI) {
+				tm.EXPECT().ParseAudience("challenge", mfaAudience).Return(claims, nil)
+				rv.EXPECT().IsRevoked(context.Background(), "jti", "userId", issuedAt).Return(false, nil)
+				r.EXPECT().Get(context.Background(), "userId").Return(user, nil)
+				expectSignInAllowed(a, "test")
+				tp.EXPECT().Validate("123456", "secret").Return(int64(42), true)
+				r.EXPECT().UseTotpStep(context.Background(), "userId", int64(42)).Return(false, nil)
+				expectSignInFailed(a, "test")
+			},
+			tokens: domain.Tokens{},
+			err:    domain.ErrInvalidMfaSignInCode,
+		},
+		{
+			name: "Invalid challenge",
+			mock: func(r *mock_service.MockUserRepositoryI, rv *mock_service.MockRevocationRepositoryI, tm *mock_jwt.MockTokenManagerI, tp *mock_totp.MockManagerI, rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI, a *mock_service.MockLoginAttemptRepositoryI) {
+				tm.EXPECT().ParseAudience("challenge", mfaAudience).Return(jwtauth.Claims{}, errors.New("token is expired"))
+			},
+			tokens: domain.Tokens{},
+			err:    domain.ErrInvalidMfaChallenge,
+		},
+		{
+			name: "Used challenge",
+			mock: func(r *mock_service.MockUserRepositoryI, rv *mock_service.MockRevocationRepositoryI, tm *mock_jwt.MockTokenManagerI, tp *mock_totp.MockManagerI, rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI, a *mock_service.MockLoginAttemptRepositoryI) {
+				tm.EXPECT().ParseAudience("challenge", mfaAudience).Return(claims, nil)
+				rv.EXPECT().IsRevoked(context.Background(), "jti", "userId", issuedAt).Return(true, nil)
+			},
+			tokens: domain.Tokens{},
+			err:    domain.ErrInvalidMfaChallenge,
+		},
+		{
+			name: "Two-factor disabled meanwhile",
+			mock: func(r *mock_service.MockUserRepositoryI, rv *mock_service.MockRevocationRepositoryI, tm *mock_jwt.MockTokenManagerI, tp *mock_totp.MockManagerI, rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI, a *mock_service.MockLoginAttemptRepositoryI) {
+				tm.EXPECT().ParseAudience("challenge", mfaAudience).Return(claims, nil)
+				rv.EXPECT().IsRevoked(context.Background(), "jti", "userId", issuedAt).Return(false, nil)
+				r.EXPECT().Get(context.Background(), "userId").Return(domain.User{Id: "userId"}, nil)
+			},
+			tokens: domain.Tokens{},
+			err:    domain.ErrInvalidMfaChallenge,
+		},
+		{
+			name: "Repository error",
+			mock: func(r *mock_service.MockUserRepositoryI, rv *mock_service.MockRevocationRepositoryI, tm *mock_jwt.MockTokenManagerI, tp *mock_totp.MockManagerI, rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI, a *mock_service.MockLoginAttemptRepositoryI) {
+				tm.EXPECT().ParseAudience("challenge", mfaAudience).Return(claims, nil)
+				rv.EXPECT().IsRevoked(context.Background(), "jti", "userId", issuedAt).Return(false, nil)
+				r.EXPECT().Get(context.Background(), "userId").Return(domain.User{}, errors.New("repository error"))
+			},
+			tokens: domain.Tokens{},
+			err:    errors.New("repository error"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepository := mock_service.NewMockUserRepositoryI(ctrl)
+			revocationRepository := mock_service.NewMockRevocationRepositoryI(ctrl)
+			tokenManager := mock_jwt.NewMockTokenManagerI(ctrl)
+			totpManager := mock_totp.NewMockManagerI(ctrl)
+			refreshManager := mock_opaque.NewMockTokenManagerI(ctrl)
+			sessionRepository := mock_service.NewMockSessionRepositoryI(ctrl)
+			attemptRepository := mock_service.NewMockLoginAttemptRepositoryI(ctrl)
+			testCase.mock(userRepository, revocationRepository, tokenManager, totpManager, refreshManager, sessionRepository, attemptRepository)
+			guard := NewSignInGuard(attemptRepository, testSignInLimits)
+
+			auth := NewAuthService(userRepository, sessionRepository, revocationRepository, nil, tokenManager, refreshManager, testRefreshTtl, totpManager, testChallengeTtl, guard, nil)
+			tokens, err := auth.SignInMfa(context.Background(), input, testClientIp)
+
+			assert.Equal(t, tokens, testCase.tokens)
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
+
+func TestAuthService_EnrollTotp(t *testing.T) {
+	type mockBehaviour func(r *mock_service.MockUserRepositoryI, tp *mock_totp.MockManagerI)
+
+	testCases := []struct {
+		name       string
+		mock       mockBehaviour
+		enrollment domain.TotpEnrollment
+		err        error
+	}{
+		{
+			name: "OK",
+			mock: func(r *mock_service.MockUserRepositoryI, tp *mock_totp.MockManagerI) {
+				r.EXPECT().Get(context.Background(), "userId").Return(domain.User{Id: "userId", Login: "test"}, nil)
+				tp.EXPECT().NewSecret().Return("secret", nil)
+				r.EXPECT().UpdateTotp(context.Background(), "userId", domain.UpdateTotpInput{Secret: "secret"}).Return(nil)
+				tp.EXPECT().ProvisioningURI("test", "secret").Return("otpauth://totp/test")
+			},
+			enrollment: domain.TotpEnrollment{Secret: "secret", ProvisioningUri: "otpauth://totp/test"},
+			err:        nil,
+		},
+		{
+			name: "Already enabled",
+			mock: func(r *mock_service.MockUserRepositoryI, tp *mock_totp.MockManagerI) {
+				r.EXPECT().Get(context.Background(), "userId").Return(domain.User{Id: "userId", TotpEnabled: true}, nil)
+			},
+			enrollment: domain.TotpEnrollment{},
+			err:        domain.ErrTotpAlreadyEnabled,
+		},
+		{
+			name: "Repository error",
+			mock: func(r *mock_service.MockUserRepositoryI, tp *mock_totp.MockManagerI) {
+				r.EXPECT().Get(context.Background(), "userId").Return(domain.User{Id: "userId", Login: "test"}, nil)
+				tp.EXPECT().NewSecret().Return("secret", nil)
+				r.EXPECT().UpdateTotp(context.Background(), "userId", domain.UpdateTotpInput{Secret: "secret"}).Return(errors.New("repository error"))
+			},
+			enrollment: domain.TotpEnrollment{},
+			err:        errors.New("repository error"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepository := mock_service.NewMockUserRepositoryI(ctrl)
+			totpManager := mock_totp.NewMockManagerI(ctrl)
+			testCase.mock(userRepository, totpManager)
+
+			auth := NewAuthService(userRepository, nil, nil, nil, nil, nil, testRefreshTtl, totpManager, testChallengeTtl, nil, nil)
+			enrollment, err := auth.EnrollTotp(context.Background(), "userId")
+
+			assert.Equal(t, enrollment, testCase.enrollment)
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
+
+func TestAuthService_ConfirmTotp(t *testing.T) {
+	type mockBehaviour func(r *mock_service.MockUserRepositoryI, tp *mock_totp.MockManagerI)
+
+	pending := domain.User{Id: "userId", TotpSecret: "secret"}
+
+	testCases := []struct {
+		name  string
+		mock  mockBehaviour
+		codes domain.RecoveryCodes
+		err   error
+	}{
+		{
+			name: "OK",
+			mock: func(r *mock_service.MockUserRepositoryI, tp *mock_totp.MockManagerI) {
+				r.EXPECT().Get(context.Background(), "userId").Return(pending, nil)
+				tp.EXPECT().Validate("123456", "secret").Return(int64(42), true)
+				r.EXPECT().UseTotpStep(context.Background(), "userId", int64(42)).Return(true, nil)
+				tp.EXPECT().NewRecoveryCodes().Return([]string{"first", "second"}, nil)
+				tp.EXPECT().HashRecoveryCode("first").Return("first hash")
+				tp.EXPECT().HashRecoveryCode("second").Return("second hash")
+				r.EXPECT().UpdateTotp(context.Background(), "userId", domain.UpdateTotpInput{
+					Secret:        "secret",
+					Enabled:       true,
+					RecoveryCodes: []string{"first hash", "second hash"},
+				}).Return(nil)
+			},
+			codes: domain.RecoveryCodes{Codes: []string{"first", "second"}},
+			err:   nil,
+		},
+		{
+			name: "Wrong code",
+			mock: func(r *mock_service.MockUserRepositoryI, tp *mock_totp.MockManagerI) {
+				r.EXPECT().Get(context.Background(), "userId").Return(pending, nil)
+				tp.EXPECT().Validate("123456", "secret").Return(int64(0), false)
+			},
+			codes: domain.RecoveryCodes{},
+			err:   domain.ErrInvalidMfaCode,
+		},
+		{
+			name: "Replayed code",
+			mock: func(r *mock_service.MockUserRepositoryI, tp *mock_totp.MockManagerI) {
+				r.EXPECT().Get(context.Background(), "userId").Return(pending, nil)
+				tp.EXPECT().Validate("123456", "secret").Return(int64(42), true)
+				r.EXPECT().UseTotpStep(context.Background(), "userId", int64(42)).Return(false, nil)
+			},
+			codes: domain.RecoveryCodes{},
+			err:   domain.ErrInvalidMfaCode,
+		},
+		{
+			name: "Not enrolled",
+			mock: func(r *mock_service.MockUserRepositoryI, tp *mock_totp.MockManagerI) {
+				r.EXPECT().Get(context.Background(), "userId").Return(domain.User{Id: "userId"}, nil)
+			},
+			codes: domain.RecoveryCodes{},
+			err:   domain.ErrTotpNotEnrolled,
+		},
+		{
+			name: "Already enabled",
+			mock: func(r *mock_service.MockUserRepositoryI, tp *mock_totp.MockManagerI) {
+				r.EXPECT().Get(context.Background(), "userId").Return(domain.User{Id: "userId", TotpSecret: "secret", TotpEnabled: true}, nil)
+			},
+			codes: domain.RecoveryCodes{},
+			err:   domain.ErrTotpAlreadyEnabled,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepository := mock_service.NewMockUserRepositoryI(ctrl)
+			totpManager := mock_totp.NewMockManagerI(ctrl)
+			testCase.mock(userRepository, totpManager)
+
+			auth := NewAuthService(userRepository, nil, nil, nil, nil, nil, testRefreshTtl, totpManager, testChallengeTtl, nil, nil)
+			codes, err := auth.ConfirmTotp(context.Background(), "userId", "123456")
+
+			assert.Equal(t, codes, testCase.codes)
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
+
+func TestAuthService_DisableTotp(t *testing.T) {
+	type mockBehaviour func(r *mock_service.MockUserRepositoryI, tp *mock_totp.MockManagerI)
+
+	enabled := domain.User{Id: "userId", TotpSecret: "secret", TotpEnabled: true}
+
+	testCases := []struct {
+		name string
+		mock mockBehaviour
+		err  error
+	}{
+		{
+			name: "OK",
+			mock: func(r *mock_service.MockUserRepositoryI, tp *mock_totp.MockManagerI) {
+				r.EXPECT().Get(context.Background(), "userId").Return(enabled, nil)
+				tp.EXPECT().Validate("123456", "secret").Return(int64(42), true)
+				r.EXPECT().UseTotpStep(context.Background(), "userId", int64(42)).Return(true, nil)
+				r.EXPECT().UpdateTotp(context.Background(), "userId", domain.UpdateTotpInput{}).Return(nil)
+			},
+			err: nil,
+		},
+		{
+			name: "Wrong code",
+			mock: func(r *mock_service.MockUserRepositoryI, tp *mock_totp.MockManagerI) {
+				r.EXPECT().Get(context.Background(), "userId").Return(enabled, nil)
+				tp.EXPECT().Validate("123456", "secret").Return(int64(0), false)
+				tp.EXPECT().HashRecoveryCode("123456").Return("code hash")
+				r.EXPECT().UseRecoveryCode(context.Background(), "userId", "code hash").Return(false, nil)
+			},
+			err: domain.ErrInvalidMfaCode,
+		},
+		{
+			name: "Replayed code",
+			mock: func(r *mock_service.MockUserRepositoryI, tp *mock_totp.MockManagerI) {
+				r.EXPECT().Get(context.Background(), "userId").Return(enabled, nil)
+				tp.EXPECT().Validate("123456", "secret").Return(int64(42), true)
+				r.EXPECT().UseTotpStep(context.Background(), "userId", int64(42)).Return(false, nil)
+			},
+			err: domain.ErrInvalidMfaCode,
+		},
+		{
+			name: "Not enabled",
+			mock: func(r *mock_service.MockUserRepositoryI, tp *mock_totp.MockManagerI) {
+				r.EXPECT().Get(context.Background(), "userId").Return(domain.User{Id: "userId", TotpSecret: "secret"}, nil)
+			},
+			err: domain.ErrTotpNotEnabled,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepository := mock_service.NewMockUserRepositoryI(ctrl)
+			totpManager := mock_totp.NewMockManagerI(ctrl)
+			testCase.mock(userRepository, totpManager)
+
+			auth := NewAuthService(userRepository, nil, nil, nil, nil, nil, testRefreshTtl, totpManager, testChallengeTtl, nil, nil)
+			err := auth.DisableTotp(context.Background(), "userId", "123456")
+
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
+
+func TestAuthService_ChangePassword(t *testing.T) {
+	type mockBehaviour func(
+		r *mock_service.MockUserRepositoryI,
+		rv *mock_service.MockRevocationRepositoryI,
+		h *mock_hash.MockHasher,
+		tm *mock_jwt.MockTokenManagerI,
+		rm *mock_opaque.MockTokenManagerI,
+		sr *mock_service.MockSessionRepositoryI,
+		a *mock_service.MockLoginAttemptRepositoryI,
+	)
+
+	input := domain.ChangePasswordInput{CurrentPassword: "old password", NewPassword: "new password"}
+	user := domain.User{Id: "userId", Login: "test", Password: "old hash"}
+
+	testCases := []struct {
+		name   string
+		mock   mockBehaviour
+		tokens domain.Tokens
+		err    error
+	}{
+		{
+			name: "OK",
+			mock: func(r *mock_service.MockUserRepositoryI, rv *mock_service.MockRevocationRepositoryI, h *mock_hash.MockHasher, tm *mock_jwt.MockTokenManagerI, rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI, a *mock_service.MockLoginAttemptRepositoryI) {
+				r.EXPECT().Get(context.Background(), "userId").Return(user, nil)
+				expectSignInAllowed(a, "test")
+				h.EXPECT().Verify("old password", "old hash").Return(true, nil)
+				h.EXPECT().Hash("new password").Return("new hash", nil)
+				r.EXPECT().UpdatePassword(context.Background(), "userId", "new hash").Return(nil)
+				rv.EXPECT().RevokeUser(context.Background(), "userId", gomock.Any()).Return(nil)
+				sr.EXPECT().RevokeUser(context.Background(), "userId").Return(nil)
+				tm.EXPECT().NewToken("userId", nil).Return("token", nil)
+				expectNewSession(rm, sr, "userId", "")
+			},
+			tokens: testTokens,
+			err:    nil,
+		},
+		{
+			name: "Wrong password",
+			mock: func(r *mock_service.MockUserRepositoryI, rv *mock_service.MockRevocationRepositoryI, h *mock_hash.MockHasher, tm *mock_jwt.MockTokenManagerI, rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI, a *mock_service.MockLoginAttemptRepositoryI) {
+				r.EXPECT().Get(context.Background(), "userId").Return(user, nil)
+				expectSignInAllowed(a, "test")
+				h.EXPECT().Verify("old password", "old hash").Return(false, nil)
+				expectSignInFailed(a, "test")
+			},
+			tokens: domain.Tokens{},
+			err:    domain.ErrWrongPassword,
+		},
+		{
+			name: "Session repository error",
+			mock: func(r *mock_service.MockUserRepositoryI, rv *mock_service.MockRevocationRepositoryI, h *mock_hash.MockHasher, tm *mock_jwt.MockTokenManagerI, rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI, a *mock_service.MockLoginAttemptRepositoryI) {
+				r.EXPECT().Get(context.Background(), "userId").Return(user, nil)
+				expectSignInAllowed(a, "test")
+				h.EXPECT().Verify("old password", "old hash").Return(true, nil)
+				h.EXPECT().Hash("new password").Return("new hash", nil)
+				r.EXPECT().UpdatePassword(context.Background(), "userId", "new hash").Return(nil)
+				rv.EXPECT().RevokeUser(context.Background(), "userId", gomock.Any()).Return(nil)
+				sr.EXPECT().RevokeUser(context.Background(), "userId").Return(errors.New("session repository error"))
+			},
+			tokens: domain.Tokens{},
+			err:    errors.New("session repository error"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepository := mock_service.NewMockUserRepositoryI(ctrl)
+			revocationRepository := mock_service.NewMockRevocationRepositoryI(ctrl)
+			hasher := mock_hash.NewMockHasher(ctrl)
+			tokenManager := mock_jwt.NewMockTokenManagerI(ctrl)
+			refreshManager := mock_opaque.NewMockTokenManagerI(ctrl)
+			sessionRepository := mock_service.NewMockSessionRepositoryI(ctrl)
+			attemptRepository := mock_service.NewMockLoginAttemptRepositoryI(ctrl)
+			testCase.mock(userRepository, revocationRepository, hasher, tokenManager, refreshManager, sessionRepository, attemptRepository)
+			guard := NewSignInGuard(attemptRepository, testSignInLimits)
+
+			auth := NewAuthService(userRepository, sessionRepository, revocationRepository, hasher, tokenManager, refreshManager, testRefreshTtl, nil, testChallengeTtl, guard, nil)
+			tokens, err := auth.ChangePassword(context.Background(), "userId", input, testClientIp)
+
+			assert.Equal(t, tokens, testCase.tokens)
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
+
+func TestAuthService_Refresh(t *testing.T) {
+	type mockBehaviour func(tm *mock_jwt.MockTokenManagerI, rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI, ur *mock_service.MockUserRepositoryI)
+
+	revokedAt := time.Now().Add(-time.Minute)
+	active := domain.Session{
+		Id:        "sessionId",
+		UserId:    "userId",
+		FamilyId:  "familyId",
+		TokenHash: "old hash",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	rotated := active
+	rotated.RevokedAt = &revokedAt
+	expired := active
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+
+	testCases := []struct {
+		name   string
+		token  string
+		mock   mockBehaviour
+		tokens domain.Tokens
+		err    error
+	}{
+		{
+			name:  "OK",
+			token: "old",
+			mock: func(tm *mock_jwt.MockTokenManagerI, rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI, ur *mock_service.MockUserRepositoryI) {
+				rm.EXPECT().Hash("old").Return("old hash")
+				sr.EXPECT().GetByTokenHash(context.Background(), "old hash").Return(active, nil)
+				sr.EXPECT().Revoke(context.Background(), "sessionId").Return(true, nil)
+				ur.EXPECT().Get(context.Background(), "userId").Return(domain.User{Id: "userId"}, nil)
+				tm.EXPECT().NewToken("userId", nil).Return("token", nil)
+				expectNewSession(rm, sr, "userId", "familyId")
+			},
+			tokens: testTokens,
+			err:    nil,
+		},
+		{
+			name:  "Admin",
+			token: "old",
+			mock: func(tm *mock_jwt.MockTokenManagerI, rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI, ur *mock_service.MockUserRepositoryI) {
+				rm.EXPECT().Hash("old").Return("old hash")
+				sr.EXPECT().GetByTokenHash(context.Background(), "old hash").Return(active, nil)
+				sr.EXPECT().Revoke(context.Background(), "sessionId").Return(true, nil)
+				ur.EXPECT().Get(context.Background(), "userId").
+					Return(domain.User{Id: "userId", Roles: []domain.Role{domain.RoleAdmin}}, nil)
+				tm.EXPECT().NewToken("userId", []string{"admin"}).Return("token", nil)
+				expectNewSession(rm, sr, "userId", "familyId")
+			},
+			tokens: testTokens,
+			err:    nil,
+		},
+		{
+			name:  "Disabled user",
+			token: "old",
+			mock: func(tm *mock_jwt.MockTokenManagerI, rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI, ur *mock_service.MockUserRepositoryI) {
+				rm.EXPECT().Hash("old").Return("old hash")
+				sr.EXPECT().GetByTokenHash(context.Background(), "old hash").Return(active, nil)
+				sr.EXPECT().Revoke(context.Background(), "sessionId").Return(true, nil)
+				ur.EXPECT().Get(context.Background(), "userId").Return(domain.User{Id: "userId", Disabled: true}, nil)
+			},
+			tokens: domain.Tokens{},
+			err:    domain.ErrInvalidRefreshToken,
+		},
+		{
+			name:  "Unknown token",
+			token: "unknown",
+			mock: func(tm *mock_jwt.MockTokenManagerI, rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI, ur *mock_service.MockUserRepositoryI) {
+				rm.EXPECT().Hash("unknown").Return("unknown hash")
+				sr.EXPECT().GetByTokenHash(context.Background(), "unknown hash").Return(domain.Session{}, domain.ErrSessionNotFound)
+			},
+			tokens: domain.Tokens{},
+			err:    domain.ErrInvalidRefreshToken,
+		},
+		{
+			name:  "Expired token",
+			token: "old",
+			mock: func(tm *mock_jwt.MockTokenManagerI, rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI, ur *mock_service.MockUserRepositoryI) {
+				rm.EXPECT().Hash("old").Return("old hash")
+				sr.EXPECT().GetByTokenHash(context.Background(), "old hash").Return(expired, nil)
+			},
+			tokens: domain.Tokens{},
+			err:    domain.ErrInvalidRefreshToken,
+		},
+		{
+			name:  "Reused token",
+			token: "old",
+			mock: func(tm *mock_jwt.MockTokenManagerI, rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI, ur *mock_service.MockUserRepositoryI) {
+				rm.EXPECT().Hash("old").Return("old hash")
+				sr.EXPECT().GetByTokenHash(context.Background(), "old hash").Return(rotated, nil)
+				sr.EXPECT().RevokeFamily(context.Background(), "familyId").Return(nil)
+			},
+			tokens: domain.Tokens{},
+			err:    domain.ErrInvalidRefreshToken,
+		},
+		{
+			name:  "Concurrent reuse",
+			token: "old",
+			mock: func(tm *mock_jwt.MockTokenManagerI, rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI, ur *mock_service.MockUserRepositoryI) {
+				rm.EXPECT().Hash("old").Return("old hash")
+				sr.EXPECT().GetByTokenHash(context.Background(), "old hash").Return(active, nil)
+				sr.EXPECT().Revoke(context.Background(), "sessionId").Return(false, nil)
+				sr.EXPECT().RevokeFamily(context.Background(), "familyId").Return(nil)
+			},
+			tokens: domain.Tokens{},
+			err:    domain.ErrInvalidRefreshToken,
+		},
+		{
+			name:  "Revoke family error",
+			token: "old",
+			mock: func(tm *mock_jwt.MockTokenManagerI, rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI, ur *mock_service.MockUserRepositoryI) {
+				rm.EXPECT().Hash("old").Return("old hash")
+				sr.EXPECT().GetByTokenHash(context.Background(), "old hash").Return(rotated, nil)
+				sr.EXPECT().RevokeFamily(context.Background(), "familyId").Return(errors.New("repository error"))
+			},
+			tokens: domain.Tokens{},
+			err:    errors.New("repository error"),
+		},
+		{
+			name:  "Repository error",
+			token: "old",
+			mock: func(tm *mock_jwt.MockTokenManagerI, rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI, ur *mock_service.MockUserRepositoryI) {
+				rm.EXPECT().Hash("old").Return("old hash")
+				sr.EXPECT().GetByTokenHash(context.Background(), "old hash").Return(domain.Session{}, errors.New("repository error"))
+			},
+			tokens: domain.Tokens{},
+			err:    errors.New("repository error"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tokenManager := mock_jwt.NewMockTokenManagerI(ctrl)
+			refreshManager := mock_opaque.NewMockTokenManagerI(ctrl)
+			sessionRepository := mock_service.NewMockSessionRepositoryI(ctrl)
+			userRepository := mock_service.NewMockUserRepositoryI(ctrl)
+			testCase.mock(tokenManager, refreshManager, sessionRepository, userRepository)
+
+			auth := NewAuthService(userRepository, sessionRepository, nil, nil, tokenManager, refreshManager, testRefreshTtl, nil, testChallengeTtl, nil, nil)
+			tokens, err := auth.Refresh(context.Background(), testCase.token)
+
+			assert.Equal(t, tokens, testCase.tokens)
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
+
+func TestAuthService_Logout(t *testing.T) {
+	type mockBehaviour func(tm *mock_jwt.MockTokenManagerI, rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI, rr *mock_service.MockRevocationRepositoryI)
+
+	expiresAt := time.Now().Add(time.Hour)
+	claims := jwtauth.Claims{Id: "tokenId", Subject: "userId", ExpiresAt: expiresAt}
+	session := domain.Session{Id: "sessionId", UserId: "userId", FamilyId: "familyId"}
+
+	testCases := []struct {
+		name  string
+		input domain.LogoutInput
+		mock  mockBehaviour
+		err   error
+	}{
+		{
+			name:  "OK",
+			input: domain.LogoutInput{},
+			mock: func(tm *mock_jwt.MockTokenManagerI, rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI, rr *mock_service.MockRevocationRepositoryI) {
+				tm.EXPECT().Parse("token").Return(claims, nil)
+				rr.EXPECT().Revoke(context.Background(), "tokenId", expiresAt).Return(nil)
+			},
+			err: nil,
+		},
+		{
+			name:  "With refresh token",
+			input: domain.LogoutInput{RefreshToken: "refresh"},
+			mock: func(tm *mock_jwt.MockTokenManagerI, rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI, rr *mock_service.MockRevocationRepositoryI) {
+				tm.EXPECT().Parse("token").Return(claims, nil)
+				rr.EXPECT().Revoke(context.Background(), "tokenId", expiresAt).Return(nil)
+				rm.EXPECT().Hash("refresh").Return("refresh hash")
+				sr.EXPECT().GetByTokenHash(context.Background(), "refresh hash").Return(session, nil)
+				sr.EXPECT().RevokeFamily(context.Background(), "familyId").Return(nil)
+			},
+			err: nil,
+		},
+		{
+			name:  "Unknown refresh token",
+			input: domain.LogoutInput{RefreshToken: "unknown"},
+			mock: func(tm *mock_jwt.MockTokenManagerI, rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI, rr *mock_service.MockRevocationRepositoryI) {
+				tm.EXPECT().Parse("token").Return(claims, nil)
+				rr.EXPECT().Revoke(context.Background(), "tokenId", expiresAt).Return(nil)
+				rm.EXPECT().Hash("unknown").Return("unknown hash")
+				sr.EXPECT().GetByTokenHash(context.Background(), "unknown hash").Return(domain.Session{}, domain.ErrSessionNotFound)
+			},
+			err: nil,
+		},
+		{
+			name:  "Refresh token of another user",
+			input: domain.LogoutInput{RefreshToken: "refresh"},
+			mock: func(tm *mock_jwt.MockTokenManagerI, rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI, rr *mock_service.MockRevocationRepositoryI) {
+				tm.EXPECT().Parse("token").Return(claims, nil)
+				rr.EXPECT().Revoke(context.Background(), "tokenId", expiresAt).Return(nil)
+				rm.EXPECT().Hash("refresh").Return("refresh hash")
+				sr.EXPECT().GetByTokenHash(context.Background(), "refresh hash").
+					Return(domain.Session{Id: "sessionId", UserId: "otherUserId", FamilyId: "familyId"}, nil)
+			},
+			err: nil,
+		},
+		{
+			name:  "Token without id",
+			input: domain.LogoutInput{},
+			mock: func(tm *mock_jwt.MockTokenManagerI, rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI, rr *mock_service.MockRevocationRepositoryI) {
+				tm.EXPECT().Parse("token").Return(jwtauth.Claims{Subject: "userId", ExpiresAt: expiresAt}, nil)
+				rr.EXPECT().RevokeUser(context.Background(), "userId", gomock.Any()).Return(nil)
+			},
+			err: nil,
+		},
+		{
+			name:  "Invalid token",
+			input: domain.LogoutInput{},
+			mock: func(tm *mock_jwt.MockTokenManagerI, rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI, rr *mock_service.MockRevocationRepositoryI) {
+				tm.EXPECT().Parse("token").Return(jwtauth.Claims{}, errors.New("invalid token"))
+			},
+			err: errors.New("invalid token"),
+		},
+		{
+			name:  "Repository error",
+			input: domain.LogoutInput{RefreshToken: "refresh"},
+			mock: func(tm *mock_jwt.MockTokenManagerI, rm *mock_opaque.MockTokenManagerI, sr *mock_service.MockSessionRepositoryI, rr *mock_service.MockRevocationRepositoryI) {
+				tm.EXPECT().Parse("token").Return(claims, nil)
+				rr.EXPECT().Revoke(context.Background(), "tokenId", expiresAt).Return(errors.New("repository error"))
+			},
+			err: errors.New("repository error"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tokenManager := mock_jwt.NewMockTokenManagerI(ctrl)
+			refreshManager := mock_opaque.NewMockTokenManagerI(ctrl)
+			sessionRepository := mock_service.NewMockSessionRepositoryI(ctrl)
+			revocationRepository := mock_service.NewMockRevocationRepositoryI(ctrl)
+			testCase.mock(tokenManager, refreshManager, sessionRepository, revocationRepository)
+
+			auth := NewAuthService(nil, sessionRepository, revocationRepository, nil, tokenManager, refreshManager, testRefreshTtl, nil, testChallengeTtl, nil, nil)
+			err := auth.Logout(context.Background(), "token", testCase.input)
+
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
+
+func TestAuthService_LogoutAll(t *testing.T) {
+	type mockBehaviour func(sr *mock_service.MockSessionRepositoryI, rr *mock_service.MockRevocationRepositoryI)
+
+	testCases := []struct {
+		name string
+		mock mockBehaviour
+		err  error
+	}{
+		{
+			name: "OK",
+			mock: func(sr *mock_service.MockSessionRepositoryI, rr *mock_service.MockRevocationRepositoryI) {
+				rr.EXPECT().RevokeUser(context.Background(), "userId", gomock.Any()).Return(nil)
+				sr.EXPECT().RevokeUser(context.Background(), "userId").Return(nil)
+			},
+			err: nil,
+		},
+		{
+			name: "Revocation repository error",
+			mock: func(sr *mock_service.MockSessionRepositoryI, rr *mock_service.MockRevocationRepositoryI) {
+				rr.EXPECT().RevokeUser(context.Background(), "userId", gomock.Any()).Return(errors.New("repository error"))
+			},
+			err: errors.New("repository error"),
+		},
+		{
+			name: "Session repository error",
+			mock: func(sr *mock_service.MockSessionRepositoryI, rr *mock_service.MockRevocationRepositoryI) {
+				rr.EXPECT().RevokeUser(context.Background(), "userId", gomock.Any()).Return(nil)
+				sr.EXPECT().RevokeUser(context.Background(), "userId").Return(errors.New("repository error"))
+			},
+			err: errors.New("repository error"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sessionRepository := mock_service.NewMockSessionRepositoryI(ctrl)
+			revocationRepository := mock_service.NewMockRevocationRepositoryI(ctrl)
+			testCase.mock(sessionRepository, revocationRepository)
+
+			auth := NewAuthService(nil, sessionRepository, revocationRepository, nil, nil, nil, testRefreshTtl, nil, testChallengeTtl, nil, nil)
+			err := auth.LogoutAll(context.Background(), "userId")
 
-			assert.Equal(t, token, testCase.token)
 			assert.Equal(t, err, testCase.err)
 		})
 	}
@@ -226,30 +1335,78 @@ func TestAuthService_SignUp(t *testing.T) {
 
 func TestAuthService_CheckToken(t *testing.T) {
 	type tokenManagerMockBehaviour func(tm *mock_jwt.MockTokenManagerI, token string)
+	type revocationMockBehaviour func(rr *mock_service.MockRevocationRepositoryI)
+
+	issuedAt := time.Now()
+	claims := jwtauth.Claims{Id: "tokenId", Subject: "userId", IssuedAt: issuedAt}
+
 	testCases := []struct {
 		name             string
 		token            string
 		tokenManagerMock tokenManagerMockBehaviour
-		userId           string
+		revocationMock   revocationMockBehaviour
+		identity         domain.Identity
 		err              error
 	}{
 		{
-			name:             "Valid token",
-			token:            "token",
+			name:  "Valid token",
+			token: "token",
+			tokenManagerMock: func(tm *mock_jwt.MockTokenManagerI, token string) {
+				tm.EXPECT().Parse(token).Return(claims, nil)
+			},
+			revocationMock: func(rr *mock_service.MockRevocationRepositoryI) {
+				rr.EXPECT().IsRevoked(context.Background(), "tokenId", "userId", issuedAt).Return(false, nil)
+			},
+			identity: domain.Identity{UserId: "userId"},
+			err:      nil,
+		},
+		{
+			name:  "Admin token",
+			token: "token",
+			tokenManagerMock: func(tm *mock_jwt.MockTokenManagerI, token string) {
+				adminClaims := claims
+				adminClaims.Roles = []string{"admin"}
+				tm.EXPECT().Parse(token).Return(adminClaims, nil)
+			},
+			revocationMock: func(rr *mock_service.MockRevocationRepositoryI) {
+				rr.EXPECT().IsRevoked(context.Background(), "tokenId", "userId", issuedAt).Return(false, nil)
+			},
+			identity: domain.Identity{UserId: "userId", Roles: []domain.Role{domain.RoleAdmin}},
+			err:      nil,
+		},
+		{
+			name:  "Invalid token",
+			token: "token",
+			tokenManagerMock: func(tm *mock_jwt.MockTokenManagerI, token string) {
+				tm.EXPECT().Parse(token).Return(jwtauth.Claims{}, errors.New("invalid token"))
+			},
+			revocationMock: func(rr *mock_service.MockRevocationRepositoryI) {},
+			identity:       domain.Identity{},
+			err:            errors.New("invalid token"),
+		},
+		{
+			name:  "Revoked token",
+			token: "token",
 			tokenManagerMock: func(tm *mock_jwt.MockTokenManagerI, token string) {
-				tm.EXPECT().Parse(token).Return("userId", nil)
+				tm.EXPECT().Parse(token).Return(claims, nil)
 			},
-			userId:           "userId",
-			err:              nil,
+			revocationMock: func(rr *mock_service.MockRevocationRepositoryI) {
+				rr.EXPECT().IsRevoked(context.Background(), "tokenId", "userId", issuedAt).Return(true, nil)
+			},
+			identity: domain.Identity{},
+			err:      domain.ErrTokenRevoked,
 		},
 		{
-			name:             "Invalid token",
-			token:            "token",
+			name:  "Repository error",
+			token: "token",
 			tokenManagerMock: func(tm *mock_jwt.MockTokenManagerI, token string) {
-				tm.EXPECT().Parse(token).Return("", errors.New("invalid token"))
+				tm.EXPECT().Parse(token).Return(claims, nil)
 			},
-			userId:           "",
-			err:              errors.New("invalid token"),
+			revocationMock: func(rr *mock_service.MockRevocationRepositoryI) {
+				rr.EXPECT().IsRevoked(context.Background(), "tokenId", "userId", issuedAt).Return(false, errors.New("repository error"))
+			},
+			identity: domain.Identity{},
+			err:      errors.New("repository error"),
 		},
 	}
 
@@ -258,17 +1415,31 @@ func TestAuthService_CheckToken(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			hasher := &hash.SHA1Hasher{}
-			userRepository := &mongorep.UserRepository{}
-
 			tokenManager := mock_jwt.NewMockTokenManagerI(ctrl)
 			testCase.tokenManagerMock(tokenManager, testCase.token)
 
-			auth := NewAuthService(userRepository, hasher, tokenManager)
-			userId, err := auth.CheckToken(context.Background(), testCase.token)
+			revocationRepository := mock_service.NewMockRevocationRepositoryI(ctrl)
+			testCase.revocationMock(revocationRepository)
 
-			assert.Equal(t, userId, testCase.userId)
+			auth := NewAuthService(nil, nil, revocationRepository, nil, tokenManager, nil, testRefreshTtl, nil, testChallengeTtl, nil, nil)
+			identity, err := auth.CheckToken(context.Background(), testCase.token)
+
+			assert.Equal(t, identity, testCase.identity)
 			assert.Equal(t, err, testCase.err)
 		})
 	}
 }
+
+func TestAuthService_GetJWKS(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	jwks := jwtauth.JWKS{Keys: []jwtauth.JWK{{Kty: "RSA", Kid: "2021-10", Alg: "RS256", N: "n", E: "AQAB"}}}
+
+	tokenManager := mock_jwt.NewMockTokenManagerI(ctrl)
+	tokenManager.EXPECT().JWKS().Return(jwks)
+
+	auth := NewAuthService(nil, nil, nil, nil, tokenManager, nil, testRefreshTtl, nil, testChallengeTtl, nil, nil)
+
+	assert.Equal(t, auth.GetJWKS(context.Background()), jwks)
+}
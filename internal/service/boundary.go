@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	jwtauth "github.com/i-vasilkov/go-todo-app/pkg/auth/jwt"
+	"time"
 )
 
 //go:generate mockgen -source=boundary.go -destination=mocks/mock.go
@@ -10,31 +12,162 @@ import (
 // -------------- Service boundary ------------------
 
 type AuthServiceI interface {
-	SignUp(ctx context.Context, in domain.CreateUserInput) (string, error)
-	SignIn(ctx context.Context, in domain.LoginUserInput) (string, error)
-	CheckToken(ctx context.Context, token string) (string, error)
+	SignUp(ctx context.Context, in domain.CreateUserInput) (domain.Tokens, error)
+	SignIn(ctx context.Context, in domain.LoginUserInput, clientIp string) (domain.SignInResult, error)
+	SignInMfa(ctx context.Context, in domain.MfaSignInInput, clientIp string) (domain.Tokens, error)
+	EnrollTotp(ctx context.Context, userId string) (domain.TotpEnrollment, error)
+	ConfirmTotp(ctx context.Context, userId, code string) (domain.RecoveryCodes, error)
+	DisableTotp(ctx context.Context, userId, code string) error
+	ChangePassword(ctx context.Context, userId string, in domain.ChangePasswordInput, clientIp string) (domain.Tokens, error)
+	Refresh(ctx context.Context, refreshToken string) (domain.Tokens, error)
+	Logout(ctx context.Context, accessToken string, in domain.LogoutInput) error
+	LogoutAll(ctx context.Context, userId string) error
+	CheckToken(ctx context.Context, token string) (domain.Identity, error)
+	GetJWKS(ctx context.Context) jwtauth.JWKS
+}
+
+type AccountServiceI interface {
+	Get(ctx context.Context, userId string) (domain.User, error)
+	Update(ctx context.Context, userId string, in domain.UpdateUserInput) (domain.User, error)
+	Delete(ctx context.Context, userId string) error
+	ForgotPassword(ctx context.Context, in domain.ForgotPasswordInput) error
+	ResetPassword(ctx context.Context, in domain.ResetPasswordInput) error
+	VerifyEmail(ctx context.Context, in domain.VerifyEmailInput) error
+	ResendVerification(ctx context.Context, userId string) error
+}
+
+type AdminServiceI interface {
+	GetUsers(ctx context.Context, filter domain.UserFilter) ([]domain.User, error)
+	UpdateUser(ctx context.Context, adminId, id string, in domain.AdminUpdateUserInput) (domain.User, error)
+	DeleteUser(ctx context.Context, adminId, id string) error
+	GetTaskCounts(ctx context.Context) (domain.TaskCounts, error)
+}
+
+type AccessTokenServiceI interface {
+	GetAll(ctx context.Context, userId string) ([]domain.AccessToken, error)
+	Create(ctx context.Context, userId string, in domain.CreateAccessTokenInput) (domain.CreatedAccessToken, error)
+	Delete(ctx context.Context, id, userId string) error
+	Check(ctx context.Context, token string) (domain.AccessToken, error)
 }
 
 type TaskServiceI interface {
 	Get(ctx context.Context, id, userId string) (domain.Task, error)
-	GetAll(ctx context.Context, userId string) ([]domain.Task, error)
+	GetTree(ctx context.Context, id, userId string) (domain.TaskTree, error)
+	GetAll(ctx context.Context, userId string, filter domain.TaskFilter) (domain.TaskPage, error)
 	Create(ctx context.Context, userId string, in domain.CreateTaskInput) (domain.Task, error)
 	Update(ctx context.Context, id, userId string, in domain.UpdateTaskInput) (domain.Task, error)
 	Delete(ctx context.Context, id, userId string) error
+	Complete(ctx context.Context, id, userId string, withSubtasks bool) (domain.Task, error)
+	Reopen(ctx context.Context, id, userId string) (domain.Task, error)
+	GetOverdue(ctx context.Context, userId string) ([]domain.Task, error)
+	GetDueBetween(ctx context.Context, userId string, from, to time.Time) ([]domain.Task, error)
+	AttachLabels(ctx context.Context, id, userId string, labelIds []string) (domain.Task, error)
+	DetachLabels(ctx context.Context, id, userId string, labelIds []string) (domain.Task, error)
+	Move(ctx context.Context, id, userId string, in domain.MoveTaskInput) (domain.Task, error)
+	GetTrash(ctx context.Context, userId string) ([]domain.Task, error)
+	Restore(ctx context.Context, id, userId string) (domain.Task, error)
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
+}
+
+type ProjectServiceI interface {
+	Get(ctx context.Context, id, userId string) (domain.Project, error)
+	GetAll(ctx context.Context, userId string) ([]domain.Project, error)
+	Create(ctx context.Context, userId string, in domain.CreateProjectInput) (domain.Project, error)
+	Update(ctx context.Context, id, userId string, in domain.UpdateProjectInput) (domain.Project, error)
+	Delete(ctx context.Context, id, userId string, mode domain.ProjectDeleteMode) error
+}
+
+type LabelServiceI interface {
+	Get(ctx context.Context, id, userId string) (domain.Label, error)
+	GetAll(ctx context.Context, userId string) ([]domain.Label, error)
+	Create(ctx context.Context, userId string, in domain.CreateLabelInput) (domain.Label, error)
+	Update(ctx context.Context, id, userId string, in domain.UpdateLabelInput) (domain.Label, error)
+	Delete(ctx context.Context, id, userId string) error
 }
 
 // -------------- Repository boundary ------------------
 
 type UserRepositoryI interface {
 	Create(ctx context.Context, in domain.CreateUserInput) (domain.User, error)
-	GetByCredentials(ctx context.Context, in domain.LoginUserInput) (domain.User, error)
+	GetByLogin(ctx context.Context, login string) (domain.User, error)
+	GetByEmail(ctx context.Context, email string) (domain.User, error)
 	Get(ctx context.Context, id string) (domain.User, error)
+	GetAll(ctx context.Context, filter domain.UserFilter) ([]domain.User, error)
+	Update(ctx context.Context, id string, in domain.UpdateUserInput) (domain.User, error)
+	Delete(ctx context.Context, id string) error
+	UpdatePassword(ctx context.Context, id, password string) error
+	SetEmailVerified(ctx context.Context, id string) error
+	SetDisabled(ctx context.Context, id string, disabled bool) error
+	SetRoles(ctx context.Context, id string, roles []domain.Role) error
+	UpdateTotp(ctx context.Context, id string, in domain.UpdateTotpInput) error
+	UseRecoveryCode(ctx context.Context, id, codeHash string) (bool, error)
+	UseTotpStep(ctx context.Context, id string, step int64) (bool, error)
+}
+
+type SessionRepositoryI interface {
+	Create(ctx context.Context, in domain.CreateSessionInput) (domain.Session, error)
+	GetByTokenHash(ctx context.Context, tokenHash string) (domain.Session, error)
+	Revoke(ctx context.Context, id string) (bool, error)
+	RevokeFamily(ctx context.Context, familyId string) error
+	RevokeUser(ctx context.Context, userId string) error
+}
+
+// RevocationRepositoryI keeps access tokens that have to be rejected before they expire:
+// single tokens by their id and all tokens of a user issued before a moment.
+type RevocationRepositoryI interface {
+	Revoke(ctx context.Context, tokenId string, expiresAt time.Time) error
+	RevokeUser(ctx context.Context, userId string, before time.Time) error
+	IsRevoked(ctx context.Context, tokenId, userId string, issuedAt time.Time) (bool, error)
+}
+
+type LoginAttemptRepositoryI interface {
+	Get(ctx context.Context, key string) (domain.LoginAttempts, error)
+	AddFailure(ctx context.Context, key string, at time.Time, window time.Duration) (domain.LoginAttempts, error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
+}
+
+type AccessTokenRepositoryI interface {
+	GetAll(ctx context.Context, userId string) ([]domain.AccessToken, error)
+	GetByTokenHash(ctx context.Context, tokenHash string) (domain.AccessToken, error)
+	Create(ctx context.Context, userId, tokenHash string, in domain.CreateAccessTokenInput) (domain.AccessToken, error)
+	Delete(ctx context.Context, id, userId string) error
+	UpdateLastUsed(ctx context.Context, id string, at time.Time) error
 }
 
 type TaskRepositoryI interface {
 	Get(ctx context.Context, id, userId string) (domain.Task, error)
-	GetAll(ctx context.Context, userId string) ([]domain.Task, error)
+	GetAll(ctx context.Context, userId string, filter domain.TaskFilter) (domain.TaskPage, error)
 	Create(ctx context.Context, userId string, in domain.CreateTaskInput) (domain.Task, error)
 	Update(ctx context.Context, id, userId string, in domain.UpdateTaskInput) (domain.Task, error)
 	Delete(ctx context.Context, id, userId string) error
+	UpdateStatus(ctx context.Context, id, userId string, status domain.TaskStatus, completedAt *time.Time) (domain.Task, error)
+	GetOverdue(ctx context.Context, userId string, now time.Time) ([]domain.Task, error)
+	GetDueBetween(ctx context.Context, userId string, from, to time.Time) ([]domain.Task, error)
+	AttachLabels(ctx context.Context, id, userId string, labelIds []string) (domain.Task, error)
+	DetachLabels(ctx context.Context, id, userId string, labelIds []string) (domain.Task, error)
+	GetSubtasks(ctx context.Context, userId string, parentIds []string) ([]domain.Task, error)
+	UpdatePosition(ctx context.Context, id, userId string, position float64) (domain.Task, error)
+	GetAdjacentPosition(ctx context.Context, userId string, task domain.Task, next bool) (*float64, error)
+	RebalancePositions(ctx context.Context, userId string, projectId *string) error
+	GetTrash(ctx context.Context, userId string) ([]domain.Task, error)
+	Restore(ctx context.Context, id, userId string) (domain.Task, error)
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
+	Count(ctx context.Context) (domain.TaskCounts, error)
+}
+
+type ProjectRepositoryI interface {
+	Get(ctx context.Context, id, userId string) (domain.Project, error)
+	GetAll(ctx context.Context, userId string) ([]domain.Project, error)
+	Create(ctx context.Context, userId string, in domain.CreateProjectInput) (domain.Project, error)
+	Update(ctx context.Context, id, userId string, in domain.UpdateProjectInput) (domain.Project, error)
+	Delete(ctx context.Context, id, userId string, mode domain.ProjectDeleteMode) error
+}
+
+type LabelRepositoryI interface {
+	Get(ctx context.Context, id, userId string) (domain.Label, error)
+	GetAll(ctx context.Context, userId string) ([]domain.Label, error)
+	Create(ctx context.Context, userId string, in domain.CreateLabelInput) (domain.Label, error)
+	Update(ctx context.Context, id, userId string, in domain.UpdateLabelInput) (domain.Label, error)
+	Delete(ctx context.Context, id, userId string) error
 }
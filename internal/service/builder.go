@@ -1,6 +1,6 @@
 package service
 
-type AppServiceBuilder struct{
+type AppServiceBuilder struct {
 	deps *Dependencies
 	reps *Repositories
 }
@@ -13,8 +13,43 @@ func NewAppServiceBuilder(deps *Dependencies, reps *Repositories) *AppServiceBui
 }
 
 func (b *AppServiceBuilder) Build() *Services {
+	verifier := NewEmailVerifier(
+		b.reps.User,
+		b.deps.JwtManager,
+		b.deps.Mailer,
+		b.deps.AppUrl,
+		b.deps.EmailVerificationTtl,
+	)
+
 	return &Services{
-		Auth: NewAuthService(b.reps.User, b.deps.Hasher, b.deps.JwtManager),
-		Task: NewTaskService(b.reps.Task),
+		Auth: NewAuthService(
+			b.reps.User,
+			b.reps.Session,
+			b.reps.Revocation,
+			b.deps.Hasher,
+			b.deps.JwtManager,
+			b.deps.RefreshTokenManager,
+			b.deps.RefreshTokenTtl,
+			b.deps.TotpManager,
+			b.deps.MfaChallengeTtl,
+			NewSignInGuard(b.reps.LoginAttempt, b.deps.SignInLimits),
+			verifier,
+		),
+		Account: NewAccountService(
+			b.reps.User,
+			b.reps.Session,
+			b.reps.Revocation,
+			b.deps.Hasher,
+			b.deps.JwtManager,
+			b.deps.Mailer,
+			b.deps.AppUrl,
+			b.deps.PasswordResetTtl,
+			verifier,
+		),
+		Admin:       NewAdminService(b.reps.User, b.reps.Task, b.reps.Session, b.reps.Revocation),
+		AccessToken: NewAccessTokenService(b.reps.AccessToken, b.reps.User, b.deps.AccessTokenManager),
+		Task:        NewTaskService(b.reps.Task, b.reps.Project, b.reps.Label, b.deps.TaskMaxDepth),
+		Project:     NewProjectService(b.reps.Project),
+		Label:       NewLabelService(b.reps.Label),
 	}
-}
\ No newline at end of file
+}
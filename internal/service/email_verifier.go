@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	jwtauth "github.com/i-vasilkov/go-todo-app/pkg/auth/jwt"
+	"github.com/i-vasilkov/go-todo-app/pkg/mailer"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// emailVerificationAudience marks the tokens mailed to prove the user owns the email.
+const emailVerificationAudience = "email-verification"
+
+// EmailVerifier mails links proving the users own their email addresses. The token names the
+// email rather than the user, so it stops working once the user switches to another address.
+type EmailVerifier struct {
+	rep    UserRepositoryI
+	jwt    jwtauth.TokenManagerI
+	mailer mailer.Mailer
+	appUrl string
+	ttl    time.Duration
+}
+
+func NewEmailVerifier(
+	rep UserRepositoryI,
+	jwt jwtauth.TokenManagerI,
+	mailer mailer.Mailer,
+	appUrl string,
+	ttl time.Duration,
+) *EmailVerifier {
+	return &EmailVerifier{
+		rep:    rep,
+		jwt:    jwt,
+		mailer: mailer,
+		appUrl: appUrl,
+		ttl:    ttl,
+	}
+}
+
+// Send mails the verification link to the user.
+func (v *EmailVerifier) Send(ctx context.Context, user domain.User) error {
+	if user.Email == "" {
+		return domain.ErrEmailMissing
+	}
+	if user.EmailVerified {
+		return domain.ErrEmailAlreadyVerified
+	}
+
+	token, err := v.jwt.NewAudienceToken(user.Email, emailVerificationAudience, v.ttl)
+	if err != nil {
+		return err
+	}
+
+	return v.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Confirm your email",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nplease confirm your email by following the link below:\n%s\n",
+			user.Login, mailLink(v.appUrl, "/verify-email", token),
+		),
+	})
+}
+
+// Verify marks the email named by the token as verified. Verifying twice is harmless, so the
+// token is not used up.
+func (v *EmailVerifier) Verify(ctx context.Context, token string) error {
+	claims, err := v.jwt.ParseAudience(token, emailVerificationAudience)
+	if err != nil {
+		return domain.ErrInvalidVerificationToken
+	}
+
+	user, err := v.rep.GetByEmail(ctx, claims.Subject)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return domain.ErrInvalidVerificationToken
+	}
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return nil
+	}
+
+	return v.rep.SetEmailVerified(ctx, user.Id)
+}
+
+// mailLink points to a page of the web app that passes the token on to the API.
+func mailLink(appUrl, path, token string) string {
+	return strings.TrimSuffix(appUrl, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+// normalizeEmail makes the lookups by email case-insensitive.
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+)
+
+type LabelService struct {
+	rep LabelRepositoryI
+}
+
+func NewLabelService(rep LabelRepositoryI) *LabelService {
+	return &LabelService{
+		rep: rep,
+	}
+}
+
+func (l *LabelService) Get(ctx context.Context, id, userId string) (domain.Label, error) {
+	return l.rep.Get(ctx, id, userId)
+}
+
+func (l *LabelService) GetAll(ctx context.Context, userId string) ([]domain.Label, error) {
+	return l.rep.GetAll(ctx, userId)
+}
+
+func (l *LabelService) Create(ctx context.Context, userId string, in domain.CreateLabelInput) (domain.Label, error) {
+	return l.rep.Create(ctx, userId, in)
+}
+
+func (l *LabelService) Update(ctx context.Context, id, userId string, in domain.UpdateLabelInput) (domain.Label, error) {
+	return l.rep.Update(ctx, id, userId, in)
+}
+
+func (l *LabelService) Delete(ctx context.Context, id, userId string) error {
+	return l.rep.Delete(ctx, id, userId)
+}
@@ -7,9 +7,11 @@ package mock_service
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	domain "github.com/i-vasilkov/go-todo-app/internal/domain"
+	jwt "github.com/i-vasilkov/go-todo-app/pkg/auth/jwt"
 )
 
 // MockAuthServiceI is a mock of AuthServiceI interface.
@@ -35,76 +37,1546 @@ func (m *MockAuthServiceI) EXPECT() *MockAuthServiceIMockRecorder {
 	return m.recorder
 }
 
+// ChangePassword mocks base method.
+func (m *MockAuthServiceI) ChangePassword(ctx context.Context, userId string, in domain.ChangePasswordInput, clientIp string) (domain.Tokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, userId, in, clientIp)
+	ret0, _ := ret[0].(domain.Tokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockAuthServiceIMockRecorder) ChangePassword(ctx, userId, in, clientIp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockAuthServiceI)(nil).ChangePassword), ctx, userId, in, clientIp)
+}
+
 // CheckToken mocks base method.
-func (m *MockAuthServiceI) CheckToken(ctx context.Context, token string) (string, error) {
+func (m *MockAuthServiceI) CheckToken(ctx context.Context, token string) (domain.Identity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckToken", ctx, token)
+	ret0, _ := ret[0].(domain.Identity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckToken indicates an expected call of CheckToken.
+func (mr *MockAuthServiceIMockRecorder) CheckToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckToken", reflect.TypeOf((*MockAuthServiceI)(nil).CheckToken), ctx, token)
+}
+
+// ConfirmTotp mocks base method.
+func (m *MockAuthServiceI) ConfirmTotp(ctx context.Context, userId, code string) (domain.RecoveryCodes, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTotp", ctx, userId, code)
+	ret0, _ := ret[0].(domain.RecoveryCodes)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmTotp indicates an expected call of ConfirmTotp.
+func (mr *MockAuthServiceIMockRecorder) ConfirmTotp(ctx, userId, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTotp", reflect.TypeOf((*MockAuthServiceI)(nil).ConfirmTotp), ctx, userId, code)
+}
+
+// DisableTotp mocks base method.
+func (m *MockAuthServiceI) DisableTotp(ctx context.Context, userId, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTotp", ctx, userId, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTotp indicates an expected call of DisableTotp.
+func (mr *MockAuthServiceIMockRecorder) DisableTotp(ctx, userId, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTotp", reflect.TypeOf((*MockAuthServiceI)(nil).DisableTotp), ctx, userId, code)
+}
+
+// EnrollTotp mocks base method.
+func (m *MockAuthServiceI) EnrollTotp(ctx context.Context, userId string) (domain.TotpEnrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollTotp", ctx, userId)
+	ret0, _ := ret[0].(domain.TotpEnrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollTotp indicates an expected call of EnrollTotp.
+func (mr *MockAuthServiceIMockRecorder) EnrollTotp(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollTotp", reflect.TypeOf((*MockAuthServiceI)(nil).EnrollTotp), ctx, userId)
+}
+
+// GetJWKS mocks base method.
+func (m *MockAuthServiceI) GetJWKS(ctx context.Context) jwt.JWKS {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJWKS", ctx)
+	ret0, _ := ret[0].(jwt.JWKS)
+	return ret0
+}
+
+// GetJWKS indicates an expected call of GetJWKS.
+func (mr *MockAuthServiceIMockRecorder) GetJWKS(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJWKS", reflect.TypeOf((*MockAuthServiceI)(nil).GetJWKS), ctx)
+}
+
+// Logout mocks base method.
+func (m *MockAuthServiceI) Logout(ctx context.Context, accessToken string, in domain.LogoutInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", ctx, accessToken, in)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockAuthServiceIMockRecorder) Logout(ctx, accessToken, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockAuthServiceI)(nil).Logout), ctx, accessToken, in)
+}
+
+// LogoutAll mocks base method.
+func (m *MockAuthServiceI) LogoutAll(ctx context.Context, userId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LogoutAll", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LogoutAll indicates an expected call of LogoutAll.
+func (mr *MockAuthServiceIMockRecorder) LogoutAll(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogoutAll", reflect.TypeOf((*MockAuthServiceI)(nil).LogoutAll), ctx, userId)
+}
+
+// Refresh mocks base method.
+func (m *MockAuthServiceI) Refresh(ctx context.Context, refreshToken string) (domain.Tokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", ctx, refreshToken)
+	ret0, _ := ret[0].(domain.Tokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockAuthServiceIMockRecorder) Refresh(ctx, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockAuthServiceI)(nil).Refresh), ctx, refreshToken)
+}
+
+// SignIn mocks base method.
+func (m *MockAuthServiceI) SignIn(ctx context.Context, in domain.LoginUserInput, clientIp string) (domain.SignInResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignIn", ctx, in, clientIp)
+	ret0, _ := ret[0].(domain.SignInResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SignIn indicates an expected call of SignIn.
+func (mr *MockAuthServiceIMockRecorder) SignIn(ctx, in, clientIp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignIn", reflect.TypeOf((*MockAuthServiceI)(nil).SignIn), ctx, in, clientIp)
+}
+
+// SignInMfa mocks base method.
+func (m *MockAuthServiceI) SignInMfa(ctx context.Context, in domain.MfaSignInInput, clientIp string) (domain.Tokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignInMfa", ctx, in, clientIp)
+	ret0, _ := ret[0].(domain.Tokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SignInMfa indicates an expected call of SignInMfa.
+func (mr *MockAuthServiceIMockRecorder) SignInMfa(ctx, in, clientIp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignInMfa", reflect.TypeOf((*MockAuthServiceI)(nil).SignInMfa), ctx, in, clientIp)
+}
+
+// SignUp mocks base method.
+func (m *MockAuthServiceI) SignUp(ctx context.Context, in domain.CreateUserInput) (domain.Tokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignUp", ctx, in)
+	ret0, _ := ret[0].(domain.Tokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SignUp indicates an expected call of SignUp.
+func (mr *MockAuthServiceIMockRecorder) SignUp(ctx, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUp", reflect.TypeOf((*MockAuthServiceI)(nil).SignUp), ctx, in)
+}
+
+// MockAccountServiceI is a mock of AccountServiceI interface.
+type MockAccountServiceI struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccountServiceIMockRecorder
+}
+
+// MockAccountServiceIMockRecorder is the mock recorder for MockAccountServiceI.
+type MockAccountServiceIMockRecorder struct {
+	mock *MockAccountServiceI
+}
+
+// NewMockAccountServiceI creates a new mock instance.
+func NewMockAccountServiceI(ctrl *gomock.Controller) *MockAccountServiceI {
+	mock := &MockAccountServiceI{ctrl: ctrl}
+	mock.recorder = &MockAccountServiceIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccountServiceI) EXPECT() *MockAccountServiceIMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockAccountServiceI) Delete(ctx context.Context, userId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockAccountServiceIMockRecorder) Delete(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockAccountServiceI)(nil).Delete), ctx, userId)
+}
+
+// ForgotPassword mocks base method.
+func (m *MockAccountServiceI) ForgotPassword(ctx context.Context, in domain.ForgotPasswordInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForgotPassword", ctx, in)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForgotPassword indicates an expected call of ForgotPassword.
+func (mr *MockAccountServiceIMockRecorder) ForgotPassword(ctx, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForgotPassword", reflect.TypeOf((*MockAccountServiceI)(nil).ForgotPassword), ctx, in)
+}
+
+// Get mocks base method.
+func (m *MockAccountServiceI) Get(ctx context.Context, userId string) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, userId)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockAccountServiceIMockRecorder) Get(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockAccountServiceI)(nil).Get), ctx, userId)
+}
+
+// ResendVerification mocks base method.
+func (m *MockAccountServiceI) ResendVerification(ctx context.Context, userId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResendVerification", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResendVerification indicates an expected call of ResendVerification.
+func (mr *MockAccountServiceIMockRecorder) ResendVerification(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResendVerification", reflect.TypeOf((*MockAccountServiceI)(nil).ResendVerification), ctx, userId)
+}
+
+// ResetPassword mocks base method.
+func (m *MockAccountServiceI) ResetPassword(ctx context.Context, in domain.ResetPasswordInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, in)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockAccountServiceIMockRecorder) ResetPassword(ctx, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockAccountServiceI)(nil).ResetPassword), ctx, in)
+}
+
+// Update mocks base method.
+func (m *MockAccountServiceI) Update(ctx context.Context, userId string, in domain.UpdateUserInput) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, userId, in)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockAccountServiceIMockRecorder) Update(ctx, userId, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockAccountServiceI)(nil).Update), ctx, userId, in)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockAccountServiceI) VerifyEmail(ctx context.Context, in domain.VerifyEmailInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", ctx, in)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockAccountServiceIMockRecorder) VerifyEmail(ctx, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockAccountServiceI)(nil).VerifyEmail), ctx, in)
+}
+
+// MockAdminServiceI is a mock of AdminServiceI interface.
+type MockAdminServiceI struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminServiceIMockRecorder
+}
+
+// MockAdminServiceIMockRecorder is the mock recorder for MockAdminServiceI.
+type MockAdminServiceIMockRecorder struct {
+	mock *MockAdminServiceI
+}
+
+// NewMockAdminServiceI creates a new mock instance.
+func NewMockAdminServiceI(ctrl *gomock.Controller) *MockAdminServiceI {
+	mock := &MockAdminServiceI{ctrl: ctrl}
+	mock.recorder = &MockAdminServiceIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdminServiceI) EXPECT() *MockAdminServiceIMockRecorder {
+	return m.recorder
+}
+
+// DeleteUser mocks base method.
+func (m *MockAdminServiceI) DeleteUser(ctx context.Context, adminId, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", ctx, adminId, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockAdminServiceIMockRecorder) DeleteUser(ctx, adminId, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockAdminServiceI)(nil).DeleteUser), ctx, adminId, id)
+}
+
+// GetTaskCounts mocks base method.
+func (m *MockAdminServiceI) GetTaskCounts(ctx context.Context) (domain.TaskCounts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaskCounts", ctx)
+	ret0, _ := ret[0].(domain.TaskCounts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTaskCounts indicates an expected call of GetTaskCounts.
+func (mr *MockAdminServiceIMockRecorder) GetTaskCounts(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskCounts", reflect.TypeOf((*MockAdminServiceI)(nil).GetTaskCounts), ctx)
+}
+
+// GetUsers mocks base method.
+func (m *MockAdminServiceI) GetUsers(ctx context.Context, filter domain.UserFilter) ([]domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsers", ctx, filter)
+	ret0, _ := ret[0].([]domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsers indicates an expected call of GetUsers.
+func (mr *MockAdminServiceIMockRecorder) GetUsers(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockAdminServiceI)(nil).GetUsers), ctx, filter)
+}
+
+// UpdateUser mocks base method.
+func (m *MockAdminServiceI) UpdateUser(ctx context.Context, adminId, id string, in domain.AdminUpdateUserInput) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUser", ctx, adminId, id, in)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUser indicates an expected call of UpdateUser.
+func (mr *MockAdminServiceIMockRecorder) UpdateUser(ctx, adminId, id, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockAdminServiceI)(nil).UpdateUser), ctx, adminId, id, in)
+}
+
+// MockAccessTokenServiceI is a mock of AccessTokenServiceI interface.
+type MockAccessTokenServiceI struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccessTokenServiceIMockRecorder
+}
+
+// MockAccessTokenServiceIMockRecorder is the mock recorder for MockAccessTokenServiceI.
+type MockAccessTokenServiceIMockRecorder struct {
+	mock *MockAccessTokenServiceI
+}
+
+// NewMockAccessTokenServiceI creates a new mock instance.
+func NewMockAccessTokenServiceI(ctrl *gomock.Controller) *MockAccessTokenServiceI {
+	mock := &MockAccessTokenServiceI{ctrl: ctrl}
+	mock.recorder = &MockAccessTokenServiceIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccessTokenServiceI) EXPECT() *MockAccessTokenServiceIMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *MockAccessTokenServiceI) Check(ctx context.Context, token string) (domain.AccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", ctx, token)
+	ret0, _ := ret[0].(domain.AccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockAccessTokenServiceIMockRecorder) Check(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockAccessTokenServiceI)(nil).Check), ctx, token)
+}
+
+// Create mocks base method.
+func (m *MockAccessTokenServiceI) Create(ctx context.Context, userId string, in domain.CreateAccessTokenInput) (domain.CreatedAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, userId, in)
+	ret0, _ := ret[0].(domain.CreatedAccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAccessTokenServiceIMockRecorder) Create(ctx, userId, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAccessTokenServiceI)(nil).Create), ctx, userId, in)
+}
+
+// Delete mocks base method.
+func (m *MockAccessTokenServiceI) Delete(ctx context.Context, id, userId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockAccessTokenServiceIMockRecorder) Delete(ctx, id, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockAccessTokenServiceI)(nil).Delete), ctx, id, userId)
+}
+
+// GetAll mocks base method.
+func (m *MockAccessTokenServiceI) GetAll(ctx context.Context, userId string) ([]domain.AccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx, userId)
+	ret0, _ := ret[0].([]domain.AccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockAccessTokenServiceIMockRecorder) GetAll(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockAccessTokenServiceI)(nil).GetAll), ctx, userId)
+}
+
+// MockTaskServiceI is a mock of TaskServiceI interface.
+type MockTaskServiceI struct {
+	ctrl     *gomock.Controller
+	recorder *MockTaskServiceIMockRecorder
+}
+
+// MockTaskServiceIMockRecorder is the mock recorder for MockTaskServiceI.
+type MockTaskServiceIMockRecorder struct {
+	mock *MockTaskServiceI
+}
+
+// NewMockTaskServiceI creates a new mock instance.
+func NewMockTaskServiceI(ctrl *gomock.Controller) *MockTaskServiceI {
+	mock := &MockTaskServiceI{ctrl: ctrl}
+	mock.recorder = &MockTaskServiceIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTaskServiceI) EXPECT() *MockTaskServiceIMockRecorder {
+	return m.recorder
+}
+
+// AttachLabels mocks base method.
+func (m *MockTaskServiceI) AttachLabels(ctx context.Context, id, userId string, labelIds []string) (domain.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AttachLabels", ctx, id, userId, labelIds)
+	ret0, _ := ret[0].(domain.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AttachLabels indicates an expected call of AttachLabels.
+func (mr *MockTaskServiceIMockRecorder) AttachLabels(ctx, id, userId, labelIds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttachLabels", reflect.TypeOf((*MockTaskServiceI)(nil).AttachLabels), ctx, id, userId, labelIds)
+}
+
+// Complete mocks base method.
+func (m *MockTaskServiceI) Complete(ctx context.Context, id, userId string, withSubtasks bool) (domain.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", ctx, id, userId, withSubtasks)
+	ret0, _ := ret[0].(domain.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockTaskServiceIMockRecorder) Complete(ctx, id, userId, withSubtasks interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockTaskServiceI)(nil).Complete), ctx, id, userId, withSubtasks)
+}
+
+// Create mocks base method.
+func (m *MockTaskServiceI) Create(ctx context.Context, userId string, in domain.CreateTaskInput) (domain.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, userId, in)
+	ret0, _ := ret[0].(domain.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockTaskServiceIMockRecorder) Create(ctx, userId, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTaskServiceI)(nil).Create), ctx, userId, in)
+}
+
+// Delete mocks base method.
+func (m *MockTaskServiceI) Delete(ctx context.Context, id, userId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockTaskServiceIMockRecorder) Delete(ctx, id, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTaskServiceI)(nil).Delete), ctx, id, userId)
+}
+
+// DetachLabels mocks base method.
+func (m *MockTaskServiceI) DetachLabels(ctx context.Context, id, userId string, labelIds []string) (domain.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DetachLabels", ctx, id, userId, labelIds)
+	ret0, _ := ret[0].(domain.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DetachLabels indicates an expected call of DetachLabels.
+func (mr *MockTaskServiceIMockRecorder) DetachLabels(ctx, id, userId, labelIds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetachLabels", reflect.TypeOf((*MockTaskServiceI)(nil).DetachLabels), ctx, id, userId, labelIds)
+}
+
+// Get mocks base method.
+func (m *MockTaskServiceI) Get(ctx context.Context, id, userId string) (domain.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id, userId)
+	ret0, _ := ret[0].(domain.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockTaskServiceIMockRecorder) Get(ctx, id, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockTaskServiceI)(nil).Get), ctx, id, userId)
+}
+
+// GetAll mocks base method.
+func (m *MockTaskServiceI) GetAll(ctx context.Context, userId string, filter domain.TaskFilter) (domain.TaskPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx, userId, filter)
+	ret0, _ := ret[0].(domain.TaskPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockTaskServiceIMockRecorder) GetAll(ctx, userId, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockTaskServiceI)(nil).GetAll), ctx, userId, filter)
+}
+
+// GetDueBetween mocks base method.
+func (m *MockTaskServiceI) GetDueBetween(ctx context.Context, userId string, from, to time.Time) ([]domain.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueBetween", ctx, userId, from, to)
+	ret0, _ := ret[0].([]domain.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueBetween indicates an expected call of GetDueBetween.
+func (mr *MockTaskServiceIMockRecorder) GetDueBetween(ctx, userId, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueBetween", reflect.TypeOf((*MockTaskServiceI)(nil).GetDueBetween), ctx, userId, from, to)
+}
+
+// GetOverdue mocks base method.
+func (m *MockTaskServiceI) GetOverdue(ctx context.Context, userId string) ([]domain.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOverdue", ctx, userId)
+	ret0, _ := ret[0].([]domain.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOverdue indicates an expected call of GetOverdue.
+func (mr *MockTaskServiceIMockRecorder) GetOverdue(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOverdue", reflect.TypeOf((*MockTaskServiceI)(nil).GetOverdue), ctx, userId)
+}
+
+// GetTrash mocks base method.
+func (m *MockTaskServiceI) GetTrash(ctx context.Context, userId string) ([]domain.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrash", ctx, userId)
+	ret0, _ := ret[0].([]domain.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrash indicates an expected call of GetTrash.
+func (mr *MockTaskServiceIMockRecorder) GetTrash(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrash", reflect.TypeOf((*MockTaskServiceI)(nil).GetTrash), ctx, userId)
+}
+
+// GetTree mocks base method.
+func (m *MockTaskServiceI) GetTree(ctx context.Context, id, userId string) (domain.TaskTree, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTree", ctx, id, userId)
+	ret0, _ := ret[0].(domain.TaskTree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTree indicates an expected call of GetTree.
+func (mr *MockTaskServiceIMockRecorder) GetTree(ctx, id, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTree", reflect.TypeOf((*MockTaskServiceI)(nil).GetTree), ctx, id, userId)
+}
+
+// Move mocks base method.
+func (m *MockTaskServiceI) Move(ctx context.Context, id, userId string, in domain.MoveTaskInput) (domain.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Move", ctx, id, userId, in)
+	ret0, _ := ret[0].(domain.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Move indicates an expected call of Move.
+func (mr *MockTaskServiceIMockRecorder) Move(ctx, id, userId, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Move", reflect.TypeOf((*MockTaskServiceI)(nil).Move), ctx, id, userId, in)
+}
+
+// PurgeTrash mocks base method.
+func (m *MockTaskServiceI) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeTrash", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeTrash indicates an expected call of PurgeTrash.
+func (mr *MockTaskServiceIMockRecorder) PurgeTrash(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeTrash", reflect.TypeOf((*MockTaskServiceI)(nil).PurgeTrash), ctx, before)
+}
+
+// Reopen mocks base method.
+func (m *MockTaskServiceI) Reopen(ctx context.Context, id, userId string) (domain.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reopen", ctx, id, userId)
+	ret0, _ := ret[0].(domain.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reopen indicates an expected call of Reopen.
+func (mr *MockTaskServiceIMockRecorder) Reopen(ctx, id, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reopen", reflect.TypeOf((*MockTaskServiceI)(nil).Reopen), ctx, id, userId)
+}
+
+// Restore mocks base method.
+func (m *MockTaskServiceI) Restore(ctx context.Context, id, userId string) (domain.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, id, userId)
+	ret0, _ := ret[0].(domain.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockTaskServiceIMockRecorder) Restore(ctx, id, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockTaskServiceI)(nil).Restore), ctx, id, userId)
+}
+
+// Update mocks base method.
+func (m *MockTaskServiceI) Update(ctx context.Context, id, userId string, in domain.UpdateTaskInput) (domain.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, id, userId, in)
+	ret0, _ := ret[0].(domain.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockTaskServiceIMockRecorder) Update(ctx, id, userId, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTaskServiceI)(nil).Update), ctx, id, userId, in)
+}
+
+// MockProjectServiceI is a mock of ProjectServiceI interface.
+type MockProjectServiceI struct {
+	ctrl     *gomock.Controller
+	recorder *MockProjectServiceIMockRecorder
+}
+
+// MockProjectServiceIMockRecorder is the mock recorder for MockProjectServiceI.
+type MockProjectServiceIMockRecorder struct {
+	mock *MockProjectServiceI
+}
+
+// NewMockProjectServiceI creates a new mock instance.
+func NewMockProjectServiceI(ctrl *gomock.Controller) *MockProjectServiceI {
+	mock := &MockProjectServiceI{ctrl: ctrl}
+	mock.recorder = &MockProjectServiceIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProjectServiceI) EXPECT() *MockProjectServiceIMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockProjectServiceI) Create(ctx context.Context, userId string, in domain.CreateProjectInput) (domain.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, userId, in)
+	ret0, _ := ret[0].(domain.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockProjectServiceIMockRecorder) Create(ctx, userId, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockProjectServiceI)(nil).Create), ctx, userId, in)
+}
+
+// Delete mocks base method.
+func (m *MockProjectServiceI) Delete(ctx context.Context, id, userId string, mode domain.ProjectDeleteMode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id, userId, mode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockProjectServiceIMockRecorder) Delete(ctx, id, userId, mode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockProjectServiceI)(nil).Delete), ctx, id, userId, mode)
+}
+
+// Get mocks base method.
+func (m *MockProjectServiceI) Get(ctx context.Context, id, userId string) (domain.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id, userId)
+	ret0, _ := ret[0].(domain.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockProjectServiceIMockRecorder) Get(ctx, id, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockProjectServiceI)(nil).Get), ctx, id, userId)
+}
+
+// GetAll mocks base method.
+func (m *MockProjectServiceI) GetAll(ctx context.Context, userId string) ([]domain.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx, userId)
+	ret0, _ := ret[0].([]domain.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockProjectServiceIMockRecorder) GetAll(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockProjectServiceI)(nil).GetAll), ctx, userId)
+}
+
+// Update mocks base method.
+func (m *MockProjectServiceI) Update(ctx context.Context, id, userId string, in domain.UpdateProjectInput) (domain.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, id, userId, in)
+	ret0, _ := ret[0].(domain.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockProjectServiceIMockRecorder) Update(ctx, id, userId, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockProjectServiceI)(nil).Update), ctx, id, userId, in)
+}
+
+// MockLabelServiceI is a mock of LabelServiceI interface.
+type MockLabelServiceI struct {
+	ctrl     *gomock.Controller
+	recorder *MockLabelServiceIMockRecorder
+}
+
+// MockLabelServiceIMockRecorder is the mock recorder for MockLabelServiceI.
+type MockLabelServiceIMockRecorder struct {
+	mock *MockLabelServiceI
+}
+
+// NewMockLabelServiceI creates a new mock instance.
+func NewMockLabelServiceI(ctrl *gomock.Controller) *MockLabelServiceI {
+	mock := &MockLabelServiceI{ctrl: ctrl}
+	mock.recorder = &MockLabelServiceIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLabelServiceI) EXPECT() *MockLabelServiceIMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockLabelServiceI) Create(ctx context.Context, userId string, in domain.CreateLabelInput) (domain.Label, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, userId, in)
+	ret0, _ := ret[0].(domain.Label)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockLabelServiceIMockRecorder) Create(ctx, userId, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockLabelServiceI)(nil).Create), ctx, userId, in)
+}
+
+// Delete mocks base method.
+func (m *MockLabelServiceI) Delete(ctx context.Context, id, userId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockLabelServiceIMockRecorder) Delete(ctx, id, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockLabelServiceI)(nil).Delete), ctx, id, userId)
+}
+
+// Get mocks base method.
+func (m *MockLabelServiceI) Get(ctx context.Context, id, userId string) (domain.Label, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id, userId)
+	ret0, _ := ret[0].(domain.Label)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockLabelServiceIMockRecorder) Get(ctx, id, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockLabelServiceI)(nil).Get), ctx, id, userId)
+}
+
+// GetAll mocks base method.
+func (m *MockLabelServiceI) GetAll(ctx context.Context, userId string) ([]domain.Label, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx, userId)
+	ret0, _ := ret[0].([]domain.Label)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockLabelServiceIMockRecorder) GetAll(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockLabelServiceI)(nil).GetAll), ctx, userId)
+}
+
+// Update mocks base method.
+func (m *MockLabelServiceI) Update(ctx context.Context, id, userId string, in domain.UpdateLabelInput) (domain.Label, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, id, userId, in)
+	ret0, _ := ret[0].(domain.Label)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockLabelServiceIMockRecorder) Update(ctx, id, userId, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockLabelServiceI)(nil).Update), ctx, id, userId, in)
+}
+
+// MockUserRepositoryI is a mock of UserRepositoryI interface.
+type MockUserRepositoryI struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserRepositoryIMockRecorder
+}
+
+// MockUserRepositoryIMockRecorder is the mock recorder for MockUserRepositoryI.
+type MockUserRepositoryIMockRecorder struct {
+	mock *MockUserRepositoryI
+}
+
+// NewMockUserRepositoryI creates a new mock instance.
+func NewMockUserRepositoryI(ctrl *gomock.Controller) *MockUserRepositoryI {
+	mock := &MockUserRepositoryI{ctrl: ctrl}
+	mock.recorder = &MockUserRepositoryIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserRepositoryI) EXPECT() *MockUserRepositoryIMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockUserRepositoryI) Create(ctx context.Context, in domain.CreateUserInput) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, in)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockUserRepositoryIMockRecorder) Create(ctx, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUserRepositoryI)(nil).Create), ctx, in)
+}
+
+// Delete mocks base method.
+func (m *MockUserRepositoryI) Delete(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockUserRepositoryIMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUserRepositoryI)(nil).Delete), ctx, id)
+}
+
+// Get mocks base method.
+func (m *MockUserRepositoryI) Get(ctx context.Context, id string) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockUserRepositoryIMockRecorder) Get(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockUserRepositoryI)(nil).Get), ctx, id)
+}
+
+// GetAll mocks base method.
+func (m *MockUserRepositoryI) GetAll(ctx context.Context, filter domain.UserFilter) ([]domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx, filter)
+	ret0, _ := ret[0].([]domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockUserRepositoryIMockRecorder) GetAll(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockUserRepositoryI)(nil).GetAll), ctx, filter)
+}
+
+// GetByEmail mocks base method.
+func (m *MockUserRepositoryI) GetByEmail(ctx context.Context, email string) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByEmail", ctx, email)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByEmail indicates an expected call of GetByEmail.
+func (mr *MockUserRepositoryIMockRecorder) GetByEmail(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByEmail", reflect.TypeOf((*MockUserRepositoryI)(nil).GetByEmail), ctx, email)
+}
+
+// GetByLogin mocks base method.
+func (m *MockUserRepositoryI) GetByLogin(ctx context.Context, login string) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByLogin", ctx, login)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByLogin indicates an expected call of GetByLogin.
+func (mr *MockUserRepositoryIMockRecorder) GetByLogin(ctx, login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByLogin", reflect.TypeOf((*MockUserRepositoryI)(nil).GetByLogin), ctx, login)
+}
+
+// SetDisabled mocks base method.
+func (m *MockUserRepositoryI) SetDisabled(ctx context.Context, id string, disabled bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDisabled", ctx, id, disabled)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDisabled indicates an expected call of SetDisabled.
+func (mr *MockUserRepositoryIMockRecorder) SetDisabled(ctx, id, disabled interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDisabled", reflect.TypeOf((*MockUserRepositoryI)(nil).SetDisabled), ctx, id, disabled)
+}
+
+// SetEmailVerified mocks base method.
+func (m *MockUserRepositoryI) SetEmailVerified(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetEmailVerified", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetEmailVerified indicates an expected call of SetEmailVerified.
+func (mr *MockUserRepositoryIMockRecorder) SetEmailVerified(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEmailVerified", reflect.TypeOf((*MockUserRepositoryI)(nil).SetEmailVerified), ctx, id)
+}
+
+// SetRoles mocks base method.
+func (m *MockUserRepositoryI) SetRoles(ctx context.Context, id string, roles []domain.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRoles", ctx, id, roles)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRoles indicates an expected call of SetRoles.
+func (mr *MockUserRepositoryIMockRecorder) SetRoles(ctx, id, roles interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRoles", reflect.TypeOf((*MockUserRepositoryI)(nil).SetRoles), ctx, id, roles)
+}
+
+// Update mocks base method.
+func (m *MockUserRepositoryI) Update(ctx context.Context, id string, in domain.UpdateUserInput) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, id, in)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockUserRepositoryIMockRecorder) Update(ctx, id, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUserRepositoryI)(nil).Update), ctx, id, in)
+}
+
+// UpdatePassword mocks base method.
+func (m *MockUserRepositoryI) UpdatePassword(ctx context.Context, id, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, id, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockUserRepositoryIMockRecorder) UpdatePassword(ctx, id, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockUserRepositoryI)(nil).UpdatePassword), ctx, id, password)
+}
+
+// UpdateTotp mocks base method.
+func (m *MockUserRepositoryI) UpdateTotp(ctx context.Context, id string, in domain.UpdateTotpInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTotp", ctx, id, in)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTotp indicates an expected call of UpdateTotp.
+func (mr *MockUserRepositoryIMockRecorder) UpdateTotp(ctx, id, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTotp", reflect.TypeOf((*MockUserRepositoryI)(nil).UpdateTotp), ctx, id, in)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockUserRepositoryI) UseRecoveryCode(ctx context.Context, id, codeHash string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, id, codeHash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockUserRepositoryIMockRecorder) UseRecoveryCode(ctx, id, codeHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockUserRepositoryI)(nil).UseRecoveryCode), ctx, id, codeHash)
+}
+
+// UseTotpStep mocks base method.
+func (m *MockUserRepositoryI) UseTotpStep(ctx context.Context, id string, step int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTotpStep", ctx, id, step)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseTotpStep indicates an expected call of UseTotpStep.
+func (mr *MockUserRepositoryIMockRecorder) UseTotpStep(ctx, id, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTotpStep", reflect.TypeOf((*MockUserRepositoryI)(nil).UseTotpStep), ctx, id, step)
+}
+
+// MockSessionRepositoryI is a mock of SessionRepositoryI interface.
+type MockSessionRepositoryI struct {
+	ctrl     *gomock.Controller
+	recorder *MockSessionRepositoryIMockRecorder
+}
+
+// MockSessionRepositoryIMockRecorder is the mock recorder for MockSessionRepositoryI.
+type MockSessionRepositoryIMockRecorder struct {
+	mock *MockSessionRepositoryI
+}
+
+// NewMockSessionRepositoryI creates a new mock instance.
+func NewMockSessionRepositoryI(ctrl *gomock.Controller) *MockSessionRepositoryI {
+	mock := &MockSessionRepositoryI{ctrl: ctrl}
+	mock.recorder = &MockSessionRepositoryIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSessionRepositoryI) EXPECT() *MockSessionRepositoryIMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockSessionRepositoryI) Create(ctx context.Context, in domain.CreateSessionInput) (domain.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, in)
+	ret0, _ := ret[0].(domain.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockSessionRepositoryIMockRecorder) Create(ctx, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSessionRepositoryI)(nil).Create), ctx, in)
+}
+
+// GetByTokenHash mocks base method.
+func (m *MockSessionRepositoryI) GetByTokenHash(ctx context.Context, tokenHash string) (domain.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByTokenHash", ctx, tokenHash)
+	ret0, _ := ret[0].(domain.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByTokenHash indicates an expected call of GetByTokenHash.
+func (mr *MockSessionRepositoryIMockRecorder) GetByTokenHash(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByTokenHash", reflect.TypeOf((*MockSessionRepositoryI)(nil).GetByTokenHash), ctx, tokenHash)
+}
+
+// Revoke mocks base method.
+func (m *MockSessionRepositoryI) Revoke(ctx context.Context, id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockSessionRepositoryIMockRecorder) Revoke(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockSessionRepositoryI)(nil).Revoke), ctx, id)
+}
+
+// RevokeFamily mocks base method.
+func (m *MockSessionRepositoryI) RevokeFamily(ctx context.Context, familyId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeFamily", ctx, familyId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeFamily indicates an expected call of RevokeFamily.
+func (mr *MockSessionRepositoryIMockRecorder) RevokeFamily(ctx, familyId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeFamily", reflect.TypeOf((*MockSessionRepositoryI)(nil).RevokeFamily), ctx, familyId)
+}
+
+// RevokeUser mocks base method.
+func (m *MockSessionRepositoryI) RevokeUser(ctx context.Context, userId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUser", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUser indicates an expected call of RevokeUser.
+func (mr *MockSessionRepositoryIMockRecorder) RevokeUser(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUser", reflect.TypeOf((*MockSessionRepositoryI)(nil).RevokeUser), ctx, userId)
+}
+
+// MockRevocationRepositoryI is a mock of RevocationRepositoryI interface.
+type MockRevocationRepositoryI struct {
+	ctrl     *gomock.Controller
+	recorder *MockRevocationRepositoryIMockRecorder
+}
+
+// MockRevocationRepositoryIMockRecorder is the mock recorder for MockRevocationRepositoryI.
+type MockRevocationRepositoryIMockRecorder struct {
+	mock *MockRevocationRepositoryI
+}
+
+// NewMockRevocationRepositoryI creates a new mock instance.
+func NewMockRevocationRepositoryI(ctrl *gomock.Controller) *MockRevocationRepositoryI {
+	mock := &MockRevocationRepositoryI{ctrl: ctrl}
+	mock.recorder = &MockRevocationRepositoryIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRevocationRepositoryI) EXPECT() *MockRevocationRepositoryIMockRecorder {
+	return m.recorder
+}
+
+// IsRevoked mocks base method.
+func (m *MockRevocationRepositoryI) IsRevoked(ctx context.Context, tokenId, userId string, issuedAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsRevoked", ctx, tokenId, userId, issuedAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsRevoked indicates an expected call of IsRevoked.
+func (mr *MockRevocationRepositoryIMockRecorder) IsRevoked(ctx, tokenId, userId, issuedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsRevoked", reflect.TypeOf((*MockRevocationRepositoryI)(nil).IsRevoked), ctx, tokenId, userId, issuedAt)
+}
+
+// Revoke mocks base method.
+func (m *MockRevocationRepositoryI) Revoke(ctx context.Context, tokenId string, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, tokenId, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockRevocationRepositoryIMockRecorder) Revoke(ctx, tokenId, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockRevocationRepositoryI)(nil).Revoke), ctx, tokenId, expiresAt)
+}
+
+// RevokeUser mocks base method.
+func (m *MockRevocationRepositoryI) RevokeUser(ctx context.Context, userId string, before time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUser", ctx, userId, before)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUser indicates an expected call of RevokeUser.
+func (mr *MockRevocationRepositoryIMockRecorder) RevokeUser(ctx, userId, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUser", reflect.TypeOf((*MockRevocationRepositoryI)(nil).RevokeUser), ctx, userId, before)
+}
+
+// MockLoginAttemptRepositoryI is a mock of LoginAttemptRepositoryI interface.
+type MockLoginAttemptRepositoryI struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginAttemptRepositoryIMockRecorder
+}
+
+// MockLoginAttemptRepositoryIMockRecorder is the mock recorder for MockLoginAttemptRepositoryI.
+type MockLoginAttemptRepositoryIMockRecorder struct {
+	mock *MockLoginAttemptRepositoryI
+}
+
+// NewMockLoginAttemptRepositoryI creates a new mock instance.
+func NewMockLoginAttemptRepositoryI(ctrl *gomock.Controller) *MockLoginAttemptRepositoryI {
+	mock := &MockLoginAttemptRepositoryI{ctrl: ctrl}
+	mock.recorder = &MockLoginAttemptRepositoryIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginAttemptRepositoryI) EXPECT() *MockLoginAttemptRepositoryIMockRecorder {
+	return m.recorder
+}
+
+// AddFailure mocks base method.
+func (m *MockLoginAttemptRepositoryI) AddFailure(ctx context.Context, key string, at time.Time, window time.Duration) (domain.LoginAttempts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddFailure", ctx, key, at, window)
+	ret0, _ := ret[0].(domain.LoginAttempts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddFailure indicates an expected call of AddFailure.
+func (mr *MockLoginAttemptRepositoryIMockRecorder) AddFailure(ctx, key, at, window interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFailure", reflect.TypeOf((*MockLoginAttemptRepositoryI)(nil).AddFailure), ctx, key, at, window)
+}
+
+// Get mocks base method.
+func (m *MockLoginAttemptRepositoryI) Get(ctx context.Context, key string) (domain.LoginAttempts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, key)
+	ret0, _ := ret[0].(domain.LoginAttempts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockLoginAttemptRepositoryIMockRecorder) Get(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockLoginAttemptRepositoryI)(nil).Get), ctx, key)
+}
+
+// Lock mocks base method.
+func (m *MockLoginAttemptRepositoryI) Lock(ctx context.Context, key string, until time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock", ctx, key, until)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Lock indicates an expected call of Lock.
+func (mr *MockLoginAttemptRepositoryIMockRecorder) Lock(ctx, key, until interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockLoginAttemptRepositoryI)(nil).Lock), ctx, key, until)
+}
+
+// Reset mocks base method.
+func (m *MockLoginAttemptRepositoryI) Reset(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reset", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *MockLoginAttemptRepositoryIMockRecorder) Reset(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockLoginAttemptRepositoryI)(nil).Reset), ctx, key)
+}
+
+// MockAccessTokenRepositoryI is a mock of AccessTokenRepositoryI interface.
+type MockAccessTokenRepositoryI struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccessTokenRepositoryIMockRecorder
+}
+
+// MockAccessTokenRepositoryIMockRecorder is the mock recorder for MockAccessTokenRepositoryI.
+type MockAccessTokenRepositoryIMockRecorder struct {
+	mock *MockAccessTokenRepositoryI
+}
+
+// NewMockAccessTokenRepositoryI creates a new mock instance.
+func NewMockAccessTokenRepositoryI(ctrl *gomock.Controller) *MockAccessTokenRepositoryI {
+	mock := &MockAccessTokenRepositoryI{ctrl: ctrl}
+	mock.recorder = &MockAccessTokenRepositoryIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccessTokenRepositoryI) EXPECT() *MockAccessTokenRepositoryIMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockAccessTokenRepositoryI) Create(ctx context.Context, userId, tokenHash string, in domain.CreateAccessTokenInput) (domain.AccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, userId, tokenHash, in)
+	ret0, _ := ret[0].(domain.AccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAccessTokenRepositoryIMockRecorder) Create(ctx, userId, tokenHash, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAccessTokenRepositoryI)(nil).Create), ctx, userId, tokenHash, in)
+}
+
+// Delete mocks base method.
+func (m *MockAccessTokenRepositoryI) Delete(ctx context.Context, id, userId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockAccessTokenRepositoryIMockRecorder) Delete(ctx, id, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockAccessTokenRepositoryI)(nil).Delete), ctx, id, userId)
+}
+
+// GetAll mocks base method.
+func (m *MockAccessTokenRepositoryI) GetAll(ctx context.Context, userId string) ([]domain.AccessToken, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CheckToken", ctx, token)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "GetAll", ctx, userId)
+	ret0, _ := ret[0].([]domain.AccessToken)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CheckToken indicates an expected call of CheckToken.
-func (mr *MockAuthServiceIMockRecorder) CheckToken(ctx, token interface{}) *gomock.Call {
+// GetAll indicates an expected call of GetAll.
+func (mr *MockAccessTokenRepositoryIMockRecorder) GetAll(ctx, userId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckToken", reflect.TypeOf((*MockAuthServiceI)(nil).CheckToken), ctx, token)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockAccessTokenRepositoryI)(nil).GetAll), ctx, userId)
 }
 
-// SignIn mocks base method.
-func (m *MockAuthServiceI) SignIn(ctx context.Context, in domain.LoginUserInput) (string, error) {
+// GetByTokenHash mocks base method.
+func (m *MockAccessTokenRepositoryI) GetByTokenHash(ctx context.Context, tokenHash string) (domain.AccessToken, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SignIn", ctx, in)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "GetByTokenHash", ctx, tokenHash)
+	ret0, _ := ret[0].(domain.AccessToken)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SignIn indicates an expected call of SignIn.
-func (mr *MockAuthServiceIMockRecorder) SignIn(ctx, in interface{}) *gomock.Call {
+// GetByTokenHash indicates an expected call of GetByTokenHash.
+func (mr *MockAccessTokenRepositoryIMockRecorder) GetByTokenHash(ctx, tokenHash interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignIn", reflect.TypeOf((*MockAuthServiceI)(nil).SignIn), ctx, in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByTokenHash", reflect.TypeOf((*MockAccessTokenRepositoryI)(nil).GetByTokenHash), ctx, tokenHash)
 }
 
-// SignUp mocks base method.
-func (m *MockAuthServiceI) SignUp(ctx context.Context, in domain.CreateUserInput) (string, error) {
+// UpdateLastUsed mocks base method.
+func (m *MockAccessTokenRepositoryI) UpdateLastUsed(ctx context.Context, id string, at time.Time) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SignUp", ctx, in)
-	ret0, _ := ret[0].(string)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "UpdateLastUsed", ctx, id, at)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// SignUp indicates an expected call of SignUp.
-func (mr *MockAuthServiceIMockRecorder) SignUp(ctx, in interface{}) *gomock.Call {
+// UpdateLastUsed indicates an expected call of UpdateLastUsed.
+func (mr *MockAccessTokenRepositoryIMockRecorder) UpdateLastUsed(ctx, id, at interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUp", reflect.TypeOf((*MockAuthServiceI)(nil).SignUp), ctx, in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLastUsed", reflect.TypeOf((*MockAccessTokenRepositoryI)(nil).UpdateLastUsed), ctx, id, at)
 }
 
-// MockTaskServiceI is a mock of TaskServiceI interface.
-type MockTaskServiceI struct {
+// MockTaskRepositoryI is a mock of TaskRepositoryI interface.
+type MockTaskRepositoryI struct {
 	ctrl     *gomock.Controller
-	recorder *MockTaskServiceIMockRecorder
+	recorder *MockTaskRepositoryIMockRecorder
 }
 
-// MockTaskServiceIMockRecorder is the mock recorder for MockTaskServiceI.
-type MockTaskServiceIMockRecorder struct {
-	mock *MockTaskServiceI
+// MockTaskRepositoryIMockRecorder is the mock recorder for MockTaskRepositoryI.
+type MockTaskRepositoryIMockRecorder struct {
+	mock *MockTaskRepositoryI
 }
 
-// NewMockTaskServiceI creates a new mock instance.
-func NewMockTaskServiceI(ctrl *gomock.Controller) *MockTaskServiceI {
-	mock := &MockTaskServiceI{ctrl: ctrl}
-	mock.recorder = &MockTaskServiceIMockRecorder{mock}
+// NewMockTaskRepositoryI creates a new mock instance.
+func NewMockTaskRepositoryI(ctrl *gomock.Controller) *MockTaskRepositoryI {
+	mock := &MockTaskRepositoryI{ctrl: ctrl}
+	mock.recorder = &MockTaskRepositoryIMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockTaskServiceI) EXPECT() *MockTaskServiceIMockRecorder {
+func (m *MockTaskRepositoryI) EXPECT() *MockTaskRepositoryIMockRecorder {
 	return m.recorder
 }
 
+// AttachLabels mocks base method.
+func (m *MockTaskRepositoryI) AttachLabels(ctx context.Context, id, userId string, labelIds []string) (domain.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AttachLabels", ctx, id, userId, labelIds)
+	ret0, _ := ret[0].(domain.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AttachLabels indicates an expected call of AttachLabels.
+func (mr *MockTaskRepositoryIMockRecorder) AttachLabels(ctx, id, userId, labelIds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttachLabels", reflect.TypeOf((*MockTaskRepositoryI)(nil).AttachLabels), ctx, id, userId, labelIds)
+}
+
+// Count mocks base method.
+func (m *MockTaskRepositoryI) Count(ctx context.Context) (domain.TaskCounts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", ctx)
+	ret0, _ := ret[0].(domain.TaskCounts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockTaskRepositoryIMockRecorder) Count(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockTaskRepositoryI)(nil).Count), ctx)
+}
+
 // Create mocks base method.
-func (m *MockTaskServiceI) Create(ctx context.Context, userId string, in domain.CreateTaskInput) (domain.Task, error) {
+func (m *MockTaskRepositoryI) Create(ctx context.Context, userId string, in domain.CreateTaskInput) (domain.Task, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Create", ctx, userId, in)
 	ret0, _ := ret[0].(domain.Task)
@@ -113,13 +1585,13 @@ func (m *MockTaskServiceI) Create(ctx context.Context, userId string, in domain.
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockTaskServiceIMockRecorder) Create(ctx, userId, in interface{}) *gomock.Call {
+func (mr *MockTaskRepositoryIMockRecorder) Create(ctx, userId, in interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTaskServiceI)(nil).Create), ctx, userId, in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTaskRepositoryI)(nil).Create), ctx, userId, in)
 }
 
 // Delete mocks base method.
-func (m *MockTaskServiceI) Delete(ctx context.Context, id, userId string) error {
+func (m *MockTaskRepositoryI) Delete(ctx context.Context, id, userId string) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Delete", ctx, id, userId)
 	ret0, _ := ret[0].(error)
@@ -127,13 +1599,28 @@ func (m *MockTaskServiceI) Delete(ctx context.Context, id, userId string) error
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockTaskServiceIMockRecorder) Delete(ctx, id, userId interface{}) *gomock.Call {
+func (mr *MockTaskRepositoryIMockRecorder) Delete(ctx, id, userId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTaskServiceI)(nil).Delete), ctx, id, userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTaskRepositoryI)(nil).Delete), ctx, id, userId)
+}
+
+// DetachLabels mocks base method.
+func (m *MockTaskRepositoryI) DetachLabels(ctx context.Context, id, userId string, labelIds []string) (domain.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DetachLabels", ctx, id, userId, labelIds)
+	ret0, _ := ret[0].(domain.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DetachLabels indicates an expected call of DetachLabels.
+func (mr *MockTaskRepositoryIMockRecorder) DetachLabels(ctx, id, userId, labelIds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetachLabels", reflect.TypeOf((*MockTaskRepositoryI)(nil).DetachLabels), ctx, id, userId, labelIds)
 }
 
 // Get mocks base method.
-func (m *MockTaskServiceI) Get(ctx context.Context, id, userId string) (domain.Task, error) {
+func (m *MockTaskRepositoryI) Get(ctx context.Context, id, userId string) (domain.Task, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Get", ctx, id, userId)
 	ret0, _ := ret[0].(domain.Task)
@@ -142,28 +1629,147 @@ func (m *MockTaskServiceI) Get(ctx context.Context, id, userId string) (domain.T
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockTaskServiceIMockRecorder) Get(ctx, id, userId interface{}) *gomock.Call {
+func (mr *MockTaskRepositoryIMockRecorder) Get(ctx, id, userId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockTaskServiceI)(nil).Get), ctx, id, userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockTaskRepositoryI)(nil).Get), ctx, id, userId)
+}
+
+// GetAdjacentPosition mocks base method.
+func (m *MockTaskRepositoryI) GetAdjacentPosition(ctx context.Context, userId string, task domain.Task, next bool) (*float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAdjacentPosition", ctx, userId, task, next)
+	ret0, _ := ret[0].(*float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAdjacentPosition indicates an expected call of GetAdjacentPosition.
+func (mr *MockTaskRepositoryIMockRecorder) GetAdjacentPosition(ctx, userId, task, next interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAdjacentPosition", reflect.TypeOf((*MockTaskRepositoryI)(nil).GetAdjacentPosition), ctx, userId, task, next)
 }
 
 // GetAll mocks base method.
-func (m *MockTaskServiceI) GetAll(ctx context.Context, userId string) ([]domain.Task, error) {
+func (m *MockTaskRepositoryI) GetAll(ctx context.Context, userId string, filter domain.TaskFilter) (domain.TaskPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll", ctx, userId)
-	ret0, _ := ret[0].([]domain.Task)
+	ret := m.ctrl.Call(m, "GetAll", ctx, userId, filter)
+	ret0, _ := ret[0].(domain.TaskPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAll indicates an expected call of GetAll.
-func (mr *MockTaskServiceIMockRecorder) GetAll(ctx, userId interface{}) *gomock.Call {
+func (mr *MockTaskRepositoryIMockRecorder) GetAll(ctx, userId, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockTaskRepositoryI)(nil).GetAll), ctx, userId, filter)
+}
+
+// GetDueBetween mocks base method.
+func (m *MockTaskRepositoryI) GetDueBetween(ctx context.Context, userId string, from, to time.Time) ([]domain.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueBetween", ctx, userId, from, to)
+	ret0, _ := ret[0].([]domain.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueBetween indicates an expected call of GetDueBetween.
+func (mr *MockTaskRepositoryIMockRecorder) GetDueBetween(ctx, userId, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueBetween", reflect.TypeOf((*MockTaskRepositoryI)(nil).GetDueBetween), ctx, userId, from, to)
+}
+
+// GetOverdue mocks base method.
+func (m *MockTaskRepositoryI) GetOverdue(ctx context.Context, userId string, now time.Time) ([]domain.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOverdue", ctx, userId, now)
+	ret0, _ := ret[0].([]domain.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOverdue indicates an expected call of GetOverdue.
+func (mr *MockTaskRepositoryIMockRecorder) GetOverdue(ctx, userId, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOverdue", reflect.TypeOf((*MockTaskRepositoryI)(nil).GetOverdue), ctx, userId, now)
+}
+
+// GetSubtasks mocks base method.
+func (m *MockTaskRepositoryI) GetSubtasks(ctx context.Context, userId string, parentIds []string) ([]domain.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubtasks", ctx, userId, parentIds)
+	ret0, _ := ret[0].([]domain.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubtasks indicates an expected call of GetSubtasks.
+func (mr *MockTaskRepositoryIMockRecorder) GetSubtasks(ctx, userId, parentIds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubtasks", reflect.TypeOf((*MockTaskRepositoryI)(nil).GetSubtasks), ctx, userId, parentIds)
+}
+
+// GetTrash mocks base method.
+func (m *MockTaskRepositoryI) GetTrash(ctx context.Context, userId string) ([]domain.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrash", ctx, userId)
+	ret0, _ := ret[0].([]domain.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrash indicates an expected call of GetTrash.
+func (mr *MockTaskRepositoryIMockRecorder) GetTrash(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrash", reflect.TypeOf((*MockTaskRepositoryI)(nil).GetTrash), ctx, userId)
+}
+
+// PurgeTrash mocks base method.
+func (m *MockTaskRepositoryI) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeTrash", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeTrash indicates an expected call of PurgeTrash.
+func (mr *MockTaskRepositoryIMockRecorder) PurgeTrash(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeTrash", reflect.TypeOf((*MockTaskRepositoryI)(nil).PurgeTrash), ctx, before)
+}
+
+// RebalancePositions mocks base method.
+func (m *MockTaskRepositoryI) RebalancePositions(ctx context.Context, userId string, projectId *string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RebalancePositions", ctx, userId, projectId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RebalancePositions indicates an expected call of RebalancePositions.
+func (mr *MockTaskRepositoryIMockRecorder) RebalancePositions(ctx, userId, projectId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RebalancePositions", reflect.TypeOf((*MockTaskRepositoryI)(nil).RebalancePositions), ctx, userId, projectId)
+}
+
+// Restore mocks base method.
+func (m *MockTaskRepositoryI) Restore(ctx context.Context, id, userId string) (domain.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, id, userId)
+	ret0, _ := ret[0].(domain.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockTaskRepositoryIMockRecorder) Restore(ctx, id, userId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockTaskServiceI)(nil).GetAll), ctx, userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockTaskRepositoryI)(nil).Restore), ctx, id, userId)
 }
 
 // Update mocks base method.
-func (m *MockTaskServiceI) Update(ctx context.Context, id, userId string, in domain.UpdateTaskInput) (domain.Task, error) {
+func (m *MockTaskRepositoryI) Update(ctx context.Context, id, userId string, in domain.UpdateTaskInput) (domain.Task, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Update", ctx, id, userId, in)
 	ret0, _ := ret[0].(domain.Task)
@@ -172,119 +1778,178 @@ func (m *MockTaskServiceI) Update(ctx context.Context, id, userId string, in dom
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockTaskServiceIMockRecorder) Update(ctx, id, userId, in interface{}) *gomock.Call {
+func (mr *MockTaskRepositoryIMockRecorder) Update(ctx, id, userId, in interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTaskServiceI)(nil).Update), ctx, id, userId, in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTaskRepositoryI)(nil).Update), ctx, id, userId, in)
 }
 
-// MockUserRepositoryI is a mock of UserRepositoryI interface.
-type MockUserRepositoryI struct {
+// UpdatePosition mocks base method.
+func (m *MockTaskRepositoryI) UpdatePosition(ctx context.Context, id, userId string, position float64) (domain.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePosition", ctx, id, userId, position)
+	ret0, _ := ret[0].(domain.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePosition indicates an expected call of UpdatePosition.
+func (mr *MockTaskRepositoryIMockRecorder) UpdatePosition(ctx, id, userId, position interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePosition", reflect.TypeOf((*MockTaskRepositoryI)(nil).UpdatePosition), ctx, id, userId, position)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockTaskRepositoryI) UpdateStatus(ctx context.Context, id, userId string, status domain.TaskStatus, completedAt *time.Time) (domain.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, id, userId, status, completedAt)
+	ret0, _ := ret[0].(domain.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockTaskRepositoryIMockRecorder) UpdateStatus(ctx, id, userId, status, completedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockTaskRepositoryI)(nil).UpdateStatus), ctx, id, userId, status, completedAt)
+}
+
+// MockProjectRepositoryI is a mock of ProjectRepositoryI interface.
+type MockProjectRepositoryI struct {
 	ctrl     *gomock.Controller
-	recorder *MockUserRepositoryIMockRecorder
+	recorder *MockProjectRepositoryIMockRecorder
 }
 
-// MockUserRepositoryIMockRecorder is the mock recorder for MockUserRepositoryI.
-type MockUserRepositoryIMockRecorder struct {
-	mock *MockUserRepositoryI
+// MockProjectRepositoryIMockRecorder is the mock recorder for MockProjectRepositoryI.
+type MockProjectRepositoryIMockRecorder struct {
+	mock *MockProjectRepositoryI
 }
 
-// NewMockUserRepositoryI creates a new mock instance.
-func NewMockUserRepositoryI(ctrl *gomock.Controller) *MockUserRepositoryI {
-	mock := &MockUserRepositoryI{ctrl: ctrl}
-	mock.recorder = &MockUserRepositoryIMockRecorder{mock}
+// NewMockProjectRepositoryI creates a new mock instance.
+func NewMockProjectRepositoryI(ctrl *gomock.Controller) *MockProjectRepositoryI {
+	mock := &MockProjectRepositoryI{ctrl: ctrl}
+	mock.recorder = &MockProjectRepositoryIMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockUserRepositoryI) EXPECT() *MockUserRepositoryIMockRecorder {
+func (m *MockProjectRepositoryI) EXPECT() *MockProjectRepositoryIMockRecorder {
 	return m.recorder
 }
 
 // Create mocks base method.
-func (m *MockUserRepositoryI) Create(ctx context.Context, in domain.CreateUserInput) (domain.User, error) {
+func (m *MockProjectRepositoryI) Create(ctx context.Context, userId string, in domain.CreateProjectInput) (domain.Project, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", ctx, in)
-	ret0, _ := ret[0].(domain.User)
+	ret := m.ctrl.Call(m, "Create", ctx, userId, in)
+	ret0, _ := ret[0].(domain.Project)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockUserRepositoryIMockRecorder) Create(ctx, in interface{}) *gomock.Call {
+func (mr *MockProjectRepositoryIMockRecorder) Create(ctx, userId, in interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUserRepositoryI)(nil).Create), ctx, in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockProjectRepositoryI)(nil).Create), ctx, userId, in)
+}
+
+// Delete mocks base method.
+func (m *MockProjectRepositoryI) Delete(ctx context.Context, id, userId string, mode domain.ProjectDeleteMode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id, userId, mode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockProjectRepositoryIMockRecorder) Delete(ctx, id, userId, mode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockProjectRepositoryI)(nil).Delete), ctx, id, userId, mode)
 }
 
 // Get mocks base method.
-func (m *MockUserRepositoryI) Get(ctx context.Context, id string) (domain.User, error) {
+func (m *MockProjectRepositoryI) Get(ctx context.Context, id, userId string) (domain.Project, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", ctx, id)
-	ret0, _ := ret[0].(domain.User)
+	ret := m.ctrl.Call(m, "Get", ctx, id, userId)
+	ret0, _ := ret[0].(domain.Project)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockUserRepositoryIMockRecorder) Get(ctx, id interface{}) *gomock.Call {
+func (mr *MockProjectRepositoryIMockRecorder) Get(ctx, id, userId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockUserRepositoryI)(nil).Get), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockProjectRepositoryI)(nil).Get), ctx, id, userId)
 }
 
-// GetByCredentials mocks base method.
-func (m *MockUserRepositoryI) GetByCredentials(ctx context.Context, in domain.LoginUserInput) (domain.User, error) {
+// GetAll mocks base method.
+func (m *MockProjectRepositoryI) GetAll(ctx context.Context, userId string) ([]domain.Project, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByCredentials", ctx, in)
-	ret0, _ := ret[0].(domain.User)
+	ret := m.ctrl.Call(m, "GetAll", ctx, userId)
+	ret0, _ := ret[0].([]domain.Project)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetByCredentials indicates an expected call of GetByCredentials.
-func (mr *MockUserRepositoryIMockRecorder) GetByCredentials(ctx, in interface{}) *gomock.Call {
+// GetAll indicates an expected call of GetAll.
+func (mr *MockProjectRepositoryIMockRecorder) GetAll(ctx, userId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByCredentials", reflect.TypeOf((*MockUserRepositoryI)(nil).GetByCredentials), ctx, in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockProjectRepositoryI)(nil).GetAll), ctx, userId)
 }
 
-// MockTaskRepositoryI is a mock of TaskRepositoryI interface.
-type MockTaskRepositoryI struct {
+// Update mocks base method.
+func (m *MockProjectRepositoryI) Update(ctx context.Context, id, userId string, in domain.UpdateProjectInput) (domain.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, id, userId, in)
+	ret0, _ := ret[0].(domain.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockProjectRepositoryIMockRecorder) Update(ctx, id, userId, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockProjectRepositoryI)(nil).Update), ctx, id, userId, in)
+}
+
+// MockLabelRepositoryI is a mock of LabelRepositoryI interface.
+type MockLabelRepositoryI struct {
 	ctrl     *gomock.Controller
-	recorder *MockTaskRepositoryIMockRecorder
+	recorder *MockLabelRepositoryIMockRecorder
 }
 
-// MockTaskRepositoryIMockRecorder is the mock recorder for MockTaskRepositoryI.
-type MockTaskRepositoryIMockRecorder struct {
-	mock *MockTaskRepositoryI
+// MockLabelRepositoryIMockRecorder is the mock recorder for MockLabelRepositoryI.
+type MockLabelRepositoryIMockRecorder struct {
+	mock *MockLabelRepositoryI
 }
 
-// NewMockTaskRepositoryI creates a new mock instance.
-func NewMockTaskRepositoryI(ctrl *gomock.Controller) *MockTaskRepositoryI {
-	mock := &MockTaskRepositoryI{ctrl: ctrl}
-	mock.recorder = &MockTaskRepositoryIMockRecorder{mock}
+// NewMockLabelRepositoryI creates a new mock instance.
+func NewMockLabelRepositoryI(ctrl *gomock.Controller) *MockLabelRepositoryI {
+	mock := &MockLabelRepositoryI{ctrl: ctrl}
+	mock.recorder = &MockLabelRepositoryIMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockTaskRepositoryI) EXPECT() *MockTaskRepositoryIMockRecorder {
+func (m *MockLabelRepositoryI) EXPECT() *MockLabelRepositoryIMockRecorder {
 	return m.recorder
 }
 
 // Create mocks base method.
-func (m *MockTaskRepositoryI) Create(ctx context.Context, userId string, in domain.CreateTaskInput) (domain.Task, error) {
+func (m *MockLabelRepositoryI) Create(ctx context.Context, userId string, in domain.CreateLabelInput) (domain.Label, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Create", ctx, userId, in)
-	ret0, _ := ret[0].(domain.Task)
+	ret0, _ := ret[0].(domain.Label)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockTaskRepositoryIMockRecorder) Create(ctx, userId, in interface{}) *gomock.Call {
+func (mr *MockLabelRepositoryIMockRecorder) Create(ctx, userId, in interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTaskRepositoryI)(nil).Create), ctx, userId, in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockLabelRepositoryI)(nil).Create), ctx, userId, in)
 }
 
 // Delete mocks base method.
-func (m *MockTaskRepositoryI) Delete(ctx context.Context, id, userId string) error {
+func (m *MockLabelRepositoryI) Delete(ctx context.Context, id, userId string) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Delete", ctx, id, userId)
 	ret0, _ := ret[0].(error)
@@ -292,52 +1957,52 @@ func (m *MockTaskRepositoryI) Delete(ctx context.Context, id, userId string) err
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockTaskRepositoryIMockRecorder) Delete(ctx, id, userId interface{}) *gomock.Call {
+func (mr *MockLabelRepositoryIMockRecorder) Delete(ctx, id, userId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTaskRepositoryI)(nil).Delete), ctx, id, userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockLabelRepositoryI)(nil).Delete), ctx, id, userId)
 }
 
 // Get mocks base method.
-func (m *MockTaskRepositoryI) Get(ctx context.Context, id, userId string) (domain.Task, error) {
+func (m *MockLabelRepositoryI) Get(ctx context.Context, id, userId string) (domain.Label, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Get", ctx, id, userId)
-	ret0, _ := ret[0].(domain.Task)
+	ret0, _ := ret[0].(domain.Label)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockTaskRepositoryIMockRecorder) Get(ctx, id, userId interface{}) *gomock.Call {
+func (mr *MockLabelRepositoryIMockRecorder) Get(ctx, id, userId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockTaskRepositoryI)(nil).Get), ctx, id, userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockLabelRepositoryI)(nil).Get), ctx, id, userId)
 }
 
 // GetAll mocks base method.
-func (m *MockTaskRepositoryI) GetAll(ctx context.Context, userId string) ([]domain.Task, error) {
+func (m *MockLabelRepositoryI) GetAll(ctx context.Context, userId string) ([]domain.Label, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetAll", ctx, userId)
-	ret0, _ := ret[0].([]domain.Task)
+	ret0, _ := ret[0].([]domain.Label)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAll indicates an expected call of GetAll.
-func (mr *MockTaskRepositoryIMockRecorder) GetAll(ctx, userId interface{}) *gomock.Call {
+func (mr *MockLabelRepositoryIMockRecorder) GetAll(ctx, userId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockTaskRepositoryI)(nil).GetAll), ctx, userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockLabelRepositoryI)(nil).GetAll), ctx, userId)
 }
 
 // Update mocks base method.
-func (m *MockTaskRepositoryI) Update(ctx context.Context, id, userId string, in domain.UpdateTaskInput) (domain.Task, error) {
+func (m *MockLabelRepositoryI) Update(ctx context.Context, id, userId string, in domain.UpdateLabelInput) (domain.Label, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Update", ctx, id, userId, in)
-	ret0, _ := ret[0].(domain.Task)
+	ret0, _ := ret[0].(domain.Label)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockTaskRepositoryIMockRecorder) Update(ctx, id, userId, in interface{}) *gomock.Call {
+func (mr *MockLabelRepositoryIMockRecorder) Update(ctx, id, userId, in interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTaskRepositoryI)(nil).Update), ctx, id, userId, in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockLabelRepositoryI)(nil).Update), ctx, id, userId, in)
 }
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+)
+
+type ProjectService struct {
+	rep ProjectRepositoryI
+}
+
+func NewProjectService(rep ProjectRepositoryI) *ProjectService {
+	return &ProjectService{
+		rep: rep,
+	}
+}
+
+func (p *ProjectService) Get(ctx context.Context, id, userId string) (domain.Project, error) {
+	return p.rep.Get(ctx, id, userId)
+}
+
+func (p *ProjectService) GetAll(ctx context.Context, userId string) ([]domain.Project, error) {
+	return p.rep.GetAll(ctx, userId)
+}
+
+func (p *ProjectService) Create(ctx context.Context, userId string, in domain.CreateProjectInput) (domain.Project, error) {
+	return p.rep.Create(ctx, userId, in)
+}
+
+func (p *ProjectService) Update(ctx context.Context, id, userId string, in domain.UpdateProjectInput) (domain.Project, error) {
+	return p.rep.Update(ctx, id, userId, in)
+}
+
+func (p *ProjectService) Delete(ctx context.Context, id, userId string, mode domain.ProjectDeleteMode) error {
+	if mode == "" {
+		mode = domain.ProjectDeleteMoveToInbox
+	}
+
+	return p.rep.Delete(ctx, id, userId, mode)
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	mock_service "github.com/i-vasilkov/go-todo-app/internal/service/mocks"
+	"github.com/magiconair/properties/assert"
+	"testing"
+)
+
+func TestProjectService_Delete(t *testing.T) {
+	type mockBehaviour func(rep *mock_service.MockProjectRepositoryI, projectId, userId string)
+
+	testCases := []struct {
+		name      string
+		projectId string
+		userId    string
+		mode      domain.ProjectDeleteMode
+		mock      mockBehaviour
+		err       error
+	}{
+		{
+			name:      "Default mode moves tasks to inbox",
+			projectId: "projectId",
+			userId:    "userId",
+			mode:      "",
+			mock: func(rep *mock_service.MockProjectRepositoryI, projectId, userId string) {
+				rep.EXPECT().Delete(context.Background(), projectId, userId, domain.ProjectDeleteMoveToInbox).Return(nil)
+			},
+			err: nil,
+		},
+		{
+			name:      "Cascade",
+			projectId: "projectId",
+			userId:    "userId",
+			mode:      domain.ProjectDeleteCascade,
+			mock: func(rep *mock_service.MockProjectRepositoryI, projectId, userId string) {
+				rep.EXPECT().Delete(context.Background(), projectId, userId, domain.ProjectDeleteCascade).Return(nil)
+			},
+			err: nil,
+		},
+		{
+			name:      "Repository error",
+			projectId: "projectId",
+			userId:    "userId",
+			mode:      domain.ProjectDeleteCascade,
+			mock: func(rep *mock_service.MockProjectRepositoryI, projectId, userId string) {
+				rep.EXPECT().
+					Delete(context.Background(), projectId, userId, domain.ProjectDeleteCascade).
+					Return(errors.New("repository error"))
+			},
+			err: errors.New("repository error"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repository := mock_service.NewMockProjectRepositoryI(ctrl)
+			testCase.mock(repository, testCase.projectId, testCase.userId)
+
+			service := NewProjectService(repository)
+			err := service.Delete(context.Background(), testCase.projectId, testCase.userId, testCase.mode)
+
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
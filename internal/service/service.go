@@ -2,20 +2,46 @@ package service
 
 import (
 	"github.com/i-vasilkov/go-todo-app/pkg/auth/jwt"
+	"github.com/i-vasilkov/go-todo-app/pkg/auth/opaque"
+	"github.com/i-vasilkov/go-todo-app/pkg/auth/totp"
 	"github.com/i-vasilkov/go-todo-app/pkg/hash"
+	"github.com/i-vasilkov/go-todo-app/pkg/mailer"
+	"time"
 )
 
 type Repositories struct {
-	Task TaskRepositoryI
-	User UserRepositoryI
+	Task         TaskRepositoryI
+	User         UserRepositoryI
+	Session      SessionRepositoryI
+	Revocation   RevocationRepositoryI
+	LoginAttempt LoginAttemptRepositoryI
+	AccessToken  AccessTokenRepositoryI
+	Project      ProjectRepositoryI
+	Label        LabelRepositoryI
 }
 
 type Dependencies struct {
-	Hasher     hash.Hasher
-	JwtManager *jwt.Manager
+	Hasher               hash.Hasher
+	JwtManager           *jwt.Manager
+	RefreshTokenManager  *opaque.Manager
+	RefreshTokenTtl      time.Duration
+	AccessTokenManager   *opaque.Manager
+	TotpManager          *totp.Manager
+	MfaChallengeTtl      time.Duration
+	SignInLimits         SignInLimits
+	Mailer               mailer.Mailer
+	AppUrl               string
+	PasswordResetTtl     time.Duration
+	EmailVerificationTtl time.Duration
+	TaskMaxDepth         int
 }
 
 type Services struct {
-	Auth AuthServiceI
-	Task TaskServiceI
+	Auth        AuthServiceI
+	Account     AccountServiceI
+	Admin       AdminServiceI
+	AccessToken AccessTokenServiceI
+	Task        TaskServiceI
+	Project     ProjectServiceI
+	Label       LabelServiceI
 }
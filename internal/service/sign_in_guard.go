@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"time"
+)
+
+// SignInLimits configure how failed sign ins slow down the following attempts.
+type SignInLimits struct {
+	// Window is how long failures are remembered after the last one.
+	Window time.Duration
+	// After BackoffAfter failures of a login, or IpBackoffAfter failures from a client address,
+	// every attempt has to wait BackoffBase after the previous failure, doubled with each further
+	// failure up to BackoffMax. Zero disables the backoff.
+	BackoffAfter   int
+	IpBackoffAfter int
+	BackoffBase    time.Duration
+	BackoffMax     time.Duration
+	// LockAfter failures of a login lock it for LockDuration. Zero disables the lockout.
+	LockAfter    int
+	LockDuration time.Duration
+}
+
+// SignInGuard tracks failed sign ins per login and per client address, so passwords and
+// second factor codes can not be guessed at full speed.
+type SignInGuard struct {
+	rep    LoginAttemptRepositoryI
+	limits SignInLimits
+}
+
+func NewSignInGuard(rep LoginAttemptRepositoryI, limits SignInLimits) *SignInGuard {
+	return &SignInGuard{rep: rep, limits: limits}
+}
+
+// Check returns a domain.RetryError while the login is locked or either key has to back off.
+// Attempts rejected here are not verified, so they do not count as failures.
+func (g *SignInGuard) Check(ctx context.Context, login, clientIp string) error {
+	now := time.Now()
+
+	attempts, err := g.rep.Get(ctx, loginAttemptsKey(login))
+	if err != nil {
+		return err
+	}
+	if attempts.LockedUntil != nil && attempts.LockedUntil.After(now) {
+		return &domain.RetryError{Err: domain.ErrAccountLocked, RetryAfter: attempts.LockedUntil.Sub(now)}
+	}
+	if wait := g.backoff(attempts, g.limits.BackoffAfter, now); wait > 0 {
+		return &domain.RetryError{Err: domain.ErrTooManyAttempts, RetryAfter: wait}
+	}
+
+	if clientIp == "" {
+		return nil
+	}
+
+	attempts, err = g.rep.Get(ctx, ipAttemptsKey(clientIp))
+	if err != nil {
+		return err
+	}
+	if wait := g.backoff(attempts, g.limits.IpBackoffAfter, now); wait > 0 {
+		return &domain.RetryError{Err: domain.ErrTooManyAttempts, RetryAfter: wait}
+	}
+
+	return nil
+}
+
+// Fail counts a failed attempt and locks the login once it reaches the limit.
+func (g *SignInGuard) Fail(ctx context.Context, login, clientIp string) error {
+	now := time.Now()
+
+	attempts, err := g.rep.AddFailure(ctx, loginAttemptsKey(login), now, g.limits.Window)
+	if err != nil {
+		return err
+	}
+	if g.limits.LockAfter > 0 && attempts.Failures >= g.limits.LockAfter {
+		if err := g.rep.Lock(ctx, loginAttemptsKey(login), now.Add(g.limits.LockDuration)); err != nil {
+			return err
+		}
+	}
+
+	if clientIp == "" {
+		return nil
+	}
+
+	_, err = g.rep.AddFailure(ctx, ipAttemptsKey(clientIp), now, g.limits.Window)
+	return err
+}
+
+// Succeed forgets the failures of the login. Those of the address are left to expire, otherwise
+// signing in to an own account would reset the limit for guessing the others.
+func (g *SignInGuard) Succeed(ctx context.Context, login string) error {
+	return g.rep.Reset(ctx, loginAttemptsKey(login))
+}
+
+// backoff returns how much longer the next attempt has to wait.
+func (g *SignInGuard) backoff(attempts domain.LoginAttempts, after int, now time.Time) time.Duration {
+	if after <= 0 || attempts.Failures < after {
+		return 0
+	}
+
+	delay := g.limits.BackoffBase
+	for i := after; i < attempts.Failures && delay < g.limits.BackoffMax; i++ {
+		delay *= 2
+	}
+	if delay > g.limits.BackoffMax {
+		delay = g.limits.BackoffMax
+	}
+
+	return attempts.LastFailureAt.Add(delay).Sub(now)
+}
+
+func loginAttemptsKey(login string) string {
+	return "login:" + login
+}
+
+func ipAttemptsKey(clientIp string) string {
+	return "ip:" + clientIp
+}
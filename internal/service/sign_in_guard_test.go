@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	mock_service "github.com/i-vasilkov/go-todo-app/internal/service/mocks"
+	"github.com/magiconair/properties/assert"
+	"testing"
+	"time"
+)
+
+func TestSignInGuard_Check(t *testing.T) {
+	type mockBehaviour func(a *mock_service.MockLoginAttemptRepositoryI)
+
+	now := time.Now()
+	lockedUntil := now.Add(30 * time.Minute)
+
+	testCases := []struct {
+		name       string
+		mock       mockBehaviour
+		err        error
+		retryAfter time.Duration
+	}{
+		{
+			name: "No failures",
+			mock: func(a *mock_service.MockLoginAttemptRepositoryI) {
+				expectSignInAllowed(a, "test")
+			},
+			err: nil,
+		},
+		{
+			name: "Failures below the backoff",
+			mock: func(a *mock_service.MockLoginAttemptRepositoryI) {
+				a.EXPECT().Get(context.Background(), "login:test").Return(domain.LoginAttempts{Failures: 2, LastFailureAt: now}, nil)
+				a.EXPECT().Get(context.Background(), "ip:"+testClientIp).Return(domain.LoginAttempts{}, nil)
+			},
+			err: nil,
+		},
+		{
+			name: "Backoff of the login",
+			mock: func(a *mock_service.MockLoginAttemptRepositoryI) {
+				a.EXPECT().Get(context.Background(), "login:test").Return(domain.LoginAttempts{Failures: 5, LastFailureAt: now}, nil)
+			},
+			err:        domain.ErrTooManyAttempts,
+			retryAfter: 4 * time.Second,
+		},
+		{
+			name: "Backoff is capped",
+			mock: func(a *mock_service.MockLoginAttemptRepositoryI) {
+				a.EXPECT().Get(context.Background(), "login:test").Return(domain.LoginAttempts{Failures: 9, LastFailureAt: now}, nil)
+			},
+			err:        domain.ErrTooManyAttempts,
+			retryAfter: time.Minute,
+		},
+		{
+			name: "Backoff has passed",
+			mock: func(a *mock_service.MockLoginAttemptRepositoryI) {
+				a.EXPECT().Get(context.Background(), "login:test").Return(domain.LoginAttempts{Failures: 5, LastFailureAt: now.Add(-5 * time.Second)}, nil)
+				a.EXPECT().Get(context.Background(), "ip:"+testClientIp).Return(domain.LoginAttempts{}, nil)
+			},
+			err: nil,
+		},
+		{
+			name: "Backoff of the address",
+			mock: func(a *mock_service.MockLoginAttemptRepositoryI) {
+				a.EXPECT().Get(context.Background(), "login:test").Return(domain.LoginAttempts{}, nil)
+				a.EXPECT().Get(context.Background(), "ip:"+testClientIp).Return(domain.LoginAttempts{Failures: 20, LastFailureAt: now}, nil)
+			},
+			err:        domain.ErrTooManyAttempts,
+			retryAfter: time.Second,
+		},
+		{
+			name: "Locked",
+			mock: func(a *mock_service.MockLoginAttemptRepositoryI) {
+				a.EXPECT().Get(context.Background(), "login:test").Return(domain.LoginAttempts{Failures: 10, LastFailureAt: now, LockedUntil: &lockedUntil}, nil)
+			},
+			err:        domain.ErrAccountLocked,
+			retryAfter: 30 * time.Minute,
+		},
+		{
+			name: "Repository error",
+			mock: func(a *mock_service.MockLoginAttemptRepositoryI) {
+				a.EXPECT().Get(context.Background(), "login:test").Return(domain.LoginAttempts{}, errors.New("repository error"))
+			},
+			err: errors.New("repository error"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			attemptRepository := mock_service.NewMockLoginAttemptRepositoryI(ctrl)
+			testCase.mock(attemptRepository)
+
+			guard := NewSignInGuard(attemptRepository, testSignInLimits)
+			err := guard.Check(context.Background(), "test", testClientIp)
+
+			var retry *domain.RetryError
+			if !errors.As(err, &retry) {
+				assert.Equal(t, err, testCase.err)
+				return
+			}
+
+			assert.Equal(t, retry.Err, testCase.err)
+			assert.Equal(t, retry.RetryAfter <= testCase.retryAfter, true)
+			assert.Equal(t, retry.RetryAfter > testCase.retryAfter-time.Second, true)
+		})
+	}
+}
+
+func TestSignInGuard_Fail(t *testing.T) {
+	type mockBehaviour func(a *mock_service.MockLoginAttemptRepositoryI)
+
+	testCases := []struct {
+		name     string
+		clientIp string
+		mock     mockBehaviour
+		err      error
+	}{
+		{
+			name:     "Below the lockout",
+			clientIp: testClientIp,
+			mock: func(a *mock_service.MockLoginAttemptRepositoryI) {
+				expectSignInFailed(a, "test")
+			},
+			err: nil,
+		},
+		{
+			name:     "Reaching the lockout",
+			clientIp: testClientIp,
+			mock: func(a *mock_service.MockLoginAttemptRepositoryI) {
+				a.EXPECT().AddFailure(context.Background(), "login:test", gomock.Any(), testSignInLimits.Window).
+					Return(domain.LoginAttempts{Failures: 10}, nil)
+				a.EXPECT().Lock(context.Background(), "login:test", gomock.Any()).Return(nil)
+				a.EXPECT().AddFailure(context.Background(), "ip:"+testClientIp, gomock.Any(), testSignInLimits.Window).
+					Return(domain.LoginAttempts{Failures: 1}, nil)
+			},
+			err: nil,
+		},
+		{
+			name:     "Unknown address",
+			clientIp: "",
+			mock: func(a *mock_service.MockLoginAttemptRepositoryI) {
+				a.EXPECT().AddFailure(context.Background(), "login:test", gomock.Any(), testSignInLimits.Window).
+					Return(domain.LoginAttempts{Failures: 1}, nil)
+			},
+			err: nil,
+		},
+		{
+			name:     "Repository error",
+			clientIp: testClientIp,
+			mock: func(a *mock_service.MockLoginAttemptRepositoryI) {
+				a.EXPECT().AddFailure(context.Background(), "login:test", gomock.Any(), testSignInLimits.Window).
+					Return(domain.LoginAttempts{}, errors.New("repository error"))
+			},
+			err: errors.New("repository error"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			attemptRepository := mock_service.NewMockLoginAttemptRepositoryI(ctrl)
+			testCase.mock(attemptRepository)
+
+			guard := NewSignInGuard(attemptRepository, testSignInLimits)
+			err := guard.Fail(context.Background(), "test", testCase.clientIp)
+
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
@@ -2,16 +2,29 @@ package service
 
 import (
 	"context"
+	"errors"
 	"github.com/i-vasilkov/go-todo-app/internal/domain"
+	"github.com/i-vasilkov/go-todo-app/pkg/recurrence"
+	"time"
 )
 
 type TaskService struct {
-	rep TaskRepositoryI
+	rep      TaskRepositoryI
+	projects ProjectRepositoryI
+	labels   LabelRepositoryI
+	maxDepth int
 }
 
-func NewTaskService(rep TaskRepositoryI) *TaskService {
+func NewTaskService(rep TaskRepositoryI, projects ProjectRepositoryI, labels LabelRepositoryI, maxDepth int) *TaskService {
+	if maxDepth <= 0 {
+		maxDepth = domain.DefaultTaskMaxDepth
+	}
+
 	return &TaskService{
-		rep: rep,
+		rep:      rep,
+		projects: projects,
+		labels:   labels,
+		maxDepth: maxDepth,
 	}
 }
 
@@ -19,18 +32,425 @@ func (t *TaskService) Get(ctx context.Context, id, userId string) (domain.Task,
 	return t.rep.Get(ctx, id, userId)
 }
 
-func (t *TaskService) GetAll(ctx context.Context, userId string) ([]domain.Task, error) {
-	return t.rep.GetAll(ctx, userId)
+// GetTree returns the task with its subtasks and the completion progress of every level.
+func (t *TaskService) GetTree(ctx context.Context, id, userId string) (domain.TaskTree, error) {
+	task, err := t.rep.Get(ctx, id, userId)
+	if err != nil {
+		return domain.TaskTree{}, err
+	}
+
+	levels, err := t.subtaskLevels(ctx, userId, task.Id)
+	if err != nil {
+		return domain.TaskTree{}, err
+	}
+
+	children := make(map[string][]domain.Task)
+	for _, level := range levels {
+		for _, subtask := range level {
+			children[*subtask.ParentId] = append(children[*subtask.ParentId], subtask)
+		}
+	}
+
+	return buildTaskTree(task, children), nil
+}
+
+func (t *TaskService) GetAll(ctx context.Context, userId string, filter domain.TaskFilter) (domain.TaskPage, error) {
+	return t.rep.GetAll(ctx, userId, filter.Normalize())
 }
 
 func (t *TaskService) Create(ctx context.Context, userId string, in domain.CreateTaskInput) (domain.Task, error) {
+	in = in.Sanitize()
+
+	if err := t.checkProject(ctx, userId, in.ProjectId); err != nil {
+		return domain.Task{}, err
+	}
+
+	if in.ParentId != nil {
+		if err := t.checkParent(ctx, "", userId, *in.ParentId); err != nil {
+			return domain.Task{}, err
+		}
+	}
+
 	return t.rep.Create(ctx, userId, in)
 }
 
 func (t *TaskService) Update(ctx context.Context, id, userId string, in domain.UpdateTaskInput) (domain.Task, error) {
-	return t.rep.Update(ctx, id, userId, in)
+	in = in.Sanitize()
+
+	if err := t.checkProject(ctx, userId, in.ProjectId); err != nil {
+		return domain.Task{}, err
+	}
+
+	if in.ParentId != nil {
+		if err := t.checkParent(ctx, id, userId, *in.ParentId); err != nil {
+			return domain.Task{}, err
+		}
+	}
+
+	task, err := t.rep.Update(ctx, id, userId, in)
+	if err != nil || in.Status == "" || in.Status == task.Status {
+		return task, err
+	}
+
+	if in.Status == domain.TaskStatusDone {
+		return t.complete(ctx, id, userId)
+	}
+	return t.setStatus(ctx, id, userId, in.Status)
 }
 
+// Delete moves the task together with its subtasks to the trash.
 func (t *TaskService) Delete(ctx context.Context, id, userId string) error {
 	return t.rep.Delete(ctx, id, userId)
 }
+
+// GetTrash returns the trashed tasks of the user, most recently deleted first.
+func (t *TaskService) GetTrash(ctx context.Context, userId string) ([]domain.Task, error) {
+	return t.rep.GetTrash(ctx, userId)
+}
+
+// Restore takes the task out of the trash together with the subtasks deleted along with it.
+func (t *TaskService) Restore(ctx context.Context, id, userId string) (domain.Task, error) {
+	return t.rep.Restore(ctx, id, userId)
+}
+
+// PurgeTrash permanently removes the tasks of all users that were trashed before the given time.
+func (t *TaskService) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	return t.rep.PurgeTrash(ctx, before)
+}
+
+// Complete marks the task as done. With withSubtasks its open subtasks on every level are completed too.
+// A task that is already done is rejected with domain.ErrTaskAlreadyDone.
+func (t *TaskService) Complete(ctx context.Context, id, userId string, withSubtasks bool) (domain.Task, error) {
+	task, err := t.rep.Get(ctx, id, userId)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if task.Status == domain.TaskStatusDone {
+		return domain.Task{}, domain.ErrTaskAlreadyDone
+	}
+
+	if withSubtasks {
+		levels, err := t.subtaskLevels(ctx, userId, id)
+		if err != nil {
+			return domain.Task{}, err
+		}
+
+		for _, level := range levels {
+			for _, subtask := range level {
+				if subtask.Status.IsClosed() {
+					continue
+				}
+				if _, err := t.complete(ctx, subtask.Id, userId); err != nil {
+					return domain.Task{}, err
+				}
+			}
+		}
+	}
+
+	return t.complete(ctx, id, userId)
+}
+
+// Reopen moves a done or cancelled task back to todo.
+func (t *TaskService) Reopen(ctx context.Context, id, userId string) (domain.Task, error) {
+	task, err := t.rep.Get(ctx, id, userId)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if !task.Status.IsClosed() {
+		return domain.Task{}, domain.ErrTaskNotClosed
+	}
+
+	return t.setStatus(ctx, id, userId, domain.TaskStatusTodo)
+}
+
+func (t *TaskService) GetOverdue(ctx context.Context, userId string) ([]domain.Task, error) {
+	return t.rep.GetOverdue(ctx, userId, time.Now())
+}
+
+func (t *TaskService) GetDueBetween(ctx context.Context, userId string, from, to time.Time) ([]domain.Task, error) {
+	return t.rep.GetDueBetween(ctx, userId, from, to)
+}
+
+func (t *TaskService) AttachLabels(ctx context.Context, id, userId string, labelIds []string) (domain.Task, error) {
+	for _, labelId := range labelIds {
+		if _, err := t.labels.Get(ctx, labelId, userId); err != nil {
+			return domain.Task{}, err
+		}
+	}
+
+	return t.rep.AttachLabels(ctx, id, userId, labelIds)
+}
+
+func (t *TaskService) DetachLabels(ctx context.Context, id, userId string, labelIds []string) (domain.Task, error) {
+	return t.rep.DetachLabels(ctx, id, userId, labelIds)
+}
+
+// Move places the task between its new neighbours in the same project. When the neighbours
+// are too close to fit another position in between, the project positions are spread out again first.
+func (t *TaskService) Move(ctx context.Context, id, userId string, in domain.MoveTaskInput) (domain.Task, error) {
+	task, err := t.rep.Get(ctx, id, userId)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	position, err := t.movePosition(ctx, task, userId, in)
+	if errors.Is(err, errPositionsExhausted) {
+		if err := t.rep.RebalancePositions(ctx, userId, task.ProjectId); err != nil {
+			return domain.Task{}, err
+		}
+		position, err = t.movePosition(ctx, task, userId, in)
+	}
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	return t.rep.UpdatePosition(ctx, id, userId, position)
+}
+
+// complete marks the task as done. A recurring task hands its rule over to a newly
+// created next occurrence, so completing it again never schedules a second copy.
+func (t *TaskService) complete(ctx context.Context, id, userId string) (domain.Task, error) {
+	task, err := t.setStatus(ctx, id, userId, domain.TaskStatusDone)
+	if err != nil || task.Recurrence == "" {
+		return task, err
+	}
+
+	if err := t.createNextOccurrence(ctx, userId, task); err != nil {
+		return domain.Task{}, err
+	}
+
+	return t.rep.Update(ctx, id, userId, domain.UpdateTaskInput{
+		Name:        task.Name,
+		Description: task.Description,
+		StartAt:     task.StartAt,
+		DueAt:       task.DueAt,
+		ProjectId:   task.ProjectId,
+		ParentId:    task.ParentId,
+		Priority:    task.Priority,
+	})
+}
+
+// createNextOccurrence copies the completed task with the due date moved to the next
+// occurrence of its rule. The start date keeps its distance to the due date.
+func (t *TaskService) createNextOccurrence(ctx context.Context, userId string, task domain.Task) error {
+	rule, err := recurrence.Parse(task.Recurrence)
+	if err != nil {
+		return err
+	}
+
+	completedAt := time.Now()
+	if task.CompletedAt != nil {
+		completedAt = *task.CompletedAt
+	}
+
+	dueAt := rule.NextAfter(task.DueAt, completedAt)
+	in := domain.CreateTaskInput{
+		Name:        task.Name,
+		Description: task.Description,
+		DueAt:       &dueAt,
+		ProjectId:   task.ProjectId,
+		ParentId:    task.ParentId,
+		Recurrence:  task.Recurrence,
+		Priority:    task.Priority,
+	}
+	if task.StartAt != nil && task.DueAt != nil {
+		startAt := dueAt.Add(-task.DueAt.Sub(*task.StartAt))
+		in.StartAt = &startAt
+	}
+
+	next, err := t.rep.Create(ctx, userId, in)
+	if err != nil || len(task.LabelIds) == 0 {
+		return err
+	}
+
+	_, err = t.rep.AttachLabels(ctx, next.Id, userId, task.LabelIds)
+	return err
+}
+
+// setStatus moves the task to the given status and keeps completed_at in sync:
+// it is stamped when the task becomes done and cleared otherwise.
+func (t *TaskService) setStatus(ctx context.Context, id, userId string, status domain.TaskStatus) (domain.Task, error) {
+	var completedAt *time.Time
+	if status == domain.TaskStatusDone {
+		now := time.Now()
+		completedAt = &now
+	}
+
+	return t.rep.UpdateStatus(ctx, id, userId, status, completedAt)
+}
+
+// checkProject makes sure a task is only put into a project of the same user.
+func (t *TaskService) checkProject(ctx context.Context, userId string, projectId *string) error {
+	if projectId == nil {
+		return nil
+	}
+
+	_, err := t.projects.Get(ctx, *projectId, userId)
+	return err
+}
+
+// checkParent makes sure the parent belongs to the user, that a task is not nested under
+// itself or its own subtasks and that the resulting tree stays within the maximum depth.
+// id is empty for a task that is being created.
+func (t *TaskService) checkParent(ctx context.Context, id, userId, parentId string) error {
+	if parentId == id {
+		return domain.ErrTaskParentCycle
+	}
+
+	parent, err := t.rep.Get(ctx, parentId, userId)
+	if err != nil {
+		return err
+	}
+
+	depth := 1
+	for parent.ParentId != nil {
+		if *parent.ParentId == id {
+			return domain.ErrTaskParentCycle
+		}
+		if depth >= t.maxDepth {
+			return domain.ErrTaskDepthExceeded
+		}
+
+		parent, err = t.rep.Get(ctx, *parent.ParentId, userId)
+		if err != nil {
+			return err
+		}
+		depth++
+	}
+
+	height := 1
+	if id != "" {
+		levels, err := t.subtaskLevels(ctx, userId, id)
+		if err != nil {
+			return err
+		}
+		height += len(levels)
+	}
+
+	if depth+height > t.maxDepth {
+		return domain.ErrTaskDepthExceeded
+	}
+	return nil
+}
+
+// subtaskLevels loads the subtasks of the task level by level. A tree never has more
+// than maxDepth levels, so at most maxDepth-1 levels of subtasks are loaded.
+func (t *TaskService) subtaskLevels(ctx context.Context, userId, id string) ([][]domain.Task, error) {
+	var levels [][]domain.Task
+
+	parentIds := []string{id}
+	for len(levels) < t.maxDepth-1 {
+		subtasks, err := t.rep.GetSubtasks(ctx, userId, parentIds)
+		if err != nil {
+			return nil, err
+		}
+		if len(subtasks) == 0 {
+			break
+		}
+
+		levels = append(levels, subtasks)
+
+		parentIds = make([]string, 0, len(subtasks))
+		for _, subtask := range subtasks {
+			parentIds = append(parentIds, subtask.Id)
+		}
+	}
+
+	return levels, nil
+}
+
+func buildTaskTree(task domain.Task, children map[string][]domain.Task) domain.TaskTree {
+	subtasks := children[task.Id]
+
+	tree := domain.TaskTree{
+		Task:     task,
+		Progress: domain.NewTaskProgress(subtasks),
+		Subtasks: make([]domain.TaskTree, 0, len(subtasks)),
+	}
+	for _, subtask := range subtasks {
+		tree.Subtasks = append(tree.Subtasks, buildTaskTree(subtask, children))
+	}
+
+	return tree
+}
+
+// minPositionDistance keeps positions far enough apart to stay exact as float64.
+const minPositionDistance = 1e-6
+
+var errPositionsExhausted = errors.New("no free position between the neighbours")
+
+// movePosition computes the new position from the neighbours in the input. A missing
+// neighbour is looked up next to the given one, or left open at the start or end of the list.
+// Positions only order the tasks of one project, so both neighbours must share the task project.
+// Neighbours sharing a position leave no room between them until the positions are rebalanced.
+func (t *TaskService) movePosition(ctx context.Context, task domain.Task, userId string, in domain.MoveTaskInput) (float64, error) {
+	var lower, upper *float64
+	var after, before domain.Task
+	var err error
+
+	if in.AfterId != nil {
+		if after, err = t.neighbour(ctx, task, userId, *in.AfterId); err != nil {
+			return 0, err
+		}
+		lower = &after.Position
+	}
+
+	if in.BeforeId != nil {
+		if before, err = t.neighbour(ctx, task, userId, *in.BeforeId); err != nil {
+			return 0, err
+		}
+		upper = &before.Position
+	}
+
+	switch {
+	case lower != nil && upper != nil:
+		if *lower > *upper {
+			return 0, domain.ErrInvalidMove
+		}
+	case lower != nil:
+		upper, err = t.rep.GetAdjacentPosition(ctx, userId, after, true)
+	case upper != nil:
+		lower, err = t.rep.GetAdjacentPosition(ctx, userId, before, false)
+	default:
+		return 0, domain.ErrInvalidMove
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case lower == nil:
+		return *upper - domain.TaskPositionGap, nil
+	case upper == nil:
+		return *lower + domain.TaskPositionGap, nil
+	case *upper-*lower < minPositionDistance:
+		return 0, errPositionsExhausted
+	default:
+		return *lower + (*upper-*lower)/2, nil
+	}
+}
+
+// neighbour returns the task a moved task is placed next to.
+func (t *TaskService) neighbour(ctx context.Context, task domain.Task, userId, neighbourId string) (domain.Task, error) {
+	if neighbourId == task.Id {
+		return domain.Task{}, domain.ErrInvalidMove
+	}
+
+	neighbour, err := t.rep.Get(ctx, neighbourId, userId)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if !sameProject(neighbour.ProjectId, task.ProjectId) {
+		return domain.Task{}, domain.ErrInvalidMove
+	}
+
+	return neighbour, nil
+}
+
+// sameProject compares two project ids, nil standing for the inbox.
+func sameProject(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -8,6 +8,7 @@ import (
 	mock_service "github.com/i-vasilkov/go-todo-app/internal/service/mocks"
 	"github.com/magiconair/properties/assert"
 	"testing"
+	"time"
 )
 
 func TestTaskService_Get(t *testing.T) {
@@ -54,7 +55,7 @@ func TestTaskService_Get(t *testing.T) {
 			repository := mock_service.NewMockTaskRepositoryI(ctrl)
 			testCase.mock(repository, testCase.taskId, testCase.userId, testCase.task)
 
-			service := NewTaskService(repository)
+			service := NewTaskService(repository, mock_service.NewMockProjectRepositoryI(ctrl), mock_service.NewMockLabelRepositoryI(ctrl), domain.DefaultTaskMaxDepth)
 			task, err := service.Get(context.Background(), testCase.taskId, testCase.userId)
 
 			assert.Equal(t, task, testCase.task)
@@ -64,37 +65,53 @@ func TestTaskService_Get(t *testing.T) {
 }
 
 func TestTaskService_GetAll(t *testing.T) {
-	type mockBehaviour func(rep *mock_service.MockTaskRepositoryI, userId string, tasks []domain.Task)
+	type mockBehaviour func(rep *mock_service.MockTaskRepositoryI, userId string, filter domain.TaskFilter, page domain.TaskPage)
 
 	testCases := []struct {
 		name   string
 		userId string
+		filter domain.TaskFilter
 		mock   mockBehaviour
-		tasks  []domain.Task
+		page   domain.TaskPage
 		err    error
 	}{
 		{
 			name:   "OK",
 			userId: "userId",
-			mock: func(rep *mock_service.MockTaskRepositoryI, userId string, tasks []domain.Task) {
-				rep.EXPECT().GetAll(context.Background(), userId).Return(tasks, nil)
+			filter: domain.TaskFilter{},
+			mock: func(rep *mock_service.MockTaskRepositoryI, userId string, filter domain.TaskFilter, page domain.TaskPage) {
+				rep.EXPECT().GetAll(context.Background(), userId, filter).Return(page, nil)
 			},
-			tasks: []domain.Task{
-				{
-					Id:     "taskId",
-					UserId: "userId",
+			page: domain.TaskPage{
+				Items: []domain.Task{
+					{
+						Id:     "taskId",
+						UserId: "userId",
+					},
 				},
+				NextCursor: "cursor",
 			},
 			err: nil,
 		},
+		{
+			name:   "Custom filter",
+			userId: "userId",
+			filter: domain.TaskFilter{SortBy: domain.TaskSortDueAt, SortDir: domain.SortDesc, Limit: 10},
+			mock: func(rep *mock_service.MockTaskRepositoryI, userId string, filter domain.TaskFilter, page domain.TaskPage) {
+				rep.EXPECT().GetAll(context.Background(), userId, filter).Return(page, nil)
+			},
+			page: domain.TaskPage{Items: []domain.Task{}},
+			err:  nil,
+		},
 		{
 			name:   "Repository error",
 			userId: "userId",
-			mock: func(rep *mock_service.MockTaskRepositoryI, userId string, tasks []domain.Task) {
-				rep.EXPECT().GetAll(context.Background(), userId).Return(tasks, errors.New("repository error"))
+			filter: domain.TaskFilter{},
+			mock: func(rep *mock_service.MockTaskRepositoryI, userId string, filter domain.TaskFilter, page domain.TaskPage) {
+				rep.EXPECT().GetAll(context.Background(), userId, filter).Return(page, errors.New("repository error"))
 			},
-			tasks: []domain.Task{},
-			err:   errors.New("repository error"),
+			page: domain.TaskPage{},
+			err:  errors.New("repository error"),
 		},
 	}
 
@@ -104,12 +121,12 @@ func TestTaskService_GetAll(t *testing.T) {
 			defer ctrl.Finish()
 
 			repository := mock_service.NewMockTaskRepositoryI(ctrl)
-			testCase.mock(repository, testCase.userId, testCase.tasks)
+			testCase.mock(repository, testCase.userId, testCase.filter.Normalize(), testCase.page)
 
-			service := NewTaskService(repository)
-			tasks, err := service.GetAll(context.Background(), testCase.userId)
+			service := NewTaskService(repository, mock_service.NewMockProjectRepositoryI(ctrl), mock_service.NewMockLabelRepositoryI(ctrl), domain.DefaultTaskMaxDepth)
+			page, err := service.GetAll(context.Background(), testCase.userId, testCase.filter)
 
-			assert.Equal(t, tasks, testCase.tasks)
+			assert.Equal(t, page, testCase.page)
 			assert.Equal(t, err, testCase.err)
 		})
 	}
@@ -153,7 +170,7 @@ func TestTaskService_Delete(t *testing.T) {
 			repository := mock_service.NewMockTaskRepositoryI(ctrl)
 			testCase.mock(repository, testCase.taskId, testCase.userId)
 
-			service := NewTaskService(repository)
+			service := NewTaskService(repository, mock_service.NewMockProjectRepositoryI(ctrl), mock_service.NewMockLabelRepositoryI(ctrl), domain.DefaultTaskMaxDepth)
 			err := service.Delete(context.Background(), testCase.taskId, testCase.userId)
 
 			assert.Equal(t, err, testCase.err)
@@ -209,7 +226,7 @@ func TestTaskService_Update(t *testing.T) {
 			repository := mock_service.NewMockTaskRepositoryI(ctrl)
 			testCase.mock(repository, testCase.taskId, testCase.userId, testCase.input, testCase.task)
 
-			service := NewTaskService(repository)
+			service := NewTaskService(repository, mock_service.NewMockProjectRepositoryI(ctrl), mock_service.NewMockLabelRepositoryI(ctrl), domain.DefaultTaskMaxDepth)
 			task, err := service.Update(context.Background(), testCase.taskId, testCase.userId, testCase.input)
 
 			assert.Equal(t, task, testCase.task)
@@ -242,6 +259,21 @@ func TestTaskService_Create(t *testing.T) {
 			},
 			err: nil,
 		},
+		{
+			name:   "Sanitized description",
+			userId: "userId",
+			input:  domain.CreateTaskInput{Name: "updated", Description: "\r\n# Title\r\n\x00text\n"},
+			mock: func(rep *mock_service.MockTaskRepositoryI, userId string, in domain.CreateTaskInput, task domain.Task) {
+				sanitized := domain.CreateTaskInput{Name: "updated", Description: "# Title\ntext"}
+				rep.EXPECT().Create(context.Background(), userId, sanitized).Return(task, nil)
+			},
+			task: domain.Task{
+				Id:          "taskId",
+				UserId:      "userId",
+				Description: "# Title\ntext",
+			},
+			err: nil,
+		},
 		{
 			name:   "Repository error",
 			userId: "userId",
@@ -262,7 +294,338 @@ func TestTaskService_Create(t *testing.T) {
 			repository := mock_service.NewMockTaskRepositoryI(ctrl)
 			testCase.mock(repository, testCase.userId, testCase.input, testCase.task)
 
-			service := NewTaskService(repository)
+			service := NewTaskService(repository, mock_service.NewMockProjectRepositoryI(ctrl), mock_service.NewMockLabelRepositoryI(ctrl), domain.DefaultTaskMaxDepth)
+			task, err := service.Create(context.Background(), testCase.userId, testCase.input)
+
+			assert.Equal(t, task, testCase.task)
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
+
+func TestTaskService_UpdateStatus(t *testing.T) {
+	type mockBehaviour func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, task domain.Task)
+
+	testCases := []struct {
+		name   string
+		taskId string
+		userId string
+		input  domain.UpdateTaskInput
+		mock   mockBehaviour
+		task   domain.Task
+		err    error
+	}{
+		{
+			name:   "Status changed",
+			taskId: "taskId",
+			userId: "userId",
+			input:  domain.UpdateTaskInput{Name: "updated", Status: domain.TaskStatusInProgress},
+			mock: func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, task domain.Task) {
+				in := domain.UpdateTaskInput{Name: "updated", Status: domain.TaskStatusInProgress}
+				rep.EXPECT().Update(context.Background(), taskId, userId, in).
+					Return(domain.Task{Id: taskId, Status: domain.TaskStatusTodo}, nil)
+				rep.EXPECT().UpdateStatus(context.Background(), taskId, userId, domain.TaskStatusInProgress, nil).
+					Return(task, nil)
+			},
+			task: domain.Task{Id: "taskId", Status: domain.TaskStatusInProgress},
+			err:  nil,
+		},
+		{
+			name:   "Status unchanged",
+			taskId: "taskId",
+			userId: "userId",
+			input:  domain.UpdateTaskInput{Name: "updated", Status: domain.TaskStatusTodo},
+			mock: func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, task domain.Task) {
+				in := domain.UpdateTaskInput{Name: "updated", Status: domain.TaskStatusTodo}
+				rep.EXPECT().Update(context.Background(), taskId, userId, in).Return(task, nil)
+			},
+			task: domain.Task{Id: "taskId", Status: domain.TaskStatusTodo},
+			err:  nil,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repository := mock_service.NewMockTaskRepositoryI(ctrl)
+			testCase.mock(repository, testCase.taskId, testCase.userId, testCase.task)
+
+			service := NewTaskService(repository, mock_service.NewMockProjectRepositoryI(ctrl), mock_service.NewMockLabelRepositoryI(ctrl), domain.DefaultTaskMaxDepth)
+			task, err := service.Update(context.Background(), testCase.taskId, testCase.userId, testCase.input)
+
+			assert.Equal(t, task, testCase.task)
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
+
+func TestTaskService_Complete(t *testing.T) {
+	type mockBehaviour func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, task domain.Task)
+
+	testCases := []struct {
+		name         string
+		taskId       string
+		userId       string
+		withSubtasks bool
+		mock         mockBehaviour
+		task         domain.Task
+		err          error
+	}{
+		{
+			name:   "OK",
+			taskId: "taskId",
+			userId: "userId",
+			mock: func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, task domain.Task) {
+				rep.EXPECT().Get(context.Background(), taskId, userId).Return(domain.Task{Id: taskId, Status: domain.TaskStatusTodo}, nil)
+				rep.EXPECT().
+					UpdateStatus(context.Background(), taskId, userId, domain.TaskStatusDone, gomock.Not(gomock.Nil())).
+					Return(task, nil)
+			},
+			task: domain.Task{Id: "taskId", Status: domain.TaskStatusDone},
+			err:  nil,
+		},
+		{
+			name:         "With subtasks",
+			taskId:       "taskId",
+			userId:       "userId",
+			withSubtasks: true,
+			mock: func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, task domain.Task) {
+				rep.EXPECT().Get(context.Background(), taskId, userId).Return(domain.Task{Id: taskId, Status: domain.TaskStatusTodo}, nil)
+				rep.EXPECT().GetSubtasks(context.Background(), userId, []string{taskId}).
+					Return([]domain.Task{{Id: "openId", Status: domain.TaskStatusTodo}, {Id: "doneId", Status: domain.TaskStatusDone}}, nil)
+				rep.EXPECT().GetSubtasks(context.Background(), userId, []string{"openId", "doneId"}).Return([]domain.Task{}, nil)
+				rep.EXPECT().
+					UpdateStatus(context.Background(), "openId", userId, domain.TaskStatusDone, gomock.Not(gomock.Nil())).
+					Return(domain.Task{Id: "openId", Status: domain.TaskStatusDone}, nil)
+				rep.EXPECT().
+					UpdateStatus(context.Background(), taskId, userId, domain.TaskStatusDone, gomock.Not(gomock.Nil())).
+					Return(task, nil)
+			},
+			task: domain.Task{Id: "taskId", Status: domain.TaskStatusDone},
+			err:  nil,
+		},
+		{
+			name:   "Repository error",
+			taskId: "taskId",
+			userId: "userId",
+			mock: func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, task domain.Task) {
+				rep.EXPECT().Get(context.Background(), taskId, userId).Return(domain.Task{Id: taskId, Status: domain.TaskStatusTodo}, nil)
+				rep.EXPECT().
+					UpdateStatus(context.Background(), taskId, userId, domain.TaskStatusDone, gomock.Not(gomock.Nil())).
+					Return(task, errors.New("repository error"))
+			},
+			task: domain.Task{},
+			err:  errors.New("repository error"),
+		},
+		{
+			name:   "Already done",
+			taskId: "taskId",
+			userId: "userId",
+			mock: func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, task domain.Task) {
+				rep.EXPECT().Get(context.Background(), taskId, userId).Return(domain.Task{Id: taskId, Status: domain.TaskStatusDone}, nil)
+			},
+			task: domain.Task{},
+			err:  domain.ErrTaskAlreadyDone,
+		},
+		{
+			name:   "Task not found",
+			taskId: "taskId",
+			userId: "userId",
+			mock: func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, task domain.Task) {
+				rep.EXPECT().Get(context.Background(), taskId, userId).Return(domain.Task{}, domain.ErrTaskNotFound)
+			},
+			task: domain.Task{},
+			err:  domain.ErrTaskNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repository := mock_service.NewMockTaskRepositoryI(ctrl)
+			testCase.mock(repository, testCase.taskId, testCase.userId, testCase.task)
+
+			service := NewTaskService(repository, mock_service.NewMockProjectRepositoryI(ctrl), mock_service.NewMockLabelRepositoryI(ctrl), domain.DefaultTaskMaxDepth)
+			task, err := service.Complete(context.Background(), testCase.taskId, testCase.userId, testCase.withSubtasks)
+
+			assert.Equal(t, task, testCase.task)
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
+
+func TestTaskService_Reopen(t *testing.T) {
+	type mockBehaviour func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, task domain.Task)
+
+	testCases := []struct {
+		name   string
+		taskId string
+		userId string
+		mock   mockBehaviour
+		task   domain.Task
+		err    error
+	}{
+		{
+			name:   "OK",
+			taskId: "taskId",
+			userId: "userId",
+			mock: func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, task domain.Task) {
+				rep.EXPECT().Get(context.Background(), taskId, userId).Return(domain.Task{Id: taskId, Status: domain.TaskStatusDone}, nil)
+				rep.EXPECT().UpdateStatus(context.Background(), taskId, userId, domain.TaskStatusTodo, nil).Return(task, nil)
+			},
+			task: domain.Task{Id: "taskId", Status: domain.TaskStatusTodo},
+			err:  nil,
+		},
+		{
+			name:   "Repository error",
+			taskId: "taskId",
+			userId: "userId",
+			mock: func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, task domain.Task) {
+				rep.EXPECT().Get(context.Background(), taskId, userId).Return(domain.Task{Id: taskId, Status: domain.TaskStatusDone}, nil)
+				rep.EXPECT().
+					UpdateStatus(context.Background(), taskId, userId, domain.TaskStatusTodo, nil).
+					Return(task, errors.New("repository error"))
+			},
+			task: domain.Task{},
+			err:  errors.New("repository error"),
+		},
+		{
+			name:   "Cancelled",
+			taskId: "taskId",
+			userId: "userId",
+			mock: func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, task domain.Task) {
+				rep.EXPECT().Get(context.Background(), taskId, userId).Return(domain.Task{Id: taskId, Status: domain.TaskStatusCancelled}, nil)
+				rep.EXPECT().UpdateStatus(context.Background(), taskId, userId, domain.TaskStatusTodo, nil).Return(task, nil)
+			},
+			task: domain.Task{Id: "taskId", Status: domain.TaskStatusTodo},
+			err:  nil,
+		},
+		{
+			name:   "In progress",
+			taskId: "taskId",
+			userId: "userId",
+			mock: func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, task domain.Task) {
+				rep.EXPECT().Get(context.Background(), taskId, userId).Return(domain.Task{Id: taskId, Status: domain.TaskStatusInProgress}, nil)
+			},
+			task: domain.Task{},
+			err:  domain.ErrTaskNotClosed,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repository := mock_service.NewMockTaskRepositoryI(ctrl)
+			testCase.mock(repository, testCase.taskId, testCase.userId, testCase.task)
+
+			service := NewTaskService(repository, mock_service.NewMockProjectRepositoryI(ctrl), mock_service.NewMockLabelRepositoryI(ctrl), domain.DefaultTaskMaxDepth)
+			task, err := service.Reopen(context.Background(), testCase.taskId, testCase.userId)
+
+			assert.Equal(t, task, testCase.task)
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
+
+func TestTaskService_GetOverdue(t *testing.T) {
+	type mockBehaviour func(rep *mock_service.MockTaskRepositoryI, userId string, tasks []domain.Task)
+
+	testCases := []struct {
+		name   string
+		userId string
+		mock   mockBehaviour
+		tasks  []domain.Task
+		err    error
+	}{
+		{
+			name:   "OK",
+			userId: "userId",
+			mock: func(rep *mock_service.MockTaskRepositoryI, userId string, tasks []domain.Task) {
+				rep.EXPECT().GetOverdue(context.Background(), userId, gomock.Any()).Return(tasks, nil)
+			},
+			tasks: []domain.Task{{Id: "taskId", UserId: "userId"}},
+			err:   nil,
+		},
+		{
+			name:   "Repository error",
+			userId: "userId",
+			mock: func(rep *mock_service.MockTaskRepositoryI, userId string, tasks []domain.Task) {
+				rep.EXPECT().GetOverdue(context.Background(), userId, gomock.Any()).Return(tasks, errors.New("repository error"))
+			},
+			tasks: []domain.Task{},
+			err:   errors.New("repository error"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repository := mock_service.NewMockTaskRepositoryI(ctrl)
+			testCase.mock(repository, testCase.userId, testCase.tasks)
+
+			service := NewTaskService(repository, mock_service.NewMockProjectRepositoryI(ctrl), mock_service.NewMockLabelRepositoryI(ctrl), domain.DefaultTaskMaxDepth)
+			tasks, err := service.GetOverdue(context.Background(), testCase.userId)
+
+			assert.Equal(t, tasks, testCase.tasks)
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
+
+func TestTaskService_CreateInProject(t *testing.T) {
+	type mockBehaviour func(rep *mock_service.MockTaskRepositoryI, projects *mock_service.MockProjectRepositoryI, userId string, in domain.CreateTaskInput, task domain.Task)
+
+	projectId := "projectId"
+
+	testCases := []struct {
+		name   string
+		userId string
+		input  domain.CreateTaskInput
+		mock   mockBehaviour
+		task   domain.Task
+		err    error
+	}{
+		{
+			name:   "OK",
+			userId: "userId",
+			input:  domain.CreateTaskInput{Name: "test", ProjectId: &projectId},
+			mock: func(rep *mock_service.MockTaskRepositoryI, projects *mock_service.MockProjectRepositoryI, userId string, in domain.CreateTaskInput, task domain.Task) {
+				projects.EXPECT().Get(context.Background(), projectId, userId).Return(domain.Project{Id: projectId}, nil)
+				rep.EXPECT().Create(context.Background(), userId, in).Return(task, nil)
+			},
+			task: domain.Task{Id: "taskId", ProjectId: &projectId},
+			err:  nil,
+		},
+		{
+			name:   "Foreign project",
+			userId: "userId",
+			input:  domain.CreateTaskInput{Name: "test", ProjectId: &projectId},
+			mock: func(rep *mock_service.MockTaskRepositoryI, projects *mock_service.MockProjectRepositoryI, userId string, in domain.CreateTaskInput, task domain.Task) {
+				projects.EXPECT().Get(context.Background(), projectId, userId).Return(domain.Project{}, errors.New("not found"))
+			},
+			task: domain.Task{},
+			err:  errors.New("not found"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repository := mock_service.NewMockTaskRepositoryI(ctrl)
+			projects := mock_service.NewMockProjectRepositoryI(ctrl)
+			testCase.mock(repository, projects, testCase.userId, testCase.input, testCase.task)
+
+			service := NewTaskService(repository, projects, mock_service.NewMockLabelRepositoryI(ctrl), domain.DefaultTaskMaxDepth)
 			task, err := service.Create(context.Background(), testCase.userId, testCase.input)
 
 			assert.Equal(t, task, testCase.task)
@@ -270,3 +633,568 @@ func TestTaskService_Create(t *testing.T) {
 		})
 	}
 }
+
+func TestTaskService_AttachLabels(t *testing.T) {
+	type mockBehaviour func(rep *mock_service.MockTaskRepositoryI, labels *mock_service.MockLabelRepositoryI, id, userId string, labelIds []string, task domain.Task)
+
+	testCases := []struct {
+		name     string
+		id       string
+		userId   string
+		labelIds []string
+		mock     mockBehaviour
+		task     domain.Task
+		err      error
+	}{
+		{
+			name:     "OK",
+			id:       "taskId",
+			userId:   "userId",
+			labelIds: []string{"work", "errand"},
+			mock: func(rep *mock_service.MockTaskRepositoryI, labels *mock_service.MockLabelRepositoryI, id, userId string, labelIds []string, task domain.Task) {
+				labels.EXPECT().Get(context.Background(), "work", userId).Return(domain.Label{Id: "work"}, nil)
+				labels.EXPECT().Get(context.Background(), "errand", userId).Return(domain.Label{Id: "errand"}, nil)
+				rep.EXPECT().AttachLabels(context.Background(), id, userId, labelIds).Return(task, nil)
+			},
+			task: domain.Task{Id: "taskId", LabelIds: []string{"errand", "work"}},
+			err:  nil,
+		},
+		{
+			name:     "Foreign label",
+			id:       "taskId",
+			userId:   "userId",
+			labelIds: []string{"work", "errand"},
+			mock: func(rep *mock_service.MockTaskRepositoryI, labels *mock_service.MockLabelRepositoryI, id, userId string, labelIds []string, task domain.Task) {
+				labels.EXPECT().Get(context.Background(), "work", userId).Return(domain.Label{}, errors.New("not found"))
+			},
+			task: domain.Task{},
+			err:  errors.New("not found"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repository := mock_service.NewMockTaskRepositoryI(ctrl)
+			labels := mock_service.NewMockLabelRepositoryI(ctrl)
+			testCase.mock(repository, labels, testCase.id, testCase.userId, testCase.labelIds, testCase.task)
+
+			service := NewTaskService(repository, mock_service.NewMockProjectRepositoryI(ctrl), labels, domain.DefaultTaskMaxDepth)
+			task, err := service.AttachLabels(context.Background(), testCase.id, testCase.userId, testCase.labelIds)
+
+			assert.Equal(t, task, testCase.task)
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
+
+func TestTaskService_GetTree(t *testing.T) {
+	type mockBehaviour func(rep *mock_service.MockTaskRepositoryI, taskId, userId string)
+
+	rootId, childId := "rootId", "childId"
+
+	testCases := []struct {
+		name   string
+		taskId string
+		userId string
+		mock   mockBehaviour
+		tree   domain.TaskTree
+		err    error
+	}{
+		{
+			name:   "OK",
+			taskId: rootId,
+			userId: "userId",
+			mock: func(rep *mock_service.MockTaskRepositoryI, taskId, userId string) {
+				rep.EXPECT().Get(context.Background(), taskId, userId).Return(domain.Task{Id: rootId}, nil)
+				rep.EXPECT().GetSubtasks(context.Background(), userId, []string{rootId}).Return([]domain.Task{
+					{Id: childId, ParentId: &rootId, Status: domain.TaskStatusTodo},
+					{Id: "doneId", ParentId: &rootId, Status: domain.TaskStatusDone},
+					{Id: "cancelledId", ParentId: &rootId, Status: domain.TaskStatusCancelled},
+				}, nil)
+				rep.EXPECT().GetSubtasks(context.Background(), userId, []string{childId, "doneId", "cancelledId"}).Return([]domain.Task{
+					{Id: "grandchildId", ParentId: &childId, Status: domain.TaskStatusDone},
+				}, nil)
+			},
+			tree: domain.TaskTree{
+				Task:     domain.Task{Id: rootId},
+				Progress: domain.TaskProgress{Done: 1, Total: 2},
+				Subtasks: []domain.TaskTree{
+					{
+						Task:     domain.Task{Id: childId, ParentId: &rootId, Status: domain.TaskStatusTodo},
+						Progress: domain.TaskProgress{Done: 1, Total: 1},
+						Subtasks: []domain.TaskTree{
+							{
+								Task:     domain.Task{Id: "grandchildId", ParentId: &childId, Status: domain.TaskStatusDone},
+								Subtasks: []domain.TaskTree{},
+							},
+						},
+					},
+					{
+						Task:     domain.Task{Id: "doneId", ParentId: &rootId, Status: domain.TaskStatusDone},
+						Subtasks: []domain.TaskTree{},
+					},
+					{
+						Task:     domain.Task{Id: "cancelledId", ParentId: &rootId, Status: domain.TaskStatusCancelled},
+						Subtasks: []domain.TaskTree{},
+					},
+				},
+			},
+			err: nil,
+		},
+		{
+			name:   "Repository error",
+			taskId: rootId,
+			userId: "userId",
+			mock: func(rep *mock_service.MockTaskRepositoryI, taskId, userId string) {
+				rep.EXPECT().Get(context.Background(), taskId, userId).Return(domain.Task{}, errors.New("repository error"))
+			},
+			tree: domain.TaskTree{},
+			err:  errors.New("repository error"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repository := mock_service.NewMockTaskRepositoryI(ctrl)
+			testCase.mock(repository, testCase.taskId, testCase.userId)
+
+			service := NewTaskService(repository, mock_service.NewMockProjectRepositoryI(ctrl), mock_service.NewMockLabelRepositoryI(ctrl), domain.DefaultTaskMaxDepth)
+			tree, err := service.GetTree(context.Background(), testCase.taskId, testCase.userId)
+
+			assert.Equal(t, tree, testCase.tree)
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
+
+func TestTaskService_CreateSubtask(t *testing.T) {
+	type mockBehaviour func(rep *mock_service.MockTaskRepositoryI, userId string, in domain.CreateTaskInput, task domain.Task)
+
+	rootId, childId := "rootId", "childId"
+
+	testCases := []struct {
+		name     string
+		userId   string
+		maxDepth int
+		input    domain.CreateTaskInput
+		mock     mockBehaviour
+		task     domain.Task
+		err      error
+	}{
+		{
+			name:     "OK",
+			userId:   "userId",
+			maxDepth: 3,
+			input:    domain.CreateTaskInput{Name: "test", ParentId: &childId},
+			mock: func(rep *mock_service.MockTaskRepositoryI, userId string, in domain.CreateTaskInput, task domain.Task) {
+				rep.EXPECT().Get(context.Background(), childId, userId).Return(domain.Task{Id: childId, ParentId: &rootId}, nil)
+				rep.EXPECT().Get(context.Background(), rootId, userId).Return(domain.Task{Id: rootId}, nil)
+				rep.EXPECT().Create(context.Background(), userId, in).Return(task, nil)
+			},
+			task: domain.Task{Id: "taskId", ParentId: &childId},
+			err:  nil,
+		},
+		{
+			name:     "Depth exceeded",
+			userId:   "userId",
+			maxDepth: 2,
+			input:    domain.CreateTaskInput{Name: "test", ParentId: &childId},
+			mock: func(rep *mock_service.MockTaskRepositoryI, userId string, in domain.CreateTaskInput, task domain.Task) {
+				rep.EXPECT().Get(context.Background(), childId, userId).Return(domain.Task{Id: childId, ParentId: &rootId}, nil)
+				rep.EXPECT().Get(context.Background(), rootId, userId).Return(domain.Task{Id: rootId}, nil)
+			},
+			task: domain.Task{},
+			err:  domain.ErrTaskDepthExceeded,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repository := mock_service.NewMockTaskRepositoryI(ctrl)
+			testCase.mock(repository, testCase.userId, testCase.input, testCase.task)
+
+			service := NewTaskService(repository, mock_service.NewMockProjectRepositoryI(ctrl), mock_service.NewMockLabelRepositoryI(ctrl), testCase.maxDepth)
+			task, err := service.Create(context.Background(), testCase.userId, testCase.input)
+
+			assert.Equal(t, task, testCase.task)
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
+
+func TestTaskService_UpdateParent(t *testing.T) {
+	type mockBehaviour func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, in domain.UpdateTaskInput, task domain.Task)
+
+	taskId, childId, otherId := "taskId", "childId", "otherId"
+
+	testCases := []struct {
+		name   string
+		taskId string
+		userId string
+		input  domain.UpdateTaskInput
+		mock   mockBehaviour
+		task   domain.Task
+		err    error
+	}{
+		{
+			name:   "OK",
+			taskId: taskId,
+			userId: "userId",
+			input:  domain.UpdateTaskInput{Name: "test", ParentId: &otherId},
+			mock: func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, in domain.UpdateTaskInput, task domain.Task) {
+				rep.EXPECT().Get(context.Background(), otherId, userId).Return(domain.Task{Id: otherId}, nil)
+				rep.EXPECT().GetSubtasks(context.Background(), userId, []string{taskId}).Return([]domain.Task{{Id: childId}}, nil)
+				rep.EXPECT().GetSubtasks(context.Background(), userId, []string{childId}).Return([]domain.Task{}, nil)
+				rep.EXPECT().Update(context.Background(), taskId, userId, in).Return(task, nil)
+			},
+			task: domain.Task{Id: taskId, ParentId: &otherId},
+			err:  nil,
+		},
+		{
+			name:   "Own parent",
+			taskId: taskId,
+			userId: "userId",
+			input:  domain.UpdateTaskInput{Name: "test", ParentId: &taskId},
+			mock: func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, in domain.UpdateTaskInput, task domain.Task) {
+			},
+			task: domain.Task{},
+			err:  domain.ErrTaskParentCycle,
+		},
+		{
+			name:   "Parent is own subtask",
+			taskId: taskId,
+			userId: "userId",
+			input:  domain.UpdateTaskInput{Name: "test", ParentId: &childId},
+			mock: func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, in domain.UpdateTaskInput, task domain.Task) {
+				rep.EXPECT().Get(context.Background(), childId, userId).Return(domain.Task{Id: childId, ParentId: &taskId}, nil)
+			},
+			task: domain.Task{},
+			err:  domain.ErrTaskParentCycle,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repository := mock_service.NewMockTaskRepositoryI(ctrl)
+			testCase.mock(repository, testCase.taskId, testCase.userId, testCase.input, testCase.task)
+
+			service := NewTaskService(repository, mock_service.NewMockProjectRepositoryI(ctrl), mock_service.NewMockLabelRepositoryI(ctrl), domain.DefaultTaskMaxDepth)
+			task, err := service.Update(context.Background(), testCase.taskId, testCase.userId, testCase.input)
+
+			assert.Equal(t, task, testCase.task)
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
+
+func TestTaskService_CompleteRecurring(t *testing.T) {
+	type mockBehaviour func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, task domain.Task)
+
+	startAt := time.Date(2021, 3, 1, 8, 0, 0, 0, time.UTC)
+	dueAt := time.Date(2021, 3, 1, 9, 0, 0, 0, time.UTC)
+	completedAt := time.Date(2021, 3, 2, 12, 0, 0, 0, time.UTC)
+	nextStartAt := time.Date(2021, 3, 3, 8, 0, 0, 0, time.UTC)
+	nextDueAt := time.Date(2021, 3, 3, 9, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name   string
+		taskId string
+		userId string
+		mock   mockBehaviour
+		task   domain.Task
+		err    error
+	}{
+		{
+			name:   "OK",
+			taskId: "taskId",
+			userId: "userId",
+			mock: func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, task domain.Task) {
+				rep.EXPECT().Get(context.Background(), taskId, userId).Return(domain.Task{Id: taskId, Status: domain.TaskStatusInProgress}, nil)
+				completed := domain.Task{
+					Id:          taskId,
+					Name:        "test",
+					Status:      domain.TaskStatusDone,
+					CompletedAt: &completedAt,
+					StartAt:     &startAt,
+					DueAt:       &dueAt,
+					LabelIds:    []string{"labelId"},
+					Recurrence:  "FREQ=WEEKLY;BYDAY=MO,WE",
+				}
+				rep.EXPECT().
+					UpdateStatus(context.Background(), taskId, userId, domain.TaskStatusDone, gomock.Not(gomock.Nil())).
+					Return(completed, nil)
+				rep.EXPECT().Create(context.Background(), userId, domain.CreateTaskInput{
+					Name:       "test",
+					StartAt:    &nextStartAt,
+					DueAt:      &nextDueAt,
+					Recurrence: "FREQ=WEEKLY;BYDAY=MO,WE",
+				}).Return(domain.Task{Id: "nextId"}, nil)
+				rep.EXPECT().AttachLabels(context.Background(), "nextId", userId, []string{"labelId"}).Return(domain.Task{Id: "nextId"}, nil)
+				rep.EXPECT().
+					Update(context.Background(), taskId, userId, domain.UpdateTaskInput{Name: "test", StartAt: &startAt, DueAt: &dueAt}).
+					Return(task, nil)
+			},
+			task: domain.Task{Id: "taskId", Status: domain.TaskStatusDone},
+			err:  nil,
+		},
+		{
+			name:   "Repository error",
+			taskId: "taskId",
+			userId: "userId",
+			mock: func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, task domain.Task) {
+				rep.EXPECT().Get(context.Background(), taskId, userId).Return(domain.Task{Id: taskId, Status: domain.TaskStatusInProgress}, nil)
+				completed := domain.Task{Id: taskId, Name: "test", Status: domain.TaskStatusDone, Recurrence: "FREQ=DAILY"}
+				rep.EXPECT().
+					UpdateStatus(context.Background(), taskId, userId, domain.TaskStatusDone, gomock.Not(gomock.Nil())).
+					Return(completed, nil)
+				rep.EXPECT().Create(context.Background(), userId, gomock.Any()).Return(domain.Task{}, errors.New("repository error"))
+			},
+			task: domain.Task{},
+			err:  errors.New("repository error"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repository := mock_service.NewMockTaskRepositoryI(ctrl)
+			testCase.mock(repository, testCase.taskId, testCase.userId, testCase.task)
+
+			service := NewTaskService(repository, mock_service.NewMockProjectRepositoryI(ctrl), mock_service.NewMockLabelRepositoryI(ctrl), domain.DefaultTaskMaxDepth)
+			task, err := service.Complete(context.Background(), testCase.taskId, testCase.userId, false)
+
+			assert.Equal(t, task, testCase.task)
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
+
+func TestTaskService_Move(t *testing.T) {
+	type mockBehaviour func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, task domain.Task)
+
+	aId, bId, taskId, projectId, otherProjectId := "aId", "bId", "taskId", "projectId", "otherProjectId"
+	upper := 2048.0
+	moved := domain.Task{Id: taskId, ProjectId: &projectId, Position: 4096}
+
+	testCases := []struct {
+		name   string
+		taskId string
+		userId string
+		input  domain.MoveTaskInput
+		mock   mockBehaviour
+		task   domain.Task
+		err    error
+	}{
+		{
+			name:   "Between neighbours",
+			taskId: taskId,
+			userId: "userId",
+			input:  domain.MoveTaskInput{AfterId: &aId, BeforeId: &bId},
+			mock: func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, task domain.Task) {
+				rep.EXPECT().Get(context.Background(), taskId, userId).Return(moved, nil)
+				rep.EXPECT().Get(context.Background(), aId, userId).Return(domain.Task{Id: aId, ProjectId: &projectId, Position: 1024}, nil)
+				rep.EXPECT().Get(context.Background(), bId, userId).Return(domain.Task{Id: bId, ProjectId: &projectId, Position: 2048}, nil)
+				rep.EXPECT().UpdatePosition(context.Background(), taskId, userId, 1536.0).Return(task, nil)
+			},
+			task: domain.Task{Id: taskId, Position: 1536},
+			err:  nil,
+		},
+		{
+			name:   "After a task",
+			taskId: taskId,
+			userId: "userId",
+			input:  domain.MoveTaskInput{AfterId: &aId},
+			mock: func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, task domain.Task) {
+				rep.EXPECT().Get(context.Background(), taskId, userId).Return(moved, nil)
+				rep.EXPECT().Get(context.Background(), aId, userId).Return(domain.Task{Id: aId, ProjectId: &projectId, Position: 1024}, nil)
+				rep.EXPECT().GetAdjacentPosition(context.Background(), userId, domain.Task{Id: aId, ProjectId: &projectId, Position: 1024}, true).Return(&upper, nil)
+				rep.EXPECT().UpdatePosition(context.Background(), taskId, userId, 1536.0).Return(task, nil)
+			},
+			task: domain.Task{Id: taskId, Position: 1536},
+			err:  nil,
+		},
+		{
+			name:   "To the top",
+			taskId: taskId,
+			userId: "userId",
+			input:  domain.MoveTaskInput{BeforeId: &aId},
+			mock: func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, task domain.Task) {
+				rep.EXPECT().Get(context.Background(), taskId, userId).Return(moved, nil)
+				rep.EXPECT().Get(context.Background(), aId, userId).Return(domain.Task{Id: aId, ProjectId: &projectId, Position: 1024}, nil)
+				rep.EXPECT().GetAdjacentPosition(context.Background(), userId, domain.Task{Id: aId, ProjectId: &projectId, Position: 1024}, false).Return(nil, nil)
+				rep.EXPECT().UpdatePosition(context.Background(), taskId, userId, 0.0).Return(task, nil)
+			},
+			task: domain.Task{Id: taskId, Position: 0},
+			err:  nil,
+		},
+		{
+			name:   "Rebalance",
+			taskId: taskId,
+			userId: "userId",
+			input:  domain.MoveTaskInput{AfterId: &aId, BeforeId: &bId},
+			mock: func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, task domain.Task) {
+				gomock.InOrder(
+					rep.EXPECT().Get(context.Background(), taskId, userId).Return(moved, nil),
+					rep.EXPECT().Get(context.Background(), aId, userId).Return(domain.Task{Id: aId, ProjectId: &projectId, Position: 1}, nil),
+					rep.EXPECT().Get(context.Background(), bId, userId).Return(domain.Task{Id: bId, ProjectId: &projectId, Position: 1 + 1e-9}, nil),
+					rep.EXPECT().RebalancePositions(context.Background(), userId, &projectId).Return(nil),
+					rep.EXPECT().Get(context.Background(), aId, userId).Return(domain.Task{Id: aId, ProjectId: &projectId, Position: 1024}, nil),
+					rep.EXPECT().Get(context.Background(), bId, userId).Return(domain.Task{Id: bId, ProjectId: &projectId, Position: 2048}, nil),
+					rep.EXPECT().UpdatePosition(context.Background(), taskId, userId, 1536.0).Return(task, nil),
+				)
+			},
+			task: domain.Task{Id: taskId, Position: 1536},
+			err:  nil,
+		},
+		{
+			name:   "Neighbours at the same position",
+			taskId: taskId,
+			userId: "userId",
+			input:  domain.MoveTaskInput{AfterId: &aId},
+			mock: func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, task domain.Task) {
+				same := 1024.0
+				gomock.InOrder(
+					rep.EXPECT().Get(context.Background(), taskId, userId).Return(moved, nil),
+					rep.EXPECT().Get(context.Background(), aId, userId).Return(domain.Task{Id: aId, ProjectId: &projectId, Position: 1024}, nil),
+					rep.EXPECT().GetAdjacentPosition(context.Background(), userId, domain.Task{Id: aId, ProjectId: &projectId, Position: 1024}, true).Return(&same, nil),
+					rep.EXPECT().RebalancePositions(context.Background(), userId, &projectId).Return(nil),
+					rep.EXPECT().Get(context.Background(), aId, userId).Return(domain.Task{Id: aId, ProjectId: &projectId, Position: 1024}, nil),
+					rep.EXPECT().GetAdjacentPosition(context.Background(), userId, domain.Task{Id: aId, ProjectId: &projectId, Position: 1024}, true).Return(&upper, nil),
+					rep.EXPECT().UpdatePosition(context.Background(), taskId, userId, 1536.0).Return(task, nil),
+				)
+			},
+			task: domain.Task{Id: taskId, Position: 1536},
+			err:  nil,
+		},
+		{
+			name:   "Neighbours in wrong order",
+			taskId: taskId,
+			userId: "userId",
+			input:  domain.MoveTaskInput{AfterId: &bId, BeforeId: &aId},
+			mock: func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, task domain.Task) {
+				rep.EXPECT().Get(context.Background(), taskId, userId).Return(moved, nil)
+				rep.EXPECT().Get(context.Background(), bId, userId).Return(domain.Task{Id: bId, ProjectId: &projectId, Position: 2048}, nil)
+				rep.EXPECT().Get(context.Background(), aId, userId).Return(domain.Task{Id: aId, ProjectId: &projectId, Position: 1024}, nil)
+			},
+			task: domain.Task{},
+			err:  domain.ErrInvalidMove,
+		},
+		{
+			name:   "Next to itself",
+			taskId: taskId,
+			userId: "userId",
+			input:  domain.MoveTaskInput{AfterId: &taskId},
+			mock: func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, task domain.Task) {
+				rep.EXPECT().Get(context.Background(), taskId, userId).Return(moved, nil)
+			},
+			task: domain.Task{},
+			err:  domain.ErrInvalidMove,
+		},
+		{
+			name:   "Neighbour in another project",
+			taskId: taskId,
+			userId: "userId",
+			input:  domain.MoveTaskInput{AfterId: &aId},
+			mock: func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, task domain.Task) {
+				rep.EXPECT().Get(context.Background(), taskId, userId).Return(moved, nil)
+				rep.EXPECT().Get(context.Background(), aId, userId).Return(domain.Task{Id: aId, ProjectId: &otherProjectId, Position: 1024}, nil)
+			},
+			task: domain.Task{},
+			err:  domain.ErrInvalidMove,
+		},
+		{
+			name:   "Task not found",
+			taskId: taskId,
+			userId: "userId",
+			input:  domain.MoveTaskInput{AfterId: &aId},
+			mock: func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, task domain.Task) {
+				rep.EXPECT().Get(context.Background(), taskId, userId).Return(domain.Task{}, domain.ErrTaskNotFound)
+			},
+			task: domain.Task{},
+			err:  domain.ErrTaskNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repository := mock_service.NewMockTaskRepositoryI(ctrl)
+			testCase.mock(repository, testCase.taskId, testCase.userId, testCase.task)
+
+			service := NewTaskService(repository, mock_service.NewMockProjectRepositoryI(ctrl), mock_service.NewMockLabelRepositoryI(ctrl), domain.DefaultTaskMaxDepth)
+			task, err := service.Move(context.Background(), testCase.taskId, testCase.userId, testCase.input)
+
+			assert.Equal(t, task, testCase.task)
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
+
+func TestTaskService_Restore(t *testing.T) {
+	type mockBehaviour func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, task domain.Task)
+
+	testCases := []struct {
+		name   string
+		taskId string
+		userId string
+		mock   mockBehaviour
+		task   domain.Task
+		err    error
+	}{
+		{
+			name:   "OK",
+			taskId: "taskId",
+			userId: "userId",
+			mock: func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, task domain.Task) {
+				rep.EXPECT().Restore(context.Background(), taskId, userId).Return(task, nil)
+			},
+			task: domain.Task{
+				Id:     "taskId",
+				UserId: "userId",
+			},
+			err: nil,
+		},
+		{
+			name:   "Repository error",
+			taskId: "taskId",
+			userId: "userId",
+			mock: func(rep *mock_service.MockTaskRepositoryI, taskId, userId string, task domain.Task) {
+				rep.EXPECT().Restore(context.Background(), taskId, userId).Return(task, errors.New("repository error"))
+			},
+			task: domain.Task{},
+			err:  errors.New("repository error"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repository := mock_service.NewMockTaskRepositoryI(ctrl)
+			testCase.mock(repository, testCase.taskId, testCase.userId, testCase.task)
+
+			service := NewTaskService(repository, mock_service.NewMockProjectRepositoryI(ctrl), mock_service.NewMockLabelRepositoryI(ctrl), domain.DefaultTaskMaxDepth)
+			task, err := service.Restore(context.Background(), testCase.taskId, testCase.userId)
+
+			assert.Equal(t, task, testCase.task)
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"context"
+	"github.com/i-vasilkov/go-todo-app/internal/config"
+	"github.com/i-vasilkov/go-todo-app/internal/service"
+	"log"
+	"time"
+)
+
+const (
+	DefaultTrashRetention     = 30 * 24 * time.Hour
+	DefaultTrashPurgeInterval = time.Hour
+)
+
+// TrashPurger periodically removes the tasks that stayed in the trash longer than the
+// configured retention period.
+type TrashPurger struct {
+	tasks     service.TaskServiceI
+	retention time.Duration
+	interval  time.Duration
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+func NewTrashPurger(tasks service.TaskServiceI, cfg *config.Config) *TrashPurger {
+	retention := cfg.Task.TrashRetention
+	if retention <= 0 {
+		retention = DefaultTrashRetention
+	}
+
+	interval := cfg.Task.TrashPurgeInterval
+	if interval <= 0 {
+		interval = DefaultTrashPurgeInterval
+	}
+
+	return &TrashPurger{
+		tasks:     tasks,
+		retention: retention,
+		interval:  interval,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Run purges the trash right away and then once per interval until Stop is called.
+func (p *TrashPurger) Run() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.purge()
+
+		select {
+		case <-ticker.C:
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// Stop waits for a running purge to finish, or for ctx to expire.
+func (p *TrashPurger) Stop(ctx context.Context) error {
+	close(p.stop)
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *TrashPurger) purge() {
+	purged, err := p.tasks.PurgeTrash(context.Background(), time.Now().Add(-p.retention))
+	if err != nil {
+		log.Printf("trash purge failed: %s", err.Error())
+		return
+	}
+
+	if purged > 0 {
+		log.Printf("purged %d tasks from the trash", purged)
+	}
+}
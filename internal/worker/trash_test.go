@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/i-vasilkov/go-todo-app/internal/config"
+	"github.com/i-vasilkov/go-todo-app/internal/service/mocks"
+	"github.com/magiconair/properties/assert"
+	"testing"
+	"time"
+)
+
+func TestTrashPurger_Run(t *testing.T) {
+	testCases := []struct {
+		name      string
+		cfg       config.TaskConfig
+		retention time.Duration
+		err       error
+	}{
+		{
+			name:      "Configured retention",
+			cfg:       config.TaskConfig{TrashRetention: 48 * time.Hour, TrashPurgeInterval: time.Minute},
+			retention: 48 * time.Hour,
+		},
+		{
+			name:      "Default retention",
+			cfg:       config.TaskConfig{},
+			retention: DefaultTrashRetention,
+		},
+		{
+			name:      "Service error",
+			cfg:       config.TaskConfig{},
+			retention: DefaultTrashRetention,
+			err:       errors.New("service error"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			purged := make(chan time.Time, 1)
+			taskService := mock_service.NewMockTaskServiceI(ctrl)
+			taskService.EXPECT().PurgeTrash(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, before time.Time) (int64, error) {
+					purged <- before
+					return 1, testCase.err
+				})
+
+			purger := NewTrashPurger(taskService, &config.Config{Task: testCase.cfg})
+
+			started := time.Now()
+			go purger.Run()
+			before := <-purged
+			finished := time.Now()
+
+			assert.Equal(t, purger.Stop(context.Background()), nil)
+			assert.Equal(t, before.Before(started.Add(-testCase.retention)), false)
+			assert.Equal(t, before.After(finished.Add(-testCase.retention)), false)
+		})
+	}
+}
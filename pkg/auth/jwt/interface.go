@@ -1,8 +1,13 @@
 package jwt
 
+import "time"
+
 //go:generate mockgen -source=interface.go -destination=mocks/mock.go
 
 type TokenManagerI interface {
-	NewToken(id string) (string, error)
-	Parse(token string) (string, error)
+	NewToken(id string, roles []string) (string, error)
+	NewAudienceToken(id, audience string, ttl time.Duration) (string, error)
+	Parse(token string) (Claims, error)
+	ParseAudience(token, audience string) (Claims, error)
+	JWKS() JWKS
 }
@@ -0,0 +1,183 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v4"
+	"math/big"
+	"sort"
+	"time"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// Key is a single key of a KeySet. A key without private material only verifies tokens,
+// which is how keys that were rotated out are kept until they retire.
+type Key struct {
+	Id        string
+	RetiresAt time.Time
+
+	method  jwt.SigningMethod
+	private interface{}
+	public  interface{}
+}
+
+// NewHMACKey creates a symmetric key. It signs and verifies, but is never published.
+// A zero retiresAt never retires the key.
+func NewHMACKey(id string, secret []byte, retiresAt time.Time) Key {
+	return Key{Id: id, RetiresAt: retiresAt, method: jwt.SigningMethodHS256, private: secret, public: secret}
+}
+
+// ParseKey creates an asymmetric key from PEM encoded material. Either key may be omitted,
+// the public key is then derived from the private one. A zero retiresAt never retires the key.
+func ParseKey(id, algorithm string, privatePEM, publicPEM []byte, retiresAt time.Time) (Key, error) {
+	if id == "" {
+		return Key{}, errors.New("asymmetric key has to have an id")
+	}
+	if len(privatePEM) == 0 && len(publicPEM) == 0 {
+		return Key{}, fmt.Errorf("key %q has neither private nor public material", id)
+	}
+
+	key := Key{Id: id, RetiresAt: retiresAt}
+	var err error
+
+	switch algorithm {
+	case AlgorithmRS256:
+		key.method = jwt.SigningMethodRS256
+		if len(privatePEM) > 0 {
+			var private *rsa.PrivateKey
+			if private, err = jwt.ParseRSAPrivateKeyFromPEM(privatePEM); err == nil {
+				key.private, key.public = private, &private.PublicKey
+			}
+		} else {
+			key.public, err = jwt.ParseRSAPublicKeyFromPEM(publicPEM)
+		}
+	case AlgorithmEdDSA:
+		key.method = jwt.SigningMethodEdDSA
+		if len(privatePEM) > 0 {
+			var private interface{}
+			if private, err = jwt.ParseEdPrivateKeyFromPEM(privatePEM); err == nil {
+				key.private, key.public = private, private.(ed25519.PrivateKey).Public()
+			}
+		} else {
+			key.public, err = jwt.ParseEdPublicKeyFromPEM(publicPEM)
+		}
+	default:
+		return Key{}, fmt.Errorf("key %q has unsupported algorithm %q", id, algorithm)
+	}
+	if err != nil {
+		return Key{}, fmt.Errorf("key %q: %w", id, err)
+	}
+
+	return key, nil
+}
+
+func (k Key) retired(now time.Time) bool {
+	return !k.RetiresAt.IsZero() && !now.Before(k.RetiresAt)
+}
+
+// jwk describes the public part of the key, or returns false for symmetric keys.
+func (k Key) jwk() (JWK, bool) {
+	jwk := JWK{Kid: k.Id, Alg: k.method.Alg(), Use: "sig"}
+
+	switch public := k.public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	default:
+		return JWK{}, false
+	}
+
+	return jwk, true
+}
+
+// JWK is a public key in the JSON Web Key format (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// KeySet holds the key new tokens are signed with and all keys tokens are still verified with.
+// Tokens carry the id of their key in the kid header; tokens without one are looked up under
+// the empty id, which is where the legacy shared secret lives.
+type KeySet struct {
+	signing Key
+	keys    map[string]Key
+}
+
+func NewKeySet(signingId string, keys ...Key) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]Key, len(keys))}
+	for _, key := range keys {
+		if _, ok := ks.keys[key.Id]; ok {
+			return nil, fmt.Errorf("duplicate key %q", key.Id)
+		}
+		ks.keys[key.Id] = key
+	}
+
+	signing, ok := ks.keys[signingId]
+	if !ok {
+		return nil, fmt.Errorf("signing key %q not found", signingId)
+	}
+	if signing.private == nil {
+		return nil, fmt.Errorf("signing key %q has no private key", signingId)
+	}
+	if signing.retired(time.Now()) {
+		return nil, fmt.Errorf("signing key %q is retired", signingId)
+	}
+	ks.signing = signing
+
+	return ks, nil
+}
+
+// verificationKey finds the key a token with the given kid header and algorithm was signed
+// with. Requiring the algorithm of the key keeps a public key from being used as an HMAC secret.
+func (ks *KeySet) verificationKey(kid, alg string, now time.Time) (interface{}, error) {
+	key, ok := ks.keys[kid]
+	if !ok || key.retired(now) {
+		return nil, fmt.Errorf("unknown signing key: %q", kid)
+	}
+	if key.method.Alg() != alg {
+		return nil, fmt.Errorf("unexpected signing method: %v", alg)
+	}
+
+	return key.public, nil
+}
+
+// JWKS publishes the public keys that have not retired yet.
+func (ks *KeySet) JWKS() JWKS {
+	now := time.Now()
+	jwks := JWKS{Keys: make([]JWK, 0, len(ks.keys))}
+	for _, key := range ks.keys {
+		if key.retired(now) {
+			continue
+		}
+		if jwk, ok := key.jwk(); ok {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+
+	sort.Slice(jwks.Keys, func(i, j int) bool { return jwks.Keys[i].Kid < jwks.Keys[j].Kid })
+	return jwks
+}
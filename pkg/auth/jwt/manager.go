@@ -1,45 +1,153 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
-	"github.com/dgrijalva/jwt-go"
+	"github.com/golang-jwt/jwt/v4"
 	"time"
 )
 
+// AccessAudience is the audience of access tokens. The other tokens are signed with the same
+// keys, so verifiers of access tokens, JWKS users included, have to check it.
+const AccessAudience = "access"
+
+// Claims are the registered claims the application relies on.
+type Claims struct {
+	// Id is the unique token id (jti) a single token is revoked by.
+	Id        string
+	Subject   string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+	Roles     []string
+}
+
+// tokenClaims are the claims of the issued tokens: the registered ones and the roles of the user.
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles,omitempty"`
+}
+
 type Manager struct {
 	ttl  time.Duration
-	sign string
+	keys *KeySet
 }
 
-func NewManager(ttl time.Duration, sign string) *Manager {
-	return &Manager{ttl: ttl, sign: sign}
+func NewManager(ttl time.Duration, keys *KeySet) *Manager {
+	return &Manager{ttl: ttl, keys: keys}
 }
 
-func (m *Manager) NewToken(id string) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &jwt.StandardClaims{
-		ExpiresAt: time.Now().Add(m.ttl).Unix(),
-		Subject:   id,
-	})
+// NewToken issues an access token. The roles are carried along, so they can be authorized
+// without a lookup until the token expires.
+func (m *Manager) NewToken(id string, roles []string) (string, error) {
+	return m.newToken(id, AccessAudience, m.ttl, roles)
+}
+
+// NewAudienceToken issues a token for a single purpose other than accessing the API, such as
+// finishing a two-factor sign in. Parse rejects it, only ParseAudience with the same audience
+// accepts it.
+func (m *Manager) NewAudienceToken(id, audience string, ttl time.Duration) (string, error) {
+	if audience == "" || audience == AccessAudience {
+		return "", fmt.Errorf("audience token can not be an access token")
+	}
+	return m.newToken(id, audience, ttl, nil)
+}
+
+func (m *Manager) newToken(id, audience string, ttl time.Duration, roles []string) (string, error) {
+	jti, err := newTokenId()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims := &tokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			Subject:   id,
+			Audience:  jwt.ClaimStrings{audience},
+		},
+		Roles: roles,
+	}
+
+	signing := m.keys.signing
+	token := jwt.NewWithClaims(signing.method, claims)
+	if audience == AccessAudience {
+		token.Header["typ"] = "at+jwt"
+	}
+	if signing.Id != "" {
+		token.Header["kid"] = signing.Id
+	}
+
+	return token.SignedString(signing.private)
+}
 
-	return token.SignedString([]byte(m.sign))
+// Parse accepts access tokens only, tokens of any other audience or of none are rejected.
+func (m *Manager) Parse(token string) (Claims, error) {
+	claims, err := m.parse(token)
+	if err != nil {
+		return Claims{}, err
+	}
+	if len(claims.Audience) != 1 || claims.Audience[0] != AccessAudience {
+		return Claims{}, fmt.Errorf("token is not an access token")
+	}
+
+	return toClaims(claims), nil
 }
 
-func (m *Manager) Parse(token string) (string, error) {
-	jwtToken, err := jwt.Parse(token, func(token *jwt.Token) (i interface{}, err error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
+func (m *Manager) ParseAudience(token, audience string) (Claims, error) {
+	claims, err := m.parse(token)
+	if err != nil {
+		return Claims{}, err
+	}
+	if len(claims.Audience) != 1 || claims.Audience[0] != audience {
+		return Claims{}, fmt.Errorf("token is not meant for %s", audience)
+	}
+
+	return toClaims(claims), nil
+}
 
-		return []byte(m.sign), nil
+func (m *Manager) parse(token string) (tokenClaims, error) {
+	var claims tokenClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(token *jwt.Token) (i interface{}, err error) {
+		kid, _ := token.Header["kid"].(string)
+		return m.keys.verificationKey(kid, token.Method.Alg(), time.Now())
 	})
 	if err != nil {
-		return "", err
+		return tokenClaims{}, err
+	}
+	if claims.ExpiresAt == nil {
+		return tokenClaims{}, fmt.Errorf("token has no expiration time")
 	}
 
-	claims, ok := jwtToken.Claims.(jwt.MapClaims)
-	if !ok {
-		return "", fmt.Errorf("error getting user claims from token")
+	return claims, nil
+}
+
+func toClaims(claims tokenClaims) Claims {
+	parsed := Claims{
+		Id:        claims.ID,
+		Subject:   claims.Subject,
+		ExpiresAt: claims.ExpiresAt.Time,
+		Roles:     claims.Roles,
+	}
+	if claims.IssuedAt != nil {
+		parsed.IssuedAt = claims.IssuedAt.Time
+	}
+
+	return parsed
+}
+
+// JWKS publishes the public keys tokens can be verified with.
+func (m *Manager) JWKS() JWKS {
+	return m.keys.JWKS()
+}
+
+func newTokenId() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
 	}
 
-	return claims["sub"].(string), nil
+	return hex.EncodeToString(buf), nil
 }
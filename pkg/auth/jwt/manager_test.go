@@ -0,0 +1,273 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/magiconair/properties/assert"
+	"testing"
+	"time"
+)
+
+func testRSAKey(t *testing.T, id string, retiresAt time.Time) (Key, []byte) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Equal(t, err, nil)
+
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(private)})
+	publicDER, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	assert.Equal(t, err, nil)
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+
+	key, err := ParseKey(id, AlgorithmRS256, privatePEM, nil, retiresAt)
+	assert.Equal(t, err, nil)
+
+	return key, publicPEM
+}
+
+func testEdKey(t *testing.T, id string) (Key, []byte) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	assert.Equal(t, err, nil)
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	assert.Equal(t, err, nil)
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	assert.Equal(t, err, nil)
+
+	key, err := ParseKey(id, AlgorithmEdDSA, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), nil, time.Time{})
+	assert.Equal(t, err, nil)
+
+	return key, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+}
+
+func testManager(t *testing.T, ttl time.Duration, signingId string, keys ...Key) *Manager {
+	keySet, err := NewKeySet(signingId, keys...)
+	assert.Equal(t, err, nil)
+
+	return NewManager(ttl, keySet)
+}
+
+func TestManager_Parse(t *testing.T) {
+	m := testManager(t, time.Hour, "", NewHMACKey("", []byte("sign"), time.Time{}))
+
+	first, err := m.NewToken("userId", nil)
+	assert.Equal(t, err, nil)
+	second, err := m.NewToken("userId", nil)
+	assert.Equal(t, err, nil)
+
+	claims, err := m.Parse(first)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, claims.Subject, "userId")
+	assert.Equal(t, len(claims.Id), 32)
+	assert.Equal(t, claims.ExpiresAt.Sub(claims.IssuedAt), time.Hour)
+
+	other, err := m.Parse(second)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, claims.Id != other.Id, true)
+
+	_, err = testManager(t, time.Hour, "", NewHMACKey("", []byte("other sign"), time.Time{})).Parse(first)
+	assert.Equal(t, err != nil, true)
+
+	expired, err := testManager(t, -time.Minute, "", NewHMACKey("", []byte("sign"), time.Time{})).NewToken("userId", nil)
+	assert.Equal(t, err, nil)
+	_, err = m.Parse(expired)
+	assert.Equal(t, err != nil, true)
+}
+
+func TestManager_ParseRoles(t *testing.T) {
+	m := testManager(t, time.Hour, "", NewHMACKey("", []byte("sign"), time.Time{}))
+
+	admin, err := m.NewToken("userId", []string{"admin"})
+	assert.Equal(t, err, nil)
+	claims, err := m.Parse(admin)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, claims.Roles, []string{"admin"})
+
+	user, err := m.NewToken("userId", nil)
+	assert.Equal(t, err, nil)
+	claims, err = m.Parse(user)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(claims.Roles), 0)
+}
+
+func TestManager_ParseAudience(t *testing.T) {
+	m := testManager(t, time.Hour, "", NewHMACKey("", []byte("sign"), time.Time{}))
+
+	challenge, err := m.NewAudienceToken("userId", "mfa", time.Minute)
+	assert.Equal(t, err, nil)
+
+	claims, err := m.ParseAudience(challenge, "mfa")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, claims.Subject, "userId")
+	assert.Equal(t, claims.ExpiresAt.Sub(claims.IssuedAt), time.Minute)
+
+	_, err = m.Parse(challenge)
+	assert.Equal(t, err != nil, true)
+
+	_, err = m.ParseAudience(challenge, "other")
+	assert.Equal(t, err != nil, true)
+
+	access, err := m.NewToken("userId", nil)
+	assert.Equal(t, err, nil)
+
+	_, err = m.ParseAudience(access, "mfa")
+	assert.Equal(t, err != nil, true)
+
+	parsed, _ := jwt.Parse(access, nil)
+	assert.Equal(t, parsed.Header["typ"], "at+jwt")
+	assert.Equal(t, parsed.Claims.(jwt.MapClaims)["aud"], []interface{}{AccessAudience})
+
+	_, err = m.NewAudienceToken("userId", AccessAudience, time.Minute)
+	assert.Equal(t, err != nil, true)
+
+	// Tokens without an audience, such as the ones issued before it was set, are not access tokens.
+	unscoped := jwt.NewWithClaims(jwt.SigningMethodHS256, &jwt.RegisteredClaims{
+		Subject:   "userId",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	})
+	signed, err := unscoped.SignedString([]byte("sign"))
+	assert.Equal(t, err, nil)
+	_, err = m.Parse(signed)
+	assert.Equal(t, err != nil, true)
+}
+
+func TestManager_Rotation(t *testing.T) {
+	oldKey, oldPublicPEM := testRSAKey(t, "old", time.Time{})
+	newKey, _ := testEdKey(t, "new")
+	legacy := NewHMACKey("", []byte("sign"), time.Time{})
+
+	legacyToken, err := testManager(t, time.Hour, "", legacy).NewToken("userId", nil)
+	assert.Equal(t, err, nil)
+	oldToken, err := testManager(t, time.Hour, "old", oldKey).NewToken("userId", nil)
+	assert.Equal(t, err, nil)
+
+	parsed, _ := jwt.Parse(oldToken, nil)
+	assert.Equal(t, parsed.Header["kid"], "old")
+	assert.Equal(t, parsed.Header["alg"], AlgorithmRS256)
+
+	verifyOnly, err := ParseKey("old", AlgorithmRS256, nil, oldPublicPEM, time.Now().Add(time.Hour))
+	assert.Equal(t, err, nil)
+	retired, err := ParseKey("old", AlgorithmRS256, nil, oldPublicPEM, time.Now().Add(-time.Second))
+	assert.Equal(t, err, nil)
+
+	rotated := testManager(t, time.Hour, "new", newKey, verifyOnly, legacy)
+	newToken, err := rotated.NewToken("userId", nil)
+	assert.Equal(t, err, nil)
+
+	testCases := []struct {
+		name    string
+		manager *Manager
+		token   string
+		ok      bool
+	}{
+		{name: "New key", manager: rotated, token: newToken, ok: true},
+		{name: "Rotated out key", manager: rotated, token: oldToken, ok: true},
+		{name: "Legacy secret", manager: rotated, token: legacyToken, ok: true},
+		{name: "Retired key", manager: testManager(t, time.Hour, "new", newKey, retired), token: oldToken, ok: false},
+		{name: "Unknown key", manager: testManager(t, time.Hour, "new", newKey), token: oldToken, ok: false},
+		{name: "Without legacy secret", manager: testManager(t, time.Hour, "new", newKey), token: legacyToken, ok: false},
+		{
+			name:    "Retired legacy secret",
+			manager: testManager(t, time.Hour, "new", newKey, NewHMACKey("", []byte("sign"), time.Now().Add(-time.Second))),
+			token:   legacyToken,
+			ok:      false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			claims, err := testCase.manager.Parse(testCase.token)
+
+			assert.Equal(t, err == nil, testCase.ok)
+			if testCase.ok {
+				assert.Equal(t, claims.Subject, "userId")
+			}
+		})
+	}
+}
+
+func TestManager_ParseAlgorithmMismatch(t *testing.T) {
+	key, publicPEM := testRSAKey(t, "rsa", time.Time{})
+	m := testManager(t, time.Hour, "rsa", key)
+
+	// The public key is known to everyone, so it must not be accepted as an HMAC secret.
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &jwt.RegisteredClaims{
+		Subject:   "userId",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	})
+	token.Header["kid"] = "rsa"
+	forged, err := token.SignedString(publicPEM)
+	assert.Equal(t, err, nil)
+
+	_, err = m.Parse(forged)
+	assert.Equal(t, err != nil, true)
+}
+
+func TestKeySet_JWKS(t *testing.T) {
+	rsaKey, _ := testRSAKey(t, "rsa", time.Time{})
+	edKey, _ := testEdKey(t, "ed")
+	retired, _ := testRSAKey(t, "retired", time.Now().Add(-time.Second))
+
+	keySet, err := NewKeySet("ed", rsaKey, edKey, retired, NewHMACKey("", []byte("sign"), time.Time{}))
+	assert.Equal(t, err, nil)
+
+	jwks := keySet.JWKS()
+	assert.Equal(t, len(jwks.Keys), 2)
+	assert.Equal(t, jwks.Keys[0].Kid, "ed")
+	assert.Equal(t, jwks.Keys[0].Kty, "OKP")
+	assert.Equal(t, jwks.Keys[0].Crv, "Ed25519")
+	assert.Equal(t, jwks.Keys[0].Alg, AlgorithmEdDSA)
+	assert.Equal(t, len(jwks.Keys[0].X), 43)
+	assert.Equal(t, jwks.Keys[1].Kid, "rsa")
+	assert.Equal(t, jwks.Keys[1].Kty, "RSA")
+	assert.Equal(t, jwks.Keys[1].Alg, AlgorithmRS256)
+	assert.Equal(t, jwks.Keys[1].E, "AQAB")
+}
+
+func TestNewKeySet(t *testing.T) {
+	key, publicPEM := testRSAKey(t, "rsa", time.Time{})
+	verifyOnly, err := ParseKey("public", AlgorithmRS256, nil, publicPEM, time.Time{})
+	assert.Equal(t, err, nil)
+	retired, _ := testRSAKey(t, "retired", time.Now().Add(-time.Second))
+
+	testCases := []struct {
+		name      string
+		signingId string
+		keys      []Key
+		err       string
+	}{
+		{name: "OK", signingId: "rsa", keys: []Key{key, verifyOnly}},
+		{name: "Missing signing key", signingId: "other", keys: []Key{key}, err: `signing key "other" not found`},
+		{name: "Verify only signing key", signingId: "public", keys: []Key{verifyOnly}, err: `signing key "public" has no private key`},
+		{name: "Retired signing key", signingId: "retired", keys: []Key{retired}, err: `signing key "retired" is retired`},
+		{name: "Duplicate key", signingId: "rsa", keys: []Key{key, key}, err: `duplicate key "rsa"`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := NewKeySet(testCase.signingId, testCase.keys...)
+
+			if testCase.err == "" {
+				assert.Equal(t, err, nil)
+			} else {
+				assert.Equal(t, err.Error(), testCase.err)
+			}
+		})
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	_, err := ParseKey("", AlgorithmRS256, nil, []byte("key"), time.Time{})
+	assert.Equal(t, err.Error(), "asymmetric key has to have an id")
+
+	_, err = ParseKey("key", AlgorithmRS256, nil, nil, time.Time{})
+	assert.Equal(t, err.Error(), `key "key" has neither private nor public material`)
+
+	_, err = ParseKey("key", "ES256", nil, []byte("key"), time.Time{})
+	assert.Equal(t, err.Error(), `key "key" has unsupported algorithm "ES256"`)
+
+	_, err = ParseKey("key", AlgorithmEdDSA, nil, []byte("not a pem"), time.Time{})
+	assert.Equal(t, err != nil, true)
+}
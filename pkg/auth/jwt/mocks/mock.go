@@ -6,8 +6,10 @@ package mock_jwt
 
 import (
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
+	jwt "github.com/i-vasilkov/go-todo-app/pkg/auth/jwt"
 )
 
 // MockTokenManagerI is a mock of TokenManagerI interface.
@@ -33,26 +35,55 @@ func (m *MockTokenManagerI) EXPECT() *MockTokenManagerIMockRecorder {
 	return m.recorder
 }
 
+// JWKS mocks base method.
+func (m *MockTokenManagerI) JWKS() jwt.JWKS {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JWKS")
+	ret0, _ := ret[0].(jwt.JWKS)
+	return ret0
+}
+
+// JWKS indicates an expected call of JWKS.
+func (mr *MockTokenManagerIMockRecorder) JWKS() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JWKS", reflect.TypeOf((*MockTokenManagerI)(nil).JWKS))
+}
+
+// NewAudienceToken mocks base method.
+func (m *MockTokenManagerI) NewAudienceToken(id, audience string, ttl time.Duration) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewAudienceToken", id, audience, ttl)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewAudienceToken indicates an expected call of NewAudienceToken.
+func (mr *MockTokenManagerIMockRecorder) NewAudienceToken(id, audience, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewAudienceToken", reflect.TypeOf((*MockTokenManagerI)(nil).NewAudienceToken), id, audience, ttl)
+}
+
 // NewToken mocks base method.
-func (m *MockTokenManagerI) NewToken(id string) (string, error) {
+func (m *MockTokenManagerI) NewToken(id string, roles []string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "NewToken", id)
+	ret := m.ctrl.Call(m, "NewToken", id, roles)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // NewToken indicates an expected call of NewToken.
-func (mr *MockTokenManagerIMockRecorder) NewToken(id interface{}) *gomock.Call {
+func (mr *MockTokenManagerIMockRecorder) NewToken(id, roles interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewToken", reflect.TypeOf((*MockTokenManagerI)(nil).NewToken), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewToken", reflect.TypeOf((*MockTokenManagerI)(nil).NewToken), id, roles)
 }
 
 // Parse mocks base method.
-func (m *MockTokenManagerI) Parse(token string) (string, error) {
+func (m *MockTokenManagerI) Parse(token string) (jwt.Claims, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Parse", token)
-	ret0, _ := ret[0].(string)
+	ret0, _ := ret[0].(jwt.Claims)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -62,3 +93,18 @@ func (mr *MockTokenManagerIMockRecorder) Parse(token interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Parse", reflect.TypeOf((*MockTokenManagerI)(nil).Parse), token)
 }
+
+// ParseAudience mocks base method.
+func (m *MockTokenManagerI) ParseAudience(token, audience string) (jwt.Claims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ParseAudience", token, audience)
+	ret0, _ := ret[0].(jwt.Claims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ParseAudience indicates an expected call of ParseAudience.
+func (mr *MockTokenManagerIMockRecorder) ParseAudience(token, audience interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseAudience", reflect.TypeOf((*MockTokenManagerI)(nil).ParseAudience), token, audience)
+}
@@ -0,0 +1,8 @@
+package opaque
+
+//go:generate mockgen -source=interface.go -destination=mocks/mock.go
+
+type TokenManagerI interface {
+	NewToken() (string, error)
+	Hash(token string) string
+}
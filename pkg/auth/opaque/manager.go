@@ -0,0 +1,35 @@
+package opaque
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// DefaultTokenSize is the number of random bytes in a token.
+const DefaultTokenSize = 32
+
+// Manager issues random tokens that carry no data and have to be looked up by their hash.
+// The tokens are long enough to make a fast unsalted digest safe to store.
+type Manager struct {
+	size int
+}
+
+func NewManager(size int) *Manager {
+	return &Manager{size: size}
+}
+
+func (m *Manager) NewToken() (string, error) {
+	buf := make([]byte, m.size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func (m *Manager) Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
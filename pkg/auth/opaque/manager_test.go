@@ -0,0 +1,26 @@
+package opaque
+
+import (
+	"github.com/magiconair/properties/assert"
+	"testing"
+)
+
+func TestManager_NewToken(t *testing.T) {
+	m := NewManager(DefaultTokenSize)
+
+	first, err := m.NewToken()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(first), 43)
+
+	second, err := m.NewToken()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, first != second, true)
+}
+
+func TestManager_Hash(t *testing.T) {
+	m := NewManager(DefaultTokenSize)
+
+	assert.Equal(t, m.Hash("token"), "3c469e9d6c5875d37a43f353d4f88e61fcf812c66eee3457465a40b0da4153e0")
+	assert.Equal(t, m.Hash("token"), m.Hash("token"))
+	assert.Equal(t, m.Hash("token") != m.Hash("other"), true)
+}
@@ -0,0 +1,63 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: interface.go
+
+// Package mock_opaque is a generated GoMock package.
+package mock_opaque
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockTokenManagerI is a mock of TokenManagerI interface.
+type MockTokenManagerI struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenManagerIMockRecorder
+}
+
+// MockTokenManagerIMockRecorder is the mock recorder for MockTokenManagerI.
+type MockTokenManagerIMockRecorder struct {
+	mock *MockTokenManagerI
+}
+
+// NewMockTokenManagerI creates a new mock instance.
+func NewMockTokenManagerI(ctrl *gomock.Controller) *MockTokenManagerI {
+	mock := &MockTokenManagerI{ctrl: ctrl}
+	mock.recorder = &MockTokenManagerIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenManagerI) EXPECT() *MockTokenManagerIMockRecorder {
+	return m.recorder
+}
+
+// Hash mocks base method.
+func (m *MockTokenManagerI) Hash(token string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Hash", token)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Hash indicates an expected call of Hash.
+func (mr *MockTokenManagerIMockRecorder) Hash(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hash", reflect.TypeOf((*MockTokenManagerI)(nil).Hash), token)
+}
+
+// NewToken mocks base method.
+func (m *MockTokenManagerI) NewToken() (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewToken")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewToken indicates an expected call of NewToken.
+func (mr *MockTokenManagerIMockRecorder) NewToken() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewToken", reflect.TypeOf((*MockTokenManagerI)(nil).NewToken))
+}
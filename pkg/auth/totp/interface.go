@@ -0,0 +1,11 @@
+package totp
+
+//go:generate mockgen -source=interface.go -destination=mocks/mock.go
+
+type ManagerI interface {
+	NewSecret() (string, error)
+	ProvisioningURI(account, secret string) string
+	Validate(code, secret string) (int64, bool)
+	NewRecoveryCodes() ([]string, error)
+	HashRecoveryCode(code string) string
+}
@@ -0,0 +1,141 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// Period, Digits and the SHA-1 digest are the RFC 6238 defaults every authenticator app supports.
+	Period = 30 * time.Second
+	Digits = 6
+
+	// Skew is the number of periods a code is still accepted for on either side of the current
+	// one, to tolerate clock drift and slow typing.
+	Skew = 1
+
+	secretSize = 20
+
+	// RecoveryCodesCount recovery codes of recoveryCodeSize random bytes are issued at once.
+	RecoveryCodesCount = 10
+	recoveryCodeSize   = 10
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Manager generates and checks RFC 6238 time-based one-time passwords and the recovery codes
+// that replace them when the authenticator is lost.
+type Manager struct {
+	issuer string
+}
+
+func NewManager(issuer string) *Manager {
+	return &Manager{issuer: issuer}
+}
+
+// NewSecret returns a random base32 encoded secret shared with the authenticator app.
+func (m *Manager) NewSecret() (string, error) {
+	buf := make([]byte, secretSize)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return encoding.EncodeToString(buf), nil
+}
+
+// ProvisioningURI returns the otpauth:// URI authenticator apps import, usually from a QR code.
+func (m *Manager) ProvisioningURI(account, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", m.issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(Digits))
+	query.Set("period", fmt.Sprint(int(Period.Seconds())))
+
+	label := url.PathEscape(m.issuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// Validate checks the code and returns the time step it was generated for. A code stays valid
+// for the skew periods, so the caller has to remember the step and reject codes of steps at or
+// before it, or the code could be replayed.
+func (m *Manager) Validate(code, secret string) (int64, bool) {
+	return validate(code, secret, time.Now())
+}
+
+func validate(code, secret string, now time.Time) (int64, bool) {
+	if len(code) != Digits {
+		return 0, false
+	}
+
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return 0, false
+	}
+
+	counter := now.Unix() / int64(Period.Seconds())
+	for i := -Skew; i <= Skew; i++ {
+		step := counter + int64(i)
+		expected := generate(key, uint64(step))
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// generate computes the HOTP value (RFC 4226) for the counter.
+func generate(key []byte, counter uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < Digits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", Digits, value%mod)
+}
+
+// NewRecoveryCodes returns single use codes formatted as xxxx-xxxx-xxxx-xxxx. They are random
+// enough for HashRecoveryCode to store them with a fast digest.
+func (m *Manager) NewRecoveryCodes() ([]string, error) {
+	codes := make([]string, 0, RecoveryCodesCount)
+	for i := 0; i < RecoveryCodesCount; i++ {
+		buf := make([]byte, recoveryCodeSize)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+
+		raw := strings.ToLower(encoding.EncodeToString(buf))
+		codes = append(codes, raw[0:4]+"-"+raw[4:8]+"-"+raw[8:12]+"-"+raw[12:16])
+	}
+
+	return codes, nil
+}
+
+// HashRecoveryCode ignores case, dashes and spaces, so codes can be typed the way they are read.
+func (m *Manager) HashRecoveryCode(code string) string {
+	normalized := strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(code))
+
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
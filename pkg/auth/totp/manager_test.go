@@ -0,0 +1,110 @@
+package totp
+
+import (
+	"github.com/magiconair/properties/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 seed of the RFC 6238 test vectors.
+var rfcSecret = encoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestGenerate(t *testing.T) {
+	key := []byte("12345678901234567890")
+
+	// The RFC 6238 vectors truncated to six digits.
+	testCases := []struct {
+		unix int64
+		code string
+	}{
+		{unix: 59, code: "287082"},
+		{unix: 1111111109, code: "081804"},
+		{unix: 1111111111, code: "050471"},
+		{unix: 1234567890, code: "005924"},
+		{unix: 2000000000, code: "279037"},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, generate(key, uint64(testCase.unix/30)), testCase.code)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+
+	testCases := []struct {
+		name   string
+		code   string
+		secret string
+		now    time.Time
+		valid  bool
+	}{
+		{name: "Current period", code: "081804", secret: rfcSecret, now: now, valid: true},
+		{name: "Previous period", code: "081804", secret: rfcSecret, now: now.Add(Period), valid: true},
+		{name: "Next period", code: "081804", secret: rfcSecret, now: now.Add(-Period), valid: true},
+		{name: "Too old", code: "081804", secret: rfcSecret, now: now.Add(2 * Period), valid: false},
+		{name: "Lowercase secret", code: "081804", secret: strings.ToLower(rfcSecret), now: now, valid: true},
+		{name: "Wrong code", code: "081805", secret: rfcSecret, now: now, valid: false},
+		{name: "Wrong length", code: "81804", secret: rfcSecret, now: now, valid: false},
+		{name: "Invalid secret", code: "081804", secret: "not base32!", now: now, valid: false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			step, valid := validate(testCase.code, testCase.secret, testCase.now)
+			assert.Equal(t, valid, testCase.valid)
+			if testCase.valid {
+				assert.Equal(t, step, now.Unix()/30)
+			}
+		})
+	}
+}
+
+func TestManager_NewSecret(t *testing.T) {
+	m := NewManager("ToDoApp")
+
+	secret, err := m.NewSecret()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(secret), 32)
+
+	step := time.Now().Unix() / 30
+	code := generate(mustDecode(t, secret), uint64(step))
+	validStep, valid := m.Validate(code, secret)
+	assert.Equal(t, valid, true)
+	assert.Equal(t, validStep == step || validStep == step+1, true)
+}
+
+func TestManager_ProvisioningURI(t *testing.T) {
+	m := NewManager("ToDo App")
+
+	assert.Equal(
+		t,
+		m.ProvisioningURI("john", "JBSWY3DPEHPK3PXP"),
+		"otpauth://totp/ToDo%20App:john?algorithm=SHA1&digits=6&issuer=ToDo+App&period=30&secret=JBSWY3DPEHPK3PXP",
+	)
+}
+
+func TestManager_RecoveryCodes(t *testing.T) {
+	m := NewManager("ToDoApp")
+
+	codes, err := m.NewRecoveryCodes()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(codes), RecoveryCodesCount)
+	assert.Equal(t, len(codes[0]), 19)
+	assert.Equal(t, codes[0] != codes[1], true)
+
+	hash := m.HashRecoveryCode(codes[0])
+	assert.Equal(t, m.HashRecoveryCode(strings.ToUpper(codes[0])), hash)
+	assert.Equal(t, m.HashRecoveryCode(strings.ReplaceAll(codes[0], "-", "")), hash)
+	assert.Equal(t, m.HashRecoveryCode(codes[1]) != hash, true)
+}
+
+func mustDecode(t *testing.T, secret string) []byte {
+	key, err := encoding.DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
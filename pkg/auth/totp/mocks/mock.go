@@ -0,0 +1,107 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: interface.go
+
+// Package mock_totp is a generated GoMock package.
+package mock_totp
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockManagerI is a mock of ManagerI interface.
+type MockManagerI struct {
+	ctrl     *gomock.Controller
+	recorder *MockManagerIMockRecorder
+}
+
+// MockManagerIMockRecorder is the mock recorder for MockManagerI.
+type MockManagerIMockRecorder struct {
+	mock *MockManagerI
+}
+
+// NewMockManagerI creates a new mock instance.
+func NewMockManagerI(ctrl *gomock.Controller) *MockManagerI {
+	mock := &MockManagerI{ctrl: ctrl}
+	mock.recorder = &MockManagerIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockManagerI) EXPECT() *MockManagerIMockRecorder {
+	return m.recorder
+}
+
+// HashRecoveryCode mocks base method.
+func (m *MockManagerI) HashRecoveryCode(code string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HashRecoveryCode", code)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// HashRecoveryCode indicates an expected call of HashRecoveryCode.
+func (mr *MockManagerIMockRecorder) HashRecoveryCode(code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HashRecoveryCode", reflect.TypeOf((*MockManagerI)(nil).HashRecoveryCode), code)
+}
+
+// NewRecoveryCodes mocks base method.
+func (m *MockManagerI) NewRecoveryCodes() ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewRecoveryCodes")
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewRecoveryCodes indicates an expected call of NewRecoveryCodes.
+func (mr *MockManagerIMockRecorder) NewRecoveryCodes() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewRecoveryCodes", reflect.TypeOf((*MockManagerI)(nil).NewRecoveryCodes))
+}
+
+// NewSecret mocks base method.
+func (m *MockManagerI) NewSecret() (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewSecret")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewSecret indicates an expected call of NewSecret.
+func (mr *MockManagerIMockRecorder) NewSecret() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewSecret", reflect.TypeOf((*MockManagerI)(nil).NewSecret))
+}
+
+// ProvisioningURI mocks base method.
+func (m *MockManagerI) ProvisioningURI(account, secret string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProvisioningURI", account, secret)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// ProvisioningURI indicates an expected call of ProvisioningURI.
+func (mr *MockManagerIMockRecorder) ProvisioningURI(account, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProvisioningURI", reflect.TypeOf((*MockManagerI)(nil).ProvisioningURI), account, secret)
+}
+
+// Validate mocks base method.
+func (m *MockManagerI) Validate(code, secret string) (int64, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Validate", code, secret)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Validate indicates an expected call of Validate.
+func (mr *MockManagerIMockRecorder) Validate(code, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockManagerI)(nil).Validate), code, secret)
+}
@@ -0,0 +1,79 @@
+package hash
+
+import (
+	"github.com/magiconair/properties/assert"
+	"strings"
+	"testing"
+)
+
+var testParams = Argon2idParams{Memory: 1024, Time: 1, Threads: 1, SaltLen: 16, KeyLen: 32}
+
+func TestArgon2idHasher_Verify(t *testing.T) {
+	legacy := NewSHA1Hasher("salt")
+	legacyHash, _ := legacy.Hash("password")
+
+	hasher := NewArgon2idHasher(testParams, legacy)
+	hash, err := hasher.Hash("password")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"), true)
+
+	testCases := []struct {
+		name     string
+		hasher   *Argon2idHasher
+		password string
+		hash     string
+		ok       bool
+		err      error
+	}{
+		{name: "Match", hasher: hasher, password: "password", hash: hash, ok: true},
+		{name: "Wrong password", hasher: hasher, password: "wrong", hash: hash, ok: false},
+		{name: "Legacy match", hasher: hasher, password: "password", hash: legacyHash, ok: true},
+		{name: "Legacy wrong password", hasher: hasher, password: "wrong", hash: legacyHash, ok: false},
+		{name: "Legacy without legacy hasher", hasher: NewArgon2idHasher(testParams, nil), password: "password", hash: legacyHash, err: ErrInvalidHash},
+		{name: "Malformed", hasher: hasher, password: "password", hash: "$argon2id$v=19$m=1024$salt$key", err: ErrInvalidHash},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ok, err := testCase.hasher.Verify(testCase.password, testCase.hash)
+
+			assert.Equal(t, ok, testCase.ok)
+			assert.Equal(t, err, testCase.err)
+		})
+	}
+}
+
+func TestArgon2idHasher_Hash(t *testing.T) {
+	hasher := NewArgon2idHasher(testParams, nil)
+
+	first, _ := hasher.Hash("password")
+	second, _ := hasher.Hash("password")
+
+	assert.Equal(t, first == second, false)
+}
+
+func TestArgon2idHasher_NeedsRehash(t *testing.T) {
+	hasher := NewArgon2idHasher(testParams, nil)
+	hash, _ := hasher.Hash("password")
+
+	stronger := testParams
+	stronger.Time = 2
+	legacyHash, _ := NewSHA1Hasher("salt").Hash("password")
+
+	testCases := []struct {
+		name   string
+		hasher *Argon2idHasher
+		hash   string
+		rehash bool
+	}{
+		{name: "Current parameters", hasher: hasher, hash: hash, rehash: false},
+		{name: "Changed parameters", hasher: NewArgon2idHasher(stronger, nil), hash: hash, rehash: true},
+		{name: "Legacy hash", hasher: hasher, hash: legacyHash, rehash: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.hasher.NeedsRehash(testCase.hash), testCase.rehash)
+		})
+	}
+}
@@ -3,5 +3,11 @@ package hash
 //go:generate mockgen -source=interface.go -destination=mocks/mock.go
 
 type Hasher interface {
-	Hash(string) (string, error)
-}
\ No newline at end of file
+	// Hash returns an encoded hash of the password that Verify accepts.
+	Hash(password string) (string, error)
+	// Verify reports whether the password matches the encoded hash.
+	Verify(password, hash string) (bool, error)
+	// NeedsRehash reports whether the hash was produced by an outdated scheme or
+	// parameters and should be replaced with a fresh Hash of the password.
+	NeedsRehash(hash string) bool
+}
@@ -2,9 +2,12 @@ package hash
 
 import (
 	"crypto/sha1"
+	"crypto/subtle"
 	"fmt"
 )
 
+// SHA1Hasher is the legacy hasher. Its salt is only prepended to the encoded digest,
+// so it is kept solely to verify hashes created before Argon2idHasher was introduced.
 type SHA1Hasher struct {
 	salt string
 }
@@ -22,3 +25,16 @@ func (h *SHA1Hasher) Hash(str string) (string, error) {
 
 	return fmt.Sprintf("%x", hash.Sum([]byte(h.salt))), nil
 }
+
+func (h *SHA1Hasher) Verify(password, hash string) (bool, error) {
+	expected, err := h.Hash(password)
+	if err != nil {
+		return false, err
+	}
+
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(hash)) == 1, nil
+}
+
+func (h *SHA1Hasher) NeedsRehash(hash string) bool {
+	return false
+}
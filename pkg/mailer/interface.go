@@ -0,0 +1,9 @@
+package mailer
+
+import "context"
+
+//go:generate mockgen -source=interface.go -destination=mocks/mock.go
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
@@ -0,0 +1,28 @@
+package mailer
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// LogMailer prints the messages to the log instead of sending them. The messages carry
+// password reset links, so it is meant for local development only.
+type LogMailer struct {
+	logger *log.Logger
+	from   string
+}
+
+func NewLogMailer(logger *log.Logger, from string) *LogMailer {
+	return &LogMailer{logger: logger, from: from}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	data, err := msg.Bytes(m.from, time.Now())
+	if err != nil {
+		return err
+	}
+
+	m.logger.Printf("mail to %s:\n%s", msg.To, data)
+	return nil
+}
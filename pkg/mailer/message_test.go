@@ -0,0 +1,79 @@
+package mailer
+
+import (
+	"context"
+	"github.com/magiconair/properties/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMessage_Bytes(t *testing.T) {
+	date := time.Date(2021, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name    string
+		msg     Message
+		want    string
+		wantErr error
+	}{
+		{
+			name: "OK",
+			msg:  Message{To: "user@example.com", Subject: "Hello", Body: "line 1\nline 2"},
+			want: "From: ToDoApp <noreply@example.com>\r\n" +
+				"To: <user@example.com>\r\n" +
+				"Subject: Hello\r\n" +
+				"Date: Mon, 18 Oct 2021 12:00:00 +0000\r\n" +
+				"MIME-Version: 1.0\r\n" +
+				"Content-Type: text/plain; charset=utf-8\r\n" +
+				"Content-Transfer-Encoding: 8bit\r\n" +
+				"\r\n" +
+				"line 1\r\nline 2",
+		},
+		{
+			name: "Header injection",
+			msg:  Message{To: "user@example.com", Subject: "Hello\r\nBcc: other@example.com"},
+			want: "Subject: Hello Bcc: other@example.com\r\n",
+		},
+		{
+			name: "Non-ASCII subject",
+			msg:  Message{To: "user@example.com", Subject: "Привет"},
+			want: "Subject: =?utf-8?q?=D0=9F=D1=80=D0=B8=D0=B2=D0=B5=D1=82?=\r\n",
+		},
+		{
+			name:    "Invalid recipient",
+			msg:     Message{To: "user@example.com\r\nBcc: other@example.com"},
+			wantErr: ErrInvalidRecipient,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			data, err := testCase.msg.Bytes("ToDoApp <noreply@example.com>", date)
+
+			assert.Equal(t, err, testCase.wantErr)
+			if testCase.wantErr == nil {
+				assert.Equal(t, strings.Contains(string(data), testCase.want), true)
+			}
+		})
+	}
+}
+
+func TestFileMailer_Send(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	m := NewFileMailer(dir, "noreply@example.com")
+
+	err := m.Send(context.Background(), Message{To: "user@example.com", Subject: "Hello", Body: "body"})
+	assert.Equal(t, err, nil)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(files), 1)
+
+	data, err := os.ReadFile(files[0])
+	assert.Equal(t, err, nil)
+	assert.Equal(t, strings.HasPrefix(string(data), "From: noreply@example.com\r\nTo: <user@example.com>\r\n"), true)
+	assert.Equal(t, strings.HasSuffix(string(data), "\r\n\r\nbody"), true)
+}
@@ -0,0 +1,39 @@
+package markdown
+
+import (
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/russross/blackfriday/v2"
+	"strings"
+	"unicode"
+)
+
+// policy allows the elements Markdown produces and drops scripts, event handlers,
+// styles and unsafe link schemes, including those written as raw HTML in the source.
+var policy = bluemonday.UGCPolicy()
+
+// Sanitize normalizes Markdown source before it is stored: line endings become \n,
+// control characters other than tabs and newlines are removed and surrounding
+// blank space is trimmed.
+func Sanitize(src string) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.ReplaceAll(src, "\r", "\n")
+
+	src = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' || !unicode.IsControl(r) {
+			return r
+		}
+		return -1
+	}, src)
+
+	return strings.TrimSpace(src)
+}
+
+// ToHTML renders Markdown source to HTML that is safe to embed in a page.
+func ToHTML(src string) string {
+	if src == "" {
+		return ""
+	}
+
+	html := blackfriday.Run([]byte(src))
+	return string(policy.SanitizeBytes(html))
+}
@@ -0,0 +1,46 @@
+package markdown
+
+import (
+	"github.com/magiconair/properties/assert"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "Unchanged", input: "# Title\n\n- item", want: "# Title\n\n- item"},
+		{name: "Windows line endings", input: "a\r\nb\rc", want: "a\nb\nc"},
+		{name: "Control characters", input: "a\x00b\x1bc\td", want: "abc\td"},
+		{name: "Surrounding space", input: "\n\n  text \n", want: "text"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, Sanitize(testCase.input), testCase.want)
+		})
+	}
+}
+
+func TestToHTML(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "Empty", input: "", want: ""},
+		{name: "Emphasis", input: "some **bold** text", want: "<p>some <strong>bold</strong> text</p>\n"},
+		{name: "Link", input: "[site](https://example.com)", want: "<p><a href=\"https://example.com\" rel=\"nofollow\">site</a></p>\n"},
+		{name: "Script", input: "hi <script>alert(1)</script>", want: "<p>hi </p>\n"},
+		{name: "Javascript link", input: "[x](javascript:alert)", want: "<p>x</p>\n"},
+		{name: "Event handler", input: "<img src=\"a.png\" onerror=\"alert(1)\">", want: "<p><img src=\"a.png\"></p>\n"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, ToHTML(testCase.input), testCase.want)
+		})
+	}
+}
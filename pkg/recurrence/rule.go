@@ -0,0 +1,223 @@
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+)
+
+// Basis tells what the next occurrence is counted from.
+type Basis string
+
+const (
+	// BasisSchedule keeps occurrences on the schedule, counting from the previous due date.
+	BasisSchedule Basis = "SCHEDULE"
+	// BasisCompletion counts the interval from the moment the task was completed.
+	BasisCompletion Basis = "COMPLETION"
+)
+
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Rule is a subset of the RFC 5545 RRULE, written as semicolon separated KEY=VALUE pairs:
+//
+//	FREQ=DAILY;INTERVAL=2                  every second day
+//	FREQ=WEEKLY;BYDAY=MO,WE                every Monday and Wednesday
+//	FREQ=MONTHLY;BYMONTHDAY=15             on the 15th of every month
+//	FREQ=DAILY;INTERVAL=3;BASIS=COMPLETION three days after the task was completed
+//
+// BASIS is not part of RFC 5545.
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay int
+	Basis      Basis
+}
+
+// Parse reads a rule in the format described on Rule.
+func Parse(s string) (Rule, error) {
+	rule := Rule{Interval: 1, Basis: BasisSchedule}
+
+	for _, part := range strings.Split(strings.TrimPrefix(strings.TrimSpace(s), "RRULE:"), ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return Rule{}, fmt.Errorf("%w: %q is not a KEY=VALUE pair", ErrInvalidRule, part)
+		}
+		key, value := strings.ToUpper(strings.TrimSpace(kv[0])), strings.ToUpper(strings.TrimSpace(kv[1]))
+
+		switch key {
+		case "FREQ":
+			rule.Freq = Frequency(value)
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 {
+				return Rule{}, fmt.Errorf("%w: INTERVAL must be a positive number", ErrInvalidRule)
+			}
+			rule.Interval = interval
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, ok := weekdays[day]
+				if !ok {
+					return Rule{}, fmt.Errorf("%w: unknown weekday %q", ErrInvalidRule, day)
+				}
+				rule.ByDay = append(rule.ByDay, weekday)
+			}
+		case "BYMONTHDAY":
+			day, err := strconv.Atoi(value)
+			if err != nil || day < 1 || day > 31 {
+				return Rule{}, fmt.Errorf("%w: BYMONTHDAY must be between 1 and 31", ErrInvalidRule)
+			}
+			rule.ByMonthDay = day
+		case "BASIS":
+			rule.Basis = Basis(value)
+		default:
+			return Rule{}, fmt.Errorf("%w: unsupported key %q", ErrInvalidRule, key)
+		}
+	}
+
+	if err := rule.validate(); err != nil {
+		return Rule{}, err
+	}
+
+	sort.Slice(rule.ByDay, func(i, j int) bool { return weekdayIndex(rule.ByDay[i]) < weekdayIndex(rule.ByDay[j]) })
+	return rule, nil
+}
+
+func (r Rule) validate() error {
+	switch r.Freq {
+	case Daily, Weekly, Monthly:
+	case "":
+		return fmt.Errorf("%w: FREQ is required", ErrInvalidRule)
+	default:
+		return fmt.Errorf("%w: unsupported FREQ %q", ErrInvalidRule, r.Freq)
+	}
+
+	if r.Basis != BasisSchedule && r.Basis != BasisCompletion {
+		return fmt.Errorf("%w: unsupported BASIS %q", ErrInvalidRule, r.Basis)
+	}
+	if len(r.ByDay) > 0 && r.Freq != Weekly {
+		return fmt.Errorf("%w: BYDAY is only supported with FREQ=WEEKLY", ErrInvalidRule)
+	}
+	if r.ByMonthDay > 0 && r.Freq != Monthly {
+		return fmt.Errorf("%w: BYMONTHDAY is only supported with FREQ=MONTHLY", ErrInvalidRule)
+	}
+	if r.Basis == BasisCompletion && (len(r.ByDay) > 0 || r.ByMonthDay > 0) {
+		return fmt.Errorf("%w: BASIS=COMPLETION can not be combined with BYDAY or BYMONTHDAY", ErrInvalidRule)
+	}
+	return nil
+}
+
+// Next returns the first occurrence strictly after t. The time of day of t is kept,
+// and weekdays and month days are evaluated in the location of t.
+func (r Rule) Next(t time.Time) time.Time {
+	switch r.Freq {
+	case Weekly:
+		if len(r.ByDay) == 0 {
+			return t.AddDate(0, 0, 7*r.Interval)
+		}
+		return r.nextWeekday(t)
+	case Monthly:
+		if r.ByMonthDay == 0 {
+			return addMonths(t, r.Interval, t.Day())
+		}
+		return r.nextMonthDay(t)
+	default:
+		return t.AddDate(0, 0, r.Interval)
+	}
+}
+
+// NextAfter returns the next occurrence of a task that was due at due and completed at
+// completedAt. Schedule based rules skip occurrences that would already be in the past
+// at completion; completion based rules, or tasks without a due date, count from completedAt.
+func (r Rule) NextAfter(due *time.Time, completedAt time.Time) time.Time {
+	if due == nil || r.Basis == BasisCompletion {
+		return r.Next(completedAt)
+	}
+
+	next := r.Next(*due)
+	for !next.After(completedAt) {
+		next = r.Next(next)
+	}
+	return next
+}
+
+// String formats the rule in the canonical form accepted by Parse.
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, weekday := range r.ByDay {
+			days = append(days, strings.ToUpper(weekday.String()[:2]))
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.ByMonthDay > 0 {
+		parts = append(parts, "BYMONTHDAY="+strconv.Itoa(r.ByMonthDay))
+	}
+	if r.Basis == BasisCompletion {
+		parts = append(parts, "BASIS="+string(r.Basis))
+	}
+	return strings.Join(parts, ";")
+}
+
+// nextWeekday looks for the next listed weekday in the week of t and otherwise
+// jumps Interval weeks ahead to the first listed weekday. Weeks start on Monday.
+func (r Rule) nextWeekday(t time.Time) time.Time {
+	current := weekdayIndex(t.Weekday())
+	for _, weekday := range r.ByDay {
+		if index := weekdayIndex(weekday); index > current {
+			return t.AddDate(0, 0, index-current)
+		}
+	}
+
+	weekStart := t.AddDate(0, 0, -current)
+	return weekStart.AddDate(0, 0, 7*r.Interval+weekdayIndex(r.ByDay[0]))
+}
+
+// nextMonthDay returns ByMonthDay of the month of t when it is still ahead and
+// otherwise the same day Interval months later. Short months use their last day.
+func (r Rule) nextMonthDay(t time.Time) time.Time {
+	candidate := addMonths(t, 0, r.ByMonthDay)
+	if candidate.After(t) {
+		return candidate
+	}
+	return addMonths(t, r.Interval, r.ByMonthDay)
+}
+
+// addMonths moves t by the given number of months to the given day, clamped to the month length.
+func addMonths(t time.Time, months, day int) time.Time {
+	firstOfMonth := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+
+	if last := firstOfMonth.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return firstOfMonth.AddDate(0, 0, day-1)
+}
+
+func weekdayIndex(weekday time.Weekday) int {
+	return (int(weekday) + 6) % 7
+}
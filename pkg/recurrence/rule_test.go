@@ -0,0 +1,146 @@
+package recurrence
+
+import (
+	"errors"
+	"github.com/magiconair/properties/assert"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		rule  string
+		err   error
+	}{
+		{name: "Daily", input: "FREQ=DAILY", rule: "FREQ=DAILY"},
+		{name: "Lower case with prefix", input: "RRULE:freq=daily;interval=2", rule: "FREQ=DAILY;INTERVAL=2"},
+		{name: "Weekly by day", input: "FREQ=WEEKLY;BYDAY=FR,MO", rule: "FREQ=WEEKLY;BYDAY=MO,FR"},
+		{name: "Monthly by month day", input: "FREQ=MONTHLY;BYMONTHDAY=31", rule: "FREQ=MONTHLY;BYMONTHDAY=31"},
+		{name: "After completion", input: "FREQ=DAILY;INTERVAL=3;BASIS=COMPLETION", rule: "FREQ=DAILY;INTERVAL=3;BASIS=COMPLETION"},
+		{name: "Empty", input: "", err: ErrInvalidRule},
+		{name: "Missing freq", input: "INTERVAL=2", err: ErrInvalidRule},
+		{name: "Unknown freq", input: "FREQ=YEARLY", err: ErrInvalidRule},
+		{name: "Zero interval", input: "FREQ=DAILY;INTERVAL=0", err: ErrInvalidRule},
+		{name: "Unknown weekday", input: "FREQ=WEEKLY;BYDAY=XX", err: ErrInvalidRule},
+		{name: "By day with daily", input: "FREQ=DAILY;BYDAY=MO", err: ErrInvalidRule},
+		{name: "Month day out of range", input: "FREQ=MONTHLY;BYMONTHDAY=32", err: ErrInvalidRule},
+		{name: "Completion with by day", input: "FREQ=WEEKLY;BYDAY=MO;BASIS=COMPLETION", err: ErrInvalidRule},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			rule, err := Parse(testCase.input)
+
+			assert.Equal(t, errors.Is(err, testCase.err), true)
+			if testCase.err == nil {
+				assert.Equal(t, rule.String(), testCase.rule)
+			}
+		})
+	}
+}
+
+func TestRule_Next(t *testing.T) {
+	// 2021-03-03 is a Wednesday.
+	wednesday := time.Date(2021, 3, 3, 9, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name string
+		rule string
+		from time.Time
+		next time.Time
+	}{
+		{
+			name: "Every second day",
+			rule: "FREQ=DAILY;INTERVAL=2",
+			from: wednesday,
+			next: time.Date(2021, 3, 5, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "Weekly",
+			rule: "FREQ=WEEKLY",
+			from: wednesday,
+			next: time.Date(2021, 3, 10, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "Later weekday in the same week",
+			rule: "FREQ=WEEKLY;BYDAY=MO,FR",
+			from: wednesday,
+			next: time.Date(2021, 3, 5, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "First weekday of the next week",
+			rule: "FREQ=WEEKLY;BYDAY=MO,WE",
+			from: wednesday,
+			next: time.Date(2021, 3, 8, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "First weekday two weeks later",
+			rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO",
+			from: wednesday,
+			next: time.Date(2021, 3, 15, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "Month day later in the same month",
+			rule: "FREQ=MONTHLY;BYMONTHDAY=15",
+			from: wednesday,
+			next: time.Date(2021, 3, 15, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "Month day clamped to a short month",
+			rule: "FREQ=MONTHLY;BYMONTHDAY=31",
+			from: time.Date(2021, 1, 31, 9, 30, 0, 0, time.UTC),
+			next: time.Date(2021, 2, 28, 9, 30, 0, 0, time.UTC),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			rule, err := Parse(testCase.rule)
+			assert.Equal(t, err, nil)
+
+			assert.Equal(t, rule.Next(testCase.from), testCase.next)
+		})
+	}
+}
+
+func TestRule_NextAfter(t *testing.T) {
+	due := time.Date(2021, 3, 1, 9, 0, 0, 0, time.UTC)
+	completedAt := time.Date(2021, 3, 4, 18, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name string
+		rule string
+		due  *time.Time
+		next time.Time
+	}{
+		{
+			name: "Skips occurrences in the past",
+			rule: "FREQ=DAILY",
+			due:  &due,
+			next: time.Date(2021, 3, 5, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "Counts from completion",
+			rule: "FREQ=DAILY;INTERVAL=3;BASIS=COMPLETION",
+			due:  &due,
+			next: time.Date(2021, 3, 7, 18, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "Without due date",
+			rule: "FREQ=WEEKLY",
+			due:  nil,
+			next: time.Date(2021, 3, 11, 18, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			rule, err := Parse(testCase.rule)
+			assert.Equal(t, err, nil)
+
+			assert.Equal(t, rule.NextAfter(testCase.due, completedAt), testCase.next)
+		})
+	}
+}